@@ -3,7 +3,9 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,6 +16,7 @@ import (
 	"github.com/opencode-ai/opencode/internal/db"
 	"github.com/opencode-ai/opencode/internal/format"
 	"github.com/opencode-ai/opencode/internal/llm/agent"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
 	"github.com/opencode-ai/opencode/internal/logging"
 	"github.com/opencode-ai/opencode/internal/pubsub"
 	"github.com/opencode-ai/opencode/internal/tui"
@@ -45,6 +48,9 @@ to assist developers in writing, debugging, and understanding code directly from
 
   # Run a single non-interactive prompt with JSON output format
   opencode -p "Explain the use of context in Go" -f json
+
+  # Pipe context into a non-interactive prompt
+  cat error.log | opencode -p "Fix this error"
   `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// If the help flag is set, show the help message
@@ -63,12 +69,24 @@ to assist developers in writing, debugging, and understanding code directly from
 		prompt, _ := cmd.Flags().GetString("prompt")
 		outputFormat, _ := cmd.Flags().GetString("output-format")
 		quiet, _ := cmd.Flags().GetBool("quiet")
+		permissions, _ := cmd.Flags().GetString("permissions")
 
 		// Validate format option
 		if !format.IsValid(outputFormat) {
 			return fmt.Errorf("invalid format option: %s\n%s", outputFormat, format.GetHelpText())
 		}
 
+		if permissions != "" && !config.UnattendedPermissions(permissions).Valid() {
+			return fmt.Errorf("invalid permissions option: %s, expected %s, %s or %s", permissions,
+				config.UnattendedAllowAll, config.UnattendedDenyAll, config.UnattendedAllowReadOnly)
+		}
+
+		// Piped input is added to the prompt and runs non-interactively
+		prompt, err := withStdinInput(prompt)
+		if err != nil {
+			return err
+		}
+
 		if cwd != "" {
 			err := os.Chdir(cwd)
 			if err != nil {
@@ -82,7 +100,7 @@ to assist developers in writing, debugging, and understanding code directly from
 			}
 			cwd = c
 		}
-		_, err := config.Load(cwd, debug)
+		_, err = config.Load(cwd, debug)
 		if err != nil {
 			return err
 		}
@@ -111,7 +129,11 @@ to assist developers in writing, debugging, and understanding code directly from
 		// Non-interactive mode
 		if prompt != "" {
 			// Run non-interactive flow using the App method
-			return app.RunNonInteractive(ctx, prompt, outputFormat, quiet)
+			mode := config.Get().UnattendedPermissions
+			if permissions != "" {
+				mode = config.UnattendedPermissions(permissions)
+			}
+			return app.RunNonInteractive(ctx, prompt, outputFormat, quiet, mode)
 		}
 
 		// Interactive mode
@@ -183,6 +205,40 @@ to assist developers in writing, debugging, and understanding code directly from
 	},
 }
 
+// maxStdinBytes caps the piped input added to a non-interactive prompt.
+const maxStdinBytes = 256 * 1024
+
+// withStdinInput appends the input piped to stdin, if any, to the prompt.
+// Without -p stdin is only read when it is a pipe or a file, so starting the
+// TUI with stdin attached to a socket or similar does not block on it.
+// Input beyond maxStdinBytes is dropped with a warning on stderr.
+func withStdinInput(prompt string) (string, error) {
+	stat, err := os.Stdin.Stat()
+	if err != nil || stat.Mode()&os.ModeCharDevice != 0 {
+		return prompt, nil
+	}
+	if prompt == "" && stat.Mode()&os.ModeNamedPipe == 0 && !stat.Mode().IsRegular() {
+		return prompt, nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(os.Stdin, maxStdinBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read stdin: %w", err)
+	}
+	if len(data) > maxStdinBytes {
+		data = data[:maxStdinBytes]
+		fmt.Fprintf(os.Stderr, "Warning: stdin input truncated to %d KB\n", maxStdinBytes/1024)
+	}
+	input := strings.TrimSpace(strings.ToValidUTF8(string(data), ""))
+	if input == "" {
+		return prompt, nil
+	}
+	if prompt == "" {
+		return input, nil
+	}
+	return fmt.Sprintf("%s\n\n<stdin>\n%s\n</stdin>", prompt, input), nil
+}
+
 // attemptTUIRecovery tries to recover the TUI after a panic
 func attemptTUIRecovery(program *tea.Program) {
 	logging.Info("Attempting to recover TUI after panic")
@@ -255,8 +311,10 @@ func setupSubscriptions(app *app.App, parentCtx context.Context) (chan tea.Msg,
 	setupSubscriber(ctx, &wg, "logging", logging.Subscribe, ch)
 	setupSubscriber(ctx, &wg, "sessions", app.Sessions.Subscribe, ch)
 	setupSubscriber(ctx, &wg, "messages", app.Messages.Subscribe, ch)
+	setupSubscriber(ctx, &wg, "memory", app.Memory.Subscribe, ch)
 	setupSubscriber(ctx, &wg, "permissions", app.Permissions.Subscribe, ch)
 	setupSubscriber(ctx, &wg, "coderAgent", app.CoderAgent.Subscribe, ch)
+	setupSubscriber(ctx, &wg, "toolProgress", tools.SubscribeProgress, ch)
 
 	cleanupFunc := func() {
 		logging.Info("Cancelling all subscriptions")
@@ -302,8 +360,19 @@ func init() {
 	// Add quiet flag to hide spinner in non-interactive mode
 	rootCmd.Flags().BoolP("quiet", "q", false, "Hide spinner in non-interactive mode")
 
+	// Permission requests cannot be answered in non-interactive mode
+	rootCmd.Flags().String("permissions", "",
+		"Permissions in non-interactive mode (allow-all, deny-all, allow-read-only); overrides unattendedPermissions")
+
 	// Register custom validation for the format flag
 	rootCmd.RegisterFlagCompletionFunc("output-format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return format.SupportedFormats, cobra.ShellCompDirectiveNoFileComp
 	})
+	rootCmd.RegisterFlagCompletionFunc("permissions", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{
+			string(config.UnattendedAllowAll),
+			string(config.UnattendedDenyAll),
+			string(config.UnattendedAllowReadOnly),
+		}, cobra.ShellCompDirectiveNoFileComp
+	})
 }
@@ -77,6 +77,319 @@ func generateSchema() map[string]any {
 		"default":     false,
 	}
 
+	schema["properties"].(map[string]any)["maxRepeatedToolCalls"] = map[string]any{
+		"type":        "integer",
+		"description": "Number of identical consecutive tool calls executed in a turn before further repeats are rejected (0 disables the check)",
+		"default":     3,
+		"minimum":     0,
+	}
+
+	schema["properties"].(map[string]any)["maxFilesReadPerTurn"] = map[string]any{
+		"type":        "integer",
+		"description": "Number of distinct files the view tool may read in a turn before further reads are refused with a hint to search instead (0 disables the limit)",
+		"default":     30,
+		"minimum":     0,
+	}
+
+	schema["properties"].(map[string]any)["emptyResponseRetries"] = map[string]any{
+		"type":        "integer",
+		"description": "How often a request is sent again when the provider returns an empty response, with no text and no tool calls (0 disables retrying)",
+		"default":     1,
+		"minimum":     0,
+	}
+
+	schema["properties"].(map[string]any)["summaryPrompt"] = map[string]any{
+		"type":        "string",
+		"description": "Custom instruction used when compacting a session; $CONVERSATION is replaced with the session transcript",
+	}
+
+	schema["properties"].(map[string]any)["autoBuildCheck"] = map[string]any{
+		"type":        "boolean",
+		"description": "Run go build after edits to Go files and report compilation errors in the tool result",
+		"default":     false,
+	}
+
+	schema["properties"].(map[string]any)["largeEditThreshold"] = map[string]any{
+		"type":        "integer",
+		"description": "Edits changing more lines than this always ask for permission, even for paths allowed for the session (0 disables the check)",
+		"default":     0,
+		"minimum":     0,
+	}
+
+	schema["properties"].(map[string]any)["pinnedPlan"] = map[string]any{
+		"type":        "boolean",
+		"description": "Have the agent keep a plan for multi-step tasks, pinned at the top of the transcript and kept in context",
+		"default":     false,
+	}
+
+	schema["properties"].(map[string]any)["networkRequestsPerSecond"] = map[string]any{
+		"type":        "number",
+		"description": "Maximum requests per second shared by the fetch and sourcegraph tools; extra requests are queued (0 disables the limit)",
+		"default":     2,
+		"minimum":     0,
+	}
+
+	schema["properties"].(map[string]any)["networkCache"] = map[string]any{
+		"type":        "object",
+		"description": "On-disk cache of fetch and sourcegraph responses",
+		"properties": map[string]any{
+			"ttlMinutes": map[string]any{
+				"type":        "integer",
+				"description": "How long cached responses are reused (0 disables the cache)",
+				"default":     60,
+				"minimum":     0,
+			},
+			"maxSizeMB": map[string]any{
+				"type":        "integer",
+				"description": "Maximum size of the cache; the oldest responses are evicted first (0 for no limit)",
+				"default":     50,
+				"minimum":     0,
+			},
+		},
+	}
+
+	schema["properties"].(map[string]any)["autoRead"] = map[string]any{
+		"type":        "object",
+		"description": "Let the edit tools read small, trusted files the agent has not viewed instead of refusing the edit",
+		"properties": map[string]any{
+			"enabled": map[string]any{
+				"type":        "boolean",
+				"description": "Read unread files automatically before editing them",
+				"default":     false,
+			},
+			"maxSizeKB": map[string]any{
+				"type":        "integer",
+				"description": "Largest file that is read automatically",
+				"default":     32,
+				"minimum":     0,
+			},
+			"paths": map[string]any{
+				"type":        "array",
+				"description": "Glob patterns, relative to the working directory, of files that may be read automatically; defaults to the whole working directory",
+				"items": map[string]any{
+					"type": "string",
+				},
+			},
+		},
+	}
+
+	schema["properties"].(map[string]any)["writeRoot"] = map[string]any{
+		"type":        "string",
+		"description": "Directory inside the working directory that the agent may change files in; files outside it can still be read",
+	}
+
+	schema["properties"].(map[string]any)["unattendedPermissions"] = map[string]any{
+		"type":        "string",
+		"description": "How permission requests are answered in non-interactive mode, where nobody can answer them",
+		"enum":        []string{"allow-all", "deny-all", "allow-read-only"},
+		"default":     "allow-all",
+	}
+
+	schema["properties"].(map[string]any)["review"] = map[string]any{
+		"type":        "object",
+		"description": "What happens with the feedback of the reviewer agent, which reviews the changes of each turn when it is configured",
+		"properties": map[string]any{
+			"autoFix": map[string]any{
+				"type":        "boolean",
+				"description": "Send the issues the reviewer finds back to the coder agent to fix",
+				"default":     false,
+			},
+			"maxFixRounds": map[string]any{
+				"type":        "integer",
+				"description": "How often a turn is sent back for fixes at most",
+				"default":     1,
+				"minimum":     0,
+			},
+		},
+	}
+
+	schema["properties"].(map[string]any)["webhook"] = map[string]any{
+		"type":        "object",
+		"description": "Post a summary of each session to a webhook when the session ends",
+		"properties": map[string]any{
+			"url": map[string]any{
+				"type":        "string",
+				"description": "URL the session summary is posted to as JSON; posting is disabled when empty",
+			},
+			"headers": map[string]any{
+				"type":        "object",
+				"description": "Extra HTTP headers sent with each request, such as an authorization header",
+				"additionalProperties": map[string]any{
+					"type": "string",
+				},
+			},
+			"timeoutSeconds": map[string]any{
+				"type":        "integer",
+				"description": "How long to wait for the webhook to respond",
+				"default":     5,
+				"minimum":     1,
+			},
+		},
+	}
+
+	schema["properties"].(map[string]any)["stepLimit"] = map[string]any{
+		"type":        "object",
+		"description": "Budget after which a turn pauses and asks whether to continue",
+		"properties": map[string]any{
+			"maxSteps": map[string]any{
+				"type":        "integer",
+				"description": "Tool cycles in a turn before it pauses (0 disables the limit)",
+				"default":     0,
+				"minimum":     0,
+			},
+			"maxMinutes": map[string]any{
+				"type":        "integer",
+				"description": "Minutes a turn may run before it pauses (0 disables the limit)",
+				"default":     0,
+				"minimum":     0,
+			},
+		},
+	}
+
+	schema["properties"].(map[string]any)["thinking"] = map[string]any{
+		"type":        "object",
+		"description": "How a message asks for extended thinking",
+		"properties": map[string]any{
+			"trigger": map[string]any{
+				"type":        "string",
+				"description": "Prefix of a message that turns on extended thinking for it",
+				"default":     "/think",
+			},
+			"budget": map[string]any{
+				"type":             "number",
+				"description":      "Fraction of the max tokens that thinking may use",
+				"default":          0.8,
+				"exclusiveMinimum": 0,
+				"exclusiveMaximum": 1,
+			},
+		},
+	}
+
+	implementedProviders := []string{}
+	for _, provider := range models.ImplementedProviders {
+		implementedProviders = append(implementedProviders, string(provider))
+	}
+	schema["properties"].(map[string]any)["models"] = map[string]any{
+		"type":        "array",
+		"description": "Models that are not built in, merged into the supported models at startup",
+		"items": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"id": map[string]any{
+					"type":        "string",
+					"description": "ID agents use to refer to the model, replacing a built-in model with the same ID",
+				},
+				"name": map[string]any{
+					"type":        "string",
+					"description": "Name shown in the model picker, the ID if empty",
+				},
+				"provider": map[string]any{
+					"type":        "string",
+					"description": "Provider the requests are sent to",
+					"enum":        implementedProviders,
+				},
+				"apiModel": map[string]any{
+					"type":        "string",
+					"description": "Model name sent to the provider's API",
+				},
+				"costPer1MIn": map[string]any{
+					"type":        "number",
+					"description": "Cost of one million input tokens in USD",
+					"minimum":     0,
+				},
+				"costPer1MOut": map[string]any{
+					"type":        "number",
+					"description": "Cost of one million output tokens in USD",
+					"minimum":     0,
+				},
+				"costPer1MInCached": map[string]any{
+					"type":        "number",
+					"description": "Cost of writing one million input tokens to the cache in USD",
+					"minimum":     0,
+				},
+				"costPer1MOutCached": map[string]any{
+					"type":        "number",
+					"description": "Cost of reading one million cached input tokens in USD",
+					"minimum":     0,
+				},
+				"contextWindow": map[string]any{
+					"type":        "integer",
+					"description": "Context window of the model in tokens (0 disables the context window checks)",
+					"minimum":     0,
+				},
+				"defaultMaxTokens": map[string]any{
+					"type":        "integer",
+					"description": "Output tokens of a response when the agent does not set maxTokens",
+					"default":     config.MaxTokensFallbackDefault,
+					"minimum":     0,
+				},
+				"canReason": map[string]any{
+					"type":        "boolean",
+					"description": "Whether the model supports reasoning",
+					"default":     false,
+				},
+				"supportsAttachments": map[string]any{
+					"type":        "boolean",
+					"description": "Whether the model accepts image attachments",
+					"default":     false,
+				},
+			},
+			"required": []string{"id", "provider", "apiModel"},
+		},
+	}
+
+	schema["properties"].(map[string]any)["tools"] = map[string]any{
+		"type":        "object",
+		"description": "Settings of individual tools",
+		"properties": map[string]any{
+			"ignore": map[string]any{
+				"type":        "array",
+				"description": "Gitignore-style patterns, relative to the working directory, that the ls, grep and glob tools skip on top of the .gitignore files",
+				"items": map[string]any{
+					"type": "string",
+				},
+			},
+			"sourcegraph": map[string]any{
+				"type":        "object",
+				"description": "Private Sourcegraph instance searched by the sourcegraph tool",
+				"properties": map[string]any{
+					"endpoint": map[string]any{
+						"type":        "string",
+						"description": "Base URL of the instance; defaults to $SRC_ENDPOINT, then https://sourcegraph.com",
+					},
+					"token": map[string]any{
+						"type":        "string",
+						"description": "Access token sent with every search; defaults to $SRC_ACCESS_TOKEN",
+					},
+					"maxResults": map[string]any{
+						"type":        "integer",
+						"description": "Maximum number of results a single search may return",
+						"default":     100,
+						"minimum":     1,
+					},
+				},
+			},
+			"view": map[string]any{
+				"type":        "object",
+				"description": "Bounds on the output of a single view tool read",
+				"properties": map[string]any{
+					"maxSizeKB": map[string]any{
+						"type":        "integer",
+						"description": "Maximum kilobytes returned by a single read",
+						"default":     250,
+						"minimum":     1,
+					},
+					"maxLines": map[string]any{
+						"type":        "integer",
+						"description": "Maximum lines returned by a single read",
+						"default":     2000,
+						"minimum":     1,
+					},
+				},
+			},
+		},
+	}
+
 	schema["properties"].(map[string]any)["contextPaths"] = map[string]any{
 		"type":        "array",
 		"description": "Context paths for the application",
@@ -118,6 +431,28 @@ func generateSchema() map[string]any {
 					"tron",
 				},
 			},
+			"codeTheme": map[string]any{
+				"type":        "string",
+				"description": "Syntax highlighting style for code blocks in the transcript, any chroma style name (e.g. monokai, github, dracula); defaults to the colors of the TUI theme",
+			},
+			"liveOutputLines": map[string]any{
+				"type":        "integer",
+				"description": "Number of last lines of output shown while a bash command runs; 0 only shows the elapsed time",
+				"default":     10,
+				"minimum":     0,
+			},
+			"startupSummary": map[string]any{
+				"type":        "boolean",
+				"description": "Show the model, git branch, languages and tools of the environment on the start screen",
+				"default":     true,
+			},
+			"snippets": map[string]any{
+				"type":        "object",
+				"description": "Reusable prompt snippets, expanded when :name is used in a message",
+				"additionalProperties": map[string]any{
+					"type": "string",
+				},
+			},
 		},
 	}
 
@@ -186,6 +521,45 @@ func generateSchema() map[string]any {
 					"description": "Whether the provider is disabled",
 					"default":     false,
 				},
+				"defaultModel": map[string]any{
+					"type":        "string",
+					"description": "Model used for the coder, summarizer and task agents when this provider is selected by default",
+				},
+				"baseURL": map[string]any{
+					"type":        "string",
+					"description": "Endpoint of an OpenAI compatible provider (openai, groq, openrouter, xai, local), replacing its default",
+				},
+				"betas": map[string]any{
+					"type":        "array",
+					"description": "Anthropic beta features sent in the anthropic-beta header of every request, e.g. output-128k-2025-02-19",
+					"items": map[string]any{
+						"type": "string",
+					},
+				},
+				"retry": map[string]any{
+					"type":        "object",
+					"description": "Retries of rate limited requests",
+					"properties": map[string]any{
+						"maxAttempts": map[string]any{
+							"type":        "integer",
+							"description": "Maximum number of retries of a request",
+							"default":     8,
+							"minimum":     1,
+						},
+						"baseBackoffMs": map[string]any{
+							"type":        "integer",
+							"description": "Wait before the first retry in milliseconds, doubled for every following one",
+							"default":     2000,
+							"minimum":     1,
+						},
+						"maxBackoffMs": map[string]any{
+							"type":        "integer",
+							"description": "Longest wait between retries in milliseconds, also capping the wait the provider asks for",
+							"default":     120000,
+							"minimum":     1,
+						},
+					},
+				},
 			},
 		},
 	}
@@ -237,12 +611,12 @@ func generateSchema() map[string]any {
 		},
 	}
 
-	// Add model enum
-	modelEnum := []string{}
+	// Add the built-in models as examples, custom models may use other IDs
+	modelIDs := []string{}
 	for modelID := range models.SupportedModels {
-		modelEnum = append(modelEnum, string(modelID))
+		modelIDs = append(modelIDs, string(modelID))
 	}
-	agentSchema["additionalProperties"].(map[string]any)["properties"].(map[string]any)["model"].(map[string]any)["enum"] = modelEnum
+	agentSchema["additionalProperties"].(map[string]any)["properties"].(map[string]any)["model"].(map[string]any)["examples"] = modelIDs
 
 	// Add specific agent properties
 	agentProperties := map[string]any{}
@@ -250,6 +624,7 @@ func generateSchema() map[string]any {
 		string(config.AgentCoder),
 		string(config.AgentTask),
 		string(config.AgentTitle),
+		string(config.AgentReviewer),
 	}
 
 	for _, agentName := range knownAgents {
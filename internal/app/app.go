@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,19 +15,26 @@ import (
 	"github.com/opencode-ai/opencode/internal/format"
 	"github.com/opencode-ai/opencode/internal/history"
 	"github.com/opencode-ai/opencode/internal/llm/agent"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/llm/tools/shell"
 	"github.com/opencode-ai/opencode/internal/logging"
 	"github.com/opencode-ai/opencode/internal/lsp"
+	"github.com/opencode-ai/opencode/internal/memory"
 	"github.com/opencode-ai/opencode/internal/message"
 	"github.com/opencode-ai/opencode/internal/permission"
+	"github.com/opencode-ai/opencode/internal/pubsub"
 	"github.com/opencode-ai/opencode/internal/session"
 	"github.com/opencode-ai/opencode/internal/tui/theme"
+	"github.com/opencode-ai/opencode/internal/webhook"
 )
 
 type App struct {
 	Sessions    session.Service
 	Messages    message.Service
 	History     history.Service
+	Memory      memory.Service
 	Permissions permission.Service
+	Webhook     *webhook.Notifier
 
 	CoderAgent agent.Service
 
@@ -49,26 +57,41 @@ func New(ctx context.Context, conn *sql.DB) (*App, error) {
 		Sessions:    sessions,
 		Messages:    messages,
 		History:     files,
+		Memory:      memory.NewService(q),
 		Permissions: permission.NewPermissionService(),
+		Webhook:     webhook.NewNotifier(sessions, messages, files),
 		LSPClients:  make(map[string]*lsp.Client),
 	}
 
+	tools.SetFileRecordStore(newFileRecordStore(q))
+
 	// Initialize theme based on configuration
 	app.initTheme()
 
 	// Initialize LSP clients in the background
 	go app.initLSPClients(ctx)
 
+	go app.trackSessionActivity(ctx)
+	go app.cleanupDeletedSessions(ctx)
+	go tools.SweepScratchFiles(func(sessionID string) bool {
+		_, err := sessions.Get(ctx, sessionID)
+		return !errors.Is(err, sql.ErrNoRows)
+	})
+
 	var err error
 	app.CoderAgent, err = agent.NewAgent(
 		config.AgentCoder,
 		app.Sessions,
 		app.Messages,
+		app.Memory,
+		app.History,
+		app.Permissions,
 		agent.CoderAgentTools(
 			app.Permissions,
 			app.Sessions,
 			app.Messages,
 			app.History,
+			app.Memory,
 			app.LSPClients,
 		),
 	)
@@ -96,8 +119,34 @@ func (app *App) initTheme() {
 	}
 }
 
+// trackSessionActivity marks sessions that receive messages as active so a
+// summary is posted to the webhook when they end.
+func (app *App) trackSessionActivity(ctx context.Context) {
+	if !app.Webhook.Enabled() {
+		return
+	}
+	for event := range app.Messages.Subscribe(ctx) {
+		if event.Type == pubsub.CreatedEvent {
+			app.Webhook.MarkActive(event.Payload.SessionID)
+		}
+	}
+}
+
+// cleanupDeletedSessions kills the shell of a session once it is deleted,
+// since nothing can run commands in it anymore, and removes its scratch
+// files.
+func (app *App) cleanupDeletedSessions(ctx context.Context) {
+	for event := range app.Sessions.Subscribe(ctx) {
+		if event.Type == pubsub.DeletedEvent {
+			shell.ResetShell(event.Payload.ID)
+			tools.CleanupScratchFiles(event.Payload.ID)
+		}
+	}
+}
+
 // RunNonInteractive handles the execution flow when a prompt is provided via CLI flag.
-func (a *App) RunNonInteractive(ctx context.Context, prompt string, outputFormat string, quiet bool) error {
+// Permission requests are decided by permissions since nobody can answer them.
+func (a *App) RunNonInteractive(ctx context.Context, prompt string, outputFormat string, quiet bool, permissions config.UnattendedPermissions) error {
 	logging.Info("Running in non-interactive mode")
 
 	// Start spinner if not in quiet mode
@@ -125,8 +174,8 @@ func (a *App) RunNonInteractive(ctx context.Context, prompt string, outputFormat
 	}
 	logging.Info("Created session for non-interactive run", "session_id", sess.ID)
 
-	// Automatically approve all permission requests for this non-interactive session
-	a.Permissions.AutoApproveSession(sess.ID)
+	a.Permissions.SetUnattended(sess.ID, permissions)
+	logging.Info("Permissions for non-interactive run", "session_id", sess.ID, "permissions", permissions)
 
 	done, err := a.CoderAgent.Run(ctx, sess.ID, prompt)
 	if err != nil {
@@ -152,9 +201,18 @@ func (a *App) RunNonInteractive(ctx context.Context, prompt string, outputFormat
 	if result.Message.Content().String() != "" {
 		content = result.Message.Content().String()
 	}
+	if result.StepLimit != nil {
+		// Nobody can let the turn continue, so it ends at the checkpoint.
+		content = strings.TrimSpace(content + "\n\n" + result.StepLimit.Summary())
+	}
 
 	fmt.Println(format.FormatOutput(content, outputFormat))
 
+	if result.StepLimit != nil {
+		// The task is unfinished, scripts must not take the run for a success.
+		return fmt.Errorf("the run stopped at the step limit before finishing, raise stepLimit.maxSteps and stepLimit.maxMinutes or set them to 0 to disable the limit")
+	}
+
 	logging.Info("Non-interactive run completed", "session_id", sess.ID)
 
 	return nil
@@ -170,6 +228,9 @@ func (app *App) Shutdown() {
 	app.cancelFuncsMutex.Unlock()
 	app.watcherWG.Wait()
 
+	// Post summaries of the sessions that are still open
+	app.Webhook.Shutdown()
+
 	// Perform additional cleanup for LSP clients
 	app.clientsMutex.RLock()
 	clients := make(map[string]*lsp.Client, len(app.LSPClients))
@@ -183,4 +244,10 @@ func (app *App) Shutdown() {
 		}
 		cancel()
 	}
+
+	// Remove any scratch files the agent created during the session
+	tools.CleanupAllScratchFiles()
+
+	// Stop the shells of the bash tool and the commands they still run
+	shell.CloseAll()
 }
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/session"
+)
+
+// BranchSession creates a session with the messages of sessionID that come
+// before the user message messageID, so an edited version of that message
+// can be sent without changing the original session.
+func (app *App) BranchSession(ctx context.Context, sessionID, messageID string) (session.Session, error) {
+	from, err := app.Sessions.Get(ctx, sessionID)
+	if err != nil {
+		return session.Session{}, fmt.Errorf("failed to get session: %w", err)
+	}
+	msgs, err := app.Messages.List(ctx, sessionID)
+	if err != nil {
+		return session.Session{}, fmt.Errorf("failed to list messages: %w", err)
+	}
+	edited := -1
+	for i, msg := range msgs {
+		if msg.ID == messageID {
+			edited = i
+			break
+		}
+	}
+	if edited < 0 || msgs[edited].Role != message.User {
+		return session.Session{}, fmt.Errorf("message %s is not a user message of the session", messageID)
+	}
+
+	branch, err := app.Sessions.CreateBranch(ctx, from)
+	if err != nil {
+		return session.Session{}, fmt.Errorf("failed to create branch: %w", err)
+	}
+	for _, msg := range msgs[:edited] {
+		copied, err := app.Messages.Copy(ctx, branch.ID, msg)
+		if err != nil {
+			if deleteErr := app.Sessions.Delete(ctx, branch.ID); deleteErr != nil {
+				return session.Session{}, fmt.Errorf("failed to copy message: %w, and to delete the partial branch: %v", err, deleteErr)
+			}
+			return session.Session{}, fmt.Errorf("failed to copy message: %w", err)
+		}
+		if msg.ID == from.SummaryMessageID {
+			branch.SummaryMessageID = copied.ID
+		}
+	}
+	if branch.SummaryMessageID != "" {
+		if branch, err = app.Sessions.Save(ctx, branch); err != nil {
+			return session.Session{}, fmt.Errorf("failed to save branch: %w", err)
+		}
+	}
+	return branch, nil
+}
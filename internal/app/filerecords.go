@@ -0,0 +1,63 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+)
+
+// fileRecordStore persists the read and write times of the tools in the
+// database, so they survive a restart of the application.
+type fileRecordStore struct {
+	q db.Querier
+}
+
+func newFileRecordStore(q db.Querier) tools.FileRecordStore {
+	return &fileRecordStore{q: q}
+}
+
+func (s *fileRecordStore) ListFileRecords(ctx context.Context, sessionID string) ([]tools.FileRecord, error) {
+	rows, err := s.q.ListFileReadsBySession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]tools.FileRecord, len(rows))
+	for i, row := range rows {
+		records[i] = tools.FileRecord{
+			SessionID: row.SessionID,
+			Path:      row.Path,
+			ReadTime:  fromUnixNano(row.ReadAt),
+			WriteTime: fromUnixNano(row.WrittenAt),
+			ReadHash:  row.ReadHash,
+		}
+	}
+	return records, nil
+}
+
+func (s *fileRecordStore) SaveFileRecord(ctx context.Context, record tools.FileRecord) error {
+	return s.q.UpsertFileRead(ctx, db.UpsertFileReadParams{
+		SessionID: record.SessionID,
+		Path:      record.Path,
+		ReadAt:    toUnixNano(record.ReadTime),
+		WrittenAt: toUnixNano(record.WriteTime),
+		ReadHash:  record.ReadHash,
+	})
+}
+
+// toUnixNano and fromUnixNano store the zero time, a file never read or
+// written, as 0.
+func toUnixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromUnixNano(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
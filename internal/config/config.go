@@ -2,11 +2,13 @@
 package config
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/opencode-ai/opencode/internal/llm/models"
@@ -40,6 +42,9 @@ const (
 	AgentSummarizer AgentName = "summarizer"
 	AgentTask       AgentName = "task"
 	AgentTitle      AgentName = "title"
+	// AgentReviewer reviews the changes of each turn of the coder agent. It
+	// only runs when it is configured.
+	AgentReviewer AgentName = "reviewer"
 )
 
 // Agent defines configuration for different LLM models and their token limits.
@@ -49,12 +54,43 @@ type Agent struct {
 	ReasoningEffort string         `json:"reasoningEffort"` // For openai models low,medium,heigh
 }
 
+// extendedOutputBeta lets Claude 3.7 Sonnet write up to 128K tokens, more
+// than half its context window, so max tokens are not capped with it.
+const extendedOutputBeta = "output-128k-2025-02-19"
+
 // Provider defines configuration for an LLM provider.
 type Provider struct {
-	APIKey   string `json:"apiKey"`
-	Disabled bool   `json:"disabled"`
+	APIKey       string         `json:"apiKey"`
+	Disabled     bool           `json:"disabled"`
+	DefaultModel models.ModelID `json:"defaultModel,omitempty"`
+	// BaseURL replaces the endpoint of an OpenAI compatible provider, such
+	// as a proxy for OpenAI or the server of local models.
+	BaseURL string `json:"baseURL,omitempty"`
+	// Betas are the Anthropic beta features requested with every call, such
+	// as "output-128k-2025-02-19". Only used by the Anthropic provider.
+	Betas []string `json:"betas,omitempty"`
+	// Retry tunes the retries of rate limited requests.
+	Retry RetryConfig `json:"retry,omitempty"`
+}
+
+// RetryConfig defines how rate limited requests to a provider are retried.
+// Zero values keep the defaults.
+type RetryConfig struct {
+	MaxAttempts   int `json:"maxAttempts,omitempty"`
+	BaseBackoffMs int `json:"baseBackoffMs,omitempty"`
+	MaxBackoffMs  int `json:"maxBackoffMs,omitempty"`
 }
 
+// APIKeySource describes where the API key of a provider was resolved from.
+type APIKeySource string
+
+// API key sources, in order of precedence.
+const (
+	APIKeySourceConfig      APIKeySource = "config"
+	APIKeySourceEnvironment APIKeySource = "environment"
+	APIKeySourceCredentials APIKeySource = "credentials file"
+)
+
 // Data defines storage configuration.
 type Data struct {
 	Directory string `json:"directory,omitempty"`
@@ -70,29 +106,187 @@ type LSPConfig struct {
 
 // TUIConfig defines the configuration for the Terminal User Interface.
 type TUIConfig struct {
-	Theme string `json:"theme,omitempty"`
+	Theme     string            `json:"theme,omitempty"`
+	CodeTheme string            `json:"codeTheme,omitempty"`
+	Snippets  map[string]string `json:"snippets,omitempty"`
+	// LiveOutputLines is how many of the last lines of output a running
+	// bash command shows in the transcript; 0 only shows the elapsed time.
+	LiveOutputLines int `json:"liveOutputLines,omitempty"`
+	// StartupSummary shows the model, git branch, languages and tools on
+	// the start screen.
+	StartupSummary bool `json:"startupSummary,omitempty"`
 }
 
 // ShellConfig defines the configuration for the shell used by the bash tool.
 type ShellConfig struct {
-	Path string   `json:"path,omitempty"`
-	Args []string `json:"args,omitempty"`
+	Path    string        `json:"path,omitempty"`
+	Args    []string      `json:"args,omitempty"`
+	Sandbox SandboxConfig `json:"sandbox,omitempty"`
+	// EnvFile is a .env file, relative to the working directory, whose
+	// variables are added to the environment of commands. Empty disables it.
+	EnvFile string `json:"envFile,omitempty"`
+	// AllowDestructive lets the bash tool run commands that wipe a disk,
+	// the file system root or the home directory.
+	AllowDestructive bool `json:"allowDestructive,omitempty"`
+}
+
+// SandboxConfig defines the restricted environment the shell runs in.
+type SandboxConfig struct {
+	// Backend is the sandbox to use: "env" only scrubs the environment,
+	// "bwrap" also isolates the file system and network with bubblewrap.
+	Backend  string   `json:"backend,omitempty"`
+	AllowEnv []string `json:"allowEnv,omitempty"`
+	Writable []string `json:"writable,omitempty"`
+	Network  bool     `json:"network,omitempty"`
+}
+
+// NetworkCacheConfig defines the on-disk cache of fetch and sourcegraph
+// responses.
+type NetworkCacheConfig struct {
+	TTLMinutes int `json:"ttlMinutes,omitempty"`
+	MaxSizeMB  int `json:"maxSizeMB,omitempty"`
+}
+
+// AutoReadConfig lets the edit tools read a file the agent has not viewed
+// yet instead of refusing the edit, for small files in trusted paths.
+type AutoReadConfig struct {
+	Enabled   bool `json:"enabled,omitempty"`
+	MaxSizeKB int  `json:"maxSizeKB,omitempty"`
+	// Paths are glob patterns relative to the working directory; when empty
+	// every file in the working directory is trusted.
+	Paths []string `json:"paths,omitempty"`
+}
+
+// UnattendedPermissions decides the permission requests of non-interactive
+// runs, where nobody can answer a prompt.
+type UnattendedPermissions string
+
+const (
+	UnattendedAllowAll      UnattendedPermissions = "allow-all"
+	UnattendedDenyAll       UnattendedPermissions = "deny-all"
+	UnattendedAllowReadOnly UnattendedPermissions = "allow-read-only"
+)
+
+// Valid reports whether p is one of the known modes.
+func (p UnattendedPermissions) Valid() bool {
+	switch p {
+	case UnattendedAllowAll, UnattendedDenyAll, UnattendedAllowReadOnly:
+		return true
+	}
+	return false
+}
+
+// ReviewConfig defines what happens with the feedback of the reviewer agent.
+type ReviewConfig struct {
+	// AutoFix sends the issues the reviewer finds back to the coder agent.
+	AutoFix bool `json:"autoFix,omitempty"`
+	// MaxFixRounds limits how often a turn is sent back for fixes.
+	MaxFixRounds int `json:"maxFixRounds,omitempty"`
+}
+
+// WebhookConfig defines where session summaries are posted.
+type WebhookConfig struct {
+	URL            string            `json:"url,omitempty"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	TimeoutSeconds int               `json:"timeoutSeconds,omitempty"`
+}
+
+// StepLimitConfig defines the budget after which a turn of the coder agent
+// pauses and asks the user whether to continue. 0 disables a limit.
+type StepLimitConfig struct {
+	// MaxSteps is the number of tool cycles in a turn.
+	MaxSteps int `json:"maxSteps,omitempty"`
+	// MaxMinutes is the time a turn may run.
+	MaxMinutes int `json:"maxMinutes,omitempty"`
+}
+
+// ThinkingConfig defines how a message asks for extended thinking.
+type ThinkingConfig struct {
+	// Trigger is the prefix of a message that turns on extended thinking
+	// for it. It is removed before the message is sent.
+	Trigger string `json:"trigger,omitempty"`
+	// Budget is the fraction of the max tokens that thinking may use.
+	Budget float64 `json:"budget,omitempty"`
+}
+
+// ToolsConfig holds the settings of individual tools.
+type ToolsConfig struct {
+	Sourcegraph SourcegraphConfig `json:"sourcegraph,omitempty"`
+	View        ViewConfig        `json:"view,omitempty"`
+	// Ignore holds gitignore-style patterns, relative to the working
+	// directory, that the ls, grep and glob tools skip on top of the
+	// .gitignore files.
+	Ignore []string `json:"ignore,omitempty"`
+}
+
+// ViewConfig bounds how much of a file a single read with the view tool
+// returns. Larger files show their head with a note to page through them.
+type ViewConfig struct {
+	MaxSizeKB int `json:"maxSizeKB,omitempty"`
+	MaxLines  int `json:"maxLines,omitempty"`
+}
+
+// SourcegraphConfig points the sourcegraph tool at a private instance. When
+// the endpoint is empty the public sourcegraph.com instance is searched.
+type SourcegraphConfig struct {
+	// Endpoint is the base URL of the instance, e.g. https://sourcegraph.example.com.
+	Endpoint string `json:"endpoint,omitempty"`
+	// Token is an access token sent with every search.
+	Token string `json:"token,omitempty"`
+	// MaxResults bounds the number of results a single search may return.
+	MaxResults int `json:"maxResults,omitempty"`
 }
 
 // Config is the main configuration structure for the application.
 type Config struct {
-	Data         Data                              `json:"data"`
-	WorkingDir   string                            `json:"wd,omitempty"`
-	MCPServers   map[string]MCPServer              `json:"mcpServers,omitempty"`
-	Providers    map[models.ModelProvider]Provider `json:"providers,omitempty"`
-	LSP          map[string]LSPConfig              `json:"lsp,omitempty"`
-	Agents       map[AgentName]Agent               `json:"agents,omitempty"`
-	Debug        bool                              `json:"debug,omitempty"`
-	DebugLSP     bool                              `json:"debugLSP,omitempty"`
-	ContextPaths []string                          `json:"contextPaths,omitempty"`
-	TUI          TUIConfig                         `json:"tui"`
-	Shell        ShellConfig                       `json:"shell,omitempty"`
-	AutoCompact  bool                              `json:"autoCompact,omitempty"`
+	Data                     Data                              `json:"data"`
+	WorkingDir               string                            `json:"wd,omitempty"`
+	MCPServers               map[string]MCPServer              `json:"mcpServers,omitempty"`
+	Providers                map[models.ModelProvider]Provider `json:"providers,omitempty"`
+	LSP                      map[string]LSPConfig              `json:"lsp,omitempty"`
+	Agents                   map[AgentName]Agent               `json:"agents,omitempty"`
+	Debug                    bool                              `json:"debug,omitempty"`
+	DebugLSP                 bool                              `json:"debugLSP,omitempty"`
+	ContextPaths             []string                          `json:"contextPaths,omitempty"`
+	TUI                      TUIConfig                         `json:"tui"`
+	Shell                    ShellConfig                       `json:"shell,omitempty"`
+	AutoCompact              bool                              `json:"autoCompact,omitempty"`
+	MaxRepeatedToolCalls     int                               `json:"maxRepeatedToolCalls,omitempty"`
+	MaxFilesReadPerTurn      int                               `json:"maxFilesReadPerTurn,omitempty"`
+	EmptyResponseRetries     int                               `json:"emptyResponseRetries,omitempty"`
+	SummaryPrompt            string                            `json:"summaryPrompt,omitempty"`
+	NetworkRequestsPerSecond float64                           `json:"networkRequestsPerSecond,omitempty"`
+	NetworkCache             NetworkCacheConfig                `json:"networkCache,omitempty"`
+	AutoBuildCheck           bool                              `json:"autoBuildCheck,omitempty"`
+	LargeEditThreshold       int                               `json:"largeEditThreshold,omitempty"`
+	PinnedPlan               bool                              `json:"pinnedPlan,omitempty"`
+	AutoRead                 AutoReadConfig                    `json:"autoRead,omitempty"`
+	Webhook                  WebhookConfig                     `json:"webhook,omitempty"`
+	WriteRoot                string                            `json:"writeRoot,omitempty"`
+	UnattendedPermissions    UnattendedPermissions             `json:"unattendedPermissions,omitempty"`
+	Review                   ReviewConfig                      `json:"review,omitempty"`
+	Tools                    ToolsConfig                       `json:"tools,omitempty"`
+	StepLimit                StepLimitConfig                   `json:"stepLimit,omitempty"`
+	Thinking                 ThinkingConfig                    `json:"thinking,omitempty"`
+	Models                   []CustomModel                     `json:"models,omitempty"`
+}
+
+// CustomModel defines a model that is not built in, such as one released
+// after this version. A custom model with the ID of a built-in one replaces
+// it.
+type CustomModel struct {
+	ID                  models.ModelID       `json:"id"`
+	Name                string               `json:"name,omitempty"`
+	Provider            models.ModelProvider `json:"provider"`
+	APIModel            string               `json:"apiModel"`
+	CostPer1MIn         float64              `json:"costPer1MIn,omitempty"`
+	CostPer1MOut        float64              `json:"costPer1MOut,omitempty"`
+	CostPer1MInCached   float64              `json:"costPer1MInCached,omitempty"`
+	CostPer1MOutCached  float64              `json:"costPer1MOutCached,omitempty"`
+	ContextWindow       int64                `json:"contextWindow,omitempty"`
+	DefaultMaxTokens    int64                `json:"defaultMaxTokens,omitempty"`
+	CanReason           bool                 `json:"canReason,omitempty"`
+	SupportsAttachments bool                 `json:"supportsAttachments,omitempty"`
 }
 
 // Application constants
@@ -100,8 +294,39 @@ const (
 	defaultDataDirectory = ".opencode"
 	defaultLogLevel      = "info"
 	appName              = "opencode"
+	credentialsFileName  = "credentials.json"
+
+	defaultMaxRepeatedToolCalls = 3
+
+	defaultMaxFilesReadPerTurn = 30
+
+	defaultEmptyResponseRetries = 1
+
+	defaultNetworkRequestsPerSecond = 2
+
+	defaultNetworkCacheTTLMinutes = 60
+	defaultNetworkCacheMaxSizeMB  = 50
+
+	defaultAutoReadMaxSizeKB = 32
+
+	defaultWebhookTimeoutSeconds = 5
+
+	defaultLiveOutputLines = 10
+
+	defaultReviewMaxFixRounds = 1
+
+	defaultThinkingTrigger = "/think"
+	defaultThinkingBudget  = 0.8
+
+	defaultSourcegraphMaxResults = 100
+
+	defaultViewMaxSizeKB = 250
+	defaultViewMaxLines  = 2000
 
 	MaxTokensFallbackDefault = 4096
+
+	// TitleMaxTokens caps the output of the title agent.
+	TitleMaxTokens = 80
 )
 
 var defaultContextPaths = []string{
@@ -118,9 +343,22 @@ var defaultContextPaths = []string{
 	"OPENCODE.local.md",
 }
 
+// providerAPIKeyEnv maps providers to the environment variable holding their API key.
+var providerAPIKeyEnv = map[models.ModelProvider]string{
+	models.ProviderAnthropic:  "ANTHROPIC_API_KEY",
+	models.ProviderOpenAI:     "OPENAI_API_KEY",
+	models.ProviderGemini:     "GEMINI_API_KEY",
+	models.ProviderGROQ:       "GROQ_API_KEY",
+	models.ProviderOpenRouter: "OPENROUTER_API_KEY",
+	models.ProviderXAI:        "XAI_API_KEY",
+}
+
 // Global configuration instance
 var cfg *Config
 
+// apiKeySources records where each provider's API key was resolved from.
+var apiKeySources = make(map[models.ModelProvider]APIKeySource)
+
 // Load initializes the configuration from environment variables and config files.
 // If debug is true, debug mode is enabled and log level is set to debug.
 // It returns an error if configuration loading fails.
@@ -147,6 +385,16 @@ func Load(workingDir string, debug bool) (*Config, error) {
 	// Load and merge local config
 	mergeLocalConfig(workingDir)
 
+	// Custom models are registered first, so a provider's defaultModel may
+	// name one of them.
+	var customModels []CustomModel
+	if err := viper.UnmarshalKey("models", &customModels); err != nil {
+		return cfg, fmt.Errorf("failed to unmarshal models: %w", err)
+	}
+	if err := registerCustomModels(customModels); err != nil {
+		return cfg, fmt.Errorf("config validation failed: %w", err)
+	}
+
 	setProviderDefaults()
 
 	// Apply configuration to the struct
@@ -189,6 +437,8 @@ func Load(workingDir string, debug bool) (*Config, error) {
 		slog.SetDefault(logger)
 	}
 
+	logAPIKeySources()
+
 	// Validate configuration
 	if err := Validate(); err != nil {
 		return cfg, fmt.Errorf("config validation failed: %w", err)
@@ -201,7 +451,7 @@ func Load(workingDir string, debug bool) (*Config, error) {
 	// Override the max tokens for title agent
 	cfg.Agents[AgentTitle] = Agent{
 		Model:     cfg.Agents[AgentTitle].Model,
-		MaxTokens: 80,
+		MaxTokens: TitleMaxTokens,
 	}
 	return cfg, nil
 }
@@ -222,7 +472,27 @@ func setDefaults(debug bool) {
 	viper.SetDefault("data.directory", defaultDataDirectory)
 	viper.SetDefault("contextPaths", defaultContextPaths)
 	viper.SetDefault("tui.theme", "opencode")
+	viper.SetDefault("tui.liveOutputLines", defaultLiveOutputLines)
+	viper.SetDefault("tui.startupSummary", true)
 	viper.SetDefault("autoCompact", true)
+	viper.SetDefault("maxRepeatedToolCalls", defaultMaxRepeatedToolCalls)
+	viper.SetDefault("maxFilesReadPerTurn", defaultMaxFilesReadPerTurn)
+	viper.SetDefault("emptyResponseRetries", defaultEmptyResponseRetries)
+	viper.SetDefault("networkRequestsPerSecond", defaultNetworkRequestsPerSecond)
+	viper.SetDefault("networkCache.ttlMinutes", defaultNetworkCacheTTLMinutes)
+	viper.SetDefault("networkCache.maxSizeMB", defaultNetworkCacheMaxSizeMB)
+	viper.SetDefault("autoRead.maxSizeKB", defaultAutoReadMaxSizeKB)
+	viper.SetDefault("webhook.timeoutSeconds", defaultWebhookTimeoutSeconds)
+	viper.SetDefault("unattendedPermissions", string(UnattendedAllowAll))
+	viper.SetDefault("review.maxFixRounds", defaultReviewMaxFixRounds)
+	viper.SetDefault("thinking.trigger", defaultThinkingTrigger)
+	viper.SetDefault("thinking.budget", defaultThinkingBudget)
+	// The same variables as the src CLI, so existing setups work unchanged.
+	viper.SetDefault("tools.sourcegraph.endpoint", os.Getenv("SRC_ENDPOINT"))
+	viper.SetDefault("tools.sourcegraph.token", os.Getenv("SRC_ACCESS_TOKEN"))
+	viper.SetDefault("tools.sourcegraph.maxResults", defaultSourcegraphMaxResults)
+	viper.SetDefault("tools.view.maxSizeKB", defaultViewMaxSizeKB)
+	viper.SetDefault("tools.view.maxLines", defaultViewMaxLines)
 
 	// Set default shell from environment or fallback to /bin/bash
 	shellPath := os.Getenv("SHELL")
@@ -243,26 +513,20 @@ func setDefaults(debug bool) {
 
 // setProviderDefaults configures LLM provider defaults based on provider provided by
 // environment variables and configuration file.
+//
+// API keys are resolved with the following precedence:
+//  1. The apiKey set in the configuration file
+//  2. The provider's environment variable (e.g. ANTHROPIC_API_KEY)
+//  3. The shared credentials file ($XDG_CONFIG_HOME/opencode/credentials.json)
 func setProviderDefaults() {
-	// Set all API keys we can find in the environment
-	if apiKey := os.Getenv("ANTHROPIC_API_KEY"); apiKey != "" {
-		viper.SetDefault("providers.anthropic.apiKey", apiKey)
-	}
-	if apiKey := os.Getenv("OPENAI_API_KEY"); apiKey != "" {
-		viper.SetDefault("providers.openai.apiKey", apiKey)
-	}
-	if apiKey := os.Getenv("GEMINI_API_KEY"); apiKey != "" {
-		viper.SetDefault("providers.gemini.apiKey", apiKey)
-	}
-	if apiKey := os.Getenv("GROQ_API_KEY"); apiKey != "" {
-		viper.SetDefault("providers.groq.apiKey", apiKey)
-	}
-	if apiKey := os.Getenv("OPENROUTER_API_KEY"); apiKey != "" {
-		viper.SetDefault("providers.openrouter.apiKey", apiKey)
-	}
-	if apiKey := os.Getenv("XAI_API_KEY"); apiKey != "" {
-		viper.SetDefault("providers.xai.apiKey", apiKey)
+	resolveProviderAPIKeys()
+
+	// Local models are listed at startup when LOCAL_ENDPOINT is set, and
+	// here when the endpoint is configured instead.
+	if endpoint := viper.GetString("providers.local.baseURL"); endpoint != "" && os.Getenv("LOCAL_ENDPOINT") == "" {
+		models.LoadLocalModels(endpoint)
 	}
+
 	if apiKey := os.Getenv("AZURE_OPENAI_ENDPOINT"); apiKey != "" {
 		// api-key may be empty when using Entra ID credentials – that's okay
 		viper.SetDefault("providers.azure.apiKey", os.Getenv("AZURE_OPENAI_API_KEY"))
@@ -280,86 +544,162 @@ func setProviderDefaults() {
 
 	// Anthropic configuration
 	if key := viper.GetString("providers.anthropic.apiKey"); strings.TrimSpace(key) != "" {
-		viper.SetDefault("agents.coder.model", models.Claude4Sonnet)
-		viper.SetDefault("agents.summarizer.model", models.Claude4Sonnet)
-		viper.SetDefault("agents.task.model", models.Claude4Sonnet)
-		viper.SetDefault("agents.title.model", models.Claude4Sonnet)
+		setAgentModelDefaults(models.ProviderAnthropic, models.Claude4Sonnet, models.Claude4Sonnet, models.Claude4Sonnet)
 		return
 	}
 
 	// OpenAI configuration
 	if key := viper.GetString("providers.openai.apiKey"); strings.TrimSpace(key) != "" {
-		viper.SetDefault("agents.coder.model", models.GPT41)
-		viper.SetDefault("agents.summarizer.model", models.GPT41)
-		viper.SetDefault("agents.task.model", models.GPT41Mini)
-		viper.SetDefault("agents.title.model", models.GPT41Mini)
+		setAgentModelDefaults(models.ProviderOpenAI, models.GPT41, models.GPT41Mini, models.GPT41Mini)
 		return
 	}
 
 	// Google Gemini configuration
 	if key := viper.GetString("providers.gemini.apiKey"); strings.TrimSpace(key) != "" {
-		viper.SetDefault("agents.coder.model", models.Gemini25)
-		viper.SetDefault("agents.summarizer.model", models.Gemini25)
-		viper.SetDefault("agents.task.model", models.Gemini25Flash)
-		viper.SetDefault("agents.title.model", models.Gemini25Flash)
+		setAgentModelDefaults(models.ProviderGemini, models.Gemini25, models.Gemini25Flash, models.Gemini25Flash)
 		return
 	}
 
 	// Groq configuration
 	if key := viper.GetString("providers.groq.apiKey"); strings.TrimSpace(key) != "" {
-		viper.SetDefault("agents.coder.model", models.QWENQwq)
-		viper.SetDefault("agents.summarizer.model", models.QWENQwq)
-		viper.SetDefault("agents.task.model", models.QWENQwq)
-		viper.SetDefault("agents.title.model", models.QWENQwq)
+		setAgentModelDefaults(models.ProviderGROQ, models.QWENQwq, models.QWENQwq, models.QWENQwq)
 		return
 	}
 
 	// OpenRouter configuration
 	if key := viper.GetString("providers.openrouter.apiKey"); strings.TrimSpace(key) != "" {
-		viper.SetDefault("agents.coder.model", models.OpenRouterClaude37Sonnet)
-		viper.SetDefault("agents.summarizer.model", models.OpenRouterClaude37Sonnet)
-		viper.SetDefault("agents.task.model", models.OpenRouterClaude37Sonnet)
-		viper.SetDefault("agents.title.model", models.OpenRouterClaude35Haiku)
+		setAgentModelDefaults(models.ProviderOpenRouter, models.OpenRouterClaude37Sonnet, models.OpenRouterClaude37Sonnet, models.OpenRouterClaude35Haiku)
 		return
 	}
 
 	// XAI configuration
 	if key := viper.GetString("providers.xai.apiKey"); strings.TrimSpace(key) != "" {
-		viper.SetDefault("agents.coder.model", models.XAIGrok3Beta)
-		viper.SetDefault("agents.summarizer.model", models.XAIGrok3Beta)
-		viper.SetDefault("agents.task.model", models.XAIGrok3Beta)
-		viper.SetDefault("agents.title.model", models.XAiGrok3MiniFastBeta)
+		setAgentModelDefaults(models.ProviderXAI, models.XAIGrok3Beta, models.XAIGrok3Beta, models.XAiGrok3MiniFastBeta)
 		return
 	}
 
 	// AWS Bedrock configuration
 	if hasAWSCredentials() {
-		viper.SetDefault("agents.coder.model", models.BedrockClaude37Sonnet)
-		viper.SetDefault("agents.summarizer.model", models.BedrockClaude37Sonnet)
-		viper.SetDefault("agents.task.model", models.BedrockClaude37Sonnet)
-		viper.SetDefault("agents.title.model", models.BedrockClaude37Sonnet)
+		setAgentModelDefaults(models.ProviderBedrock, models.BedrockClaude37Sonnet, models.BedrockClaude37Sonnet, models.BedrockClaude37Sonnet)
 		return
 	}
 
 	// Azure OpenAI configuration
 	if os.Getenv("AZURE_OPENAI_ENDPOINT") != "" {
-		viper.SetDefault("agents.coder.model", models.AzureGPT41)
-		viper.SetDefault("agents.summarizer.model", models.AzureGPT41)
-		viper.SetDefault("agents.task.model", models.AzureGPT41Mini)
-		viper.SetDefault("agents.title.model", models.AzureGPT41Mini)
+		setAgentModelDefaults(models.ProviderAzure, models.AzureGPT41, models.AzureGPT41Mini, models.AzureGPT41Mini)
 		return
 	}
 
 	// Google Cloud VertexAI configuration
 	if hasVertexAICredentials() {
-		viper.SetDefault("agents.coder.model", models.VertexAIGemini25)
-		viper.SetDefault("agents.summarizer.model", models.VertexAIGemini25)
-		viper.SetDefault("agents.task.model", models.VertexAIGemini25Flash)
-		viper.SetDefault("agents.title.model", models.VertexAIGemini25Flash)
+		setAgentModelDefaults(models.ProviderVertexAI, models.VertexAIGemini25, models.VertexAIGemini25Flash, models.VertexAIGemini25Flash)
 		return
 	}
 }
 
+// resolveProviderAPIKeys resolves the API key of every known provider and
+// records where it came from. Keys set in the configuration file win over
+// environment variables, which win over the shared credentials file.
+func resolveProviderAPIKeys() {
+	credentials := readCredentialsFile()
+	for provider, envVar := range providerAPIKeyEnv {
+		configKey := fmt.Sprintf("providers.%s.apiKey", provider)
+		if strings.TrimSpace(viper.GetString(configKey)) != "" {
+			apiKeySources[provider] = APIKeySourceConfig
+			continue
+		}
+		if apiKey := os.Getenv(envVar); apiKey != "" {
+			viper.SetDefault(configKey, apiKey)
+			apiKeySources[provider] = APIKeySourceEnvironment
+			continue
+		}
+		if apiKey := credentials[provider].APIKey; apiKey != "" {
+			viper.SetDefault(configKey, apiKey)
+			apiKeySources[provider] = APIKeySourceCredentials
+		}
+	}
+}
+
+// credentialsFilePath returns the location of the shared credentials file.
+func credentialsFilePath() string {
+	if xdgConfigHome := os.Getenv("XDG_CONFIG_HOME"); xdgConfigHome != "" {
+		return filepath.Join(xdgConfigHome, appName, credentialsFileName)
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".config", appName, credentialsFileName)
+}
+
+// readCredentialsFile reads the shared credentials file. It has the same shape
+// as the providers section of the configuration file. A missing or invalid
+// file yields no credentials.
+func readCredentialsFile() map[models.ModelProvider]Provider {
+	path := credentialsFilePath()
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var credentials map[models.ModelProvider]Provider
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		logging.Warn("failed to parse credentials file", "path", path, "error", err)
+		return nil
+	}
+	return credentials
+}
+
+// setAgentModelDefaults sets the default models of the agents for the given
+// provider. A defaultModel configured for the provider replaces the built-in
+// coder, summarizer and task models; the title agent keeps its lightweight model.
+func setAgentModelDefaults(provider models.ModelProvider, coder, task, title models.ModelID) {
+	if model, ok := providerDefaultModel(provider); ok {
+		coder = model
+		task = model
+	}
+	viper.SetDefault("agents.coder.model", coder)
+	viper.SetDefault("agents.summarizer.model", coder)
+	viper.SetDefault("agents.task.model", task)
+	viper.SetDefault("agents.title.model", title)
+}
+
+// providerDefaultModel returns the defaultModel configured for a provider, if it
+// is a supported model of that provider.
+func providerDefaultModel(provider models.ModelProvider) (models.ModelID, bool) {
+	modelID := models.ModelID(viper.GetString(fmt.Sprintf("providers.%s.defaultModel", provider)))
+	if modelID == "" {
+		return "", false
+	}
+	model, ok := models.SupportedModels[modelID]
+	if !ok || model.Provider != provider {
+		logging.Warn("ignoring unsupported default model for provider", "provider", provider, "model", modelID)
+		return "", false
+	}
+	return modelID, true
+}
+
+// logAPIKeySources logs, with the key redacted, which source supplied the API
+// key of each provider.
+func logAPIKeySources() {
+	for provider, source := range apiKeySources {
+		logging.Info("resolved provider API key",
+			"provider", provider,
+			"source", source,
+			"key", redactAPIKey(viper.GetString(fmt.Sprintf("providers.%s.apiKey", provider))))
+	}
+}
+
+// redactAPIKey hides all but the last four characters of an API key.
+func redactAPIKey(key string) string {
+	if len(key) <= 8 {
+		return strings.Repeat("*", len(key))
+	}
+	return strings.Repeat("*", 8) + key[len(key)-4:]
+}
+
 // hasAWSCredentials checks if AWS credentials are available in the environment.
 func hasAWSCredentials() bool {
 	// Check for explicit AWS credentials
@@ -437,6 +777,37 @@ func applyDefaultValues() {
 	}
 }
 
+// registerCustomModels adds the models defined in the configuration to the
+// supported models, so agents can use them like built-in ones.
+func registerCustomModels(custom []CustomModel) error {
+	for i, m := range custom {
+		if m.ID == "" || m.APIModel == "" {
+			return fmt.Errorf("models[%d] must have an id and an apiModel", i)
+		}
+		if !slices.Contains(models.ImplementedProviders, m.Provider) {
+			return fmt.Errorf("model %s uses provider %q, which is not implemented", m.ID, m.Provider)
+		}
+		if m.ContextWindow < 0 || m.DefaultMaxTokens < 0 {
+			return fmt.Errorf("model %s must not have a negative contextWindow or defaultMaxTokens", m.ID)
+		}
+		models.SupportedModels[m.ID] = models.Model{
+			ID:                  m.ID,
+			Name:                cmp.Or(m.Name, string(m.ID)),
+			Provider:            m.Provider,
+			APIModel:            m.APIModel,
+			CostPer1MIn:         m.CostPer1MIn,
+			CostPer1MOut:        m.CostPer1MOut,
+			CostPer1MInCached:   m.CostPer1MInCached,
+			CostPer1MOutCached:  m.CostPer1MOutCached,
+			ContextWindow:       m.ContextWindow,
+			DefaultMaxTokens:    cmp.Or(m.DefaultMaxTokens, MaxTokensFallbackDefault),
+			CanReason:           m.CanReason,
+			SupportsAttachments: m.SupportsAttachments,
+		}
+	}
+	return nil
+}
+
 // It validates model IDs and providers, ensuring they are supported.
 func validateAgent(cfg *Config, name AgentName, agent Agent) error {
 	// Check if model exists
@@ -511,7 +882,7 @@ func validateAgent(cfg *Config, name AgentName, agent Agent) error {
 			updatedAgent.MaxTokens = MaxTokensFallbackDefault
 		}
 		cfg.Agents[name] = updatedAgent
-	} else if model.ContextWindow > 0 && agent.MaxTokens > model.ContextWindow/2 {
+	} else if model.ContextWindow > 0 && agent.MaxTokens > model.ContextWindow/2 && !slices.Contains(cfg.Providers[provider].Betas, extendedOutputBeta) {
 		// Ensure max tokens doesn't exceed half the context window (reasonable limit)
 		logging.Warn("max tokens exceeds half the context window, adjusting",
 			"agent", name,
@@ -590,6 +961,26 @@ func Validate() error {
 		}
 	}
 
+	if cfg.WriteRoot != "" {
+		root := WriteRoot()
+		rel, err := filepath.Rel(cfg.WorkingDir, root)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("writeRoot %s must be inside the working directory %s", cfg.WriteRoot, cfg.WorkingDir)
+		}
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			return fmt.Errorf("writeRoot %s is not a directory", cfg.WriteRoot)
+		}
+	}
+
+	if cfg.UnattendedPermissions != "" && !cfg.UnattendedPermissions.Valid() {
+		return fmt.Errorf("unattendedPermissions must be %s, %s or %s, got %q",
+			UnattendedAllowAll, UnattendedDenyAll, UnattendedAllowReadOnly, cfg.UnattendedPermissions)
+	}
+
+	if cfg.Review.MaxFixRounds < 0 {
+		return fmt.Errorf("review.maxFixRounds must not be negative, got %d", cfg.Review.MaxFixRounds)
+	}
+
 	// Validate LSP configurations
 	for language, lspConfig := range cfg.LSP {
 		if lspConfig.Command == "" && !lspConfig.Disabled {
@@ -602,21 +993,18 @@ func Validate() error {
 	return nil
 }
 
-// getProviderAPIKey gets the API key for a provider from environment variables
+// getProviderAPIKey gets the API key for a provider from environment variables,
+// falling back to the shared credentials file.
 func getProviderAPIKey(provider models.ModelProvider) string {
+	if envVar, ok := providerAPIKeyEnv[provider]; ok {
+		if apiKey := os.Getenv(envVar); apiKey != "" {
+			return apiKey
+		}
+		return readCredentialsFile()[provider].APIKey
+	}
 	switch provider {
-	case models.ProviderAnthropic:
-		return os.Getenv("ANTHROPIC_API_KEY")
-	case models.ProviderOpenAI:
-		return os.Getenv("OPENAI_API_KEY")
-	case models.ProviderGemini:
-		return os.Getenv("GEMINI_API_KEY")
-	case models.ProviderGROQ:
-		return os.Getenv("GROQ_API_KEY")
 	case models.ProviderAzure:
 		return os.Getenv("AZURE_OPENAI_API_KEY")
-	case models.ProviderOpenRouter:
-		return os.Getenv("OPENROUTER_API_KEY")
 	case models.ProviderBedrock:
 		if hasAWSCredentials() {
 			return "aws-credentials-available"
@@ -632,7 +1020,7 @@ func getProviderAPIKey(provider models.ModelProvider) string {
 // setDefaultModelForAgent sets a default model for an agent based on available providers
 func setDefaultModelForAgent(agent AgentName) bool {
 	// Check providers in order of preference
-	if apiKey := os.Getenv("ANTHROPIC_API_KEY"); apiKey != "" {
+	if apiKey := getProviderAPIKey(models.ProviderAnthropic); apiKey != "" {
 		maxTokens := int64(5000)
 		if agent == AgentTitle {
 			maxTokens = 80
@@ -644,7 +1032,7 @@ func setDefaultModelForAgent(agent AgentName) bool {
 		return true
 	}
 
-	if apiKey := os.Getenv("OPENAI_API_KEY"); apiKey != "" {
+	if apiKey := getProviderAPIKey(models.ProviderOpenAI); apiKey != "" {
 		var model models.ModelID
 		maxTokens := int64(5000)
 		reasoningEffort := ""
@@ -672,7 +1060,7 @@ func setDefaultModelForAgent(agent AgentName) bool {
 		return true
 	}
 
-	if apiKey := os.Getenv("OPENROUTER_API_KEY"); apiKey != "" {
+	if apiKey := getProviderAPIKey(models.ProviderOpenRouter); apiKey != "" {
 		var model models.ModelID
 		maxTokens := int64(5000)
 		reasoningEffort := ""
@@ -700,7 +1088,7 @@ func setDefaultModelForAgent(agent AgentName) bool {
 		return true
 	}
 
-	if apiKey := os.Getenv("GEMINI_API_KEY"); apiKey != "" {
+	if apiKey := getProviderAPIKey(models.ProviderGemini); apiKey != "" {
 		var model models.ModelID
 		maxTokens := int64(5000)
 
@@ -718,7 +1106,7 @@ func setDefaultModelForAgent(agent AgentName) bool {
 		return true
 	}
 
-	if apiKey := os.Getenv("GROQ_API_KEY"); apiKey != "" {
+	if apiKey := getProviderAPIKey(models.ProviderGROQ); apiKey != "" {
 		maxTokens := int64(5000)
 		if agent == AgentTitle {
 			maxTokens = 80
@@ -826,6 +1214,21 @@ func WorkingDirectory() string {
 	return cfg.WorkingDir
 }
 
+// WriteRoot returns the directory the agent may change files in. It is the
+// working directory unless a narrower writeRoot is configured.
+func WriteRoot() string {
+	if cfg == nil {
+		panic("config not loaded")
+	}
+	if cfg.WriteRoot == "" {
+		return cfg.WorkingDir
+	}
+	if filepath.IsAbs(cfg.WriteRoot) {
+		return filepath.Clean(cfg.WriteRoot)
+	}
+	return filepath.Join(cfg.WorkingDir, cfg.WriteRoot)
+}
+
 func UpdateAgentModel(agentName AgentName, modelID models.ModelID) error {
 	if cfg == nil {
 		panic("config not loaded")
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/llm/models"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomModelAsProviderDefault(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	for _, env := range providerAPIKeyEnv {
+		t.Setenv(env, "")
+	}
+	cfg = nil
+	viper.Reset()
+	t.Cleanup(func() {
+		cfg = nil
+		viper.Reset()
+		delete(models.SupportedModels, "my-gpt")
+	})
+
+	workingDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workingDir, ".opencode.json"), []byte(`{
+  "providers": {
+    "openai": {"apiKey": "sk-test", "defaultModel": "my-gpt"}
+  },
+  "models": [
+    {"id": "my-gpt", "provider": "openai", "apiModel": "gpt-custom", "contextWindow": 64000}
+  ]
+}`), 0o644))
+
+	loaded, err := Load(workingDir, false)
+	require.NoError(t, err)
+	assert.Equal(t, models.ModelID("my-gpt"), loaded.Agents[AgentCoder].Model)
+	assert.Equal(t, models.ModelID("my-gpt"), loaded.Agents[AgentTask].Model)
+}
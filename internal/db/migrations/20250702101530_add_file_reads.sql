@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS file_reads (
+    session_id TEXT NOT NULL,
+    path TEXT NOT NULL,
+    read_at INTEGER NOT NULL,     -- Unix timestamp in nanoseconds, 0 if never read
+    written_at INTEGER NOT NULL,  -- Unix timestamp in nanoseconds, 0 if never written
+    read_hash TEXT NOT NULL,
+    PRIMARY KEY (session_id, path),
+    FOREIGN KEY (session_id) REFERENCES sessions (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_file_reads_session_id ON file_reads (session_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_file_reads_session_id;
+DROP TABLE IF EXISTS file_reads;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+UPDATE memories SET key = '_plan' WHERE key = 'plan';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE memories SET key = 'plan' WHERE key = '_plan';
+-- +goose StatementEnd
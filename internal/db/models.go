@@ -18,6 +18,23 @@ type File struct {
 	UpdatedAt int64  `json:"updated_at"`
 }
 
+type FileRead struct {
+	SessionID string `json:"session_id"`
+	Path      string `json:"path"`
+	ReadAt    int64  `json:"read_at"`
+	WrittenAt int64  `json:"written_at"`
+	ReadHash  string `json:"read_hash"`
+}
+
+type Memory struct {
+	ID        string `json:"id"`
+	SessionID string `json:"session_id"`
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
 type Message struct {
 	ID         string         `json:"id"`
 	SessionID  string         `json:"session_id"`
@@ -40,4 +57,7 @@ type Session struct {
 	UpdatedAt        int64          `json:"updated_at"`
 	CreatedAt        int64          `json:"created_at"`
 	SummaryMessageID sql.NullString `json:"summary_message_id"`
+	Model            sql.NullString `json:"model"`
+	BranchedFrom     sql.NullString `json:"branched_from"`
+	TitleLocked      bool           `json:"title_locked"`
 }
@@ -0,0 +1,20 @@
+-- name: ListFileReadsBySession :many
+SELECT *
+FROM file_reads
+WHERE session_id = ?
+ORDER BY path ASC;
+
+-- name: UpsertFileRead :exec
+INSERT INTO file_reads (
+    session_id,
+    path,
+    read_at,
+    written_at,
+    read_hash
+) VALUES (
+    ?, ?, ?, ?, ?
+)
+ON CONFLICT (session_id, path) DO UPDATE SET
+    read_at = excluded.read_at,
+    written_at = excluded.written_at,
+    read_hash = excluded.read_hash;
@@ -0,0 +1,31 @@
+-- name: GetMemory :one
+SELECT *
+FROM memories
+WHERE session_id = ? AND key = ?
+LIMIT 1;
+
+-- name: ListMemoriesBySession :many
+SELECT *
+FROM memories
+WHERE session_id = ?
+ORDER BY created_at ASC;
+
+-- name: UpsertMemory :one
+INSERT INTO memories (
+    id,
+    session_id,
+    key,
+    value,
+    created_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
+)
+ON CONFLICT (session_id, key) DO UPDATE SET
+    value = excluded.value,
+    updated_at = strftime('%s', 'now')
+RETURNING *;
+
+-- name: DeleteMemory :exec
+DELETE FROM memories
+WHERE session_id = ? AND key = ?;
@@ -23,6 +23,21 @@ INSERT INTO messages (
 )
 RETURNING *;
 
+-- name: CopyMessage :one
+INSERT INTO messages (
+    id,
+    session_id,
+    role,
+    parts,
+    model,
+    created_at,
+    updated_at,
+    finished_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
 -- name: UpdateMessage :exec
 UPDATE messages
 SET
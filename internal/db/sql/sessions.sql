@@ -8,6 +8,7 @@ INSERT INTO sessions (
     completion_tokens,
     cost,
     summary_message_id,
+    branched_from,
     updated_at,
     created_at
 ) VALUES (
@@ -19,6 +20,7 @@ INSERT INTO sessions (
     ?,
     ?,
     null,
+    ?,
     strftime('%s', 'now'),
     strftime('%s', 'now')
 ) RETURNING *;
@@ -34,17 +36,31 @@ FROM sessions
 WHERE parent_session_id is NULL
 ORDER BY created_at DESC;
 
+-- name: ListChildSessions :many
+SELECT *
+FROM sessions
+WHERE parent_session_id = ?
+ORDER BY created_at ASC;
+
 -- name: UpdateSession :one
 UPDATE sessions
 SET
-    title = ?,
+    title = CASE WHEN title_locked THEN title ELSE ? END,
     prompt_tokens = ?,
     completion_tokens = ?,
     summary_message_id = ?,
-    cost = ?
+    cost = ?,
+    model = ?
 WHERE id = ?
 RETURNING *;
 
+-- name: RenameSession :one
+UPDATE sessions
+SET
+    title = ?,
+    title_locked = TRUE
+WHERE id = ?
+RETURNING *;
 
 -- name: DeleteSession :exec
 DELETE FROM sessions
@@ -0,0 +1,66 @@
+// Package export renders sessions into formats that can be shared outside of OpenCode.
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/session"
+)
+
+// maxToolResultLines bounds how much of each tool result ends up in the transcript.
+const maxToolResultLines = 20
+
+// Markdown renders the session transcript as markdown.
+func Markdown(sess session.Session, messages []message.Message) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# %s\n", sess.Title))
+
+	// Tool results don't carry the tool name, look it up from the calls
+	toolNames := make(map[string]string)
+	for _, msg := range messages {
+		switch msg.Role {
+		case message.User:
+			sb.WriteString("\n## User\n\n")
+			sb.WriteString(strings.TrimSpace(msg.Content().String()))
+			sb.WriteString("\n")
+			for _, attachment := range msg.BinaryContent() {
+				sb.WriteString(fmt.Sprintf("\n_Attachment: %s_\n", attachment.Path))
+			}
+		case message.Assistant:
+			sb.WriteString("\n## Assistant\n")
+			if content := strings.TrimSpace(msg.Content().String()); content != "" {
+				sb.WriteString("\n")
+				sb.WriteString(content)
+				sb.WriteString("\n")
+			}
+			for _, toolCall := range msg.ToolCalls() {
+				toolNames[toolCall.ID] = toolCall.Name
+				sb.WriteString(fmt.Sprintf("\n**Tool call: %s**\n\n```json\n%s\n```\n", toolCall.Name, strings.TrimSpace(toolCall.Input)))
+			}
+		case message.Review:
+			sb.WriteString("\n## Review\n\n")
+			sb.WriteString(strings.TrimSpace(msg.Content().String()))
+			sb.WriteString("\n")
+		case message.Tool:
+			for _, result := range msg.ToolResults() {
+				label := "Tool result"
+				if result.IsError {
+					label = "Tool error"
+				}
+				sb.WriteString(fmt.Sprintf("\n**%s: %s**\n\n```\n%s\n```\n", label, toolNames[result.ToolCallID], truncateLines(result.Content, maxToolResultLines)))
+			}
+		}
+	}
+	return sb.String()
+}
+
+func truncateLines(content string, limit int) string {
+	content = strings.TrimSpace(content)
+	lines := strings.Split(content, "\n")
+	if len(lines) <= limit {
+		return content
+	}
+	return strings.Join(lines[:limit], "\n") + fmt.Sprintf("\n... (%d more lines)", len(lines)-limit)
+}
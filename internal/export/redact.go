@@ -0,0 +1,102 @@
+package export
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	redactedSecret = "[REDACTED]"
+	redactedID     = "[ID]"
+	redactedPath   = "<path>"
+)
+
+var (
+	secretPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`sk-(?:ant-|proj-)?[A-Za-z0-9_\-]{16,}`),
+		regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),
+		regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{20,}\b`),
+		regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9\-]{10,}\b`),
+		regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`),
+		regexp.MustCompile(`(?i)\bBearer\s+[A-Za-z0-9._\-]{8,}`),
+	}
+	// Matches assignments such as API_KEY=value or "password": "value"
+	secretAssignmentPattern = regexp.MustCompile(`(?i)((?:api[_-]?key|secret|token|password|passwd)["']?\s*[:=]\s*["']?)([^\s"',]{4,})`)
+
+	idPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`),
+		regexp.MustCompile(`\b(?:toolu|msg|call|chatcmpl|req)_[A-Za-z0-9]{8,}\b`),
+	}
+
+	// Absolute paths under common user and system locations
+	absolutePathPattern = regexp.MustCompile(`(?:/(?:home|Users|root|tmp|var|private|opt|mnt)|[A-Za-z]:\\Users)(?:[/\\][^\s/\\'"` + "`" + `()\[\]<>,;:]+)+`)
+)
+
+// Redactor removes environment specific details from text so it can be
+// shared in bug reports: absolute paths, secrets and API identifiers.
+type Redactor struct {
+	workingDir string
+	homeDir    string
+	secrets    []string
+	// workingDirPattern matches the working directory only where a path
+	// component ends, so a sibling such as project2 is left alone.
+	workingDirPattern *regexp.Regexp
+}
+
+// NewRedactor creates a redactor. Paths under workingDir are made relative,
+// paths under homeDir are rewritten to start with ~ and every value in
+// secrets is masked wherever it appears.
+func NewRedactor(workingDir, homeDir string, secrets []string) *Redactor {
+	known := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		if len(strings.TrimSpace(secret)) >= 4 {
+			known = append(known, secret)
+		}
+	}
+	// Replace longer secrets first so overlapping values are fully masked
+	sort.Slice(known, func(i, j int) bool {
+		return len(known[i]) > len(known[j])
+	})
+	r := &Redactor{
+		workingDir: strings.TrimRight(workingDir, `/\`),
+		homeDir:    strings.TrimRight(homeDir, `/\`),
+		secrets:    known,
+	}
+	if r.workingDir != "" {
+		r.workingDirPattern = regexp.MustCompile(regexp.QuoteMeta(r.workingDir) + `([/\\]|[^A-Za-z0-9_.~\-]|$)`)
+	}
+	return r
+}
+
+// Redact returns text with paths, secrets and identifiers replaced by placeholders.
+func (r *Redactor) Redact(text string) string {
+	for _, secret := range r.secrets {
+		text = strings.ReplaceAll(text, secret, redactedSecret)
+	}
+	for _, pattern := range secretPatterns {
+		text = pattern.ReplaceAllString(text, redactedSecret)
+	}
+	text = secretAssignmentPattern.ReplaceAllString(text, "${1}"+redactedSecret)
+
+	for _, pattern := range idPatterns {
+		text = pattern.ReplaceAllString(text, redactedID)
+	}
+
+	if r.workingDirPattern != nil {
+		text = r.workingDirPattern.ReplaceAllStringFunc(text, func(match string) string {
+			next := match[len(r.workingDir):]
+			if next == `\` {
+				next = "/"
+			}
+			return "." + next
+		})
+	}
+	if r.homeDir != "" {
+		text = strings.ReplaceAll(text, r.homeDir+"/", "~/")
+	}
+	return absolutePathPattern.ReplaceAllStringFunc(text, func(path string) string {
+		return redactedPath + "/" + filepath.Base(filepath.ToSlash(strings.ReplaceAll(path, `\`, "/")))
+	})
+}
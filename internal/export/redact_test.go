@@ -0,0 +1,74 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactor_Redact(t *testing.T) {
+	r := NewRedactor("/home/alice/project", "/home/alice", []string{"my-configured-key-1234"})
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "makes working directory paths relative",
+			input:    "edited /home/alice/project/internal/app.go",
+			expected: "edited ./internal/app.go",
+		},
+		{
+			name:     "makes the working directory itself relative",
+			input:    "cd /home/alice/project && ls",
+			expected: "cd . && ls",
+		},
+		{
+			name:     "leaves sibling directories of the working directory alone",
+			input:    "read /home/alice/project2/main.go",
+			expected: "read ~/project2/main.go",
+		},
+		{
+			name:     "rewrites home directory paths",
+			input:    "read /home/alice/.config/opencode/.opencode.json",
+			expected: "read ~/.config/opencode/.opencode.json",
+		},
+		{
+			name:     "replaces other absolute paths with a placeholder",
+			input:    "see /Users/bob/work/main.go for details",
+			expected: "see <path>/main.go for details",
+		},
+		{
+			name:     "masks configured secrets",
+			input:    "using key my-configured-key-1234",
+			expected: "using key [REDACTED]",
+		},
+		{
+			name:     "masks well known key formats",
+			input:    "export OPENAI=sk-proj-abcdefghijklmnopqrstuvwxyz",
+			expected: "export OPENAI=[REDACTED]",
+		},
+		{
+			name:     "masks secret assignments",
+			input:    `password: hunter22`,
+			expected: `password: [REDACTED]`,
+		},
+		{
+			name:     "removes API identifiers",
+			input:    "call toolu_01ABCDEFGHIJKLMN in session 6f1c2a3b-1234-4cde-8f00-0123456789ab",
+			expected: "call [ID] in session [ID]",
+		},
+		{
+			name:     "leaves urls untouched",
+			input:    "see https://example.com/docs/page",
+			expected: "see https://example.com/docs/page",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, r.Redact(tt.input))
+		})
+	}
+}
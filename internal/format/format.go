@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 )
 
 // OutputFormat represents the output format type for non-interactive mode
@@ -97,3 +98,35 @@ func formatAsJSON(content string) string {
 
 	return string(jsonBytes)
 }
+
+// Tokens formats a token count for display, e.g. 950, 12.3K or 1.2M.
+func Tokens(tokens int64) string {
+	switch {
+	case tokens >= 1_000_000:
+		return strings.Replace(fmt.Sprintf("%.1fM", float64(tokens)/1_000_000), ".0M", "M", 1)
+	case tokens >= 1_000:
+		return strings.Replace(fmt.Sprintf("%.1fK", float64(tokens)/1_000), ".0K", "K", 1)
+	default:
+		return fmt.Sprintf("%d", tokens)
+	}
+}
+
+// Age formats how long ago then was, e.g. "just now", "5m ago" or "3d ago".
+// Anything older than a month is shown as its date.
+func Age(then, now time.Time) string {
+	d := now.Sub(then)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d/time.Hour))
+	case d < 30*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(d/(24*time.Hour)))
+	case then.Year() == now.Year():
+		return then.Format("Jan 2")
+	default:
+		return then.Format("Jan 2, 2006")
+	}
+}
@@ -0,0 +1,107 @@
+package format
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// CutPoint returns where to cut content so the result is at most limit bytes.
+// It prefers to cut after a line break in the second half of the limit and
+// never splits a UTF-8 character.
+func CutPoint(content string, limit int) int {
+	if limit >= len(content) {
+		return len(content)
+	}
+	if limit <= 0 {
+		return 0
+	}
+	if newline := strings.LastIndexByte(content[:limit], '\n'); newline > limit/2 {
+		return newline + 1
+	}
+	end := limit
+	for end > 0 && !utf8.RuneStart(content[end]) {
+		end--
+	}
+	return end
+}
+
+// Truncate returns content unchanged if it fits in limit bytes. Otherwise it
+// cuts it with CutPoint, closes a fenced code block the cut left open and
+// appends notice on its own line.
+func Truncate(content string, limit int, notice string) string {
+	if len(content) <= limit {
+		return content
+	}
+	truncated := strings.TrimSuffix(CloseFence(content[:CutPoint(content, limit)]), "\n")
+	if notice == "" {
+		return truncated
+	}
+	return truncated + "\n\n" + notice
+}
+
+// OpenFence returns the fence of a markdown code block that is still open at
+// the end of text, or an empty string if every block is closed.
+func OpenFence(text string) string {
+	fence := ""
+	for line := range strings.SplitSeq(text, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if len(line)-len(trimmed) > 3 {
+			continue
+		}
+		marker := fenceMarker(trimmed)
+		switch {
+		case marker == "":
+		case fence == "":
+			fence = marker
+		case marker[0] == fence[0] && len(marker) >= len(fence) && strings.TrimSpace(trimmed) == marker:
+			fence = ""
+		}
+	}
+	return fence
+}
+
+// CloseFence appends a closing fence to text if it ends inside a fenced code
+// block.
+func CloseFence(text string) string {
+	fence := OpenFence(text)
+	if fence == "" {
+		return text
+	}
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return text + fence
+}
+
+// Fence wraps content in a fenced code block whose fence is longer than any
+// run of backticks in content, so fences inside it cannot end the block.
+func Fence(content, lang string) string {
+	longest, run := 0, 0
+	for i := 0; i < len(content); i++ {
+		if content[i] == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	return fence + lang + "\n" + content + "\n" + fence
+}
+
+// fenceMarker returns the run of three or more backticks or tildes that line
+// starts with.
+func fenceMarker(line string) string {
+	if line == "" || (line[0] != '`' && line[0] != '~') {
+		return ""
+	}
+	n := len(line) - len(strings.TrimLeft(line, line[:1]))
+	if n < 3 {
+		return ""
+	}
+	// The info string of a backtick fence cannot contain backticks.
+	if line[0] == '`' && strings.Contains(line[n:], "`") {
+		return ""
+	}
+	return line[:n]
+}
@@ -0,0 +1,66 @@
+package format
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		limit   int
+		want    string
+	}{
+		{
+			name:    "fits",
+			content: "short",
+			limit:   10,
+			want:    "short",
+		},
+		{
+			name:    "closes open code block",
+			content: "intro\n```go\nfunc a() {}\nfunc b() {}\n```\n",
+			limit:   30,
+			want:    "intro\n```go\nfunc a() {}\n```\n\n[truncated]",
+		},
+		{
+			name:    "keeps closed code block",
+			content: "```\ncode\n```\nmore text after the block that is long",
+			limit:   20,
+			want:    "```\ncode\n```\n\n[truncated]",
+		},
+		{
+			name:    "does not split runes",
+			content: "héllo wörld",
+			limit:   2,
+			want:    "h\n\n[truncated]",
+		},
+		{
+			name:    "longer closing fence",
+			content: "~~~~\na\n~~~~~\n````\nlong code block here\nmore code",
+			limit:   40,
+			want:    "~~~~\na\n~~~~~\n````\nlong code block here\n````\n\n[truncated]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Truncate(tt.content, tt.limit, "[truncated]")
+			if got != tt.want {
+				t.Errorf("Truncate() = %q, want %q", got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("Truncate() returned invalid UTF-8: %q", got)
+			}
+		})
+	}
+}
+
+func TestFence(t *testing.T) {
+	if got, want := Fence("a\n```go\nb\n```", "md"), "````md\na\n```go\nb\n```\n````"; got != want {
+		t.Errorf("Fence() = %q, want %q", got, want)
+	}
+	if got, want := Fence("plain", ""), "```\nplain\n```"; got != want {
+		t.Errorf("Fence() = %q, want %q", got, want)
+	}
+}
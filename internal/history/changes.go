@@ -0,0 +1,136 @@
+package history
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/diff"
+)
+
+// FileChange describes how a file changed over a session.
+type FileChange struct {
+	Path      string
+	Additions int
+	Removals  int
+	// ChangedAt is when the latest version of the file was recorded.
+	ChangedAt int64
+}
+
+// SessionChanges returns the files whose latest version differs from the
+// first version recorded in the session, sorted by path.
+func SessionChanges(ctx context.Context, files Service, sessionID string) ([]FileChange, error) {
+	_, changes, err := SessionDiff(ctx, files, sessionID)
+	return changes, err
+}
+
+// SessionDiff returns the unified diff of every file changed in the session,
+// from its first recorded version to its latest, along with the changes.
+func SessionDiff(ctx context.Context, files Service, sessionID string) (string, []FileChange, error) {
+	versions, err := changedFileVersions(ctx, files, sessionID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var sb strings.Builder
+	changes := make([]FileChange, 0, len(versions))
+	for _, v := range versions {
+		fileDiff, additions, removals := diff.GenerateDiff(v.initial.Content, v.latest.Content, v.latest.Path)
+		if additions == 0 && removals == 0 {
+			continue
+		}
+		sb.WriteString(fileDiff)
+		if !strings.HasSuffix(fileDiff, "\n") {
+			sb.WriteString("\n")
+		}
+		changes = append(changes, FileChange{
+			Path:      v.latest.Path,
+			Additions: additions,
+			Removals:  removals,
+			ChangedAt: v.latest.CreatedAt,
+		})
+	}
+	return sb.String(), changes, nil
+}
+
+type fileVersions struct {
+	initial, latest File
+}
+
+// changedFileVersions returns the first and latest versions of the files
+// whose content changed in the session, sorted by path.
+func changedFileVersions(ctx context.Context, files Service, sessionID string) ([]fileVersions, error) {
+	latestFiles, err := files.ListLatestSessionFiles(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	allFiles, err := files.ListBySession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	initialVersions := make(map[string]File)
+	for _, file := range allFiles {
+		if file.Version == InitialVersion {
+			initialVersions[file.Path] = file
+		}
+	}
+
+	versions := make([]fileVersions, 0, len(latestFiles))
+	for _, file := range latestFiles {
+		if file.Version == InitialVersion {
+			continue
+		}
+		initialVersion, ok := initialVersions[file.Path]
+		if !ok || initialVersion.Content == file.Content {
+			continue
+		}
+		versions = append(versions, fileVersions{initial: initialVersion, latest: file})
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].latest.Path < versions[j].latest.Path
+	})
+	return versions, nil
+}
+
+// TurnDiff returns the unified diff of the files changed since the given
+// time, from the version each file had before then to its latest.
+func TurnDiff(ctx context.Context, files Service, sessionID string, since int64) (string, error) {
+	allFiles, err := files.ListBySession(ctx, sessionID)
+	if err != nil {
+		return "", err
+	}
+
+	// Versions are listed from oldest to newest.
+	before := make(map[string]File)
+	latest := make(map[string]File)
+	changed := make(map[string]bool)
+	for _, file := range allFiles {
+		if _, ok := before[file.Path]; !ok || file.CreatedAt < since {
+			before[file.Path] = file
+		}
+		latest[file.Path] = file
+		if file.CreatedAt >= since && file.Version != InitialVersion {
+			changed[file.Path] = true
+		}
+	}
+
+	paths := make([]string, 0, len(changed))
+	for path := range changed {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var sb strings.Builder
+	for _, path := range paths {
+		fileDiff, additions, removals := diff.GenerateDiff(before[path].Content, latest[path].Content, path)
+		if additions == 0 && removals == 0 {
+			continue
+		}
+		sb.WriteString(fileDiff)
+		if !strings.HasSuffix(fileDiff, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,211 @@
+// Package language detects the programming language of a file, so tools and
+// the TUI treat a file the same way whether they highlight, parse or send it
+// to a language server.
+package language
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ID is a canonical language identifier. The values are the language
+// identifiers of the Language Server Protocol.
+type ID string
+
+const (
+	Unknown         ID = ""
+	C               ID = "c"
+	CPP             ID = "cpp"
+	CSharp          ID = "csharp"
+	CSS             ID = "css"
+	Dart            ID = "dart"
+	Diff            ID = "diff"
+	Dockerfile      ID = "dockerfile"
+	Elixir          ID = "elixir"
+	Erlang          ID = "erlang"
+	Go              ID = "go"
+	Haskell         ID = "haskell"
+	HTML            ID = "html"
+	Java            ID = "java"
+	JavaScript      ID = "javascript"
+	JavaScriptReact ID = "javascriptreact"
+	JSON            ID = "json"
+	Kotlin          ID = "kotlin"
+	Lua             ID = "lua"
+	Makefile        ID = "makefile"
+	Markdown        ID = "markdown"
+	Perl            ID = "perl"
+	PHP             ID = "php"
+	Python          ID = "python"
+	Ruby            ID = "ruby"
+	Rust            ID = "rust"
+	Scala           ID = "scala"
+	ShellScript     ID = "shellscript"
+	SQL             ID = "sql"
+	Swift           ID = "swift"
+	TOML            ID = "toml"
+	TypeScript      ID = "typescript"
+	TypeScriptReact ID = "typescriptreact"
+	XML             ID = "xml"
+	YAML            ID = "yaml"
+)
+
+// extensions maps lower-case file extensions to their language. Languages
+// without a constant are only needed by the language servers.
+var extensions = map[string]ID{
+	".abap": "abap", ".bat": "bat", ".bib": "bibtex", ".bibtex": "bibtex",
+	".clj": "clojure", ".coffee": "coffeescript",
+	".c": C, ".h": C,
+	".cpp": CPP, ".cxx": CPP, ".cc": CPP, ".c++": CPP, ".hpp": CPP, ".hh": CPP, ".hxx": CPP,
+	".cs": CSharp, ".css": CSS, ".d": "d", ".pas": "pascal", ".pascal": "pascal",
+	".diff": Diff, ".patch": Diff, ".dart": Dart, ".dockerfile": Dockerfile,
+	".ex": Elixir, ".exs": Elixir, ".erl": Erlang, ".hrl": Erlang,
+	".fs": "fsharp", ".fsi": "fsharp", ".fsx": "fsharp", ".fsscript": "fsharp",
+	".gitcommit": "git-commit", ".gitrebase": "rebase",
+	".go": Go, ".groovy": "groovy", ".hbs": "handlebars", ".handlebars": "handlebars",
+	".hs": Haskell, ".html": HTML, ".htm": HTML, ".ini": "ini",
+	".java": Java, ".kt": Kotlin, ".kts": Kotlin,
+	".js": JavaScript, ".mjs": JavaScript, ".cjs": JavaScript, ".jsx": JavaScriptReact,
+	".json": JSON, ".tex": "latex", ".latex": "latex", ".less": "less", ".lua": Lua,
+	".makefile": Makefile, ".mk": Makefile, ".md": Markdown, ".markdown": Markdown,
+	".m": "objective-c", ".mm": "objective-cpp", ".pl": Perl, ".pm": "perl6",
+	".php": PHP, ".ps1": "powershell", ".psm1": "powershell", ".pug": "jade", ".jade": "jade",
+	".py": Python, ".pyi": Python, ".r": "r", ".cshtml": "razor", ".razor": "razor",
+	".rb": Ruby, ".rs": Rust, ".scss": "scss", ".sass": "sass", ".scala": Scala,
+	".shader": "shaderlab", ".sh": ShellScript, ".bash": ShellScript, ".zsh": ShellScript, ".ksh": ShellScript,
+	".sql": SQL, ".swift": Swift, ".toml": TOML,
+	".ts": TypeScript, ".mts": TypeScript, ".cts": TypeScript, ".tsx": TypeScriptReact,
+	".xml": XML, ".xsl": "xsl", ".yaml": YAML, ".yml": YAML,
+}
+
+// fileNames maps file names without a telling extension to their language.
+var fileNames = map[string]ID{
+	"makefile":    Makefile,
+	"gnumakefile": Makefile,
+	"dockerfile":  Dockerfile,
+	"gemfile":     Ruby,
+	"rakefile":    Ruby,
+	".bashrc":     ShellScript,
+	".zshrc":      ShellScript,
+	".profile":    ShellScript,
+}
+
+// interpreters maps the interpreter of a shebang line to its language.
+var interpreters = map[string]ID{
+	"sh": ShellScript, "bash": ShellScript, "zsh": ShellScript, "ksh": ShellScript, "dash": ShellScript,
+	"python": Python, "node": JavaScript, "deno": TypeScript, "bun": TypeScript,
+	"ruby": Ruby, "perl": Perl, "php": PHP, "lua": Lua,
+}
+
+// braceSyntax holds the languages whose blocks are delimited by braces.
+var braceSyntax = map[ID]bool{
+	C: true, CPP: true, CSharp: true, Java: true, Kotlin: true, Scala: true,
+	Swift: true, Dart: true, JavaScript: true, JavaScriptReact: true,
+	TypeScript: true, TypeScriptReact: true, Rust: true, PHP: true,
+}
+
+// families groups languages served by the same kind of language server.
+var families = map[ID]ID{
+	JavaScript:      TypeScript,
+	JavaScriptReact: TypeScript,
+	TypeScriptReact: TypeScript,
+	CPP:             C,
+	"objective-c":   C,
+	"objective-cpp": C,
+}
+
+// Detect returns the language of path from its extension or file name.
+func Detect(path string) ID {
+	base := strings.ToLower(filepath.Base(path))
+	if id, ok := extensions[filepath.Ext(base)]; ok {
+		return id
+	}
+	return fileNames[base]
+}
+
+// DetectContent returns the language of path, falling back to the shebang
+// line of content when the name does not tell.
+func DetectContent(path string, content []byte) ID {
+	if id := Detect(path); id != Unknown {
+		return id
+	}
+	return shebang(content)
+}
+
+// DetectFile returns the language of the file at path, reading the start of
+// the file when the name does not tell.
+func DetectFile(path string) ID {
+	if id := Detect(path); id != Unknown {
+		return id
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return Unknown
+	}
+	defer f.Close()
+	head := make([]byte, 256)
+	n, _ := f.Read(head)
+	return shebang(head[:n])
+}
+
+// shebang returns the language of the interpreter named by a #! line, such
+// as "#!/usr/bin/env python3" or "#!/bin/bash".
+func shebang(content []byte) ID {
+	if !bytes.HasPrefix(content, []byte("#!")) {
+		return Unknown
+	}
+	line, _, _ := bytes.Cut(content[2:], []byte("\n"))
+	fields := strings.Fields(string(line))
+	if len(fields) == 0 {
+		return Unknown
+	}
+	interpreter := filepath.Base(fields[0])
+	if interpreter == "env" {
+		// Skip options such as "env -S".
+		interpreter = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") {
+				interpreter = field
+				break
+			}
+		}
+	}
+	// python3, python3.12 and perl5 name the same languages.
+	interpreter = strings.TrimRight(interpreter, "0123456789.")
+	return interpreters[interpreter]
+}
+
+// BraceSyntax reports whether blocks of the language are delimited by
+// braces, so a function can be found by matching them.
+func (id ID) BraceSyntax() bool {
+	return braceSyntax[id]
+}
+
+// Family returns the language whose language server also handles id, or id
+// itself.
+func (id ID) Family() ID {
+	if family, ok := families[id]; ok {
+		return family
+	}
+	return id
+}
+
+// Fence returns the name of the language for a markdown code fence, as
+// understood by the syntax highlighter.
+func (id ID) Fence() string {
+	switch id {
+	case Unknown:
+		return "text"
+	case JavaScriptReact:
+		return "jsx"
+	case TypeScriptReact:
+		return "tsx"
+	case ShellScript:
+		return "bash"
+	case "objective-c":
+		return "objc"
+	}
+	return string(id)
+}
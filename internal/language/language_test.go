@@ -0,0 +1,31 @@
+package language
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    ID
+	}{
+		{"extension", "/src/main.go", "", Go},
+		{"upper case extension", "/src/App.TSX", "", TypeScriptReact},
+		{"file name", "/src/Makefile", "", Makefile},
+		{"extension wins over shebang", "/bin/run.py", "#!/bin/bash\n", Python},
+		{"shebang", "/bin/run", "#!/bin/bash\necho hi\n", ShellScript},
+		{"env shebang with version", "/bin/run", "#!/usr/bin/env python3.12\n", Python},
+		{"env shebang with options", "/bin/run", "#!/usr/bin/env -S deno run\n", TypeScript},
+		{"unknown", "/bin/run", "plain text\n", Unknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DetectContent(tt.path, []byte(tt.content)))
+		})
+	}
+}
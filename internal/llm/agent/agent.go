@@ -4,22 +4,39 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
+	"unicode"
 
 	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/export"
+	"github.com/opencode-ai/opencode/internal/history"
 	"github.com/opencode-ai/opencode/internal/llm/models"
 	"github.com/opencode-ai/opencode/internal/llm/prompt"
 	"github.com/opencode-ai/opencode/internal/llm/provider"
 	"github.com/opencode-ai/opencode/internal/llm/tools"
 	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/memory"
 	"github.com/opencode-ai/opencode/internal/message"
 	"github.com/opencode-ai/opencode/internal/permission"
 	"github.com/opencode-ai/opencode/internal/pubsub"
 	"github.com/opencode-ai/opencode/internal/session"
 )
 
+const (
+	// titleUpdateInterval throttles how often a streaming title is saved.
+	titleUpdateInterval = 150 * time.Millisecond
+	// maxTitleLength is a hard bound on generated titles; the title prompt asks
+	// for at most 50 characters but models do not always comply.
+	maxTitleLength = 80
+	// maxReportedChanges is the number of changed files listed to the model
+	// at the start of a turn.
+	maxReportedChanges = 50
+)
+
 // Common errors
 var (
 	ErrRequestCancelled = errors.New("request cancelled by user")
@@ -39,12 +56,30 @@ type AgentEvent struct {
 	Message message.Message
 	Error   error
 
+	// StepLimit is set when the turn paused because it used up its step
+	// budget, and the user should be asked whether it continues.
+	StepLimit *StepLimitPause
+
 	// When summarizing
 	SessionID string
 	Progress  string
 	Done      bool
 }
 
+// SummaryPreview is a generated summary of a session awaiting approval.
+type SummaryPreview struct {
+	SessionID string
+	Messages  int
+	Summary   string
+	Usage     provider.TokenUsage
+}
+
+// ToolState describes whether a tool is available to the agent in a session.
+type ToolState struct {
+	Name    string
+	Enabled bool
+}
+
 type Service interface {
 	pubsub.Suscriber[AgentEvent]
 	Model() models.Model
@@ -53,27 +88,78 @@ type Service interface {
 	IsSessionBusy(sessionID string) bool
 	IsBusy() bool
 	Update(agentName config.AgentName, modelID models.ModelID) (models.Model, error)
+	SessionModel(ctx context.Context, sessionID string) models.Model
+	SetSessionModel(ctx context.Context, sessionID string, modelID models.ModelID) error
 	Summarize(ctx context.Context, sessionID string) error
+	PreviewSummary(ctx context.Context, sessionID string) (SummaryPreview, error)
+	ApplySummary(ctx context.Context, preview SummaryPreview) error
+	ResetContext(ctx context.Context, sessionID string, archive bool) (string, error)
+	DropOldestTurns(ctx context.Context, overflow *ContextOverflowError) (int, error)
+	Tools(sessionID string) []ToolState
+	SetToolEnabled(sessionID, toolName string, enabled bool) error
+	AskMode(sessionID string) bool
+	SetAskMode(sessionID string, enabled bool) error
 }
 
 type agent struct {
 	*pubsub.Broker[AgentEvent]
 	sessions session.Service
 	messages message.Service
+	memories memory.Service
+
+	// files is used to tell the model which files changed in the session. It
+	// is nil for agents that never change files.
+	files history.Service
+
+	// permissions is used to pick up the reason the user gave when denying
+	// a tool call. It is nil for agents whose tools never ask for permission.
+	permissions permission.Service
 
+	name     config.AgentName
 	tools    []tools.BaseTool
 	provider provider.Provider
 
+	// sessionProviders holds the providers of the models chosen for single
+	// sessions, created on first use.
+	sessionProviders   map[sessionProviderKey]provider.Provider
+	sessionProvidersMu sync.Mutex
+
+	// disabledTools holds the tools turned off per session.
+	disabledTools   map[string]map[string]bool
+	disabledToolsMu sync.RWMutex
+
 	titleProvider     provider.Provider
 	summarizeProvider provider.Provider
 
+	// reviewProvider reviews the changes of each turn. It is nil unless a
+	// reviewer agent is configured.
+	reviewProvider provider.Provider
+
+	// askProvider answers in ask mode: it has a Q&A system prompt and is
+	// never given tools. askSessions holds the sessions in ask mode.
+	askProvider   provider.Provider
+	askSessions   map[string]bool
+	askSessionsMu sync.RWMutex
+
 	activeRequests sync.Map
+
+	// sessionMu serializes read-modify-write updates of the session, which
+	// happen concurrently from title generation and usage tracking.
+	sessionMu sync.Mutex
+}
+
+type sessionProviderKey struct {
+	model models.ModelID
+	ask   bool
 }
 
 func NewAgent(
 	agentName config.AgentName,
 	sessions session.Service,
 	messages message.Service,
+	memories memory.Service,
+	files history.Service,
+	permissions permission.Service,
 	agentTools []tools.BaseTool,
 ) (Service, error) {
 	agentProvider, err := createAgentProvider(agentName)
@@ -95,15 +181,38 @@ func NewAgent(
 			return nil, err
 		}
 	}
+	var askProvider provider.Provider
+	if agentName == config.AgentCoder {
+		askProvider, err = createAskProvider(agentName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var reviewProvider provider.Provider
+	if _, ok := config.Get().Agents[config.AgentReviewer]; ok && agentName == config.AgentCoder {
+		reviewProvider, err = createAgentProvider(config.AgentReviewer)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	agent := &agent{
 		Broker:            pubsub.NewBroker[AgentEvent](),
+		name:              agentName,
 		provider:          agentProvider,
+		sessionProviders:  make(map[sessionProviderKey]provider.Provider),
 		messages:          messages,
 		sessions:          sessions,
+		memories:          memories,
+		files:             files,
+		permissions:       permissions,
 		tools:             agentTools,
+		disabledTools:     make(map[string]map[string]bool),
 		titleProvider:     titleProvider,
 		summarizeProvider: summarizeProvider,
+		askProvider:       askProvider,
+		reviewProvider:    reviewProvider,
+		askSessions:       make(map[string]bool),
 		activeRequests:    sync.Map{},
 	}
 
@@ -114,6 +223,90 @@ func (a *agent) Model() models.Model {
 	return a.provider.Model()
 }
 
+func (a *agent) Tools(sessionID string) []ToolState {
+	a.disabledToolsMu.RLock()
+	defer a.disabledToolsMu.RUnlock()
+	states := make([]ToolState, len(a.tools))
+	for i, tool := range a.tools {
+		name := tool.Info().Name
+		states[i] = ToolState{
+			Name:    name,
+			Enabled: !a.disabledTools[sessionID][name],
+		}
+	}
+	return states
+}
+
+func (a *agent) SetToolEnabled(sessionID, toolName string, enabled bool) error {
+	found := false
+	for _, tool := range a.tools {
+		if tool.Info().Name == toolName {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("unknown tool: %s", toolName)
+	}
+
+	a.disabledToolsMu.Lock()
+	defer a.disabledToolsMu.Unlock()
+	if enabled {
+		delete(a.disabledTools[sessionID], toolName)
+		if len(a.disabledTools[sessionID]) == 0 {
+			delete(a.disabledTools, sessionID)
+		}
+		return nil
+	}
+	if a.disabledTools[sessionID] == nil {
+		a.disabledTools[sessionID] = make(map[string]bool)
+	}
+	a.disabledTools[sessionID][toolName] = true
+	return nil
+}
+
+// sessionTools returns the tools that are enabled for the session.
+func (a *agent) sessionTools(sessionID string) []tools.BaseTool {
+	a.disabledToolsMu.RLock()
+	defer a.disabledToolsMu.RUnlock()
+	disabled := a.disabledTools[sessionID]
+	if len(disabled) == 0 {
+		return a.tools
+	}
+	enabled := make([]tools.BaseTool, 0, len(a.tools))
+	for _, tool := range a.tools {
+		if !disabled[tool.Info().Name] {
+			enabled = append(enabled, tool)
+		}
+	}
+	return enabled
+}
+
+// AskMode reports whether the session is in ask mode.
+func (a *agent) AskMode(sessionID string) bool {
+	a.askSessionsMu.RLock()
+	defer a.askSessionsMu.RUnlock()
+	return a.askSessions[sessionID]
+}
+
+// SetAskMode switches the session in or out of ask mode, where requests are
+// sent with a Q&A system prompt and without any tools. It applies from the
+// next request.
+func (a *agent) SetAskMode(sessionID string, enabled bool) error {
+	if enabled && a.askProvider == nil {
+		return fmt.Errorf("ask mode is not available for this agent")
+	}
+
+	a.askSessionsMu.Lock()
+	defer a.askSessionsMu.Unlock()
+	if enabled {
+		a.askSessions[sessionID] = true
+	} else {
+		delete(a.askSessions, sessionID)
+	}
+	return nil
+}
+
 func (a *agent) Cancel(sessionID string) {
 	// Cancel regular requests
 	if cancelFunc, exists := a.activeRequests.LoadAndDelete(sessionID); exists {
@@ -158,12 +351,11 @@ func (a *agent) generateTitle(ctx context.Context, sessionID string, content str
 	if a.titleProvider == nil {
 		return nil
 	}
-	session, err := a.sessions.Get(ctx, sessionID)
-	if err != nil {
-		return err
+	if session, err := a.sessions.Get(ctx, sessionID); err == nil && session.TitleLocked {
+		return nil
 	}
 	parts := []message.ContentPart{message.TextContent{Text: content}}
-	response, err := a.titleProvider.SendMessages(
+	eventChan := a.titleProvider.StreamResponse(
 		ctx,
 		[]message.Message{
 			{
@@ -173,15 +365,55 @@ func (a *agent) generateTitle(ctx context.Context, sessionID string, content str
 		},
 		make([]tools.BaseTool, 0),
 	)
-	if err != nil {
-		return err
+
+	// Update the title while it streams in so the UI shows it forming.
+	var title strings.Builder
+	var lastUpdate time.Time
+	for event := range eventChan {
+		switch event.Type {
+		case provider.EventContentDelta:
+			title.WriteString(event.Content)
+			if time.Since(lastUpdate) < titleUpdateInterval {
+				continue
+			}
+			if err := a.updateTitle(ctx, sessionID, title.String()); err != nil {
+				return err
+			}
+			lastUpdate = time.Now()
+		case provider.EventComplete:
+			if event.Response.Content != "" {
+				title.Reset()
+				title.WriteString(event.Response.Content)
+			}
+		case provider.EventError:
+			return event.Error
+		}
 	}
 
-	title := strings.TrimSpace(strings.ReplaceAll(response.Content, "\n", " "))
+	return a.updateTitle(ctx, sessionID, title.String())
+}
+
+// updateTitle saves the (possibly partial) title of a session. Saving publishes
+// an UpdatedEvent, which refreshes the sidebar and the status bar. Sessions
+// the user renamed keep their title.
+func (a *agent) updateTitle(ctx context.Context, sessionID string, title string) error {
+	title = strings.TrimSpace(strings.ReplaceAll(title, "\n", " "))
+	if runes := []rune(title); len(runes) > maxTitleLength {
+		title = strings.TrimSpace(string(runes[:maxTitleLength]))
+	}
 	if title == "" {
 		return nil
 	}
 
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+	session, err := a.sessions.Get(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session.TitleLocked || session.Title == title {
+		return nil
+	}
 	session.Title = title
 	_, err = a.sessions.Save(ctx, session)
 	return err
@@ -195,17 +427,29 @@ func (a *agent) err(err error) AgentEvent {
 }
 
 func (a *agent) Run(ctx context.Context, sessionID string, content string, attachments ...message.Attachment) (<-chan AgentEvent, error) {
-	if !a.provider.Model().SupportsAttachments && attachments != nil {
+	if !a.SessionModel(ctx, sessionID).SupportsAttachments && attachments != nil {
 		attachments = nil
 	}
 	events := make(chan AgentEvent)
 	if a.IsSessionBusy(sessionID) {
 		return nil, ErrSessionBusy
 	}
+	if text, ok := stripThinkingTrigger(content); ok {
+		content = text
+		if a.name == config.AgentCoder && a.SessionModel(ctx, sessionID).CanReason {
+			ctx = provider.WithThinking(ctx)
+		}
+	}
+	if err := a.checkContextWindow(ctx, sessionID, content, attachments); err != nil {
+		return nil, err
+	}
 
 	genCtx, cancel := context.WithCancel(ctx)
 
-	a.activeRequests.Store(sessionID, cancel)
+	if _, busy := a.activeRequests.LoadOrStore(sessionID, cancel); busy {
+		cancel()
+		return nil, ErrSessionBusy
+	}
 	go func() {
 		logging.Debug("Request started", "sessionID", sessionID)
 		defer logging.RecoverPanic("agent.Run", func() {
@@ -229,6 +473,25 @@ func (a *agent) Run(ctx context.Context, sessionID string, content string, attac
 	return events, nil
 }
 
+// stripThinkingTrigger reports whether content starts with the configured
+// thinking trigger, and returns content without it.
+func stripThinkingTrigger(content string) (string, bool) {
+	trigger := config.Get().Thinking.Trigger
+	if trigger == "" {
+		return content, false
+	}
+	rest, ok := strings.CutPrefix(strings.TrimLeftFunc(content, unicode.IsSpace), trigger)
+	// "/thinking about it" does not start with the trigger "/think".
+	if !ok || rest == "" || !unicode.IsSpace([]rune(rest)[0]) {
+		return content, false
+	}
+	rest = strings.TrimSpace(rest)
+	if rest == "" {
+		return content, false
+	}
+	return rest, true
+}
+
 func (a *agent) processGeneration(ctx context.Context, sessionID, content string, attachmentParts []message.ContentPart) AgentEvent {
 	// List existing messages; if none, start title generation asynchronously.
 	msgs, err := a.messages.List(ctx, sessionID)
@@ -251,25 +514,35 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 		return a.err(fmt.Errorf("failed to get session: %w", err))
 	}
 	if session.SummaryMessageID != "" {
-		summaryMsgInex := -1
-		for i, msg := range msgs {
-			if msg.ID == session.SummaryMessageID {
-				summaryMsgInex = i
-				break
-			}
-		}
-		if summaryMsgInex != -1 {
-			msgs = msgs[summaryMsgInex:]
+		msgs = contextMessages(msgs, session.SummaryMessageID)
+		if len(msgs) > 0 && msgs[0].ID == session.SummaryMessageID {
 			msgs[0].Role = message.User
 		}
 	}
+	// A resumed session keeps the files it read before a restart.
+	if err := tools.RestoreFileRecords(ctx, sessionID); err != nil {
+		logging.Warn("Failed to restore file records", "session", sessionID, "error", err)
+	}
 
 	userMsg, err := a.createUserMessage(ctx, sessionID, content, attachmentParts)
 	if err != nil {
 		return a.err(fmt.Errorf("failed to create user message: %w", err))
 	}
+	// The previous turn started with the last user message.
+	var previousTurn int64
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Role == message.User {
+			previousTurn = msgs[i].CreatedAt
+			break
+		}
+	}
 	// Append the new user message to the conversation history.
-	msgHistory := append(msgs, userMsg)
+	msgHistory := append(msgs, a.withChanges(ctx, sessionID, a.withMemory(ctx, sessionID, userMsg), previousTurn))
+	repeats := &repeatTracker{}
+	reads := &fileReadTracker{}
+	steps := newStepBudget()
+	fixRounds := 0
+	emptyRetries := 0
 
 	for {
 		// Check for cancellation before each iteration
@@ -279,7 +552,7 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 		default:
 			// Continue processing
 		}
-		agentMessage, toolResults, err := a.streamAndHandleEvents(ctx, sessionID, msgHistory)
+		agentMessage, toolResults, err := a.streamAndHandleEvents(ctx, sessionID, msgHistory, repeats, reads)
 		if err != nil {
 			if errors.Is(err, context.Canceled) {
 				agentMessage.AddFinish(message.FinishReasonCanceled)
@@ -289,9 +562,49 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 			return a.err(fmt.Errorf("failed to process events: %w", err))
 		}
 		logging.Info("Result", "message", agentMessage.FinishReason(), "toolResults", toolResults)
+		if isEmptyResponse(agentMessage) && emptyRetries < config.Get().EmptyResponseRetries {
+			// An empty answer is almost always a glitch of the provider, so
+			// drop it and ask again instead of ending the turn with nothing.
+			emptyRetries++
+			logging.Warn("Retrying empty assistant response", "sessionID", sessionID, "attempt", emptyRetries)
+			if err := a.messages.Delete(ctx, agentMessage.ID); err != nil {
+				logging.Warn("Failed to delete empty assistant message", "messageID", agentMessage.ID, "error", err)
+			}
+			continue
+		}
 		if (agentMessage.FinishReason() == message.FinishReasonToolUse) && toolResults != nil {
 			// We are not done, we need to respond with the tool response
 			msgHistory = append(msgHistory, agentMessage, *toolResults)
+			steps.observe(agentMessage)
+			if a.name == config.AgentCoder && steps.exceeded(config.Get().StepLimit) {
+				pause := steps.pause(sessionID)
+				logging.Info("Turn paused at the step limit", "sessionID", sessionID, "steps", pause.Steps, "elapsed", pause.Elapsed)
+				return AgentEvent{
+					Type:      AgentEventTypeResponse,
+					Message:   agentMessage,
+					Done:      true,
+					StepLimit: pause,
+				}
+			}
+			continue
+		}
+
+		reviewMsg, issues, err := a.review(ctx, sessionID, content, userMsg.CreatedAt)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return a.err(ErrRequestCancelled)
+			}
+			logging.ErrorPersist(fmt.Sprintf("Review failed: %v", err))
+		}
+		if issues && config.Get().Review.AutoFix && fixRounds < config.Get().Review.MaxFixRounds {
+			fixRounds++
+			fixMsg, err := a.createUserMessage(ctx, sessionID, prompt.ReviewFixPrompt(reviewMsg.Content().Text), nil)
+			if err != nil {
+				return a.err(fmt.Errorf("failed to create user message: %w", err))
+			}
+			// The fix prompt quotes the review, the review message itself is
+			// not sent to the model.
+			msgHistory = append(msgHistory, agentMessage, fixMsg)
 			continue
 		}
 		return AgentEvent{
@@ -302,6 +615,15 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 	}
 }
 
+// isEmptyResponse reports whether msg ended its turn normally without any
+// text or tool calls.
+func isEmptyResponse(msg message.Message) bool {
+	if reason := msg.FinishReason(); reason != message.FinishReasonEndTurn && reason != message.FinishReasonUnknown {
+		return false
+	}
+	return strings.TrimSpace(msg.Content().Text) == "" && len(msg.ToolCalls()) == 0
+}
+
 func (a *agent) createUserMessage(ctx context.Context, sessionID, content string, attachmentParts []message.ContentPart) (message.Message, error) {
 	parts := []message.ContentPart{message.TextContent{Text: content}}
 	parts = append(parts, attachmentParts...)
@@ -311,13 +633,131 @@ func (a *agent) createUserMessage(ctx context.Context, sessionID, content string
 	})
 }
 
-func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msgHistory []message.Message) (message.Message, *message.Message, error) {
-	eventChan := a.provider.StreamResponse(ctx, msgHistory, a.tools)
+// withMemory returns a copy of msg with the session's memory notes and plan
+// appended to its text, so they stay in context even after older messages are
+// summarized.
+func (a *agent) withMemory(ctx context.Context, sessionID string, msg message.Message) message.Message {
+	if a.memories == nil {
+		return msg
+	}
+	entries, err := a.memories.List(ctx, sessionID)
+	if err != nil {
+		logging.Warn("failed to list memory notes", "session", sessionID, "error", err)
+		return msg
+	}
+	if len(entries) == 0 {
+		return msg
+	}
+
+	extra := ""
+	notes := make([]memory.Entry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Key == memory.PlanKey {
+			extra += fmt.Sprintf("\n\n<plan>\n%s\n</plan>", entry.Value)
+			continue
+		}
+		if memory.IsReserved(entry.Key) {
+			continue
+		}
+		notes = append(notes, entry)
+	}
+	if len(notes) > 0 {
+		extra = fmt.Sprintf("\n\n<memory>\n%s</memory>", memory.Format(notes)) + extra
+	}
+	return appendText(msg, extra)
+}
+
+// withChanges returns a copy of msg with a short summary of the files changed
+// in the session appended to its text, so the model keeps an accurate picture
+// of the files without reading them again. Files changed since previousTurn
+// are marked.
+func (a *agent) withChanges(ctx context.Context, sessionID string, msg message.Message, previousTurn int64) message.Message {
+	if a.files == nil {
+		return msg
+	}
+	changes, err := history.SessionChanges(ctx, a.files, sessionID)
+	if err != nil {
+		logging.Warn("failed to list session changes", "session", sessionID, "error", err)
+		return msg
+	}
+	if len(changes) == 0 {
+		return msg
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n\n<changes>\nFiles changed in this session, with lines added and removed since the session started. Files changed in the previous turn are marked with *.\n")
+	for i, change := range changes {
+		if i == maxReportedChanges {
+			fmt.Fprintf(&sb, "... and %d more files\n", len(changes)-i)
+			break
+		}
+		marker := " "
+		if previousTurn > 0 && change.ChangedAt >= previousTurn {
+			marker = "*"
+		}
+		path := change.Path
+		if rel, err := filepath.Rel(config.WorkingDirectory(), path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+		fmt.Fprintf(&sb, "%s %s +%d -%d\n", marker, path, change.Additions, change.Removals)
+	}
+	sb.WriteString("</changes>")
+	return appendText(msg, sb.String())
+}
+
+// appendText returns a copy of msg with extra appended to its first text
+// part.
+func appendText(msg message.Message, extra string) message.Message {
+	parts := make([]message.ContentPart, 0, len(msg.Parts))
+	added := false
+	for _, part := range msg.Parts {
+		if text, ok := part.(message.TextContent); ok && !added {
+			part = message.TextContent{Text: text.Text + extra}
+			added = true
+		}
+		parts = append(parts, part)
+	}
+	msg.Parts = parts
+	return msg
+}
+
+// flattenToolParts returns msgs with tool calls and results written out as
+// text. Providers reject tool calls in the history of a request without
+// tools, so ask mode sends them like this; tool messages become user ones.
+func flattenToolParts(msgs []message.Message) []message.Message {
+	flattened := make([]message.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		parts := make([]message.ContentPart, 0, len(msg.Parts))
+		for _, part := range msg.Parts {
+			switch p := part.(type) {
+			case message.ToolCall:
+				part = message.TextContent{Text: fmt.Sprintf("[Called the %s tool with %s]", p.Name, p.Input)}
+			case message.ToolResult:
+				part = message.TextContent{Text: fmt.Sprintf("[Result of the %s tool]\n%s", p.Name, p.Content)}
+			}
+			parts = append(parts, part)
+		}
+		msg.Parts = parts
+		if msg.Role == message.Tool {
+			msg.Role = message.User
+		}
+		flattened = append(flattened, msg)
+	}
+	return flattened
+}
+
+func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msgHistory []message.Message, repeats *repeatTracker, reads *fileReadTracker) (message.Message, *message.Message, error) {
+	agentProvider, agentTools := a.sessionProvider(ctx, sessionID), a.sessionTools(sessionID)
+	if a.AskMode(sessionID) {
+		agentTools = nil
+		msgHistory = flattenToolParts(msgHistory)
+	}
+	eventChan := agentProvider.StreamResponse(ctx, a.fitContextWindow(ctx, sessionID, msgHistory), agentTools)
 
 	assistantMsg, err := a.messages.Create(ctx, sessionID, message.CreateMessageParams{
 		Role:  message.Assistant,
 		Parts: []message.ContentPart{},
-		Model: a.provider.Model().ID,
+		Model: agentProvider.Model().ID,
 	})
 	if err != nil {
 		return assistantMsg, nil, fmt.Errorf("failed to create assistant message: %w", err)
@@ -357,7 +797,7 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 		default:
 			// Continue processing
 			var tool tools.BaseTool
-			for _, availableTools := range a.tools {
+			for _, availableTools := range agentTools {
 				if availableTools.Info().Name == toolCall.Name {
 					tool = availableTools
 				}
@@ -372,6 +812,33 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 				}
 				continue
 			}
+
+			if limit := config.Get().MaxRepeatedToolCalls; limit > 0 && repeats.observe(toolCall) > limit {
+				logging.Warn("Rejected repeated tool call", "tool", toolCall.Name, "count", repeats.count)
+				toolResults[i] = message.ToolResult{
+					ToolCallID: toolCall.ID,
+					Content: fmt.Sprintf(
+						"You have called %s with the same input %d times in a row. Repeating it will not give a different result. Change the input, try a different approach, or explain to the user what is blocking you.",
+						toolCall.Name,
+						repeats.count,
+					),
+					IsError: true,
+				}
+				continue
+			}
+			if limit := config.Get().MaxFilesReadPerTurn; limit > 0 && !reads.allow(toolCall, limit) {
+				logging.Warn("Rejected file read over the per-turn limit", "tool", toolCall.Name, "limit", limit)
+				toolResults[i] = message.ToolResult{
+					ToolCallID: toolCall.ID,
+					Content: fmt.Sprintf(
+						"You have already read %d files in this turn, the limit for a single turn. Reading more whole files would fill the context window. Use grep or glob to find the exact lines you need, work with what you have read so far, or tell the user which files you still need to read.",
+						limit,
+					),
+					IsError: true,
+				}
+				continue
+			}
+			tools.ReportProgress(ctx, toolCall.ID, "")
 			toolResult, toolErr := tool.Run(ctx, tools.ToolCall{
 				ID:    toolCall.ID,
 				Name:  toolCall.Name,
@@ -379,11 +846,18 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 			})
 			if toolErr != nil {
 				if errors.Is(toolErr, permission.ErrorPermissionDenied) {
+					reason := ""
+					if a.permissions != nil {
+						reason = a.permissions.DenialReason(sessionID)
+					}
 					toolResults[i] = message.ToolResult{
 						ToolCallID: toolCall.ID,
 						Content:    "Permission denied",
 						IsError:    true,
 					}
+					if reason != "" {
+						toolResults[i].Content = fmt.Sprintf("Permission denied by the user: %s", reason)
+					}
 					for j := i + 1; j < len(toolCalls); j++ {
 						toolResults[j] = message.ToolResult{
 							ToolCallID: toolCalls[j].ID,
@@ -391,7 +865,11 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 							IsError:    true,
 						}
 					}
-					a.finishMessage(ctx, &assistantMsg, message.FinishReasonPermissionDenied)
+					// With a reason the user expects the model to adjust its
+					// approach, so the turn continues with the tool results.
+					if reason == "" {
+						a.finishMessage(ctx, &assistantMsg, message.FinishReasonPermissionDenied)
+					}
 					break
 				}
 			}
@@ -445,15 +923,9 @@ func (a *agent) processEvent(ctx context.Context, sessionID string, assistantMsg
 	case provider.EventToolUseStart:
 		assistantMsg.AddToolCall(*event.ToolCall)
 		return a.messages.Update(ctx, *assistantMsg)
-	// TODO: see how to handle this
-	// case provider.EventToolUseDelta:
-	// 	tm := time.Unix(assistantMsg.UpdatedAt, 0)
-	// 	assistantMsg.AppendToolCallInput(event.ToolCall.ID, event.ToolCall.Input)
-	// 	if time.Since(tm) > 1000*time.Millisecond {
-	// 		err := a.messages.Update(ctx, *assistantMsg)
-	// 		assistantMsg.UpdatedAt = time.Now().Unix()
-	// 		return err
-	// 	}
+	case provider.EventToolUseDelta:
+		assistantMsg.AppendToolCallInput(event.ToolCall.ID, event.ToolCall.Input)
+		return a.messages.Update(ctx, *assistantMsg)
 	case provider.EventToolUseStop:
 		assistantMsg.FinishToolCall(event.ToolCall.ID)
 		return a.messages.Update(ctx, *assistantMsg)
@@ -465,31 +937,41 @@ func (a *agent) processEvent(ctx context.Context, sessionID string, assistantMsg
 		logging.ErrorPersist(event.Error.Error())
 		return event.Error
 	case provider.EventComplete:
+		model, usage := models.SupportedModels[assistantMsg.Model], event.Response.Usage
 		assistantMsg.SetToolCalls(event.Response.ToolCalls)
 		assistantMsg.AddFinish(event.Response.FinishReason)
+		assistantMsg.SetUsage(message.Usage{
+			InputTokens:         usage.InputTokens,
+			OutputTokens:        usage.OutputTokens,
+			CacheCreationTokens: usage.CacheCreationTokens,
+			CacheReadTokens:     usage.CacheReadTokens,
+			Cost:                usage.Cost(model),
+		})
 		if err := a.messages.Update(ctx, *assistantMsg); err != nil {
 			return fmt.Errorf("failed to update message: %w", err)
 		}
-		return a.TrackUsage(ctx, sessionID, a.provider.Model(), event.Response.Usage)
+		return a.TrackUsage(ctx, sessionID, model, usage)
 	}
 
 	return nil
 }
 
 func (a *agent) TrackUsage(ctx context.Context, sessionID string, model models.Model, usage provider.TokenUsage) error {
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
 	sess, err := a.sessions.Get(ctx, sessionID)
 	if err != nil {
 		return fmt.Errorf("failed to get session: %w", err)
 	}
 
-	cost := model.CostPer1MInCached/1e6*float64(usage.CacheCreationTokens) +
-		model.CostPer1MOutCached/1e6*float64(usage.CacheReadTokens) +
-		model.CostPer1MIn/1e6*float64(usage.InputTokens) +
-		model.CostPer1MOut/1e6*float64(usage.OutputTokens)
-
-	sess.Cost += cost
+	sess.Cost += usage.Cost(model)
 	sess.CompletionTokens = usage.OutputTokens + usage.CacheReadTokens
 	sess.PromptTokens = usage.InputTokens + usage.CacheCreationTokens
+	// Keep the session on the model it was started with when the configured
+	// model changes later.
+	if sess.Model == "" {
+		sess.Model = model.ID
+	}
 
 	_, err = a.sessions.Save(ctx, sess)
 	if err != nil {
@@ -513,10 +995,91 @@ func (a *agent) Update(agentName config.AgentName, modelID models.ModelID) (mode
 	}
 
 	a.provider = provider
+	if a.askProvider != nil {
+		askProvider, err := createAskProvider(agentName)
+		if err != nil {
+			return models.Model{}, fmt.Errorf("failed to create ask mode provider for model %s: %w", modelID, err)
+		}
+		a.askProvider = askProvider
+	}
 
 	return a.provider.Model(), nil
 }
 
+// SessionModel returns the model used for requests in the session.
+func (a *agent) SessionModel(ctx context.Context, sessionID string) models.Model {
+	return a.sessionProvider(ctx, sessionID).Model()
+}
+
+// SetSessionModel saves modelID as the model of the session so it is used
+// whenever the session is open, instead of the model configured for the
+// agent.
+func (a *agent) SetSessionModel(ctx context.Context, sessionID string, modelID models.ModelID) error {
+	if a.IsSessionBusy(sessionID) {
+		return fmt.Errorf("cannot change model while processing requests")
+	}
+	if _, err := a.providerForModel(modelID, a.AskMode(sessionID)); err != nil {
+		return err
+	}
+
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+	sess, err := a.sessions.Get(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+	sess.Model = modelID
+	if _, err := a.sessions.Save(ctx, sess); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+// sessionProvider returns the provider for requests in the session: the one
+// for the model chosen for the session if there is one, the agent's otherwise.
+func (a *agent) sessionProvider(ctx context.Context, sessionID string) provider.Provider {
+	ask := a.AskMode(sessionID)
+	defaultProvider := a.provider
+	if ask {
+		defaultProvider = a.askProvider
+	}
+
+	sess, err := a.sessions.Get(ctx, sessionID)
+	if err != nil || sess.Model == "" || sess.Model == defaultProvider.Model().ID {
+		return defaultProvider
+	}
+	sessionProvider, err := a.providerForModel(sess.Model, ask)
+	if err != nil {
+		logging.Warn("Failed to use the model of the session, using the default model", "session_id", sessionID, "model", sess.Model, "error", err)
+		return defaultProvider
+	}
+	return sessionProvider
+}
+
+// providerForModel returns a provider for the agent with another model than
+// the configured one.
+func (a *agent) providerForModel(modelID models.ModelID, ask bool) (provider.Provider, error) {
+	key := sessionProviderKey{model: modelID, ask: ask}
+	a.sessionProvidersMu.Lock()
+	defer a.sessionProvidersMu.Unlock()
+	if p, ok := a.sessionProviders[key]; ok {
+		return p, nil
+	}
+
+	systemPrompt := func(p models.ModelProvider) string {
+		return prompt.GetAgentPrompt(a.name, p)
+	}
+	if ask {
+		systemPrompt = prompt.AskPrompt
+	}
+	p, err := newAgentProvider(a.name, modelID, systemPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create provider for model %s: %w", modelID, err)
+	}
+	a.sessionProviders[key] = p
+	return p, nil
+}
+
 func (a *agent) Summarize(ctx context.Context, sessionID string) error {
 	if a.summarizeProvider == nil {
 		return fmt.Errorf("summarize provider not available")
@@ -564,24 +1127,6 @@ func (a *agent) Summarize(ctx context.Context, sessionID string) error {
 			return
 		}
 
-		event = AgentEvent{
-			Type:     AgentEventTypeSummarize,
-			Progress: "Analyzing conversation...",
-		}
-		a.Publish(pubsub.CreatedEvent, event)
-
-		// Add a system message to guide the summarization
-		summarizePrompt := "Provide a detailed but concise summary of our conversation above. Focus on information that would be helpful for continuing the conversation, including what we did, what we're doing, which files we're working on, and what we're going to do next."
-
-		// Create a new message with the summarize prompt
-		promptMsg := message.Message{
-			Role:  message.User,
-			Parts: []message.ContentPart{message.TextContent{Text: summarizePrompt}},
-		}
-
-		// Append the prompt to the messages
-		msgsWithPrompt := append(msgs, promptMsg)
-
 		event = AgentEvent{
 			Type:     AgentEventTypeSummarize,
 			Progress: "Generating summary...",
@@ -589,27 +1134,11 @@ func (a *agent) Summarize(ctx context.Context, sessionID string) error {
 
 		a.Publish(pubsub.CreatedEvent, event)
 
-		// Send the messages to the summarize provider
-		response, err := a.summarizeProvider.SendMessages(
-			summarizeCtx,
-			msgsWithPrompt,
-			make([]tools.BaseTool, 0),
-		)
+		response, err := a.generateSummary(summarizeCtx, sessionID, msgs)
 		if err != nil {
 			event = AgentEvent{
 				Type:  AgentEventTypeError,
-				Error: fmt.Errorf("failed to summarize: %w", err),
-				Done:  true,
-			}
-			a.Publish(pubsub.CreatedEvent, event)
-			return
-		}
-
-		summary := strings.TrimSpace(response.Content)
-		if summary == "" {
-			event = AgentEvent{
-				Type:  AgentEventTypeError,
-				Error: fmt.Errorf("empty summary returned"),
+				Error: err,
 				Done:  true,
 			}
 			a.Publish(pubsub.CreatedEvent, event)
@@ -621,62 +1150,19 @@ func (a *agent) Summarize(ctx context.Context, sessionID string) error {
 		}
 
 		a.Publish(pubsub.CreatedEvent, event)
-		oldSession, err := a.sessions.Get(summarizeCtx, sessionID)
-		if err != nil {
-			event = AgentEvent{
-				Type:  AgentEventTypeError,
-				Error: fmt.Errorf("failed to get session: %w", err),
-				Done:  true,
-			}
-
-			a.Publish(pubsub.CreatedEvent, event)
-			return
-		}
-		// Create a message in the new session with the summary
-		msg, err := a.messages.Create(summarizeCtx, oldSession.ID, message.CreateMessageParams{
-			Role: message.Assistant,
-			Parts: []message.ContentPart{
-				message.TextContent{Text: summary},
-				message.Finish{
-					Reason: message.FinishReasonEndTurn,
-					Time:   time.Now().Unix(),
-				},
-			},
-			Model: a.summarizeProvider.Model().ID,
-		})
-		if err != nil {
+		if err := a.saveSummary(summarizeCtx, sessionID, response.Content, response.Usage); err != nil {
 			event = AgentEvent{
 				Type:  AgentEventTypeError,
-				Error: fmt.Errorf("failed to create summary message: %w", err),
+				Error: err,
 				Done:  true,
 			}
-
 			a.Publish(pubsub.CreatedEvent, event)
 			return
 		}
-		oldSession.SummaryMessageID = msg.ID
-		oldSession.CompletionTokens = response.Usage.OutputTokens
-		oldSession.PromptTokens = 0
-		model := a.summarizeProvider.Model()
-		usage := response.Usage
-		cost := model.CostPer1MInCached/1e6*float64(usage.CacheCreationTokens) +
-			model.CostPer1MOutCached/1e6*float64(usage.CacheReadTokens) +
-			model.CostPer1MIn/1e6*float64(usage.InputTokens) +
-			model.CostPer1MOut/1e6*float64(usage.OutputTokens)
-		oldSession.Cost += cost
-		_, err = a.sessions.Save(summarizeCtx, oldSession)
-		if err != nil {
-			event = AgentEvent{
-				Type:  AgentEventTypeError,
-				Error: fmt.Errorf("failed to save session: %w", err),
-				Done:  true,
-			}
-			a.Publish(pubsub.CreatedEvent, event)
-		}
 
 		event = AgentEvent{
 			Type:      AgentEventTypeSummarize,
-			SessionID: oldSession.ID,
+			SessionID: sessionID,
 			Progress:  "Summary complete",
 			Done:      true,
 		}
@@ -687,15 +1173,204 @@ func (a *agent) Summarize(ctx context.Context, sessionID string) error {
 	return nil
 }
 
+// PreviewSummary generates a summary of the session without applying it, so
+// the user can review it first. It can be cancelled like Summarize.
+func (a *agent) PreviewSummary(ctx context.Context, sessionID string) (SummaryPreview, error) {
+	if a.summarizeProvider == nil {
+		return SummaryPreview{}, fmt.Errorf("summarize provider not available")
+	}
+	if a.IsSessionBusy(sessionID) {
+		return SummaryPreview{}, ErrSessionBusy
+	}
+
+	previewCtx, cancel := context.WithCancel(ctx)
+	a.activeRequests.Store(sessionID+"-summarize", cancel)
+	defer a.activeRequests.Delete(sessionID + "-summarize")
+	defer cancel()
+
+	msgs, err := a.messages.List(previewCtx, sessionID)
+	if err != nil {
+		return SummaryPreview{}, fmt.Errorf("failed to list messages: %w", err)
+	}
+	if len(msgs) == 0 {
+		return SummaryPreview{}, fmt.Errorf("no messages to summarize")
+	}
+
+	response, err := a.generateSummary(previewCtx, sessionID, msgs)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return SummaryPreview{}, ErrRequestCancelled
+		}
+		return SummaryPreview{}, err
+	}
+	return SummaryPreview{
+		SessionID: sessionID,
+		Messages:  len(msgs),
+		Summary:   response.Content,
+		Usage:     response.Usage,
+	}, nil
+}
+
+// ApplySummary makes an approved preview the start of the history sent with
+// future requests. Earlier messages stay in storage.
+func (a *agent) ApplySummary(ctx context.Context, preview SummaryPreview) error {
+	if a.IsSessionBusy(preview.SessionID) {
+		return ErrSessionBusy
+	}
+	return a.saveSummary(ctx, preview.SessionID, preview.Summary, preview.Usage)
+}
+
+// ResetContext deletes the messages of a session so future requests start
+// without any conversation, while the session, its cost, file history and
+// memory notes are kept. With archive, the transcript is first written to a
+// Markdown file in the data directory, whose path is returned.
+func (a *agent) ResetContext(ctx context.Context, sessionID string, archive bool) (string, error) {
+	// The session is marked busy while it is reset, so no request can start
+	// between the check and the delete.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if _, busy := a.activeRequests.LoadOrStore(sessionID, cancel); busy {
+		return "", ErrSessionBusy
+	}
+	defer a.activeRequests.Delete(sessionID)
+
+	sess, err := a.sessions.Get(ctx, sessionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get session: %w", err)
+	}
+
+	archivePath := ""
+	if archive {
+		msgs, err := a.messages.List(ctx, sessionID)
+		if err != nil {
+			return "", fmt.Errorf("failed to list messages: %w", err)
+		}
+		dir := filepath.Join(config.Get().Data.Directory, "archive")
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return "", fmt.Errorf("failed to create archive directory: %w", err)
+		}
+		archivePath = filepath.Join(dir, fmt.Sprintf("%s-%s.md", sessionID, time.Now().Format("20060102-150405")))
+		if err := os.WriteFile(archivePath, []byte(export.Markdown(sess, msgs)), 0o644); err != nil {
+			return "", fmt.Errorf("failed to archive messages: %w", err)
+		}
+	}
+
+	if err := a.messages.DeleteSessionMessages(ctx, sessionID); err != nil {
+		return archivePath, fmt.Errorf("failed to delete messages: %w", err)
+	}
+
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+	sess, err = a.sessions.Get(ctx, sessionID)
+	if err != nil {
+		return archivePath, fmt.Errorf("failed to get session: %w", err)
+	}
+	sess.SummaryMessageID = ""
+	sess.PromptTokens = 0
+	sess.CompletionTokens = 0
+	if _, err := a.sessions.Save(ctx, sess); err != nil {
+		return archivePath, fmt.Errorf("failed to save session: %w", err)
+	}
+	return archivePath, nil
+}
+
+// generateSummary asks the summarize provider to summarize msgs. The returned
+// response content is the trimmed summary.
+func (a *agent) generateSummary(ctx context.Context, sessionID string, msgs []message.Message) (*provider.ProviderResponse, error) {
+	// Add a user message to guide the summarization
+	summarizePrompt := prompt.SummaryRequestPrompt()
+	msgsWithPrompt := append(msgs, message.Message{
+		Role:  message.User,
+		Parts: []message.ContentPart{message.TextContent{Text: summarizePrompt}},
+	})
+
+	// A prompt with the conversation placeholder gets the transcript
+	// inline instead of the message history.
+	if strings.Contains(summarizePrompt, prompt.ConversationPlaceholder) {
+		sess, err := a.sessions.Get(ctx, sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get session: %w", err)
+		}
+		transcript := export.Markdown(sess, msgs)
+		msgsWithPrompt = []message.Message{
+			{
+				Role:  message.User,
+				Parts: []message.ContentPart{message.TextContent{Text: strings.ReplaceAll(summarizePrompt, prompt.ConversationPlaceholder, transcript)}},
+			},
+		}
+	}
+
+	// Send the messages to the summarize provider
+	response, err := a.summarizeProvider.SendMessages(
+		ctx,
+		msgsWithPrompt,
+		make([]tools.BaseTool, 0),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize: %w", err)
+	}
+
+	response.Content = strings.TrimSpace(response.Content)
+	if response.Content == "" {
+		return nil, fmt.Errorf("empty summary returned")
+	}
+	return response, nil
+}
+
+// saveSummary stores summary as a message of the session and marks it as the
+// point the history sent to the provider starts from.
+func (a *agent) saveSummary(ctx context.Context, sessionID string, summary string, usage provider.TokenUsage) error {
+	oldSession, err := a.sessions.Get(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+	// Create a message in the new session with the summary
+	msg, err := a.messages.Create(ctx, oldSession.ID, message.CreateMessageParams{
+		Role: message.Assistant,
+		Parts: []message.ContentPart{
+			message.TextContent{Text: summary},
+			message.Finish{
+				Reason: message.FinishReasonEndTurn,
+				Time:   time.Now().Unix(),
+			},
+		},
+		Model: a.summarizeProvider.Model().ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create summary message: %w", err)
+	}
+	oldSession.SummaryMessageID = msg.ID
+	oldSession.CompletionTokens = usage.OutputTokens
+	oldSession.PromptTokens = 0
+	model := a.summarizeProvider.Model()
+	oldSession.Cost += usage.Cost(model)
+	if _, err := a.sessions.Save(ctx, oldSession); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
 func createAgentProvider(agentName config.AgentName) (provider.Provider, error) {
+	return newAgentProvider(agentName, config.Get().Agents[agentName].Model, func(p models.ModelProvider) string {
+		return prompt.GetAgentPrompt(agentName, p)
+	})
+}
+
+// createAskProvider creates a provider with the model of the agent and the
+// ask mode system prompt.
+func createAskProvider(agentName config.AgentName) (provider.Provider, error) {
+	return newAgentProvider(agentName, config.Get().Agents[agentName].Model, prompt.AskPrompt)
+}
+
+func newAgentProvider(agentName config.AgentName, modelID models.ModelID, systemPrompt func(models.ModelProvider) string) (provider.Provider, error) {
 	cfg := config.Get()
 	agentConfig, ok := cfg.Agents[agentName]
 	if !ok {
 		return nil, fmt.Errorf("agent %s not found", agentName)
 	}
-	model, ok := models.SupportedModels[agentConfig.Model]
+	model, ok := models.SupportedModels[modelID]
 	if !ok {
-		return nil, fmt.Errorf("model %s not supported", agentConfig.Model)
+		return nil, fmt.Errorf("model %s not supported", modelID)
 	}
 
 	providerCfg, ok := cfg.Providers[model.Provider]
@@ -706,14 +1381,28 @@ func createAgentProvider(agentName config.AgentName) (provider.Provider, error)
 		return nil, fmt.Errorf("provider %s is not enabled", model.Provider)
 	}
 	maxTokens := model.DefaultMaxTokens
-	if agentConfig.MaxTokens > 0 {
+	// The configured limit is for the configured model.
+	if agentConfig.MaxTokens > 0 && modelID == agentConfig.Model {
 		maxTokens = agentConfig.MaxTokens
 	}
+	if agentName == config.AgentTitle && (maxTokens <= 0 || maxTokens > config.TitleMaxTokens) {
+		// Titles are short, keep the request cheap whatever model is configured
+		maxTokens = config.TitleMaxTokens
+	}
 	opts := []provider.ProviderClientOption{
 		provider.WithAPIKey(providerCfg.APIKey),
+		provider.WithBaseURL(providerCfg.BaseURL),
 		provider.WithModel(model),
-		provider.WithSystemMessage(prompt.GetAgentPrompt(agentName, model.Provider)),
+		provider.WithSystemMessage(systemPrompt(model.Provider)),
 		provider.WithMaxTokens(maxTokens),
+		provider.WithRetryPolicy(
+			providerCfg.Retry.MaxAttempts,
+			time.Duration(providerCfg.Retry.BaseBackoffMs)*time.Millisecond,
+			time.Duration(providerCfg.Retry.MaxBackoffMs)*time.Millisecond,
+		),
+	}
+	if cfg.Debug {
+		opts = append(opts, provider.WithRequestLogger(logging.Debug))
 	}
 	if model.Provider == models.ProviderOpenAI || model.Provider == models.ProviderLocal && model.CanReason {
 		opts = append(
@@ -722,13 +1411,15 @@ func createAgentProvider(agentName config.AgentName) (provider.Provider, error)
 				provider.WithReasoningEffort(agentConfig.ReasoningEffort),
 			),
 		)
-	} else if model.Provider == models.ProviderAnthropic && model.CanReason && agentName == config.AgentCoder {
-		opts = append(
-			opts,
-			provider.WithAnthropicOptions(
-				provider.WithAnthropicShouldThinkFn(provider.DefaultShouldThinkFn),
-			),
-		)
+	} else if model.Provider == models.ProviderAnthropic {
+		var anthropicOpts []provider.AnthropicOption
+		if model.CanReason && agentName == config.AgentCoder {
+			anthropicOpts = append(anthropicOpts, provider.WithAnthropicThinkingBudget(config.Get().Thinking.Budget))
+		}
+		if len(providerCfg.Betas) > 0 {
+			anthropicOpts = append(anthropicOpts, provider.WithAnthropicBeta(providerCfg.Betas...))
+		}
+		opts = append(opts, provider.WithAnthropicOptions(anthropicOpts...))
 	}
 	agentProvider, err := provider.NewProvider(
 		model.Provider,
@@ -0,0 +1,245 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/models"
+	"github.com/opencode-ai/opencode/internal/llm/prompt"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+// attachmentTokens is the estimated cost of a single attached image.
+const attachmentTokens = 1500
+
+// charsPerToken is the rough ratio used to estimate the size of a request
+// before it is sent. Providers do not expose their tokenizers, so the estimate
+// only needs to be good enough to catch requests that clearly do not fit.
+// Claude's tokenizer splits code into more tokens than the others.
+func charsPerToken(provider models.ModelProvider) int64 {
+	switch provider {
+	case models.ProviderAnthropic, models.ProviderBedrock:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// ContextOverflowError is returned by Run when the request, together with the
+// conversation it continues, would not fit in the context window of the
+// session's model. It carries the prompt so it can be sent again once the
+// context has been made smaller.
+type ContextOverflowError struct {
+	SessionID   string
+	Estimated   int64
+	Limit       int64
+	Content     string
+	Attachments []message.Attachment
+}
+
+func (e *ContextOverflowError) Error() string {
+	return fmt.Sprintf("the request needs about %d tokens but the model's context window is %d tokens", e.Estimated, e.Limit)
+}
+
+// contextMessages returns the messages that are sent to the model: everything
+// from the summary on if the session has been compacted.
+func contextMessages(msgs []message.Message, summaryMessageID string) []message.Message {
+	if summaryMessageID == "" {
+		return msgs
+	}
+	for i, msg := range msgs {
+		if msg.ID == summaryMessageID {
+			return msgs[i:]
+		}
+	}
+	return msgs
+}
+
+// estimateTokens estimates the number of tokens the messages take up in a
+// request to a model of provider.
+func estimateTokens(msgs []message.Message, provider models.ModelProvider) int64 {
+	var chars, tokens int64
+	for _, msg := range msgs {
+		for _, part := range msg.Parts {
+			switch p := part.(type) {
+			case message.TextContent:
+				chars += int64(len(p.Text))
+			case message.ReasoningContent:
+				chars += int64(len(p.Thinking))
+			case message.ToolCall:
+				chars += int64(len(p.Name) + len(p.Input))
+			case message.ToolResult:
+				chars += int64(len(p.Name) + len(p.Content))
+			case message.BinaryContent, message.ImageURLContent:
+				tokens += attachmentTokens
+			}
+		}
+	}
+	return tokens + chars/charsPerToken(provider)
+}
+
+// requestOverhead estimates the tokens every request of the session needs
+// besides the conversation: the system prompt, the tool definitions and the
+// room reserved for the response.
+func (a *agent) requestOverhead(ctx context.Context, sessionID string) int64 {
+	model := a.SessionModel(ctx, sessionID)
+	var chars int64
+	if a.AskMode(sessionID) {
+		chars += int64(len(prompt.AskPrompt(model.Provider)))
+	} else {
+		chars += int64(len(prompt.GetAgentPrompt(a.name, model.Provider)))
+		for _, tool := range a.sessionTools(sessionID) {
+			info, err := json.Marshal(tool.Info())
+			if err == nil {
+				chars += int64(len(info))
+			}
+		}
+	}
+	return chars/charsPerToken(model.Provider) + config.Get().Agents[a.name].MaxTokens
+}
+
+// estimateRequest estimates the size of a request sending content with
+// attachments after the given conversation.
+func (a *agent) estimateRequest(ctx context.Context, sessionID string, history []message.Message, content string, attachments []message.Attachment) int64 {
+	provider := a.SessionModel(ctx, sessionID).Provider
+	return a.requestOverhead(ctx, sessionID) +
+		estimateTokens(history, provider) +
+		int64(len(content))/charsPerToken(provider) +
+		int64(len(attachments))*attachmentTokens
+}
+
+// checkContextWindow returns a *ContextOverflowError if sending content would
+// exceed the context window of the session's model. Models without a known
+// context window are never rejected, nor are requests when the conversation
+// cannot be loaded; the provider reports those itself.
+func (a *agent) checkContextWindow(ctx context.Context, sessionID, content string, attachments []message.Attachment) error {
+	limit := a.SessionModel(ctx, sessionID).ContextWindow
+	if limit <= 0 {
+		return nil
+	}
+	sess, err := a.sessions.Get(ctx, sessionID)
+	if err != nil {
+		logging.Warn("Failed to get session to check the context window", "session_id", sessionID, "error", err)
+		return nil
+	}
+	msgs, err := a.messages.List(ctx, sessionID)
+	if err != nil {
+		logging.Warn("Failed to list messages to check the context window", "session_id", sessionID, "error", err)
+		return nil
+	}
+	estimated := a.estimateRequest(ctx, sessionID, contextMessages(msgs, sess.SummaryMessageID), content, attachments)
+	if estimated <= limit {
+		return nil
+	}
+	return &ContextOverflowError{
+		SessionID:   sessionID,
+		Estimated:   estimated,
+		Limit:       limit,
+		Content:     content,
+		Attachments: attachments,
+	}
+}
+
+// fitContextWindow drops the oldest turns of history until the request fits
+// in the context window of the session's model. Run already refuses prompts
+// that do not fit, but the tool results of a long turn can still grow the
+// request past the limit. Only the request is trimmed, the session keeps its
+// messages. The summary of a compacted session and the current turn are
+// always sent; if they alone do not fit, the provider reports the error.
+func (a *agent) fitContextWindow(ctx context.Context, sessionID string, history []message.Message) []message.Message {
+	model := a.SessionModel(ctx, sessionID)
+	if model.ContextWindow <= 0 {
+		return history
+	}
+	overhead := a.requestOverhead(ctx, sessionID)
+	if overhead+estimateTokens(history, model.Provider) <= model.ContextWindow {
+		return history
+	}
+
+	var kept []message.Message
+	if sess, err := a.sessions.Get(ctx, sessionID); err == nil && sess.SummaryMessageID != "" && len(history) > 0 && history[0].ID == sess.SummaryMessageID {
+		kept, history = history[:1], history[1:]
+	}
+	current := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == message.User {
+			current = i
+			break
+		}
+	}
+	keptTokens := overhead + estimateTokens(kept, model.Provider)
+	dropped := 0
+	for current > 0 && keptTokens+estimateTokens(history, model.Provider) > model.ContextWindow {
+		// A turn runs up to the next user message, so tool calls are never
+		// separated from their results.
+		end := 1
+		for end < current && history[end].Role != message.User {
+			end++
+		}
+		history = history[end:]
+		current -= end
+		dropped += end
+	}
+	if dropped > 0 {
+		logging.Warn("Dropped the oldest messages of the request to fit the context window",
+			"session_id", sessionID,
+			"dropped", dropped,
+			"context_window", model.ContextWindow)
+	}
+	return slices.Concat(kept, history)
+}
+
+// DropOldestTurns deletes the oldest turns of the conversation, each a user
+// message with everything that answered it, until the prompt of the overflow
+// fits in the context window. The summary of a compacted session is kept. It
+// returns the number of deleted messages.
+func (a *agent) DropOldestTurns(ctx context.Context, overflow *ContextOverflowError) (int, error) {
+	if a.IsSessionBusy(overflow.SessionID) {
+		return 0, ErrSessionBusy
+	}
+	sess, err := a.sessions.Get(ctx, overflow.SessionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get session: %w", err)
+	}
+	msgs, err := a.messages.List(ctx, overflow.SessionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list messages: %w", err)
+	}
+	history := contextMessages(msgs, sess.SummaryMessageID)
+	var kept []message.Message
+	if sess.SummaryMessageID != "" && len(history) > 0 && history[0].ID == sess.SummaryMessageID {
+		kept, history = history[:1], history[1:]
+	}
+
+	limit := a.SessionModel(ctx, overflow.SessionID).ContextWindow
+	fits := func() bool {
+		remaining := append(append([]message.Message{}, kept...), history...)
+		return a.estimateRequest(ctx, overflow.SessionID, remaining, overflow.Content, overflow.Attachments) <= limit
+	}
+
+	var drop []message.Message
+	for len(history) > 0 && !fits() {
+		// A turn runs up to the next user message, so tool calls are never
+		// separated from their results.
+		end := 1
+		for end < len(history) && history[end].Role != message.User {
+			end++
+		}
+		drop = append(drop, history[:end]...)
+		history = history[end:]
+	}
+	if !fits() {
+		return 0, fmt.Errorf("the prompt does not fit in the model's context window even without any history")
+	}
+
+	for _, msg := range drop {
+		if err := a.messages.Delete(ctx, msg.ID); err != nil {
+			return 0, fmt.Errorf("failed to delete message: %w", err)
+		}
+	}
+	return len(drop), nil
+}
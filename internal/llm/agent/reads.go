@@ -0,0 +1,43 @@
+package agent
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+// fileReadTracker counts the distinct files read within a single turn, so a
+// model pulling in file after file can be nudged toward targeted searches
+// before it fills the context window.
+type fileReadTracker struct {
+	files map[string]bool
+}
+
+// allow records the file read by call and reports whether it stays within
+// limit distinct files. Calls that do not read a file, and files already
+// read this turn, are always allowed.
+func (r *fileReadTracker) allow(call message.ToolCall, limit int) bool {
+	if call.Name != tools.ViewToolName {
+		return true
+	}
+	var params struct {
+		FilePath string `json:"file_path"`
+	}
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil || params.FilePath == "" {
+		return true
+	}
+	path := filepath.Clean(params.FilePath)
+	if r.files[path] {
+		return true
+	}
+	if len(r.files) >= limit {
+		return false
+	}
+	if r.files == nil {
+		r.files = make(map[string]bool)
+	}
+	r.files[path] = true
+	return true
+}
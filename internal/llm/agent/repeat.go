@@ -0,0 +1,37 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+// repeatTracker counts identical consecutive tool calls within a single turn,
+// so a model that keeps issuing the same call can be stopped.
+type repeatTracker struct {
+	last  string
+	count int
+}
+
+// observe records the call and returns how many times in a row it was made.
+func (r *repeatTracker) observe(call message.ToolCall) int {
+	key := call.Name + "\x00" + normalizeToolInput(call.Input)
+	if key == r.last {
+		r.count++
+	} else {
+		r.last = key
+		r.count = 1
+	}
+	return r.count
+}
+
+// normalizeToolInput compacts JSON input so whitespace differences do not
+// hide a repeated call.
+func normalizeToolInput(input string) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(input)); err != nil {
+		return input
+	}
+	return buf.String()
+}
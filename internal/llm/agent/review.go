@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/format"
+	"github.com/opencode-ai/opencode/internal/history"
+	"github.com/opencode-ai/opencode/internal/llm/prompt"
+	"github.com/opencode-ai/opencode/internal/llm/provider"
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+// maxReviewDiffLength caps the diff sent to the reviewer, so a turn that
+// rewrote many files does not overflow the context window of its model.
+const maxReviewDiffLength = 100000
+
+// review has the reviewer agent look at the changes made since the turn
+// started and stores its feedback as a review message of the session, which
+// is not part of the conversation sent to the model. It reports whether the
+// reviewer found issues, and returns no message when nothing changed or no
+// reviewer is configured.
+func (a *agent) review(ctx context.Context, sessionID string, request string, turnStart int64) (*message.Message, bool, error) {
+	if a.reviewProvider == nil || a.files == nil || a.AskMode(sessionID) {
+		return nil, false, nil
+	}
+	turnDiff, err := history.TurnDiff(ctx, a.files, sessionID, turnStart)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to collect the changes: %w", err)
+	}
+	if turnDiff == "" {
+		return nil, false, nil
+	}
+	if len(turnDiff) > maxReviewDiffLength {
+		omitted := len(turnDiff) - maxReviewDiffLength
+		turnDiff = fmt.Sprintf("%s\n\n[Diff truncated: %d more characters not shown, review what is above]", turnDiff[:format.CutPoint(turnDiff, maxReviewDiffLength)], omitted)
+	}
+
+	response, err := a.reviewProvider.SendMessages(provider.WithoutThinking(ctx), []message.Message{
+		{
+			Role:  message.User,
+			Parts: []message.ContentPart{message.TextContent{Text: prompt.ReviewRequestPrompt(request, turnDiff)}},
+		},
+	}, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	model := a.reviewProvider.Model()
+	if err := a.TrackUsage(ctx, sessionID, model, response.Usage); err != nil {
+		return nil, false, err
+	}
+
+	feedback := strings.TrimSpace(response.Content)
+	issues := feedback != "" && !strings.HasPrefix(feedback, prompt.ReviewApproved)
+	if feedback == "" {
+		feedback = prompt.ReviewApproved
+	}
+	msg, err := a.messages.Create(ctx, sessionID, message.CreateMessageParams{
+		Role: message.Review,
+		Parts: []message.ContentPart{
+			message.TextContent{Text: fmt.Sprintf("**Review by %s**\n\n%s", model.Name, feedback)},
+			message.Finish{
+				Reason: message.FinishReasonEndTurn,
+				Time:   time.Now().Unix(),
+			},
+		},
+		Model: model.ID,
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create review message: %w", err)
+	}
+	return &msg, issues, nil
+}
@@ -0,0 +1,93 @@
+package agent
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+// StepLimitContinuePrompt is sent as the next user message when the user
+// lets a paused turn go on.
+const StepLimitContinuePrompt = "Continue with the task where you left off."
+
+// StepLimitPause describes a turn that was paused because it used up its
+// step budget, so the user can decide whether it should go on.
+type StepLimitPause struct {
+	SessionID string
+	Steps     int
+	Elapsed   time.Duration
+	// ToolCalls counts the calls made during the turn by tool name.
+	ToolCalls map[string]int
+}
+
+// Summary describes the progress of the paused turn in a few lines.
+func (p *StepLimitPause) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Paused after %d tool steps in %s.", p.Steps, p.Elapsed.Round(time.Second))
+	if len(p.ToolCalls) == 0 {
+		return b.String()
+	}
+	names := make([]string, 0, len(p.ToolCalls))
+	for name := range p.ToolCalls {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if p.ToolCalls[names[i]] != p.ToolCalls[names[j]] {
+			return p.ToolCalls[names[i]] > p.ToolCalls[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	calls := make([]string, 0, len(names))
+	for _, name := range names {
+		calls = append(calls, fmt.Sprintf("%s ×%d", name, p.ToolCalls[name]))
+	}
+	fmt.Fprintf(&b, "\nTool calls: %s", strings.Join(calls, ", "))
+	return b.String()
+}
+
+// stepBudget counts the tool cycles and the time of a single turn, so a long
+// autonomous turn can be paused at a checkpoint instead of running unbounded.
+// Unlike the repeated call guard it does not abort anything: the user decides
+// whether the turn goes on.
+type stepBudget struct {
+	started   time.Time
+	steps     int
+	toolCalls map[string]int
+}
+
+func newStepBudget() *stepBudget {
+	return &stepBudget{
+		started:   time.Now(),
+		toolCalls: make(map[string]int),
+	}
+}
+
+// observe records a tool cycle of the turn.
+func (b *stepBudget) observe(msg message.Message) {
+	b.steps++
+	for _, call := range msg.ToolCalls() {
+		b.toolCalls[call.Name]++
+	}
+}
+
+// exceeded reports whether the turn used up the configured number of steps
+// or minutes. A limit of 0 disables it.
+func (b *stepBudget) exceeded(limit config.StepLimitConfig) bool {
+	if limit.MaxSteps > 0 && b.steps >= limit.MaxSteps {
+		return true
+	}
+	return limit.MaxMinutes > 0 && time.Since(b.started) >= time.Duration(limit.MaxMinutes)*time.Minute
+}
+
+func (b *stepBudget) pause(sessionID string) *StepLimitPause {
+	return &StepLimitPause{
+		SessionID: sessionID,
+		Steps:     b.steps,
+		Elapsed:   time.Since(b.started),
+		ToolCalls: b.toolCalls,
+	}
+}
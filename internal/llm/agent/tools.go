@@ -3,9 +3,11 @@ package agent
 import (
 	"context"
 
+	"github.com/opencode-ai/opencode/internal/config"
 	"github.com/opencode-ai/opencode/internal/history"
 	"github.com/opencode-ai/opencode/internal/llm/tools"
 	"github.com/opencode-ai/opencode/internal/lsp"
+	"github.com/opencode-ai/opencode/internal/memory"
 	"github.com/opencode-ai/opencode/internal/message"
 	"github.com/opencode-ai/opencode/internal/permission"
 	"github.com/opencode-ai/opencode/internal/session"
@@ -16,17 +18,28 @@ func CoderAgentTools(
 	sessions session.Service,
 	messages message.Service,
 	history history.Service,
+	memories memory.Service,
 	lspClients map[string]*lsp.Client,
 ) []tools.BaseTool {
 	ctx := context.Background()
 	otherTools := GetMcpTools(ctx, permissions)
 	if len(lspClients) > 0 {
-		otherTools = append(otherTools, tools.NewDiagnosticsTool(lspClients))
+		otherTools = append(otherTools,
+			tools.NewDiagnosticsTool(lspClients),
+			tools.NewFixDiagnosticsTool(lspClients, permissions, history),
+		)
+	}
+	if config.Get().PinnedPlan {
+		otherTools = append(otherTools, tools.NewPlanTool(memories))
+	}
+	if tools.DetectMultiplexer() != "" {
+		otherTools = append(otherTools, tools.NewEditorPanesTool())
 	}
 	return append(
 		[]tools.BaseTool{
 			tools.NewBashTool(permissions),
 			tools.NewEditTool(lspClients, permissions, history),
+			tools.NewMultiEditTool(lspClients, permissions, history),
 			tools.NewFetchTool(permissions),
 			tools.NewGlobTool(),
 			tools.NewGrepTool(),
@@ -34,7 +47,20 @@ func CoderAgentTools(
 			tools.NewSourcegraphTool(),
 			tools.NewViewTool(lspClients),
 			tools.NewPatchTool(lspClients, permissions, history),
+			tools.NewRefactorTool(lspClients, permissions, history),
+			tools.NewRenameTextTool(lspClients, permissions, history),
+			tools.NewOrganizeImportsTool(lspClients, permissions, history),
 			tools.NewWriteTool(lspClients, permissions, history),
+			tools.NewScratchTool(),
+			tools.NewMemoryTool(memories),
+			tools.NewBuildCheckTool(permissions),
+			tools.NewRunTestTool(),
+			tools.NewRunFunctionTool(permissions),
+			tools.NewImportGraphTool(),
+			tools.NewGitLogSearchTool(),
+			tools.NewComplexityTool(),
+			tools.NewMergeConflictsTool(),
+			tools.NewContinueTool(),
 			NewAgentTool(sessions, messages, lspClients),
 		}, otherTools...,
 	)
@@ -47,5 +73,6 @@ func TaskAgentTools(lspClients map[string]*lsp.Client) []tools.BaseTool {
 		tools.NewLsTool(),
 		tools.NewSourcegraphTool(),
 		tools.NewViewTool(lspClients),
+		tools.NewGitLogSearchTool(),
 	}
 }
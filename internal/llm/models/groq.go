@@ -42,6 +42,7 @@ var GroqModels = map[ModelID]Model{
 		CostPer1MOutCached:  0,
 		CostPer1MOut:        0.34,
 		ContextWindow:       128_000, // 10M when?
+		DefaultMaxTokens:    8192,
 		SupportsAttachments: true,
 	},
 
@@ -55,6 +56,7 @@ var GroqModels = map[ModelID]Model{
 		CostPer1MOutCached:  0,
 		CostPer1MOut:        0.20,
 		ContextWindow:       128_000,
+		DefaultMaxTokens:    8192,
 		SupportsAttachments: true,
 	},
 
@@ -68,6 +70,7 @@ var GroqModels = map[ModelID]Model{
 		CostPer1MOutCached:  0,
 		CostPer1MOut:        0.79,
 		ContextWindow:       128_000,
+		DefaultMaxTokens:    32768,
 		SupportsAttachments: false,
 	},
 
@@ -81,6 +84,7 @@ var GroqModels = map[ModelID]Model{
 		CostPer1MOutCached:  0,
 		CostPer1MOut:        0.99,
 		ContextWindow:       128_000,
+		DefaultMaxTokens:    16384,
 		CanReason:           true,
 		SupportsAttachments: false,
 	},
@@ -8,6 +8,7 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/opencode-ai/opencode/internal/logging"
@@ -19,42 +20,52 @@ const (
 
 	localModelsPath        = "v1/models"
 	lmStudioBetaModelsPath = "api/v0/models"
+
+	// localModelsTimeout bounds the model listing, which runs at startup,
+	// so a local server that does not answer cannot hang it.
+	localModelsTimeout = 5 * time.Second
 )
 
 func init() {
 	if endpoint := os.Getenv("LOCAL_ENDPOINT"); endpoint != "" {
-		localEndpoint, err := url.Parse(endpoint)
-		if err != nil {
-			logging.Debug("Failed to parse local endpoint",
-				"error", err,
-				"endpoint", endpoint,
-			)
-			return
-		}
-
-		load := func(url *url.URL, path string) []localModel {
-			url.Path = path
-			return listLocalModels(url.String())
-		}
+		LoadLocalModels(endpoint)
+	}
+}
 
-		models := load(localEndpoint, lmStudioBetaModelsPath)
+// LoadLocalModels adds the models served by the OpenAI compatible server at
+// endpoint, such as Ollama or LM Studio, to the supported models.
+func LoadLocalModels(endpoint string) {
+	localEndpoint, err := url.Parse(endpoint)
+	if err != nil {
+		logging.Debug("Failed to parse local endpoint",
+			"error", err,
+			"endpoint", endpoint,
+		)
+		return
+	}
 
-		if len(models) == 0 {
-			models = load(localEndpoint, localModelsPath)
-		}
+	load := func(url *url.URL, path string) []localModel {
+		url.Path = path
+		return listLocalModels(url.String())
+	}
 
-		if len(models) == 0 {
-			logging.Debug("No local models found",
-				"endpoint", endpoint,
-			)
-			return
-		}
+	models := load(localEndpoint, lmStudioBetaModelsPath)
 
-		loadLocalModels(models)
+	if len(models) == 0 {
+		models = load(localEndpoint, localModelsPath)
+	}
 
-		viper.SetDefault("providers.local.apiKey", "dummy")
-		ProviderPopularity[ProviderLocal] = 0
+	if len(models) == 0 {
+		logging.Debug("No local models found",
+			"endpoint", endpoint,
+		)
+		return
 	}
+
+	loadLocalModels(models)
+
+	viper.SetDefault("providers.local.apiKey", "dummy")
+	ProviderPopularity[ProviderLocal] = 0
 }
 
 type localModelList struct {
@@ -75,12 +86,14 @@ type localModel struct {
 }
 
 func listLocalModels(modelsEndpoint string) []localModel {
-	res, err := http.Get(modelsEndpoint)
+	client := &http.Client{Timeout: localModelsTimeout}
+	res, err := client.Get(modelsEndpoint)
 	if err != nil {
 		logging.Debug("Failed to list local models",
 			"error", err,
 			"endpoint", modelsEndpoint,
 		)
+		return nil
 	}
 	defer res.Body.Close()
 
@@ -89,6 +102,7 @@ func listLocalModels(modelsEndpoint string) []localModel {
 			"status", res.StatusCode,
 			"endpoint", modelsEndpoint,
 		)
+		return nil
 	}
 
 	var modelList localModelList
@@ -97,6 +111,7 @@ func listLocalModels(modelsEndpoint string) []localModel {
 			"error", err,
 			"endpoint", modelsEndpoint,
 		)
+		return nil
 	}
 
 	var supportedModels []localModel
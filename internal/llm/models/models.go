@@ -46,6 +46,20 @@ var ProviderPopularity = map[ModelProvider]int{
 	ProviderVertexAI:   8,
 }
 
+// ImplementedProviders are the providers requests can be sent to.
+var ImplementedProviders = []ModelProvider{
+	ProviderAnthropic,
+	ProviderOpenAI,
+	ProviderGemini,
+	ProviderGROQ,
+	ProviderOpenRouter,
+	ProviderBedrock,
+	ProviderAzure,
+	ProviderVertexAI,
+	ProviderXAI,
+	ProviderLocal,
+}
+
 var SupportedModels = map[ModelID]Model{
 	//
 	// // GEMINI
@@ -81,6 +95,8 @@ var SupportedModels = map[ModelID]Model{
 		CostPer1MInCached:  3.75,
 		CostPer1MOutCached: 0.30,
 		CostPer1MOut:       15.0,
+		ContextWindow:      AnthropicModels[Claude37Sonnet].ContextWindow,
+		DefaultMaxTokens:   AnthropicModels[Claude37Sonnet].DefaultMaxTokens,
 	},
 }
 
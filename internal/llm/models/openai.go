@@ -93,6 +93,7 @@ var OpenAIModels = map[ModelID]Model{
 		CostPer1MOutCached:  0.0,
 		CostPer1MOut:        0.60,
 		ContextWindow:       128_000,
+		DefaultMaxTokens:    16384,
 		SupportsAttachments: true,
 	},
 	O1: {
@@ -147,6 +148,7 @@ var OpenAIModels = map[ModelID]Model{
 		CostPer1MOutCached:  0.0,
 		CostPer1MOut:        40.00,
 		ContextWindow:       200_000,
+		DefaultMaxTokens:    50000,
 		CanReason:           true,
 		SupportsAttachments: true,
 	},
@@ -95,6 +95,7 @@ var OpenRouterModels = map[ModelID]Model{
 		CostPer1MOut:       OpenAIModels[GPT4oMini].CostPer1MOut,
 		CostPer1MOutCached: OpenAIModels[GPT4oMini].CostPer1MOutCached,
 		ContextWindow:      OpenAIModels[GPT4oMini].ContextWindow,
+		DefaultMaxTokens:   OpenAIModels[GPT4oMini].DefaultMaxTokens,
 	},
 	OpenRouterO1: {
 		ID:                 OpenRouterO1,
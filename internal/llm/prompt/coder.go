@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/opencode-ai/opencode/internal/config"
@@ -21,7 +22,7 @@ func CoderPrompt(provider models.ModelProvider) string {
 	}
 	envInfo := getEnvironmentInfo()
 
-	return fmt.Sprintf("%s\n\n%s\n%s", basePrompt, envInfo, lspInformation())
+	return fmt.Sprintf("%s\n\n%s\n%s%s", basePrompt, envInfo, lspInformation(), planInformation())
 }
 
 const baseOpenAICoderPrompt = `
@@ -176,22 +177,53 @@ func getEnvironmentInfo() string {
 	r, _ := ls.Run(context.Background(), tools.ToolCall{
 		Input: `{"path":"."}`,
 	})
+	writeRoot := ""
+	if root := config.WriteRoot(); root != cwd {
+		writeRoot = fmt.Sprintf("Write root: %s (only change files under it; the rest of the working directory is read-only)\n", root)
+	}
 	return fmt.Sprintf(`Here is useful information about the environment you are running in:
 <env>
 Working directory: %s
-Is directory a git repo: %s
+%sIs directory a git repo: %s
 Platform: %s
 Today's date: %s
 </env>
 <project>
 %s
 </project>
-		`, cwd, boolToYesNo(isGit), platform, date, r.Content)
+		`, cwd, writeRoot, boolToYesNo(isGit), platform, date, r.Content)
 }
 
+var (
+	gitRepoOnce sync.Once
+	gitRepo     bool
+)
+
+// isGitRepo reports whether dir is inside a git repository. The result is
+// computed once, since the working directory does not change while running.
 func isGitRepo(dir string) bool {
-	_, err := os.Stat(filepath.Join(dir, ".git"))
-	return err == nil
+	gitRepoOnce.Do(func() {
+		gitRepo = findGitDir(dir)
+	})
+	return gitRepo
+}
+
+func findGitDir(dir string) bool {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	for {
+		// .git is a directory in regular checkouts and a file in worktrees and submodules.
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
 }
 
 func lspInformation() string {
@@ -214,6 +246,19 @@ Tools that support it will also include useful diagnostics such as linting and t
 `
 }
 
+func planInformation() string {
+	if !config.Get().PinnedPlan {
+		return ""
+	}
+	return `# Task Plan
+For tasks that take several steps, start by calling the plan tool with a short plan of 3 to 8 concrete steps before making changes.
+- The plan is pinned at the top of the transcript for the user and is included with every user message within <plan></plan> tags.
+- Mark a step "in_progress" when you start it and "done" as soon as it is complete, always sending the full list of steps.
+- If the approach changes, update the plan instead of silently diverging from it.
+- Skip the plan for simple questions and single-step changes.
+`
+}
+
 func boolToYesNo(b bool) string {
 	if b {
 		return "Yes"
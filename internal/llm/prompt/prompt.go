@@ -23,17 +23,25 @@ func GetAgentPrompt(agentName config.AgentName, provider models.ModelProvider) s
 		basePrompt = TaskPrompt(provider)
 	case config.AgentSummarizer:
 		basePrompt = SummarizerPrompt(provider)
+	case config.AgentReviewer:
+		basePrompt = ReviewerPrompt(provider)
 	default:
 		basePrompt = "You are a helpful assistant"
 	}
 
 	if agentName == config.AgentCoder || agentName == config.AgentTask {
-		// Add context from project-specific instruction files if they exist
-		contextContent := getContextFromPaths()
-		logging.Debug("Context content", "Context", contextContent)
-		if contextContent != "" {
-			return fmt.Sprintf("%s\n\n# Project-Specific Context\n Make sure to follow the instructions in the context below\n%s", basePrompt, contextContent)
-		}
+		return withProjectContext(basePrompt)
+	}
+	return basePrompt
+}
+
+// withProjectContext adds the content of the project-specific instruction
+// files, if they exist, to basePrompt.
+func withProjectContext(basePrompt string) string {
+	contextContent := getContextFromPaths()
+	logging.Debug("Context content", "Context", contextContent)
+	if contextContent != "" {
+		return fmt.Sprintf("%s\n\n# Project-Specific Context\n Make sure to follow the instructions in the context below\n%s", basePrompt, contextContent)
 	}
 	return basePrompt
 }
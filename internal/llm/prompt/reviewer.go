@@ -0,0 +1,37 @@
+package prompt
+
+import (
+	"fmt"
+
+	"github.com/opencode-ai/opencode/internal/llm/models"
+)
+
+// ReviewApproved starts the reply of the reviewer when it finds no issues.
+const ReviewApproved = "LGTM"
+
+func ReviewerPrompt(_ models.ModelProvider) string {
+	return `You are a senior engineer reviewing changes made by a coding assistant.
+
+You are given the request of the user and the unified diff of the changes. Look for:
+- Bugs, such as wrong logic, unhandled errors, off-by-one mistakes and race conditions
+- Changes that do not do what the user asked for, or do more than that
+- Code that does not compile or breaks existing callers
+- Security problems
+
+Do not comment on style or formatting unless it hides a bug.
+
+If there are no issues, reply with ` + ReviewApproved + ` and nothing else.
+Otherwise reply with a short numbered list of the issues. For each issue, name the file and line and say what is wrong and how to fix it.`
+}
+
+// ReviewRequestPrompt returns the message that asks the reviewer to review
+// diff, the changes made for request.
+func ReviewRequestPrompt(request, diff string) string {
+	return fmt.Sprintf("<request>\n%s\n</request>\n\n<diff>\n%s</diff>", request, diff)
+}
+
+// ReviewFixPrompt returns the message that sends the issues found by the
+// reviewer back to the coder agent.
+func ReviewFixPrompt(review string) string {
+	return fmt.Sprintf("A reviewer found these issues in your changes:\n\n%s\n\nFix the issues you agree with. If you disagree with an issue, say why.", review)
+}
@@ -1,6 +1,17 @@
 package prompt
 
-import "github.com/opencode-ai/opencode/internal/llm/models"
+import (
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/models"
+)
+
+// ConversationPlaceholder is replaced with the transcript of the session when
+// it appears in a custom summary prompt.
+const ConversationPlaceholder = "$CONVERSATION"
+
+const defaultSummaryRequestPrompt = "Provide a detailed but concise summary of our conversation above. Focus on information that would be helpful for continuing the conversation, including what we did, what we're doing, which files we're working on, and what we're going to do next."
 
 func SummarizerPrompt(_ models.ModelProvider) string {
 	return `You are a helpful AI assistant tasked with summarizing conversations.
@@ -14,3 +25,12 @@ Focus on information that would be helpful for continuing the conversation, incl
 
 Your summary should be comprehensive enough to provide context but concise enough to be quickly understood.`
 }
+
+// SummaryRequestPrompt returns the instruction sent to the summarizer when a
+// session is compacted, using the configured summaryPrompt when there is one.
+func SummaryRequestPrompt() string {
+	if custom := strings.TrimSpace(config.Get().SummaryPrompt); custom != "" {
+		return custom
+	}
+	return defaultSummaryRequestPrompt
+}
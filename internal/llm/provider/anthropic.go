@@ -23,10 +23,20 @@ type anthropicOptions struct {
 	useBedrock   bool
 	disableCache bool
 	shouldThink  func(userMessage string) bool
+	// thinkingBudget is the fraction of the max tokens that extended
+	// thinking may use.
+	thinkingBudget float64
+	// betas are sent in the anthropic-beta header to opt into beta
+	// features, such as longer outputs.
+	betas []string
 }
 
 type AnthropicOption func(*anthropicOptions)
 
+// defaultThinkingBudget is the fraction of the max tokens extended thinking
+// may use when no budget is set.
+const defaultThinkingBudget = 0.8
+
 type anthropicClient struct {
 	providerOptions providerClientOptions
 	options         anthropicOptions
@@ -128,9 +138,14 @@ func (a *anthropicClient) convertTools(tools []tools.BaseTool) []anthropic.ToolU
 			Description: anthropic.String(info.Description),
 			InputSchema: anthropic.ToolInputSchemaParam{
 				Properties: info.Parameters,
-				// TODO: figure out how we can tell claude the required fields?
 			},
 		}
+		// The SDK has no field for the required properties of the schema.
+		if len(info.Required) > 0 {
+			toolParam.InputSchema.WithExtraFields(map[string]any{
+				"required": info.Required,
+			})
+		}
 
 		if i == len(tools)-1 && !a.options.disableCache {
 			toolParam.CacheControl = anthropic.CacheControlEphemeralParam{
@@ -159,7 +174,7 @@ func (a *anthropicClient) finishReason(reason string) message.FinishReason {
 	}
 }
 
-func (a *anthropicClient) preparedMessages(messages []anthropic.MessageParam, tools []anthropic.ToolUnionParam) anthropic.MessageNewParams {
+func (a *anthropicClient) preparedMessages(ctx context.Context, messages []anthropic.MessageParam, tools []anthropic.ToolUnionParam) anthropic.MessageNewParams {
 	var thinkingParam anthropic.ThinkingConfigParamUnion
 	lastMessage := messages[len(messages)-1]
 	isUser := lastMessage.Role == anthropic.MessageParamRoleUser
@@ -171,10 +186,11 @@ func (a *anthropicClient) preparedMessages(messages []anthropic.MessageParam, to
 				messageContent = m.OfRequestTextBlock.Text
 			}
 		}
-		if messageContent != "" && a.options.shouldThink != nil && a.options.shouldThink(messageContent) {
+		think := thinkingRequested(ctx) || (a.options.shouldThink != nil && a.options.shouldThink(messageContent))
+		if messageContent != "" && think && a.providerOptions.model.CanReason {
 			thinkingParam = anthropic.ThinkingConfigParamUnion{
 				OfThinkingConfigEnabled: &anthropic.ThinkingConfigEnabledParam{
-					BudgetTokens: int64(float64(a.providerOptions.maxTokens) * 0.8),
+					BudgetTokens: a.thinkingBudgetTokens(),
 					Type:         "enabled",
 				},
 			}
@@ -200,8 +216,26 @@ func (a *anthropicClient) preparedMessages(messages []anthropic.MessageParam, to
 	}
 }
 
+// thinkingBudgetTokens returns the tokens extended thinking may use, a
+// fraction of the max tokens of the response.
+func (a *anthropicClient) thinkingBudgetTokens() int64 {
+	fraction := a.options.thinkingBudget
+	if fraction <= 0 || fraction >= 1 {
+		fraction = defaultThinkingBudget
+	}
+	return int64(float64(a.providerOptions.maxTokens) * fraction)
+}
+
+// requestOptions returns the options added to every request of the client.
+func (a *anthropicClient) requestOptions() []option.RequestOption {
+	if len(a.options.betas) == 0 {
+		return nil
+	}
+	return []option.RequestOption{option.WithHeader("anthropic-beta", strings.Join(a.options.betas, ","))}
+}
+
 func (a *anthropicClient) send(ctx context.Context, messages []message.Message, tools []tools.BaseTool) (resposne *ProviderResponse, err error) {
-	preparedMessages := a.preparedMessages(a.convertMessages(messages), a.convertTools(tools))
+	preparedMessages := a.preparedMessages(ctx, a.convertMessages(messages), a.convertTools(tools))
 	cfg := config.Get()
 	if cfg.Debug {
 		jsonData, _ := json.Marshal(preparedMessages)
@@ -214,6 +248,7 @@ func (a *anthropicClient) send(ctx context.Context, messages []message.Message,
 		anthropicResponse, err := a.client.Messages.New(
 			ctx,
 			preparedMessages,
+			a.requestOptions()...,
 		)
 		// If there is an error we are going to see if we can retry the call
 		if err != nil {
@@ -223,7 +258,7 @@ func (a *anthropicClient) send(ctx context.Context, messages []message.Message,
 				return nil, retryErr
 			}
 			if retry {
-				logging.WarnPersist(fmt.Sprintf("Retrying due to rate limit... attempt %d of %d", attempts, maxRetries), logging.PersistTimeArg, time.Millisecond*time.Duration(after+100))
+				logging.WarnPersist(fmt.Sprintf("Retrying due to rate limit... attempt %d of %d", attempts, a.providerOptions.maxRetries), logging.PersistTimeArg, time.Millisecond*time.Duration(after+100))
 				select {
 				case <-ctx.Done():
 					return nil, ctx.Err()
@@ -250,7 +285,7 @@ func (a *anthropicClient) send(ctx context.Context, messages []message.Message,
 }
 
 func (a *anthropicClient) stream(ctx context.Context, messages []message.Message, tools []tools.BaseTool) <-chan ProviderEvent {
-	preparedMessages := a.preparedMessages(a.convertMessages(messages), a.convertTools(tools))
+	preparedMessages := a.preparedMessages(ctx, a.convertMessages(messages), a.convertTools(tools))
 	cfg := config.Get()
 	if cfg.Debug {
 		// jsonData, _ := json.Marshal(preparedMessages)
@@ -264,6 +299,7 @@ func (a *anthropicClient) stream(ctx context.Context, messages []message.Message
 			anthropicStream := a.client.Messages.NewStreaming(
 				ctx,
 				preparedMessages,
+				a.requestOptions()...,
 			)
 			accumulatedMessage := anthropic.Message{}
 
@@ -310,7 +346,7 @@ func (a *anthropicClient) stream(ctx context.Context, messages []message.Message
 								ToolCall: &message.ToolCall{
 									ID:       currentToolCallID,
 									Finished: false,
-									Input:    event.Delta.JSON.PartialJSON.Raw(),
+									Input:    event.Delta.PartialJSON,
 								},
 							}
 						}
@@ -361,7 +397,7 @@ func (a *anthropicClient) stream(ctx context.Context, messages []message.Message
 				return
 			}
 			if retry {
-				logging.WarnPersist(fmt.Sprintf("Retrying due to rate limit... attempt %d of %d", attempts, maxRetries), logging.PersistTimeArg, time.Millisecond*time.Duration(after+100))
+				logging.WarnPersist(fmt.Sprintf("Retrying due to rate limit... attempt %d of %d", attempts, a.providerOptions.maxRetries), logging.PersistTimeArg, time.Millisecond*time.Duration(after+100))
 				select {
 				case <-ctx.Done():
 					// context cancelled
@@ -395,22 +431,11 @@ func (a *anthropicClient) shouldRetry(attempts int, err error) (bool, int64, err
 		return false, 0, err
 	}
 
-	if attempts > maxRetries {
-		return false, 0, fmt.Errorf("maximum retry attempts reached for rate limit: %d retries", maxRetries)
+	if attempts > a.providerOptions.maxRetries {
+		return false, 0, fmt.Errorf("maximum retry attempts reached for rate limit: %d retries", a.providerOptions.maxRetries)
 	}
 
-	retryMs := 0
-	retryAfterValues := apierr.Response.Header.Values("Retry-After")
-
-	backoffMs := 2000 * (1 << (attempts - 1))
-	jitterMs := int(float64(backoffMs) * 0.2)
-	retryMs = backoffMs + jitterMs
-	if len(retryAfterValues) > 0 {
-		if _, err := fmt.Sscanf(retryAfterValues[0], "%d", &retryMs); err == nil {
-			retryMs = retryMs * 1000
-		}
-	}
-	return true, int64(retryMs), nil
+	return true, a.providerOptions.retryDelay(attempts, apierr.Response.Header), nil
 }
 
 func (a *anthropicClient) toolCalls(msg anthropic.Message) []message.ToolCall {
@@ -454,12 +479,25 @@ func WithAnthropicDisableCache() AnthropicOption {
 	}
 }
 
-func DefaultShouldThinkFn(s string) bool {
-	return strings.Contains(strings.ToLower(s), "think")
-}
-
 func WithAnthropicShouldThinkFn(fn func(string) bool) AnthropicOption {
 	return func(options *anthropicOptions) {
 		options.shouldThink = fn
 	}
 }
+
+// WithAnthropicThinkingBudget sets the fraction of the max tokens that
+// extended thinking may use. Values outside (0, 1) keep the default of 0.8.
+func WithAnthropicThinkingBudget(fraction float64) AnthropicOption {
+	return func(options *anthropicOptions) {
+		options.thinkingBudget = fraction
+	}
+}
+
+// WithAnthropicBeta opts into beta features by adding their names to the
+// anthropic-beta header, e.g. "output-128k-2025-02-19" for longer outputs of
+// Claude 3.7 Sonnet.
+func WithAnthropicBeta(headers ...string) AnthropicOption {
+	return func(options *anthropicOptions) {
+		options.betas = append(options.betas, headers...)
+	}
+}
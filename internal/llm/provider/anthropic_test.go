@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/models"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnthropicBetaHeader(t *testing.T) {
+	_, err := config.Load(t.TempDir(), false)
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var betas []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		betas = append(betas, r.Header.Get("anthropic-beta"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"type":"error","error":{"type":"invalid_request_error","message":"test"}}`))
+	}))
+	defer server.Close()
+
+	options := anthropicOptions{}
+	WithAnthropicBeta("output-128k-2025-02-19", "token-efficient-tools-2025-02-19")(&options)
+	client := &anthropicClient{
+		providerOptions: providerClientOptions{
+			model:     models.SupportedModels[models.Claude37Sonnet],
+			maxTokens: 1024,
+		},
+		options: options,
+		client:  anthropic.NewClient(option.WithBaseURL(server.URL), option.WithAPIKey("test")),
+	}
+	messages := []message.Message{{
+		Role:  message.User,
+		Parts: []message.ContentPart{message.TextContent{Text: "hello"}},
+	}}
+
+	_, err = client.send(context.Background(), messages, nil)
+	assert.Error(t, err)
+	for event := range client.stream(context.Background(), messages, nil) {
+		if event.Type == EventError {
+			break
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{
+		"output-128k-2025-02-19,token-efficient-tools-2025-02-19",
+		"output-128k-2025-02-19,token-efficient-tools-2025-02-19",
+	}, betas)
+}
+
+func TestAnthropicThinking(t *testing.T) {
+	options := anthropicOptions{}
+	WithAnthropicThinkingBudget(0.5)(&options)
+	client := &anthropicClient{
+		providerOptions: providerClientOptions{
+			model:     models.SupportedModels[models.Claude37Sonnet],
+			maxTokens: 8000,
+		},
+		options: options,
+	}
+	messages := client.convertMessages([]message.Message{{
+		Role:  message.User,
+		Parts: []message.ContentPart{message.TextContent{Text: "think about it"}},
+	}})
+
+	params := client.preparedMessages(context.Background(), messages, nil)
+	assert.Nil(t, params.Thinking.OfThinkingConfigEnabled, "thinking is only on when asked for")
+
+	params = client.preparedMessages(WithThinking(context.Background()), messages, nil)
+	require.NotNil(t, params.Thinking.OfThinkingConfigEnabled)
+	assert.Equal(t, int64(4000), params.Thinking.OfThinkingConfigEnabled.BudgetTokens)
+
+	params = client.preparedMessages(WithoutThinking(WithThinking(context.Background())), messages, nil)
+	assert.Nil(t, params.Thinking.OfThinkingConfigEnabled, "side requests drop the thinking request")
+
+	client.providerOptions.model = models.SupportedModels[models.Claude35Haiku]
+	params = client.preparedMessages(WithThinking(context.Background()), messages, nil)
+	assert.Nil(t, params.Thinking.OfThinkingConfigEnabled, "models that cannot reason never think")
+}
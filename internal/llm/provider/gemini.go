@@ -93,6 +93,9 @@ func (g *geminiClient) convertMessages(messages []message.Message) []*genai.Cont
 			history = append(history, content)
 
 		case message.Tool:
+			// Gemini expects the responses to all the function calls of a
+			// turn in a single content, in the user role.
+			content := &genai.Content{Role: genai.RoleUser}
 			for _, result := range msg.ToolResults() {
 				response := map[string]interface{}{"result": result.Content}
 				parsed, err := parseJsonToMap(result.Content)
@@ -112,18 +115,16 @@ func (g *geminiClient) convertMessages(messages []message.Message) []*genai.Cont
 					}
 				}
 
-				history = append(history, &genai.Content{
-					Parts: []*genai.Part{
-						{
-							FunctionResponse: &genai.FunctionResponse{
-								Name:     toolCall.Name,
-								Response: response,
-							},
-						},
+				content.Parts = append(content.Parts, &genai.Part{
+					FunctionResponse: &genai.FunctionResponse{
+						Name:     toolCall.Name,
+						Response: response,
 					},
-					Role: "function",
 				})
 			}
+			if len(content.Parts) > 0 {
+				history = append(history, content)
+			}
 		}
 	}
 
@@ -203,7 +204,7 @@ func (g *geminiClient) send(ctx context.Context, messages []message.Message, too
 				return nil, retryErr
 			}
 			if retry {
-				logging.WarnPersist(fmt.Sprintf("Retrying due to rate limit... attempt %d of %d", attempts, maxRetries), logging.PersistTimeArg, time.Millisecond*time.Duration(after+100))
+				logging.WarnPersist(fmt.Sprintf("Retrying due to rate limit... attempt %d of %d", attempts, g.providerOptions.maxRetries), logging.PersistTimeArg, time.Millisecond*time.Duration(after+100))
 				select {
 				case <-ctx.Done():
 					return nil, ctx.Err()
@@ -220,7 +221,7 @@ func (g *geminiClient) send(ctx context.Context, messages []message.Message, too
 			for _, part := range resp.Candidates[0].Content.Parts {
 				switch {
 				case part.Text != "":
-					content = string(part.Text)
+					content += part.Text
 				case part.FunctionCall != nil:
 					id := "call_" + uuid.New().String()
 					args, _ := json.Marshal(part.FunctionCall.Args)
@@ -280,6 +281,8 @@ func (g *geminiClient) stream(ctx context.Context, messages []message.Message, t
 	go func() {
 		defer close(eventChan)
 
+		eventChan <- ProviderEvent{Type: EventContentStart}
+
 		for {
 			attempts++
 
@@ -287,22 +290,27 @@ func (g *geminiClient) stream(ctx context.Context, messages []message.Message, t
 			toolCalls := []message.ToolCall{}
 			var finalResp *genai.GenerateContentResponse
 
-			eventChan <- ProviderEvent{Type: EventContentStart}
-
 			var lastMsgParts []genai.Part
 
 			for _, part := range lastMsg.Parts {
 				lastMsgParts = append(lastMsgParts, *part)
 			}
+			retrying := false
 			for resp, err := range chat.SendMessageStream(ctx, lastMsgParts...) {
 				if err != nil {
+					// Once text was streamed the consumer already shows it,
+					// so a retry would repeat it; the error ends the turn.
+					if currentContent != "" {
+						eventChan <- ProviderEvent{Type: EventError, Error: err}
+						return
+					}
 					retry, after, retryErr := g.shouldRetry(attempts, err)
 					if retryErr != nil {
 						eventChan <- ProviderEvent{Type: EventError, Error: retryErr}
 						return
 					}
 					if retry {
-						logging.WarnPersist(fmt.Sprintf("Retrying due to rate limit... attempt %d of %d", attempts, maxRetries), logging.PersistTimeArg, time.Millisecond*time.Duration(after+100))
+						logging.WarnPersist(fmt.Sprintf("Retrying due to rate limit... attempt %d of %d", attempts, g.providerOptions.maxRetries), logging.PersistTimeArg, time.Millisecond*time.Duration(after+100))
 						select {
 						case <-ctx.Done():
 							if ctx.Err() != nil {
@@ -311,12 +319,12 @@ func (g *geminiClient) stream(ctx context.Context, messages []message.Message, t
 
 							return
 						case <-time.After(time.Duration(after) * time.Millisecond):
-							break
 						}
-					} else {
-						eventChan <- ProviderEvent{Type: EventError, Error: err}
-						return
+						retrying = true
+						break
 					}
+					eventChan <- ProviderEvent{Type: EventError, Error: err}
+					return
 				}
 
 				finalResp = resp
@@ -360,29 +368,31 @@ func (g *geminiClient) stream(ctx context.Context, messages []message.Message, t
 				}
 			}
 
-			eventChan <- ProviderEvent{Type: EventContentStop}
+			// The failed attempt, which streamed nothing yet, is sent again
+			// from the start.
+			if retrying {
+				continue
+			}
 
-			if finalResp != nil {
+			eventChan <- ProviderEvent{Type: EventContentStop}
 
-				finishReason := message.FinishReasonEndTurn
-				if len(finalResp.Candidates) > 0 {
-					finishReason = g.finishReason(finalResp.Candidates[0].FinishReason)
-				}
-				if len(toolCalls) > 0 {
-					finishReason = message.FinishReasonToolUse
-				}
-				eventChan <- ProviderEvent{
-					Type: EventComplete,
-					Response: &ProviderResponse{
-						Content:      currentContent,
-						ToolCalls:    toolCalls,
-						Usage:        g.usage(finalResp),
-						FinishReason: finishReason,
-					},
-				}
-				return
+			finishReason := message.FinishReasonEndTurn
+			if finalResp != nil && len(finalResp.Candidates) > 0 {
+				finishReason = g.finishReason(finalResp.Candidates[0].FinishReason)
 			}
-
+			if len(toolCalls) > 0 {
+				finishReason = message.FinishReasonToolUse
+			}
+			eventChan <- ProviderEvent{
+				Type: EventComplete,
+				Response: &ProviderResponse{
+					Content:      currentContent,
+					ToolCalls:    toolCalls,
+					Usage:        g.usage(finalResp),
+					FinishReason: finishReason,
+				},
+			}
+			return
 		}
 	}()
 
@@ -391,8 +401,8 @@ func (g *geminiClient) stream(ctx context.Context, messages []message.Message, t
 
 func (g *geminiClient) shouldRetry(attempts int, err error) (bool, int64, error) {
 	// Check if error is a rate limit error
-	if attempts > maxRetries {
-		return false, 0, fmt.Errorf("maximum retry attempts reached for rate limit: %d retries", maxRetries)
+	if attempts > g.providerOptions.maxRetries {
+		return false, 0, fmt.Errorf("maximum retry attempts reached for rate limit: %d retries", g.providerOptions.maxRetries)
 	}
 
 	// Gemini doesn't have a standard error type we can check against
@@ -413,12 +423,7 @@ func (g *geminiClient) shouldRetry(attempts int, err error) (bool, int64, error)
 		return false, 0, err
 	}
 
-	// Calculate backoff with jitter
-	backoffMs := 2000 * (1 << (attempts - 1))
-	jitterMs := int(float64(backoffMs) * 0.2)
-	retryMs := backoffMs + jitterMs
-
-	return true, int64(retryMs), nil
+	return true, g.providerOptions.retryDelay(attempts, nil), nil
 }
 
 func (g *geminiClient) toolCalls(resp *genai.GenerateContentResponse) []message.ToolCall {
@@ -447,11 +452,13 @@ func (g *geminiClient) usage(resp *genai.GenerateContentResponse) TokenUsage {
 		return TokenUsage{}
 	}
 
+	// The prompt tokens include the cached ones.
+	cachedTokens := int64(resp.UsageMetadata.CachedContentTokenCount)
 	return TokenUsage{
-		InputTokens:         int64(resp.UsageMetadata.PromptTokenCount),
+		InputTokens:         int64(resp.UsageMetadata.PromptTokenCount) - cachedTokens,
 		OutputTokens:        int64(resp.UsageMetadata.CandidatesTokenCount),
 		CacheCreationTokens: 0, // Not directly provided by Gemini
-		CacheReadTokens:     int64(resp.UsageMetadata.CachedContentTokenCount),
+		CacheReadTokens:     cachedTokens,
 	}
 }
 
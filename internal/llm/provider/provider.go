@@ -1,9 +1,14 @@
 package provider
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/opencode-ai/opencode/internal/llm/models"
 	"github.com/opencode-ai/opencode/internal/llm/tools"
@@ -12,7 +17,11 @@ import (
 
 type EventType string
 
-const maxRetries = 8
+const (
+	defaultMaxRetries  = 8
+	defaultBaseBackoff = 2 * time.Second
+	defaultMaxBackoff  = 2 * time.Minute
+)
 
 const (
 	EventContentStart  EventType = "content_start"
@@ -34,6 +43,26 @@ type TokenUsage struct {
 	CacheReadTokens     int64
 }
 
+// Cost returns the cost in dollars of the tokens with the prices of model.
+// Anthropic models price cache writes at CostPer1MInCached and cache reads
+// at CostPer1MOutCached. Other models only have the price of cache reads,
+// in CostPer1MInCached, and without any cache prices cached tokens cost as
+// much as other input tokens.
+func (u TokenUsage) Cost(model models.Model) float64 {
+	writeRate := model.CostPer1MInCached
+	if writeRate == 0 {
+		writeRate = model.CostPer1MIn
+	}
+	readRate := model.CostPer1MOutCached
+	if readRate == 0 {
+		readRate = writeRate
+	}
+	return writeRate/1e6*float64(u.CacheCreationTokens) +
+		readRate/1e6*float64(u.CacheReadTokens) +
+		model.CostPer1MIn/1e6*float64(u.InputTokens) +
+		model.CostPer1MOut/1e6*float64(u.OutputTokens)
+}
+
 type ProviderResponse struct {
 	Content      string
 	ToolCalls    []message.ToolCall
@@ -60,9 +89,14 @@ type Provider interface {
 
 type providerClientOptions struct {
 	apiKey        string
+	baseURL       string
 	model         models.Model
 	maxTokens     int64
 	systemMessage string
+	maxRetries    int
+	baseBackoff   time.Duration
+	maxBackoff    time.Duration
+	requestLogger RequestLogger
 
 	anthropicOptions []AnthropicOption
 	openaiOptions    []OpenAIOption
@@ -83,7 +117,11 @@ type baseProvider[C ProviderClient] struct {
 }
 
 func NewProvider(providerName models.ModelProvider, opts ...ProviderClientOption) (Provider, error) {
-	clientOptions := providerClientOptions{}
+	clientOptions := providerClientOptions{
+		maxRetries:  defaultMaxRetries,
+		baseBackoff: defaultBaseBackoff,
+		maxBackoff:  defaultMaxBackoff,
+	}
 	for _, o := range opts {
 		o(&clientOptions)
 	}
@@ -94,6 +132,11 @@ func NewProvider(providerName models.ModelProvider, opts ...ProviderClientOption
 			client:  newAnthropicClient(clientOptions),
 		}, nil
 	case models.ProviderOpenAI:
+		if clientOptions.baseURL != "" {
+			clientOptions.openaiOptions = append(clientOptions.openaiOptions,
+				WithOpenAIBaseURL(clientOptions.baseURL),
+			)
+		}
 		return &baseProvider[OpenAIClient]{
 			options: clientOptions,
 			client:  newOpenAIClient(clientOptions),
@@ -110,7 +153,7 @@ func NewProvider(providerName models.ModelProvider, opts ...ProviderClientOption
 		}, nil
 	case models.ProviderGROQ:
 		clientOptions.openaiOptions = append(clientOptions.openaiOptions,
-			WithOpenAIBaseURL("https://api.groq.com/openai/v1"),
+			WithOpenAIBaseURL(cmp.Or(clientOptions.baseURL, "https://api.groq.com/openai/v1")),
 		)
 		return &baseProvider[OpenAIClient]{
 			options: clientOptions,
@@ -128,7 +171,7 @@ func NewProvider(providerName models.ModelProvider, opts ...ProviderClientOption
 		}, nil
 	case models.ProviderOpenRouter:
 		clientOptions.openaiOptions = append(clientOptions.openaiOptions,
-			WithOpenAIBaseURL("https://openrouter.ai/api/v1"),
+			WithOpenAIBaseURL(cmp.Or(clientOptions.baseURL, "https://openrouter.ai/api/v1")),
 			WithOpenAIExtraHeaders(map[string]string{
 				"HTTP-Referer": "opencode.ai",
 				"X-Title":      "OpenCode",
@@ -140,7 +183,7 @@ func NewProvider(providerName models.ModelProvider, opts ...ProviderClientOption
 		}, nil
 	case models.ProviderXAI:
 		clientOptions.openaiOptions = append(clientOptions.openaiOptions,
-			WithOpenAIBaseURL("https://api.x.ai/v1"),
+			WithOpenAIBaseURL(cmp.Or(clientOptions.baseURL, "https://api.x.ai/v1")),
 		)
 		return &baseProvider[OpenAIClient]{
 			options: clientOptions,
@@ -148,7 +191,7 @@ func NewProvider(providerName models.ModelProvider, opts ...ProviderClientOption
 		}, nil
 	case models.ProviderLocal:
 		clientOptions.openaiOptions = append(clientOptions.openaiOptions,
-			WithOpenAIBaseURL(os.Getenv("LOCAL_ENDPOINT")),
+			WithOpenAIBaseURL(cmp.Or(clientOptions.baseURL, os.Getenv("LOCAL_ENDPOINT"))),
 		)
 		return &baseProvider[OpenAIClient]{
 			options: clientOptions,
@@ -174,7 +217,10 @@ func (p *baseProvider[C]) cleanMessages(messages []message.Message) (cleaned []m
 
 func (p *baseProvider[C]) SendMessages(ctx context.Context, messages []message.Message, tools []tools.BaseTool) (*ProviderResponse, error) {
 	messages = p.cleanMessages(messages)
-	return p.client.send(ctx, messages, tools)
+	log := p.logRequest(messages, tools, false)
+	resp, err := p.client.send(ctx, messages, tools)
+	log.response(resp, err)
+	return resp, err
 }
 
 func (p *baseProvider[C]) Model() models.Model {
@@ -183,7 +229,8 @@ func (p *baseProvider[C]) Model() models.Model {
 
 func (p *baseProvider[C]) StreamResponse(ctx context.Context, messages []message.Message, tools []tools.BaseTool) <-chan ProviderEvent {
 	messages = p.cleanMessages(messages)
-	return p.client.stream(ctx, messages, tools)
+	log := p.logRequest(messages, tools, true)
+	return log.events(p.client.stream(ctx, messages, tools))
 }
 
 func WithAPIKey(apiKey string) ProviderClientOption {
@@ -192,6 +239,14 @@ func WithAPIKey(apiKey string) ProviderClientOption {
 	}
 }
 
+// WithBaseURL sends the requests of an OpenAI compatible provider to baseURL
+// instead of the provider's default endpoint.
+func WithBaseURL(baseURL string) ProviderClientOption {
+	return func(options *providerClientOptions) {
+		options.baseURL = baseURL
+	}
+}
+
 func WithModel(model models.Model) ProviderClientOption {
 	return func(options *providerClientOptions) {
 		options.model = model
@@ -210,6 +265,60 @@ func WithSystemMessage(systemMessage string) ProviderClientOption {
 	}
 }
 
+// WithRetryPolicy sets how often a rate limited request is retried and the
+// bounds of the exponential backoff between attempts. Zero values keep the
+// defaults.
+func WithRetryPolicy(maxRetries int, baseBackoff, maxBackoff time.Duration) ProviderClientOption {
+	return func(options *providerClientOptions) {
+		if maxRetries > 0 {
+			options.maxRetries = maxRetries
+		}
+		if baseBackoff > 0 {
+			options.baseBackoff = baseBackoff
+		}
+		if maxBackoff > 0 {
+			options.maxBackoff = maxBackoff
+		}
+	}
+}
+
+// retryDelay returns how many milliseconds to wait before the given retry
+// attempt. A delay the server asks for in the retry-after-ms header, in
+// milliseconds, or the Retry-After header, in seconds or as an HTTP date, is
+// used when present; otherwise the backoff doubles from baseBackoff with 20%
+// jitter. Either way it is capped at maxBackoff.
+func (o providerClientOptions) retryDelay(attempts int, header http.Header) int64 {
+	delay := o.baseBackoff << (attempts - 1)
+	if delay <= 0 || delay > o.maxBackoff {
+		// The shift overflowed or passed the cap.
+		delay = o.maxBackoff
+	}
+	delay += delay / 5
+	if after, ok := retryAfter(header, time.Now()); ok {
+		delay = after
+	}
+	return min(delay, o.maxBackoff).Milliseconds()
+}
+
+// retryAfter returns the delay requested by the retry headers of a response
+// received at now. A date in the past asks for no delay.
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	if header == nil {
+		return 0, false
+	}
+	if ms, err := strconv.ParseFloat(strings.TrimSpace(header.Get("Retry-After-Ms")), 64); err == nil && ms >= 0 {
+		return time.Duration(ms * float64(time.Millisecond)), true
+	}
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
 func WithAnthropicOptions(anthropicOptions ...AnthropicOption) ProviderClientOption {
 	return func(options *providerClientOptions) {
 		options.anthropicOptions = anthropicOptions
@@ -233,3 +342,22 @@ func WithBedrockOptions(bedrockOptions ...BedrockOption) ProviderClientOption {
 		options.bedrockOptions = bedrockOptions
 	}
 }
+
+type thinkingKey struct{}
+
+// WithThinking marks the requests made with ctx as asking for extended
+// thinking, for the providers and models that support it.
+func WithThinking(ctx context.Context) context.Context {
+	return context.WithValue(ctx, thinkingKey{}, true)
+}
+
+// WithoutThinking clears a thinking request made with WithThinking, for
+// side requests that run on ctx but are not the turn the user asked for.
+func WithoutThinking(ctx context.Context) context.Context {
+	return context.WithValue(ctx, thinkingKey{}, false)
+}
+
+func thinkingRequested(ctx context.Context) bool {
+	think, _ := ctx.Value(thinkingKey{}).(bool)
+	return think
+}
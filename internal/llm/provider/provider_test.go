@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/llm/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenUsageCost(t *testing.T) {
+	usage := TokenUsage{
+		InputTokens:         1_000_000,
+		OutputTokens:        100_000,
+		CacheCreationTokens: 200_000,
+		CacheReadTokens:     2_000_000,
+	}
+
+	tests := []struct {
+		name  string
+		model models.Model
+		want  float64
+	}{
+		{
+			name:  "anthropic prices cache writes and reads",
+			model: models.Model{CostPer1MIn: 3, CostPer1MOut: 15, CostPer1MInCached: 3.75, CostPer1MOutCached: 0.3},
+			// 3 input + 1.5 output + 0.75 cache writes + 0.6 cache reads
+			want: 5.85,
+		},
+		{
+			name:  "openai prices cache reads only",
+			model: models.Model{CostPer1MIn: 2, CostPer1MOut: 8, CostPer1MInCached: 0.5},
+			// 2 input + 0.8 output + 0.1 cache writes + 1 cache reads
+			want: 3.9,
+		},
+		{
+			name:  "no cache prices",
+			model: models.Model{CostPer1MIn: 1, CostPer1MOut: 4},
+			// 1 input + 0.4 output + 0.2 cache writes + 2 cache reads
+			want: 3.6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, usage.Cost(tt.model), 1e-9)
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	options := providerClientOptions{
+		maxRetries:  defaultMaxRetries,
+		baseBackoff: defaultBaseBackoff,
+		maxBackoff:  10 * time.Second,
+	}
+
+	tests := []struct {
+		name     string
+		attempts int
+		header   http.Header
+		want     int64
+	}{
+		{name: "first backoff", attempts: 1, want: 2400},
+		{name: "doubled backoff", attempts: 2, want: 4800},
+		{name: "backoff capped", attempts: 5, want: 10000},
+		{name: "retry-after in seconds", attempts: 1, header: http.Header{"Retry-After": {"3"}}, want: 3000},
+		{name: "fractional retry-after", attempts: 1, header: http.Header{"Retry-After": {"1.5"}}, want: 1500},
+		{name: "retry-after-ms wins", attempts: 1, header: http.Header{"Retry-After": {"3"}, "Retry-After-Ms": {"250"}}, want: 250},
+		{name: "retry-after capped", attempts: 1, header: http.Header{"Retry-After": {"3600"}}, want: 10000},
+		{name: "malformed retry-after", attempts: 2, header: http.Header{"Retry-After": {"soon"}}, want: 4800},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, options.retryDelay(tt.attempts, tt.header))
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, time.October, 21, 7, 26, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{name: "seconds", value: "120", want: 120 * time.Second, ok: true},
+		{name: "http date", value: "Wed, 21 Oct 2025 07:28:00 GMT", want: 2 * time.Minute, ok: true},
+		{name: "http date in the past", value: "Wed, 21 Oct 2025 07:20:00 GMT", want: 0, ok: true},
+		{name: "malformed", value: "in a while", ok: false},
+		{name: "negative", value: "-5", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := retryAfter(http.Header{"Retry-After": {tt.value}}, now)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRequestLogEvents(t *testing.T) {
+	var logged []string
+	log := &requestLog{
+		logger: func(msg string, args ...any) {
+			logged = append(logged, fmt.Sprint(args[3]))
+		},
+		id:    1,
+		start: time.Now(),
+	}
+
+	events := make(chan ProviderEvent)
+	go func() {
+		defer close(events)
+		for _, eventType := range []EventType{EventContentStart, EventContentDelta, EventContentDelta, EventThinkingDelta, EventContentStop, EventComplete} {
+			events <- ProviderEvent{Type: eventType}
+		}
+	}()
+
+	var received []EventType
+	for event := range log.events(events) {
+		received = append(received, event.Type)
+	}
+
+	assert.Len(t, received, 6)
+	assert.Equal(t, []string{"content_start", "content_delta", "thinking_delta", "content_stop", "complete"}, logged)
+}
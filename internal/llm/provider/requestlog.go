@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/message"
+)
+
+// RequestLogger receives a message with key value pairs for every request a
+// provider sends and every event of its responses, like logging.Debug.
+type RequestLogger func(msg string, args ...any)
+
+// WithRequestLogger logs the requests of the provider and the events of its
+// responses with their timing, whatever the provider.
+func WithRequestLogger(logger RequestLogger) ProviderClientOption {
+	return func(options *providerClientOptions) {
+		options.requestLogger = logger
+	}
+}
+
+var requestCounter atomic.Int64
+
+// requestLog follows a single request, so its lines can be told apart when
+// several agents call providers at the same time.
+type requestLog struct {
+	logger RequestLogger
+	id     int64
+	start  time.Time
+}
+
+func (p *baseProvider[C]) logRequest(messages []message.Message, tools []tools.BaseTool, stream bool) *requestLog {
+	if p.options.requestLogger == nil {
+		return nil
+	}
+	toolNames := make([]string, len(tools))
+	for i, tool := range tools {
+		toolNames[i] = tool.Info().Name
+	}
+	roles := make([]string, len(messages))
+	for i, msg := range messages {
+		roles[i] = string(msg.Role)
+	}
+	log := &requestLog{
+		logger: p.options.requestLogger,
+		id:     requestCounter.Add(1),
+		start:  time.Now(),
+	}
+	log.logger("Provider request",
+		"request", log.id,
+		"provider", p.options.model.Provider,
+		"model", p.options.model.APIModel,
+		"stream", stream,
+		"messages", len(messages),
+		"roles", roles,
+		"tools", toolNames,
+	)
+	return log
+}
+
+// response logs the result of a request sent without streaming.
+func (l *requestLog) response(resp *ProviderResponse, err error) {
+	if l == nil {
+		return
+	}
+	args := []any{"request", l.id, "elapsed", time.Since(l.start).Round(time.Millisecond)}
+	if err != nil {
+		l.logger("Provider response", append(args, "error", err)...)
+		return
+	}
+	l.logger("Provider response", append(args, responseArgs(resp)...)...)
+}
+
+// events passes on the events of a streamed response, logging each with the
+// time since the request was sent. Runs of deltas are logged once, with
+// their count, to keep the log readable.
+func (l *requestLog) events(events <-chan ProviderEvent) <-chan ProviderEvent {
+	if l == nil {
+		return events
+	}
+	logged := make(chan ProviderEvent)
+	go func() {
+		defer close(logged)
+		var deltaType EventType
+		deltas := 0
+		flush := func() {
+			if deltas > 0 {
+				l.logger("Provider event", "request", l.id, "type", deltaType, "count", deltas, "elapsed", time.Since(l.start).Round(time.Millisecond))
+				deltas = 0
+			}
+		}
+		for event := range events {
+			switch event.Type {
+			case EventContentDelta, EventThinkingDelta, EventToolUseDelta:
+				if event.Type != deltaType {
+					flush()
+					deltaType = event.Type
+				}
+				deltas++
+			default:
+				flush()
+				args := []any{"request", l.id, "type", event.Type, "elapsed", time.Since(l.start).Round(time.Millisecond)}
+				switch {
+				case event.ToolCall != nil:
+					args = append(args, "tool", event.ToolCall.Name, "tool_call_id", event.ToolCall.ID)
+				case event.Error != nil:
+					args = append(args, "error", event.Error)
+				case event.Response != nil:
+					args = append(args, responseArgs(event.Response)...)
+				}
+				l.logger("Provider event", args...)
+			}
+			logged <- event
+		}
+		flush()
+	}()
+	return logged
+}
+
+func responseArgs(resp *ProviderResponse) []any {
+	if resp == nil {
+		return nil
+	}
+	toolNames := make([]string, len(resp.ToolCalls))
+	for i, call := range resp.ToolCalls {
+		toolNames[i] = call.Name
+	}
+	return []any{
+		"finish_reason", resp.FinishReason,
+		"tool_calls", toolNames,
+		"input_tokens", resp.Usage.InputTokens,
+		"output_tokens", resp.Usage.OutputTokens,
+		"cache_read_tokens", resp.Usage.CacheReadTokens,
+	}
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"path"
+	"regexp"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/format"
 	"github.com/opencode-ai/opencode/internal/llm/tools/shell"
 	"github.com/opencode-ai/opencode/internal/permission"
 )
@@ -15,6 +19,7 @@ import (
 type BashParams struct {
 	Command string `json:"command"`
 	Timeout int    `json:"timeout"`
+	Restart bool   `json:"restart"`
 }
 
 type BashPermissionsParams struct {
@@ -25,6 +30,7 @@ type BashPermissionsParams struct {
 type BashResponseMetadata struct {
 	StartTime int64 `json:"start_time"`
 	EndTime   int64 `json:"end_time"`
+	ExitCode  int   `json:"exit_code"`
 }
 type bashTool struct {
 	permissions permission.Service
@@ -54,6 +60,86 @@ var safeReadOnlyCommands = []string{
 	"go version", "go help", "go list", "go env", "go doc", "go vet", "go fmt", "go mod", "go test", "go build", "go run", "go install", "go clean",
 }
 
+// destructivePatterns match commands that wipe a disk, the file system root
+// or the home directory, which are refused unless shell.allowDestructive is
+// set.
+var destructivePatterns = []struct {
+	pattern *regexp.Regexp
+	reason  string
+}{
+	{regexp.MustCompile(`\bmkfs(\.\w+)?\b`), "formats a file system"},
+	{regexp.MustCompile(`\bdd\b.*\bof=/dev/`), "writes directly to a device"},
+	{regexp.MustCompile(`>\s*/dev/(sd|nvme|hd|vd|disk)`), "writes directly to a device"},
+	{regexp.MustCompile(`:\(\)\s*\{\s*:\s*\|\s*:\s*&\s*\}\s*;\s*:`), "is a fork bomb"},
+	{regexp.MustCompile(`\bchmod\s+(-[a-zA-Z]*R[a-zA-Z]*\s+|--recursive\s+)+\S+\s+/(\s|;|&|\||$)`), "changes the permissions of the whole file system"},
+}
+
+// commandSeparators split a command line into the simple commands it runs.
+var commandSeparators = regexp.MustCompile(`&&|\|\||[;&|\n]`)
+
+// destructiveCommand reports why command is obviously destructive, or false
+// when it is not.
+func destructiveCommand(command string) (string, bool) {
+	for _, part := range commandSeparators.Split(command, -1) {
+		if removesRootOrHome(strings.Fields(part)) {
+			return "removes the root or home directory", true
+		}
+	}
+	for _, p := range destructivePatterns {
+		if p.pattern.MatchString(command) {
+			return p.reason, true
+		}
+	}
+	return "", false
+}
+
+// removesRootOrHome reports whether args, a simple command, is a forced or
+// recursive rm of the root or home directory or everything in them. The
+// targets are cleaned first, so spellings like "//", "/." or "~/" are caught
+// as well, and "--" ends the flags as it does for rm.
+func removesRootOrHome(args []string) bool {
+	if len(args) > 0 && args[0] == "sudo" {
+		args = args[1:]
+	}
+	if len(args) == 0 || path.Base(args[0]) != "rm" {
+		return false
+	}
+	forced := false
+	var targets []string
+	flags := true
+	for _, arg := range args[1:] {
+		switch {
+		case flags && arg == "--":
+			flags = false
+		case flags && strings.HasPrefix(arg, "--"):
+			forced = forced || arg == "--recursive" || arg == "--force" || arg == "--no-preserve-root"
+		case flags && strings.HasPrefix(arg, "-") && len(arg) > 1:
+			forced = forced || strings.ContainsAny(arg, "rRf")
+		default:
+			targets = append(targets, arg)
+		}
+	}
+	if !forced {
+		return false
+	}
+	for _, target := range targets {
+		target = strings.Trim(target, `"'`)
+		for _, home := range []string{"~", "${HOME}", "$HOME"} {
+			if target == home || strings.HasPrefix(target, home+"/") {
+				target = "/" + strings.TrimPrefix(target, home)
+				break
+			}
+		}
+		if !strings.HasPrefix(target, "/") {
+			continue
+		}
+		if cleaned := path.Clean(target); cleaned == "/" || cleaned == "/*" {
+			return true
+		}
+	}
+	return false
+}
+
 func bashDescription() string {
 	bannedCommandsStr := strings.Join(bannedCommands, ", ")
 	return fmt.Sprintf(`Executes a given bash command in a persistent shell session with optional timeout, ensuring proper handling and security measures.
@@ -67,13 +153,14 @@ Before executing the command, please follow these steps:
 2. Security Check:
  - For security and to limit the threat of a prompt injection attack, some commands are limited or banned. If you use a disallowed command, you will receive an error message explaining the restriction. Explain the error to the User.
  - Verify that the command is not one of the banned commands: %s.
+ - Obviously destructive commands, such as removing the root or home directory, formatting a file system or writing directly to a device, are refused.
 
 3. Command Execution:
  - After ensuring proper quoting, execute the command.
  - Capture the output of the command.
 
 4. Output Processing:
- - If the output exceeds %d characters, output will be truncated before being returned to you.
+ - If the output exceeds %d characters, output will be truncated before being returned to you. The truncated part can be read with the continue_tool tool.
  - Prepare the output for display to the user.
 
 5. Return Result:
@@ -86,6 +173,7 @@ Usage notes:
 - VERY IMPORTANT: You MUST avoid using search commands like 'find' and 'grep'. Instead use Grep, Glob, or Agent tools to search. You MUST avoid read tools like 'cat', 'head', 'tail', and 'ls', and use FileRead and LS tools to read files.
 - When issuing multiple commands, use the ';' or '&&' operator to separate them. DO NOT use newlines (newlines are ok in quoted strings).
 - IMPORTANT: All commands share the same shell session. Shell state (environment variables, virtual environments, current directory, etc.) persist between commands. For example, if you set an environment variable as part of a command, the environment variable will persist for subsequent commands.
+- If a command hangs or leaves the shell in a bad state, set restart to true to kill the shell and start a fresh one in the working directory. A command may be left empty to only restart the shell. A shell that does not recover from a timeout is restarted automatically, keeping its current directory.
 - Try to maintain your current working directory throughout the session by using absolute paths and avoiding usage of 'cd'. You may use 'cd' if the User explicitly requests it.
 <good-example>
 pytest /foo/bar/tests
@@ -222,6 +310,10 @@ func (b *bashTool) Info() ToolInfo {
 				"type":        "number",
 				"description": "Optional timeout in milliseconds (max 600000)",
 			},
+			"restart": map[string]any{
+				"type":        "boolean",
+				"description": "Kill the shell and start a fresh one in the working directory before running the command",
+			},
 		},
 		Required: []string{"command"},
 	}
@@ -239,7 +331,14 @@ func (b *bashTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 		params.Timeout = DefaultTimeout
 	}
 
-	if params.Command == "" {
+	sessionID, messageID := GetContextValues(ctx)
+	if params.Restart {
+		shell.ResetShell(sessionID)
+		if strings.TrimSpace(params.Command) == "" {
+			return NewTextResponse(fmt.Sprintf("Shell restarted in %s", config.WorkingDirectory())), nil
+		}
+	}
+	if strings.TrimSpace(params.Command) == "" {
 		return NewTextErrorResponse("missing command"), nil
 	}
 
@@ -250,6 +349,12 @@ func (b *bashTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 		}
 	}
 
+	if !config.Get().Shell.AllowDestructive {
+		if reason, ok := destructiveCommand(params.Command); ok {
+			return NewTextErrorResponse(fmt.Sprintf("command refused because it %s. Do not try to work around this; ask the user to run it themselves if it is really needed", reason)), nil
+		}
+	}
+
 	isSafeReadOnly := false
 	cmdLower := strings.ToLower(params.Command)
 
@@ -262,7 +367,6 @@ func (b *bashTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 		}
 	}
 
-	sessionID, messageID := GetContextValues(ctx)
 	if sessionID == "" || messageID == "" {
 		return ToolResponse{}, fmt.Errorf("session ID and message ID are required for creating a new file")
 	}
@@ -284,8 +388,13 @@ func (b *bashTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 		}
 	}
 	startTime := time.Now()
-	shell := shell.GetPersistentShell(config.WorkingDirectory())
-	stdout, stderr, exitCode, interrupted, err := shell.Exec(ctx, params.Command, params.Timeout)
+	shell := shell.GetPersistentShell(sessionID, config.WorkingDirectory())
+	if shell == nil {
+		return ToolResponse{}, fmt.Errorf("failed to start the shell")
+	}
+	stdout, stderr, exitCode, interrupted, err := shell.ExecWithOutput(ctx, params.Command, params.Timeout, func(stdout, stderr string) {
+		ReportProgress(ctx, call.ID, joinOutput(stdout, stderr))
+	})
 	if err != nil {
 		return ToolResponse{}, fmt.Errorf("error executing command: %w", err)
 	}
@@ -319,6 +428,7 @@ func (b *bashTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	metadata := BashResponseMetadata{
 		StartTime: startTime.UnixMilli(),
 		EndTime:   time.Now().UnixMilli(),
+		ExitCode:  exitCode,
 	}
 	if stdout == "" {
 		return WithResponseMetadata(NewTextResponse("no output"), metadata), nil
@@ -326,17 +436,45 @@ func (b *bashTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	return WithResponseMetadata(NewTextResponse(stdout), metadata), nil
 }
 
+// joinOutput combines the output of a running command for its progress
+// updates. The streams are kept in separate files, so stderr follows stdout
+// instead of being interleaved with it.
+func joinOutput(stdout, stderr string) string {
+	if stdout == "" || stderr == "" {
+		return stdout + stderr
+	}
+	if !strings.HasSuffix(stdout, "\n") {
+		stdout += "\n"
+	}
+	return stdout + stderr
+}
+
 func truncateOutput(content string) string {
 	if len(content) <= MaxOutputLength {
 		return content
 	}
 
 	halfLength := MaxOutputLength / 2
-	start := content[:halfLength]
-	end := content[len(content)-halfLength:]
+	startEnd := format.CutPoint(content, halfLength)
+	endStart := len(content) - halfLength
+	if newline := strings.IndexByte(content[endStart:], '\n'); newline >= 0 && newline < halfLength/2 {
+		endStart += newline + 1
+	}
+	for endStart < len(content) && !utf8.RuneStart(content[endStart]) {
+		endStart++
+	}
+
+	start := strings.TrimSuffix(format.CloseFence(content[:startEnd]), "\n")
+	end := content[endStart:]
+	// Reopen a code block that the kept end of the output is inside of.
+	if fence := format.OpenFence(content[:endStart]); fence != "" {
+		end = fence + "\n" + end
+	}
 
-	truncatedLinesCount := countLines(content[halfLength : len(content)-halfLength])
-	return fmt.Sprintf("%s\n\n... [%d lines truncated] ...\n\n%s", start, truncatedLinesCount, end)
+	middle := content[startEnd:endStart]
+	token := storeContinuation(BashToolName, middle)
+	return fmt.Sprintf("%s\n\n... [%d lines truncated, call %s with token %q to read them] ...\n\n%s",
+		start, countLines(middle), ContinueToolName, token, end)
 }
 
 func countLines(s string) int {
@@ -0,0 +1,47 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDestructiveCommand(t *testing.T) {
+	tests := []struct {
+		command     string
+		destructive bool
+	}{
+		{"rm -rf /", true},
+		{"rm -rf /*", true},
+		{"sudo rm -r -f ~", true},
+		{"rm -rf $HOME/ && echo done", true},
+		{"rm --recursive --force --no-preserve-root /", true},
+		{"rm -rf -- /", true},
+		{"rm -rf /.", true},
+		{"rm -rf //", true},
+		{"rm -rf /./*", true},
+		{"rm -fr ~/..", true},
+		{"ls && /bin/rm -r ${HOME}/", true},
+		{"mkfs.ext4 /dev/sda1", true},
+		{"dd if=/dev/zero of=/dev/sda bs=1M", true},
+		{"echo x > /dev/nvme0n1", true},
+		{":(){ :|:& };:", true},
+		{"chmod -R 777 /", true},
+		{"rm -rf ./build", false},
+		{"rm -rf /tmp/cache", false},
+		{"rm -rf ~/project/node_modules", false},
+		{"rm -rf -- ./-build", false},
+		{"rm /", false},
+		{"dd if=/dev/zero of=disk.img bs=1M count=10", false},
+		{"echo hi > /dev/null", false},
+		{"chmod -R 755 ./bin", false},
+		{"go test ./...", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			_, destructive := destructiveCommand(tt.command)
+			assert.Equal(t, tt.destructive, destructive)
+		})
+	}
+}
@@ -0,0 +1,351 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/format"
+	"github.com/opencode-ai/opencode/internal/permission"
+)
+
+type BuildCheckParams struct {
+	Packages string `json:"packages"`
+}
+
+type BuildError struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+type BuildCheckResponseMetadata struct {
+	Success bool         `json:"success"`
+	Cached  bool         `json:"cached"`
+	Errors  []BuildError `json:"errors"`
+}
+
+type BuildCheckPermissionsParams struct {
+	Packages []string `json:"packages"`
+}
+
+type buildCheckTool struct {
+	permissions permission.Service
+}
+
+const (
+	BuildCheckToolName    = "build_check"
+	defaultBuildPackages  = "./..."
+	buildCheckTimeout     = 2 * time.Minute
+	maxReportedBuildLines = 50
+	buildCheckDescription = `Compiles the Go project to verify that the code builds, returning compilation errors in a structured form.
+
+WHEN TO USE THIS TOOL:
+- Use after editing Go code to make sure the change did not break the build
+- Helpful for catching cross-file breakage (renamed functions, changed signatures) that per-file diagnostics miss
+- Use before telling the user a Go change is complete
+
+HOW TO USE:
+- Call without parameters to build every package (./...)
+- Optionally pass package patterns separated by spaces (e.g. "./internal/... ./cmd/...") to build a subset
+
+FEATURES:
+- Runs "go build" in the working directory and parses errors into file, line, column and message
+- Results are cached and reused until a Go source or module file changes
+
+LIMITATIONS:
+- Package patterns cannot carry flags for go build
+- Only works for Go modules (a go.mod file must exist in the working directory)
+- Only compiles; it does not run tests or vet checks
+- Builds are limited to 2 minutes
+
+TIPS:
+- Fix the first errors first; later errors are often caused by earlier ones
+- Use the View tool to read the reported lines before editing them`
+)
+
+var (
+	buildErrorPattern = regexp.MustCompile(`^(.+?\.go):(\d+)(?::(\d+))?: (.*)$`)
+	// buildPackagePattern matches import paths and relative package
+	// patterns, such as ./..., ./internal/... or example.com/mod/pkg.
+	buildPackagePattern = regexp.MustCompile(`^[A-Za-z0-9_.~/@+-]+$`)
+)
+
+// buildCheckResult is a cached build outcome, keyed by the fingerprint of the
+// Go sources it was computed from.
+type buildCheckResult struct {
+	fingerprint uint64
+	output      string
+	errors      []BuildError
+	success     bool
+}
+
+var (
+	buildCheckCache   = make(map[string]buildCheckResult)
+	buildCheckCacheMu sync.Mutex
+)
+
+func NewBuildCheckTool(permissions permission.Service) BaseTool {
+	return &buildCheckTool{permissions: permissions}
+}
+
+func (b *buildCheckTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        BuildCheckToolName,
+		Description: buildCheckDescription,
+		Parameters: map[string]any{
+			"packages": map[string]any{
+				"type":        "string",
+				"description": "The package pattern to build (defaults to ./...)",
+			},
+		},
+		Required: []string{},
+	}
+}
+
+func (b *buildCheckTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params BuildCheckParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+
+	workingDir := config.WorkingDirectory()
+	if _, err := os.Stat(filepath.Join(workingDir, "go.mod")); err != nil {
+		return NewTextErrorResponse("build_check only supports Go modules, no go.mod found in the working directory"), nil
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		return NewTextErrorResponse("the go toolchain was not found in $PATH"), nil
+	}
+
+	packages, err := parseBuildPackages(params.Packages)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	sessionID, messageID := GetContextValues(ctx)
+	if sessionID == "" || messageID == "" {
+		return ToolResponse{}, fmt.Errorf("session ID and message ID are required for running a build")
+	}
+	p := b.permissions.Request(
+		permission.CreatePermissionRequest{
+			SessionID:   sessionID,
+			Path:        workingDir,
+			ToolName:    BuildCheckToolName,
+			Action:      "execute",
+			Description: fmt.Sprintf("Build packages: %s", strings.Join(packages, " ")),
+			Params:      BuildCheckPermissionsParams{Packages: packages},
+		},
+	)
+	if !p {
+		return ToolResponse{}, permission.ErrorPermissionDenied
+	}
+
+	result, cached, err := runBuildCheck(ctx, workingDir, packages)
+	if err != nil {
+		return ToolResponse{}, err
+	}
+
+	metadata := BuildCheckResponseMetadata{
+		Success: result.success,
+		Cached:  cached,
+		Errors:  result.errors,
+	}
+	if result.success {
+		return WithResponseMetadata(NewTextResponse(fmt.Sprintf("Build succeeded: go build %s", strings.Join(packages, " "))), metadata), nil
+	}
+	return WithResponseMetadata(NewTextResponse(formatBuildFailure(packages, result)), metadata), nil
+}
+
+// parseBuildPackages splits the packages parameter into package patterns.
+// Anything that could be read as a flag of go build, such as -toolexec, is
+// refused.
+func parseBuildPackages(packages string) ([]string, error) {
+	patterns := strings.Fields(packages)
+	if len(patterns) == 0 {
+		return []string{defaultBuildPackages}, nil
+	}
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "-") || !buildPackagePattern.MatchString(pattern) {
+			return nil, fmt.Errorf("invalid package pattern %q, pass package paths or patterns like ./... only", pattern)
+		}
+	}
+	return patterns, nil
+}
+
+// runBuildCheck builds packages in workingDir, reusing the previous result
+// when no Go source or module file has changed since.
+func runBuildCheck(ctx context.Context, workingDir string, packages []string) (buildCheckResult, bool, error) {
+	cacheKey := workingDir + "\x00" + strings.Join(packages, " ")
+	fingerprint, err := goSourcesFingerprint(workingDir)
+	if err != nil {
+		return buildCheckResult{}, false, fmt.Errorf("error scanning Go sources: %w", err)
+	}
+
+	// The lock only guards the cache; builds of different tool calls may
+	// run at the same time, go build serializes what it has to itself.
+	buildCheckCacheMu.Lock()
+	result, ok := buildCheckCache[cacheKey]
+	buildCheckCacheMu.Unlock()
+	if ok && result.fingerprint == fingerprint {
+		return result, true, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, buildCheckTimeout)
+	defer cancel()
+
+	// "--" ends the flags, so no pattern is taken for one.
+	args := append([]string{"build", "-o", os.DevNull, "--"}, packages...)
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = workingDir
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return buildCheckResult{}, false, fmt.Errorf("build check did not finish: %w", ctx.Err())
+	}
+
+	result = buildCheckResult{
+		fingerprint: fingerprint,
+		output:      strings.TrimSpace(string(output)),
+		success:     err == nil,
+	}
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			return buildCheckResult{}, false, fmt.Errorf("error running go build: %w", err)
+		}
+		result.errors = parseBuildErrors(result.output, workingDir)
+	}
+	buildCheckCacheMu.Lock()
+	buildCheckCache[cacheKey] = result
+	buildCheckCacheMu.Unlock()
+	return result, false, nil
+}
+
+func parseBuildErrors(output, workingDir string) []BuildError {
+	errors := make([]BuildError, 0)
+	for line := range strings.SplitSeq(output, "\n") {
+		matches := buildErrorPattern.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		file := matches[1]
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(workingDir, file)
+		}
+		lineNumber, _ := strconv.Atoi(matches[2])
+		column, _ := strconv.Atoi(matches[3])
+		errors = append(errors, BuildError{
+			File:    file,
+			Line:    lineNumber,
+			Column:  column,
+			Message: matches[4],
+		})
+	}
+	return errors
+}
+
+func formatBuildFailure(packages []string, result buildCheckResult) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Build failed: go build %s\n", strings.Join(packages, " "))
+	if len(result.errors) == 0 {
+		// Not a compile error, e.g. a missing module; show the raw output.
+		sb.WriteString(truncateLines(BuildCheckToolName, result.output, maxReportedBuildLines))
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "%d errors:\n", len(result.errors))
+	for i, buildErr := range result.errors {
+		if i == maxReportedBuildLines {
+			fmt.Fprintf(&sb, "... and %d more\n", len(result.errors)-i)
+			break
+		}
+		if buildErr.Column > 0 {
+			fmt.Fprintf(&sb, "%s:%d:%d: %s\n", buildErr.File, buildErr.Line, buildErr.Column, buildErr.Message)
+		} else {
+			fmt.Fprintf(&sb, "%s:%d: %s\n", buildErr.File, buildErr.Line, buildErr.Message)
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func truncateLines(tool, text string, limit int) string {
+	lines := strings.Split(text, "\n")
+	if len(lines) <= limit {
+		return text
+	}
+	token := storeContinuation(tool, strings.Join(lines[limit:], "\n"))
+	return format.CloseFence(strings.Join(lines[:limit], "\n")) + fmt.Sprintf("\n... (%d more lines, call %s with token %q to read them)", len(lines)-limit, ContinueToolName, token)
+}
+
+// goSourcesFingerprint summarizes the paths, sizes and modification times of
+// every Go source and module file under root.
+func goSourcesFingerprint(root string) (uint64, error) {
+	hash := fnv.New64a()
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") && name != "go.mod" && name != "go.sum" && name != "go.work" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		fmt.Fprintf(hash, "%s\x00%d\x00%d\n", path, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	return hash.Sum64(), err
+}
+
+// autoBuildCheck runs the build check after an edit when autoBuildCheck is
+// enabled and the working directory is a Go module. Only failures are
+// reported so successful edits stay quiet.
+func autoBuildCheck(ctx context.Context, filePaths ...string) string {
+	if !config.Get().AutoBuildCheck {
+		return ""
+	}
+	touchesGo := false
+	for _, filePath := range filePaths {
+		base := filepath.Base(filePath)
+		if strings.HasSuffix(base, ".go") || base == "go.mod" || base == "go.sum" {
+			touchesGo = true
+			break
+		}
+	}
+	if !touchesGo {
+		return ""
+	}
+
+	workingDir := config.WorkingDirectory()
+	if _, err := os.Stat(filepath.Join(workingDir, "go.mod")); err != nil {
+		return ""
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		return ""
+	}
+	packages := []string{defaultBuildPackages}
+	result, _, err := runBuildCheck(ctx, workingDir, packages)
+	if err != nil || result.success {
+		return ""
+	}
+	return fmt.Sprintf("\n<build_check>\n%s\n</build_check>\n", formatBuildFailure(packages, result))
+}
@@ -0,0 +1,387 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/language"
+)
+
+type ComplexityParams struct {
+	FilePath string `json:"file_path"`
+	Limit    int    `json:"limit"`
+}
+
+type ComplexityResponseMetadata struct {
+	Functions     int  `json:"functions"`
+	MaxComplexity int  `json:"max_complexity"`
+	Heuristic     bool `json:"heuristic"`
+}
+
+type complexityTool struct{}
+
+const (
+	ComplexityToolName     = "complexity"
+	defaultComplexityLimit = 20
+	maxComplexityFileSize  = 1024 * 1024
+	complexityDescription  = `Measures the complexity of the functions in a file and returns them ranked from most to least complex.
+
+WHEN TO USE THIS TOOL:
+- Use to find refactoring targets in a file
+- Use before changing a function to check whether it is already complex, so the change does not make it worse
+- Use after a change to compare a function with its previous complexity
+
+HOW TO USE:
+- Provide the path of the file to measure
+- Optionally limit the number of functions returned (default 20)
+
+FEATURES:
+- Reports cyclomatic complexity, number of lines and maximum nesting depth for each function
+- Exact for Go files, which are parsed
+- Estimated from keywords, braces and indentation for Python, JavaScript, TypeScript, Java, C, C++, C#, Rust and similar languages
+
+LIMITATIONS:
+- Only measures one file at a time
+- Estimates for languages other than Go can be off when keywords or braces appear in strings or block comments
+- Nested functions outside Go are reported on their own and also count toward the function containing them
+
+TIPS:
+- Cyclomatic complexity above 10 or nesting deeper than 4 usually marks a function worth simplifying
+- Prefer extracting helpers over adding branches to a function near the top of the list`
+)
+
+// functionComplexity holds the metrics of one function. Lines are 1-based.
+type functionComplexity struct {
+	name       string
+	startLine  int
+	endLine    int
+	cyclomatic int
+	depth      int
+}
+
+var (
+	decisionKeywordRe = regexp.MustCompile(`\b(if|elif|for|foreach|while|case|catch|except|when)\b|&&|\|\||\?\?`)
+	pythonDecisionRe  = regexp.MustCompile(`\b(if|elif|for|while|except|case|and|or)\b`)
+	functionNameRe    = regexp.MustCompile(`([A-Za-z_$][\w$]*)\s*(?:=\s*(?:async\s*)?(?:function\s*)?)?(?:<[^<>]*>)?\s*\(`)
+	pythonDefNameRe   = regexp.MustCompile(`^\s*(?:async\s+)?def\s+(\w+)`)
+)
+
+func NewComplexityTool() BaseTool {
+	return &complexityTool{}
+}
+
+func (c *complexityTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ComplexityToolName,
+		Description: complexityDescription,
+		Parameters: map[string]any{
+			"file_path": map[string]any{
+				"type":        "string",
+				"description": "The path to the file to measure",
+			},
+			"limit": map[string]any{
+				"type":        "integer",
+				"description": "The maximum number of functions to return (defaults to 20)",
+			},
+		},
+		Required: []string{"file_path"},
+	}
+}
+
+func (c *complexityTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params ComplexityParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+	if params.FilePath == "" {
+		return NewTextErrorResponse("file_path is required"), nil
+	}
+	if params.Limit <= 0 {
+		params.Limit = defaultComplexityLimit
+	}
+
+	filePath := params.FilePath
+	if !filepath.IsAbs(filePath) {
+		filePath = filepath.Join(config.WorkingDirectory(), filePath)
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewTextErrorResponse(fmt.Sprintf("File not found: %s", filePath)), nil
+		}
+		return ToolResponse{}, fmt.Errorf("error accessing file: %w", err)
+	}
+	if info.IsDir() {
+		return NewTextErrorResponse(fmt.Sprintf("Path is a directory, not a file: %s", filePath)), nil
+	}
+	if info.Size() > maxComplexityFileSize {
+		return NewTextErrorResponse(fmt.Sprintf("File is too large (%d bytes). Maximum size is %d bytes",
+			info.Size(), maxComplexityFileSize)), nil
+	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return ToolResponse{}, fmt.Errorf("error reading file: %w", err)
+	}
+
+	var functions []functionComplexity
+	heuristic := true
+	switch lang := language.DetectContent(filePath, content); {
+	case lang == language.Go:
+		functions, err = goComplexity(content)
+		if err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("Failed to parse Go file: %s", err)), nil
+		}
+		heuristic = false
+	case lang == language.Python:
+		functions = pythonComplexity(strings.Split(string(content), "\n"))
+	case lang.BraceSyntax():
+		functions = braceComplexity(strings.Split(string(content), "\n"))
+	default:
+		return NewTextErrorResponse(fmt.Sprintf("Unsupported file type: %s. Supported are Go, Python and brace languages such as JavaScript, TypeScript, Java, C and Rust", filepath.Base(filePath))), nil
+	}
+
+	metadata := ComplexityResponseMetadata{Functions: len(functions), Heuristic: heuristic}
+	if len(functions) == 0 {
+		return WithResponseMetadata(NewTextResponse(fmt.Sprintf("No functions found in %s", filePath)), metadata), nil
+	}
+	sort.SliceStable(functions, func(i, j int) bool {
+		a, b := functions[i], functions[j]
+		if a.cyclomatic != b.cyclomatic {
+			return a.cyclomatic > b.cyclomatic
+		}
+		if a.depth != b.depth {
+			return a.depth > b.depth
+		}
+		return a.endLine-a.startLine > b.endLine-b.startLine
+	})
+	metadata.MaxComplexity = functions[0].cyclomatic
+
+	var sb strings.Builder
+	method := "parsed"
+	if heuristic {
+		method = "estimated"
+	}
+	fmt.Fprintf(&sb, "Functions in %s ranked by cyclomatic complexity (%s):\n", filePath, method)
+	fmt.Fprintf(&sb, "%-10s  %-5s  %-5s  %s\n", "complexity", "lines", "depth", "function")
+	for _, fn := range functions[:min(params.Limit, len(functions))] {
+		fmt.Fprintf(&sb, "%-10d  %-5d  %-5d  %s (lines %d-%d)\n",
+			fn.cyclomatic, fn.endLine-fn.startLine+1, fn.depth, fn.name, fn.startLine, fn.endLine)
+	}
+	if len(functions) > params.Limit {
+		fmt.Fprintf(&sb, "(%d more functions not shown)\n", len(functions)-params.Limit)
+	}
+	return WithResponseMetadata(NewTextResponse(strings.TrimSuffix(sb.String(), "\n")), metadata), nil
+}
+
+// goComplexity measures every function and method declared in a Go file.
+func goComplexity(content []byte) ([]functionComplexity, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", content, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	var functions []functionComplexity
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		cyclomatic, depth := goFuncMetrics(fn.Body)
+		functions = append(functions, functionComplexity{
+			name:       goFuncName(fn),
+			startLine:  fset.Position(fn.Pos()).Line,
+			endLine:    fset.Position(fn.End()).Line,
+			cyclomatic: cyclomatic,
+			depth:      depth,
+		})
+	}
+	return functions, nil
+}
+
+// goFuncMetrics returns the cyclomatic complexity and the maximum nesting
+// depth of a function body. Function literals count toward the function
+// declaring them, and else-if chains do not nest.
+func goFuncMetrics(body *ast.BlockStmt) (int, int) {
+	cyclomatic := 1
+	depth, maxDepth := 0, 0
+	elseIfs := make(map[ast.Node]bool)
+	var nesting []bool
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil {
+			if nesting[len(nesting)-1] {
+				depth--
+			}
+			nesting = nesting[:len(nesting)-1]
+			return true
+		}
+		nests := false
+		switch n := n.(type) {
+		case *ast.IfStmt:
+			cyclomatic++
+			if n.Else != nil {
+				if elseIf, ok := n.Else.(*ast.IfStmt); ok {
+					elseIfs[elseIf] = true
+				}
+			}
+			nests = !elseIfs[n]
+		case *ast.ForStmt, *ast.RangeStmt:
+			cyclomatic++
+			nests = true
+		case *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt, *ast.FuncLit:
+			nests = true
+		case *ast.CaseClause:
+			if n.List != nil {
+				cyclomatic++
+			}
+		case *ast.CommClause:
+			if n.Comm != nil {
+				cyclomatic++
+			}
+		case *ast.BinaryExpr:
+			if n.Op == token.LAND || n.Op == token.LOR {
+				cyclomatic++
+			}
+		}
+		if nests {
+			depth++
+			maxDepth = max(maxDepth, depth)
+		}
+		nesting = append(nesting, nests)
+		return true
+	})
+	return cyclomatic, maxDepth
+}
+
+func goFuncName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+	recv := fn.Recv.List[0].Type
+	pointer := ""
+	if star, ok := recv.(*ast.StarExpr); ok {
+		pointer = "*"
+		recv = star.X
+	}
+	// Drop the type parameters of generic receivers.
+	switch r := recv.(type) {
+	case *ast.IndexExpr:
+		recv = r.X
+	case *ast.IndexListExpr:
+		recv = r.X
+	}
+	name := "?"
+	if ident, ok := recv.(*ast.Ident); ok {
+		name = ident.Name
+	}
+	if pointer != "" {
+		return fmt.Sprintf("(*%s).%s", name, fn.Name.Name)
+	}
+	return name + "." + fn.Name.Name
+}
+
+// braceComplexity estimates the metrics of the functions in a brace language
+// from their decision keywords and brace nesting.
+func braceComplexity(lines []string) []functionComplexity {
+	var functions []functionComplexity
+	for i, line := range lines {
+		if !functionHeaderRe.MatchString(line) || controlFlowRe.MatchString(line) {
+			continue
+		}
+		end, ok := blockEnd(lines, i, len(lines))
+		if !ok {
+			continue
+		}
+		fn := functionComplexity{name: braceFunctionName(line), startLine: i + 1, endLine: end, cyclomatic: 1}
+		depth := 0
+		for j := i; j < end; j++ {
+			fn.cyclomatic += len(decisionKeywordRe.FindAllString(codeOnly(lines[j], "//"), -1))
+			for _, delta := range braceDeltas(lines[j]) {
+				depth += delta
+				// The function's own braces do not count as nesting.
+				fn.depth = max(fn.depth, depth-1)
+			}
+		}
+		functions = append(functions, fn)
+	}
+	return functions
+}
+
+// pythonComplexity estimates the metrics of the functions in a Python file
+// from their decision keywords and indentation.
+func pythonComplexity(lines []string) []functionComplexity {
+	var functions []functionComplexity
+	for i, line := range lines {
+		m := pythonDefNameRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		end := pythonBlockEnd(lines, i, indentation(line))
+		fn := functionComplexity{name: m[1], startLine: i + 1, endLine: end, cyclomatic: 1}
+		var indents []int
+		for j := i + 1; j < end; j++ {
+			if strings.TrimSpace(lines[j]) == "" {
+				continue
+			}
+			fn.cyclomatic += len(pythonDecisionRe.FindAllString(codeOnly(lines[j], "#"), -1))
+			indent := indentation(lines[j])
+			for len(indents) > 0 && indent <= indents[len(indents)-1] {
+				indents = indents[:len(indents)-1]
+			}
+			indents = append(indents, indent)
+			// Lines directly in the body are at depth zero.
+			fn.depth = max(fn.depth, len(indents)-1)
+		}
+		functions = append(functions, fn)
+	}
+	return functions
+}
+
+// braceFunctionName returns the name of the function declared on a header
+// line, or "anonymous" when it has none.
+func braceFunctionName(line string) string {
+	for _, m := range functionNameRe.FindAllStringSubmatch(line, -1) {
+		switch m[1] {
+		case "function", "async", "new", "return":
+			continue
+		}
+		return m[1]
+	}
+	return "anonymous"
+}
+
+// codeOnly returns line without its string literals and without the comment
+// starting with comment.
+func codeOnly(line, comment string) string {
+	var sb strings.Builder
+	var quote rune
+	escaped := false
+	for i, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if r == '\\' {
+				escaped = true
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'' || r == '`':
+			quote = r
+		case strings.HasPrefix(line[i:], comment):
+			return sb.String()
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,175 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/opencode/internal/format"
+)
+
+type ContinueParams struct {
+	Token string `json:"token"`
+}
+
+type ContinueResponseMetadata struct {
+	Tool      string `json:"tool"`
+	Offset    int    `json:"offset"`
+	Remaining int    `json:"remaining"`
+}
+
+type continueTool struct{}
+
+const (
+	ContinueToolName      = "continue_tool"
+	maxContinuations      = 20
+	continuationChunkSize = 30000
+	continueDescription   = `Returns the next part of a tool result that was truncated.
+
+WHEN TO USE THIS TOOL:
+- Use when a tool result ends with a note that more output is available and gives a continuation token
+- Helpful to read the middle of long command output, the rest of a fetched page or a long report
+
+HOW TO USE:
+- Provide the token from the truncation note
+- If the returned part is truncated too, call the tool again with the new token it gives
+
+FEATURES:
+- Works for every tool that reports a continuation token
+- Tokens are stable: calling the tool twice with the same token returns the same part
+
+LIMITATIONS:
+- Only the most recent truncated results are kept, older tokens expire
+- Results are kept in memory and do not survive a restart
+- The content is what the tool produced at the time; it is not fetched or run again
+
+TIPS:
+- Prefer narrowing the original call (a more specific command, path or pattern) when you only need a small part of the result`
+)
+
+// continuation is the part of a tool result that was left out when it was
+// truncated.
+type continuation struct {
+	tool    string
+	content string
+}
+
+var (
+	continuations      = make(map[string]continuation)
+	continuationOrder  []string
+	continuationsMutex sync.Mutex
+)
+
+// storeContinuation keeps content so it can be read later with the continue
+// tool and returns the token for its beginning.
+func storeContinuation(tool, content string) string {
+	continuationsMutex.Lock()
+	defer continuationsMutex.Unlock()
+
+	id := uuid.New().String()[:8]
+	continuations[id] = continuation{tool: tool, content: content}
+	continuationOrder = append(continuationOrder, id)
+	if len(continuationOrder) > maxContinuations {
+		delete(continuations, continuationOrder[0])
+		continuationOrder = continuationOrder[1:]
+	}
+	return continuationToken(id, 0)
+}
+
+func continuationToken(id string, offset int) string {
+	return fmt.Sprintf("%s:%d", id, offset)
+}
+
+func getContinuation(token string) (continuation, string, int, bool) {
+	id, offsetText, found := strings.Cut(token, ":")
+	offset, err := strconv.Atoi(offsetText)
+	if !found || err != nil || offset < 0 {
+		return continuation{}, "", 0, false
+	}
+
+	continuationsMutex.Lock()
+	defer continuationsMutex.Unlock()
+	c, ok := continuations[id]
+	if !ok || offset > len(c.content) {
+		return continuation{}, "", 0, false
+	}
+	return c, id, offset, true
+}
+
+// continuationNote tells the model how to read the rest of a truncated
+// result.
+func continuationNote(remaining int, token string) string {
+	return fmt.Sprintf("[%d more characters not shown. Call %s with token %q to read them.]", remaining, ContinueToolName, token)
+}
+
+// truncateWithContinuation returns content unchanged if it fits in limit.
+// Otherwise it returns the beginning of content and keeps the rest for the
+// continue tool.
+func truncateWithContinuation(tool, content string, limit int) string {
+	if len(content) <= limit {
+		return content
+	}
+	end := chunkEnd(content, 0, limit)
+	token := storeContinuation(tool, content[end:])
+	return strings.TrimSuffix(format.CloseFence(content[:end]), "\n") + "\n\n" + continuationNote(len(content)-end, token)
+}
+
+// chunkEnd returns where a chunk of at most size bytes starting at offset
+// ends, preferring the end of a line and never splitting a UTF-8 character.
+func chunkEnd(content string, offset, size int) int {
+	return offset + format.CutPoint(content[offset:], size)
+}
+
+func NewContinueTool() BaseTool {
+	return &continueTool{}
+}
+
+func (c *continueTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ContinueToolName,
+		Description: continueDescription,
+		Parameters: map[string]any{
+			"token": map[string]any{
+				"type":        "string",
+				"description": "The continuation token from the truncated tool result",
+			},
+		},
+		Required: []string{"token"},
+	}
+}
+
+func (c *continueTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params ContinueParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+	if params.Token == "" {
+		return NewTextErrorResponse("token is required"), nil
+	}
+
+	cont, id, offset, ok := getContinuation(strings.TrimSpace(params.Token))
+	if !ok {
+		return NewTextErrorResponse("unknown or expired continuation token. Run the original tool again, with a narrower request if possible"), nil
+	}
+
+	end := chunkEnd(cont.content, offset, continuationChunkSize)
+	output := cont.content[offset:end]
+	if end < len(cont.content) {
+		output += "\n\n" + continuationNote(len(cont.content)-end, continuationToken(id, end))
+	} else {
+		output += "\n\n[End of the truncated output.]"
+	}
+
+	return WithResponseMetadata(
+		NewTextResponse(output),
+		ContinueResponseMetadata{
+			Tool:      cont.tool,
+			Offset:    offset,
+			Remaining: len(cont.content) - end,
+		},
+	), nil
+}
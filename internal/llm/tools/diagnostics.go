@@ -5,10 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"maps"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/language"
 	"github.com/opencode-ai/opencode/internal/lsp"
 	"github.com/opencode-ai/opencode/internal/lsp/protocol"
 )
@@ -21,7 +25,11 @@ type diagnosticsTool struct {
 }
 
 const (
-	DiagnosticsToolName    = "diagnostics"
+	DiagnosticsToolName = "diagnostics"
+	// maxDiagnosticsPerFile and maxDiagnosticFiles bound the output of the
+	// diagnostics tool for projects with many problems.
+	maxDiagnosticsPerFile  = 20
+	maxDiagnosticFiles     = 30
 	diagnosticsDescription = `Get diagnostics for a file and/or project.
 WHEN TO USE THIS TOOL:
 - Use when you need to check for errors or warnings in your code
@@ -33,7 +41,7 @@ HOW TO USE:
 - Results are displayed in a structured format with severity levels
 FEATURES:
 - Displays errors, warnings, and hints
-- Groups diagnostics by severity
+- Groups diagnostics by file, errors first, with their line, column, source and message
 - Provides detailed information about each diagnostic
 LIMITATIONS:
 - Results are limited to the diagnostics provided by the LSP clients
@@ -58,7 +66,7 @@ func (b *diagnosticsTool) Info() ToolInfo {
 		Parameters: map[string]any{
 			"file_path": map[string]any{
 				"type":        "string",
-				"description": "The path to the file to get diagnostics for (leave w empty for project diagnostics)",
+				"description": "The path to the file to get diagnostics for (leave empty for project diagnostics)",
 			},
 		},
 		Required: []string{},
@@ -78,17 +86,133 @@ func (b *diagnosticsTool) Run(ctx context.Context, call ToolCall) (ToolResponse,
 	}
 
 	if params.FilePath != "" {
+		if !filepath.IsAbs(params.FilePath) {
+			params.FilePath = filepath.Join(config.WorkingDirectory(), params.FilePath)
+		}
 		notifyLspOpenFile(ctx, params.FilePath, lsps)
 		waitForLspDiagnostics(ctx, params.FilePath, lsps)
 	}
 
-	output := getDiagnostics(params.FilePath, lsps)
+	output := groupedDiagnostics(params.FilePath, lsps)
 
 	return NewTextResponse(output), nil
 }
 
+// fileDiagnostic is a diagnostic together with the LSP client reporting it.
+type fileDiagnostic struct {
+	diagnostic protocol.Diagnostic
+	source     string
+}
+
+// groupedDiagnostics lists the diagnostics of filePath, or of every file when
+// it is empty, grouped by file. Files with errors come first.
+func groupedDiagnostics(filePath string, lsps map[string]*lsp.Client) string {
+	byFile := make(map[string][]fileDiagnostic)
+	for lspName, client := range lsps {
+		for uri, diags := range client.GetDiagnostics() {
+			path := uri.Path()
+			if filePath != "" && path != filePath {
+				continue
+			}
+			for _, diag := range diags {
+				byFile[path] = append(byFile[path], fileDiagnostic{diagnostic: diag, source: lspName})
+			}
+		}
+	}
+	if len(byFile) == 0 {
+		if filePath != "" {
+			return fmt.Sprintf("No diagnostics for %s", filePath)
+		}
+		return "No diagnostics in the project"
+	}
+
+	errorCount := func(diags []fileDiagnostic) int {
+		count := 0
+		for _, d := range diags {
+			if d.diagnostic.Severity == protocol.SeverityError {
+				count++
+			}
+		}
+		return count
+	}
+	files := slices.Collect(maps.Keys(byFile))
+	sort.Slice(files, func(i, j int) bool {
+		ei, ej := errorCount(byFile[files[i]]), errorCount(byFile[files[j]])
+		if (ei > 0) != (ej > 0) {
+			return ei > 0
+		}
+		return files[i] < files[j]
+	})
+
+	var b strings.Builder
+	totalErrors, totalWarnings := 0, 0
+	for i, file := range files {
+		diags := byFile[file]
+		sort.Slice(diags, func(i, j int) bool {
+			si, sj := diags[i].diagnostic.Severity, diags[j].diagnostic.Severity
+			if si != sj {
+				return si < sj
+			}
+			return diags[i].diagnostic.Range.Start.Line < diags[j].diagnostic.Range.Start.Line
+		})
+		errors := errorCount(diags)
+		warnings := 0
+		for _, d := range diags {
+			if d.diagnostic.Severity == protocol.SeverityWarning {
+				warnings++
+			}
+		}
+		totalErrors += errors
+		totalWarnings += warnings
+		if i >= maxDiagnosticFiles {
+			continue
+		}
+
+		fmt.Fprintf(&b, "%s (%d errors, %d warnings)\n", file, errors, warnings)
+		for j, d := range diags {
+			if j == maxDiagnosticsPerFile {
+				fmt.Fprintf(&b, "  ... and %d more diagnostics\n", len(diags)-j)
+				break
+			}
+			fmt.Fprintf(&b, "  %s: %d:%d %s\n",
+				diagnosticSeverity(d.diagnostic),
+				d.diagnostic.Range.Start.Line+1,
+				d.diagnostic.Range.Start.Character+1,
+				diagnosticDetails(d.diagnostic, d.source),
+			)
+		}
+		b.WriteString("\n")
+	}
+	if len(files) > maxDiagnosticFiles {
+		fmt.Fprintf(&b, "... and %d more files with diagnostics\n\n", len(files)-maxDiagnosticFiles)
+	}
+	fmt.Fprintf(&b, "Total: %d errors, %d warnings in %d files", totalErrors, totalWarnings, len(files))
+	return b.String()
+}
+
+// lspClientsForFile returns the language servers configured under the
+// language of filePath, or under the language whose server also handles it.
+// When there is none all servers are returned, since their names do not have
+// to be languages.
+func lspClientsForFile(filePath string, lsps map[string]*lsp.Client) map[string]*lsp.Client {
+	lang := language.DetectFile(filePath)
+	if lang == language.Unknown {
+		return lsps
+	}
+	matched := make(map[string]*lsp.Client)
+	for name, client := range lsps {
+		if id := language.ID(strings.ToLower(name)); id == lang || id == lang.Family() {
+			matched[name] = client
+		}
+	}
+	if len(matched) == 0 {
+		return lsps
+	}
+	return matched
+}
+
 func notifyLspOpenFile(ctx context.Context, filePath string, lsps map[string]*lsp.Client) {
-	for _, client := range lsps {
+	for _, client := range lspClientsForFile(filePath, lsps) {
 		err := client.OpenFile(ctx, filePath)
 		if err != nil {
 			continue
@@ -97,6 +221,7 @@ func notifyLspOpenFile(ctx context.Context, filePath string, lsps map[string]*ls
 }
 
 func waitForLspDiagnostics(ctx context.Context, filePath string, lsps map[string]*lsp.Client) {
+	lsps = lspClientsForFile(filePath, lsps)
 	if len(lsps) == 0 {
 		return
 	}
@@ -154,59 +279,59 @@ func hasDiagnosticsChanged(current, original map[protocol.DocumentUri][]protocol
 	return false
 }
 
-func getDiagnostics(filePath string, lsps map[string]*lsp.Client) string {
-	fileDiagnostics := []string{}
-	projectDiagnostics := []string{}
+// diagnosticSeverity names the severity of a diagnostic as shown to the model.
+func diagnosticSeverity(diagnostic protocol.Diagnostic) string {
+	switch diagnostic.Severity {
+	case protocol.SeverityError:
+		return "Error"
+	case protocol.SeverityWarning:
+		return "Warn"
+	case protocol.SeverityHint:
+		return "Hint"
+	}
+	return "Info"
+}
 
-	formatDiagnostic := func(pth string, diagnostic protocol.Diagnostic, source string) string {
-		severity := "Info"
-		switch diagnostic.Severity {
-		case protocol.SeverityError:
-			severity = "Error"
-		case protocol.SeverityWarning:
-			severity = "Warn"
-		case protocol.SeverityHint:
-			severity = "Hint"
-		}
+// diagnosticDetails formats the source, code, tags and message of a
+// diagnostic, falling back to the name of the LSP client for the source.
+func diagnosticDetails(diagnostic protocol.Diagnostic, source string) string {
+	sourceInfo := source
+	if diagnostic.Source != "" {
+		sourceInfo = diagnostic.Source
+	}
 
-		location := fmt.Sprintf("%s:%d:%d", pth, diagnostic.Range.Start.Line+1, diagnostic.Range.Start.Character+1)
+	codeInfo := ""
+	if diagnostic.Code != nil {
+		codeInfo = fmt.Sprintf("[%v]", diagnostic.Code)
+	}
 
-		sourceInfo := ""
-		if diagnostic.Source != "" {
-			sourceInfo = diagnostic.Source
-		} else if source != "" {
-			sourceInfo = source
+	tagsInfo := ""
+	if len(diagnostic.Tags) > 0 {
+		tags := []string{}
+		for _, tag := range diagnostic.Tags {
+			switch tag {
+			case protocol.Unnecessary:
+				tags = append(tags, "unnecessary")
+			case protocol.Deprecated:
+				tags = append(tags, "deprecated")
+			}
 		}
-
-		codeInfo := ""
-		if diagnostic.Code != nil {
-			codeInfo = fmt.Sprintf("[%v]", diagnostic.Code)
+		if len(tags) > 0 {
+			tagsInfo = fmt.Sprintf(" (%s)", strings.Join(tags, ", "))
 		}
+	}
 
-		tagsInfo := ""
-		if len(diagnostic.Tags) > 0 {
-			tags := []string{}
-			for _, tag := range diagnostic.Tags {
-				switch tag {
-				case protocol.Unnecessary:
-					tags = append(tags, "unnecessary")
-				case protocol.Deprecated:
-					tags = append(tags, "deprecated")
-				}
-			}
-			if len(tags) > 0 {
-				tagsInfo = fmt.Sprintf(" (%s)", strings.Join(tags, ", "))
-			}
-		}
+	return fmt.Sprintf("[%s]%s%s %s", sourceInfo, codeInfo, tagsInfo, diagnostic.Message)
+}
 
-		return fmt.Sprintf("%s: %s [%s]%s%s %s",
-			severity,
-			location,
-			sourceInfo,
-			codeInfo,
-			tagsInfo,
-			diagnostic.Message)
-	}
+func formatDiagnostic(pth string, diagnostic protocol.Diagnostic, source string) string {
+	location := fmt.Sprintf("%s:%d:%d", pth, diagnostic.Range.Start.Line+1, diagnostic.Range.Start.Character+1)
+	return fmt.Sprintf("%s: %s %s", diagnosticSeverity(diagnostic), location, diagnosticDetails(diagnostic, source))
+}
+
+func getDiagnostics(filePath string, lsps map[string]*lsp.Client) string {
+	fileDiagnostics := []string{}
+	projectDiagnostics := []string{}
 
 	for lspName, client := range lsps {
 		diagnostics := client.GetDiagnostics()
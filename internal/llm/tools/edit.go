@@ -88,7 +88,7 @@ When making edits:
    - Do not leave the code in a broken state
    - Always use absolute file paths (starting with /)
 
-Remember: when making multiple file edits in a row to the same file, you should prefer to send all edits in a single message with multiple calls to this tool, rather than multiple messages with a single call each.`
+Remember: when making multiple edits to the same file, prefer the MultiEdit tool, which applies them all in one call and only writes the file if every edit succeeds.`
 )
 
 func NewEditTool(lspClients map[string]*lsp.Client, permissions permission.Service, files history.Service) BaseTool {
@@ -136,6 +136,10 @@ func (e *editTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 		params.FilePath = filepath.Join(wd, params.FilePath)
 	}
 
+	if err := checkWritable(params.FilePath); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
 	var response ToolResponse
 	var err error
 
@@ -166,6 +170,7 @@ func (e *editTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	waitForLspDiagnostics(ctx, params.FilePath, e.lspClients)
 	text := fmt.Sprintf("<result>\n%s\n</result>\n", response.Content)
 	text += getDiagnostics(params.FilePath, e.lspClients)
+	text += autoBuildCheck(ctx, params.FilePath)
 	response.Content = text
 	return response, nil
 }
@@ -212,13 +217,14 @@ func (e *editTool) createNewFile(ctx context.Context, filePath, content string)
 				FilePath: filePath,
 				Diff:     diff,
 			},
+			LinesChanged: additions + removals,
 		},
 	)
 	if !p {
 		return ToolResponse{}, permission.ErrorPermissionDenied
 	}
 
-	err = os.WriteFile(filePath, []byte(content), 0o644)
+	err = writeFileWithRetry(filePath, []byte(content), 0o644)
 	if err != nil {
 		return ToolResponse{}, fmt.Errorf("failed to write file: %w", err)
 	}
@@ -237,8 +243,8 @@ func (e *editTool) createNewFile(ctx context.Context, filePath, content string)
 		logging.Debug("Error creating file history version", "error", err)
 	}
 
-	recordFileWrite(filePath)
-	recordFileRead(filePath)
+	recordFileWrite(ctx, filePath)
+	recordFileRead(ctx, filePath)
 
 	return WithResponseMetadata(
 		NewTextResponse("File created: "+filePath),
@@ -263,12 +269,12 @@ func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string
 		return NewTextErrorResponse(fmt.Sprintf("path is a directory, not a file: %s", filePath)), nil
 	}
 
-	if getLastReadTime(filePath).IsZero() {
+	if !autoReadForEdit(ctx, filePath, fileInfo.Size()) {
 		return NewTextErrorResponse("you must read the file before editing it. Use the View tool first"), nil
 	}
 
 	modTime := fileInfo.ModTime()
-	lastRead := getLastReadTime(filePath)
+	lastRead := getLastReadTime(ctx, filePath)
 	if modTime.After(lastRead) {
 		return NewTextErrorResponse(
 			fmt.Sprintf("file %s has been modified since it was last read (mod time: %s, last read: %s)",
@@ -276,10 +282,13 @@ func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string
 			)), nil
 	}
 
-	content, err := os.ReadFile(filePath)
+	content, err := readFileWithRetry(filePath)
 	if err != nil {
 		return ToolResponse{}, fmt.Errorf("failed to read file: %w", err)
 	}
+	if contentChangedSinceRead(ctx, filePath, content) {
+		return NewTextErrorResponse(contentConflictMessage(filePath)), nil
+	}
 
 	oldContent := string(content)
 
@@ -323,13 +332,17 @@ func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string
 				FilePath: filePath,
 				Diff:     diff,
 			},
+			LinesChanged: additions + removals,
 		},
 	)
 	if !p {
 		return ToolResponse{}, permission.ErrorPermissionDenied
 	}
 
-	err = os.WriteFile(filePath, []byte(newContent), 0o644)
+	if changedBeforeWrite(filePath, []byte(oldContent)) {
+		return NewTextErrorResponse(contentConflictMessage(filePath)), nil
+	}
+	err = writeFileWithRetry(filePath, []byte(newContent), 0o644)
 	if err != nil {
 		return ToolResponse{}, fmt.Errorf("failed to write file: %w", err)
 	}
@@ -356,8 +369,8 @@ func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string
 		logging.Debug("Error creating file history version", "error", err)
 	}
 
-	recordFileWrite(filePath)
-	recordFileRead(filePath)
+	recordFileWrite(ctx, filePath)
+	recordFileRead(ctx, filePath)
 
 	return WithResponseMetadata(
 		NewTextResponse("Content deleted from file: "+filePath),
@@ -382,12 +395,12 @@ func (e *editTool) replaceContent(ctx context.Context, filePath, oldString, newS
 		return NewTextErrorResponse(fmt.Sprintf("path is a directory, not a file: %s", filePath)), nil
 	}
 
-	if getLastReadTime(filePath).IsZero() {
+	if !autoReadForEdit(ctx, filePath, fileInfo.Size()) {
 		return NewTextErrorResponse("you must read the file before editing it. Use the View tool first"), nil
 	}
 
 	modTime := fileInfo.ModTime()
-	lastRead := getLastReadTime(filePath)
+	lastRead := getLastReadTime(ctx, filePath)
 	if modTime.After(lastRead) {
 		return NewTextErrorResponse(
 			fmt.Sprintf("file %s has been modified since it was last read (mod time: %s, last read: %s)",
@@ -395,10 +408,13 @@ func (e *editTool) replaceContent(ctx context.Context, filePath, oldString, newS
 			)), nil
 	}
 
-	content, err := os.ReadFile(filePath)
+	content, err := readFileWithRetry(filePath)
 	if err != nil {
 		return ToolResponse{}, fmt.Errorf("failed to read file: %w", err)
 	}
+	if contentChangedSinceRead(ctx, filePath, content) {
+		return NewTextErrorResponse(contentConflictMessage(filePath)), nil
+	}
 
 	oldContent := string(content)
 
@@ -443,13 +459,17 @@ func (e *editTool) replaceContent(ctx context.Context, filePath, oldString, newS
 				FilePath: filePath,
 				Diff:     diff,
 			},
+			LinesChanged: additions + removals,
 		},
 	)
 	if !p {
 		return ToolResponse{}, permission.ErrorPermissionDenied
 	}
 
-	err = os.WriteFile(filePath, []byte(newContent), 0o644)
+	if changedBeforeWrite(filePath, []byte(oldContent)) {
+		return NewTextErrorResponse(contentConflictMessage(filePath)), nil
+	}
+	err = writeFileWithRetry(filePath, []byte(newContent), 0o644)
 	if err != nil {
 		return ToolResponse{}, fmt.Errorf("failed to write file: %w", err)
 	}
@@ -476,8 +496,8 @@ func (e *editTool) replaceContent(ctx context.Context, filePath, oldString, newS
 		logging.Debug("Error creating file history version", "error", err)
 	}
 
-	recordFileWrite(filePath)
-	recordFileRead(filePath)
+	recordFileWrite(ctx, filePath)
+	recordFileRead(ctx, filePath)
 
 	return WithResponseMetadata(
 		NewTextResponse("Content replaced in file: "+filePath),
@@ -0,0 +1,273 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+type EditorPanesResponseMetadata struct {
+	Multiplexer string `json:"multiplexer"`
+	Files       int    `json:"files"`
+}
+
+type editorPanesTool struct{}
+
+const (
+	EditorPanesToolName        = "editor_panes"
+	multiplexerCommandTimeout  = 3 * time.Second
+	editorPanesToolDescription = `Lists the files open in editors running in the other panes of the user's terminal multiplexer (tmux or zellij).
+
+WHEN TO USE THIS TOOL:
+- Use when the user refers to "this file", "the file I have open" or "what I'm looking at" without naming it
+- Helpful to learn which files the user is working on before asking them
+
+HOW TO USE:
+- Call it without parameters
+
+FEATURES:
+- Finds vim, neovim, helix, nano, emacs, micro and kakoune in the panes of the current tmux session
+- In zellij, finds the editors in the panes focused by each client
+- Reports which pane is active
+
+LIMITATIONS:
+- Only works when opencode runs inside tmux or zellij
+- Only sees the files passed to the editor on its command line, not files opened from inside the editor afterwards
+- The tool does not read the files; use the View tool for their content
+
+TIPS:
+- Treat the result as a hint about what the user is looking at, and confirm with the user when it is ambiguous`
+)
+
+// editorCommands are the editors whose file arguments are reported.
+var editorCommands = map[string]bool{
+	"vi": true, "vim": true, "nvim": true, "view": true, "gvim": true,
+	"hx": true, "helix": true, "nano": true, "emacs": true, "emacsclient": true,
+	"micro": true, "kak": true,
+}
+
+// editorPane is a multiplexer pane with an editor and the files it has open.
+type editorPane struct {
+	id      string
+	editor  string
+	files   []string
+	focused bool
+}
+
+func NewEditorPanesTool() BaseTool {
+	return &editorPanesTool{}
+}
+
+// DetectMultiplexer returns the terminal multiplexer opencode runs in, "tmux"
+// or "zellij", or an empty string when there is none.
+func DetectMultiplexer() string {
+	switch {
+	case os.Getenv("TMUX") != "":
+		return "tmux"
+	case os.Getenv("ZELLIJ") != "":
+		return "zellij"
+	}
+	return ""
+}
+
+func (e *editorPanesTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        EditorPanesToolName,
+		Description: editorPanesToolDescription,
+		Parameters:  map[string]any{},
+		Required:    []string{},
+	}
+}
+
+func (e *editorPanesTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	multiplexer := DetectMultiplexer()
+	metadata := EditorPanesResponseMetadata{Multiplexer: multiplexer}
+
+	ctx, cancel := context.WithTimeout(ctx, multiplexerCommandTimeout)
+	defer cancel()
+
+	var panes []editorPane
+	var err error
+	switch multiplexer {
+	case "tmux":
+		panes, err = tmuxEditorPanes(ctx)
+	case "zellij":
+		panes, err = zellijEditorPanes(ctx)
+	default:
+		return WithResponseMetadata(NewTextResponse("Not running inside tmux or zellij, so there are no panes to look at"), metadata), nil
+	}
+	if err != nil {
+		// The multiplexer may be too old or not reachable; the result is
+		// only a hint, so report no panes rather than an error.
+		logging.Debug("Failed to list multiplexer panes", "multiplexer", multiplexer, "error", err)
+	}
+
+	var sb strings.Builder
+	for _, pane := range panes {
+		if len(pane.files) == 0 {
+			continue
+		}
+		focused := ""
+		if pane.focused {
+			focused = ", focused"
+		}
+		fmt.Fprintf(&sb, "- %s in pane %s%s:\n", pane.editor, pane.id, focused)
+		for _, file := range pane.files {
+			fmt.Fprintf(&sb, "  %s\n", file)
+			metadata.Files++
+		}
+	}
+	if metadata.Files == 0 {
+		return WithResponseMetadata(NewTextResponse(fmt.Sprintf("No files are open in editors in the other %s panes", multiplexer)), metadata), nil
+	}
+	return WithResponseMetadata(
+		NewTextResponse(fmt.Sprintf("Files open in editors in other %s panes:\n%s", multiplexer, strings.TrimSuffix(sb.String(), "\n"))),
+		metadata,
+	), nil
+}
+
+// tmuxEditorPanes returns the editors running in the panes of the current
+// tmux session, other than the pane opencode runs in.
+func tmuxEditorPanes(ctx context.Context) ([]editorPane, error) {
+	out, err := exec.CommandContext(ctx, "tmux", "list-panes", "-s", "-F",
+		"#{pane_id}\t#{pane_pid}\t#{pane_active}\t#{window_active}\t#{pane_current_path}").Output()
+	if err != nil {
+		return nil, err
+	}
+	processes, err := listProcesses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ownPane := os.Getenv("TMUX_PANE")
+	var panes []editorPane
+	for line := range strings.SplitSeq(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 5 || fields[0] == ownPane {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		for _, args := range processes.descendants(pid) {
+			if pane, ok := parseEditorCommand(args, fields[4]); ok {
+				pane.id = fields[0]
+				pane.focused = fields[2] == "1" && fields[3] == "1"
+				panes = append(panes, pane)
+			}
+		}
+	}
+	return panes, nil
+}
+
+// zellijEditorPanes returns the editors in the panes focused by the clients
+// of the current zellij session, other than the pane opencode runs in.
+func zellijEditorPanes(ctx context.Context) ([]editorPane, error) {
+	out, err := exec.CommandContext(ctx, "zellij", "action", "list-clients").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	ownPane := "terminal_" + os.Getenv("ZELLIJ_PANE_ID")
+	var panes []editorPane
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	// The first line is the header: CLIENT_ID ZELLIJ_PANE_ID RUNNING_COMMAND
+	for _, line := range lines[min(1, len(lines)):] {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[1] == ownPane {
+			continue
+		}
+		// zellij does not report the working directory of the pane.
+		if pane, ok := parseEditorCommand(fields[2:], config.WorkingDirectory()); ok {
+			pane.id = fields[1]
+			pane.focused = true
+			panes = append(panes, pane)
+		}
+	}
+	return panes, nil
+}
+
+// parseEditorCommand returns the editor and its existing file arguments,
+// resolved against dir, if args run a known editor.
+func parseEditorCommand(args []string, dir string) (editorPane, bool) {
+	if len(args) == 0 {
+		return editorPane{}, false
+	}
+	editor := filepath.Base(args[0])
+	if !editorCommands[editor] {
+		return editorPane{}, false
+	}
+	pane := editorPane{editor: editor}
+	for _, arg := range args[1:] {
+		if arg == "" || strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "+") {
+			continue
+		}
+		path := arg
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		// Option values and other arguments are skipped since they are not
+		// files.
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			pane.files = append(pane.files, path)
+		}
+	}
+	return pane, true
+}
+
+// processTree maps process IDs to their children and arguments.
+type processTree struct {
+	children map[int][]int
+	args     map[int][]string
+}
+
+func listProcesses(ctx context.Context) (processTree, error) {
+	out, err := exec.CommandContext(ctx, "ps", "-A", "-o", "pid=,ppid=,args=").Output()
+	if err != nil {
+		return processTree{}, err
+	}
+	tree := processTree{children: make(map[int][]int), args: make(map[int][]string)}
+	for line := range strings.SplitSeq(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		ppid, err2 := strconv.Atoi(fields[1])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		tree.children[ppid] = append(tree.children[ppid], pid)
+		tree.args[pid] = fields[2:]
+	}
+	return tree, nil
+}
+
+// descendants returns the arguments of pid and every process below it.
+func (t processTree) descendants(pid int) [][]string {
+	var result [][]string
+	seen := make(map[int]bool)
+	queue := []int{pid}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if seen[current] {
+			continue
+		}
+		seen[current] = true
+		if args, ok := t.args[current]; ok {
+			result = append(result, args)
+		}
+		queue = append(queue, t.children[current]...)
+	}
+	return result
+}
@@ -34,6 +34,11 @@ type fetchTool struct {
 
 const (
 	FetchToolName        = "fetch"
+	maxFetchOutputLength = 100000
+	maxFetchSize         = 5 * 1024 * 1024
+	maxFetchRedirects    = 10
+	maxFetchErrorBody    = 1000
+	defaultFetchTimeout  = 30 * time.Second
 	fetchToolDescription = `Fetches content from a URL and returns it in the specified format.
 
 WHEN TO USE THIS TOOL:
@@ -48,12 +53,14 @@ HOW TO USE:
 
 FEATURES:
 - Supports three output formats: text, markdown, and html
-- Automatically handles HTTP redirects
+- Follows up to 10 HTTP redirects
+- Removes scripts and styles before converting HTML to text or markdown
 - Sets reasonable timeouts to prevent hanging
 - Validates input parameters before making requests
 
 LIMITATIONS:
 - Maximum response size is 5MB
+- Output over 100,000 characters is truncated; the rest can be read with the continue_tool tool
 - Only supports HTTP and HTTPS protocols
 - Cannot handle authentication or cookies
 - Some websites may block automated requests
@@ -67,13 +74,25 @@ TIPS:
 
 func NewFetchTool(permissions permission.Service) BaseTool {
 	return &fetchTool{
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client:      newFetchClient(defaultFetchTimeout),
 		permissions: permissions,
 	}
 }
 
+// newFetchClient returns a client that gives up after timeout and refuses to
+// follow more than maxFetchRedirects redirects.
+func newFetchClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxFetchRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxFetchRedirects)
+			}
+			return nil
+		},
+	}
+}
+
 func (t *fetchTool) Info() ToolInfo {
 	return ToolInfo{
 		Name:        FetchToolName,
@@ -136,15 +155,22 @@ func (t *fetchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		return ToolResponse{}, permission.ErrorPermissionDenied
 	}
 
+	cacheKey := "fetch\x00" + format + "\x00" + params.URL
+	if cached, created, ok := getCachedNetworkResponse(cacheKey); ok {
+		return NewTextResponse(truncateWithContinuation(FetchToolName, cached, maxFetchOutputLength) + cachedNote(created)), nil
+	}
+
 	client := t.client
 	if params.Timeout > 0 {
 		maxTimeout := 120 // 2 minutes
 		if params.Timeout > maxTimeout {
 			params.Timeout = maxTimeout
 		}
-		client = &http.Client{
-			Timeout: time.Duration(params.Timeout) * time.Second,
-		}
+		client = newFetchClient(time.Duration(params.Timeout) * time.Second)
+	}
+
+	if err := waitForNetworkSlot(ctx); err != nil {
+		return ToolResponse{}, fmt.Errorf("waiting for rate limiter: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "GET", params.URL, nil)
@@ -160,19 +186,27 @@ func (t *fetchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return NewTextErrorResponse(fmt.Sprintf("Request failed with status code: %d", resp.StatusCode)), nil
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxFetchErrorBody))
+		if len(body) > 0 {
+			return NewTextErrorResponse(fmt.Sprintf("Request failed with status %s, response: %s", resp.Status, string(body))), nil
+		}
+		return NewTextErrorResponse(fmt.Sprintf("Request failed with status %s", resp.Status)), nil
 	}
 
-	maxSize := int64(5 * 1024 * 1024) // 5MB
-	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSize))
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchSize+1))
 	if err != nil {
 		return NewTextErrorResponse("Failed to read response body: " + err.Error()), nil
 	}
+	truncated := len(body) > maxFetchSize
+	if truncated {
+		body = body[:maxFetchSize]
+	}
 
 	content := string(body)
 	contentType := resp.Header.Get("Content-Type")
 
+	output := content
 	switch format {
 	case "text":
 		if strings.Contains(contentType, "text/html") {
@@ -180,9 +214,8 @@ func (t *fetchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 			if err != nil {
 				return NewTextErrorResponse("Failed to extract text from HTML: " + err.Error()), nil
 			}
-			return NewTextResponse(text), nil
+			output = text
 		}
-		return NewTextResponse(content), nil
 
 	case "markdown":
 		if strings.Contains(contentType, "text/html") {
@@ -190,24 +223,29 @@ func (t *fetchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 			if err != nil {
 				return NewTextErrorResponse("Failed to convert HTML to Markdown: " + err.Error()), nil
 			}
-			return NewTextResponse(markdown), nil
+			output = markdown
+		} else {
+			output = "```\n" + content + "\n```"
 		}
+	}
 
-		return NewTextResponse("```\n" + content + "\n```"), nil
-
-	case "html":
-		return NewTextResponse(content), nil
-
-	default:
-		return NewTextResponse(content), nil
+	if truncated {
+		output += fmt.Sprintf("\n\n[The response is larger than %d MB; only the beginning was read]", maxFetchSize/(1024*1024))
 	}
+
+	cacheNetworkResponse(cacheKey, output)
+	return NewTextResponse(truncateWithContinuation(FetchToolName, output, maxFetchOutputLength)), nil
 }
 
+// nonContentSelector matches the elements whose text is not part of the page.
+const nonContentSelector = "script, style, noscript, template"
+
 func extractTextFromHTML(html string) (string, error) {
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
 	if err != nil {
 		return "", err
 	}
+	doc.Find(nonContentSelector).Remove()
 
 	text := doc.Text()
 	text = strings.Join(strings.Fields(text), " ")
@@ -216,12 +254,14 @@ func extractTextFromHTML(html string) (string, error) {
 }
 
 func convertHTMLToMarkdown(html string) (string, error) {
-	converter := md.NewConverter("", true, nil)
-
-	markdown, err := converter.ConvertString(html)
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
 	if err != nil {
 		return "", err
 	}
+	doc.Find(nonContentSelector).Remove()
+
+	converter := md.NewConverter("", true, nil)
+	markdown := converter.Convert(doc.Selection)
 
 	return markdown, nil
 }
@@ -1,8 +1,19 @@
 package tools
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/logging"
 )
 
 // File record to track when files were read/written
@@ -10,44 +21,286 @@ type fileRecord struct {
 	path      string
 	readTime  time.Time
 	writeTime time.Time
+	// readHash is the hash of the content at the last read. Unlike the
+	// modification time it also catches changes made within the same clock
+	// tick or with a preserved timestamp, e.g. by another session.
+	readHash string
+}
+
+// FileRecord is the stored form of when a file was last read and written in
+// a session, so the agent can edit the files it read before a restart
+// without reading them again.
+type FileRecord struct {
+	SessionID string
+	Path      string
+	ReadTime  time.Time
+	WriteTime time.Time
+	ReadHash  string
+}
+
+// FileRecordStore persists file records across restarts.
+type FileRecordStore interface {
+	ListFileRecords(ctx context.Context, sessionID string) ([]FileRecord, error)
+	SaveFileRecord(ctx context.Context, record FileRecord) error
+}
+
+// fileRecordKey identifies the records of a file in a session. Each session
+// has to read a file itself before changing it, another session's read does
+// not count.
+type fileRecordKey struct {
+	sessionID string
+	path      string
 }
 
 var (
-	fileRecords     = make(map[string]fileRecord)
+	fileRecords     = make(map[fileRecordKey]fileRecord)
 	fileRecordMutex sync.RWMutex
+
+	fileRecordStore  FileRecordStore
+	restoredSessions = make(map[string]bool)
 )
 
-func recordFileRead(path string) {
+// SetFileRecordStore sets where the read and write times of files are
+// persisted. Without a store they only last as long as the process.
+func SetFileRecordStore(store FileRecordStore) {
+	fileRecordMutex.Lock()
+	defer fileRecordMutex.Unlock()
+	fileRecordStore = store
+}
+
+// RestoreFileRecords loads the persisted file records of a session, once per
+// process, so a resumed session keeps the reads it made before a restart.
+// Records made since the process started win over older persisted ones.
+func RestoreFileRecords(ctx context.Context, sessionID string) error {
+	fileRecordMutex.Lock()
+	store := fileRecordStore
+	if store == nil || sessionID == "" || restoredSessions[sessionID] {
+		fileRecordMutex.Unlock()
+		return nil
+	}
+	restoredSessions[sessionID] = true
+	fileRecordMutex.Unlock()
+
+	records, err := store.ListFileRecords(ctx, sessionID)
+	if err != nil {
+		fileRecordMutex.Lock()
+		delete(restoredSessions, sessionID)
+		fileRecordMutex.Unlock()
+		return fmt.Errorf("failed to restore file records: %w", err)
+	}
+
 	fileRecordMutex.Lock()
 	defer fileRecordMutex.Unlock()
+	for _, r := range records {
+		key := fileRecordKey{sessionID: sessionID, path: r.Path}
+		record, exists := fileRecords[key]
+		if !exists {
+			record = fileRecord{path: r.Path}
+		}
+		if r.ReadTime.After(record.readTime) {
+			record.readTime = r.ReadTime
+			record.readHash = r.ReadHash
+		}
+		if r.WriteTime.After(record.writeTime) {
+			record.writeTime = r.WriteTime
+		}
+		fileRecords[key] = record
+	}
+	return nil
+}
+
+// saveFileRecord persists record for the session of ctx. Failures only cost
+// a re-read after a restart, so they are logged and not returned.
+func saveFileRecord(ctx context.Context, record fileRecord) {
+	fileRecordMutex.RLock()
+	store := fileRecordStore
+	fileRecordMutex.RUnlock()
+
+	sessionID, _ := GetContextValues(ctx)
+	if store == nil || sessionID == "" {
+		return
+	}
+	err := store.SaveFileRecord(ctx, FileRecord{
+		SessionID: sessionID,
+		Path:      record.path,
+		ReadTime:  record.readTime,
+		WriteTime: record.writeTime,
+		ReadHash:  record.readHash,
+	})
+	if err != nil {
+		logging.Warn("Failed to persist file record", "path", record.path, "error", err)
+	}
+}
+
+func recordFileRead(ctx context.Context, path string) {
+	readHash := ""
+	if content, err := os.ReadFile(path); err == nil {
+		readHash = contentHash(content)
+	}
 
-	record, exists := fileRecords[path]
+	key := fileRecordKeyFor(ctx, path)
+	fileRecordMutex.Lock()
+	record, exists := fileRecords[key]
 	if !exists {
 		record = fileRecord{path: path}
 	}
 	record.readTime = time.Now()
-	fileRecords[path] = record
+	record.readHash = readHash
+	fileRecords[key] = record
+	fileRecordMutex.Unlock()
+
+	saveFileRecord(ctx, record)
+}
+
+// fileRecordKeyFor returns the key of the records of path in the session of
+// ctx.
+func fileRecordKeyFor(ctx context.Context, path string) fileRecordKey {
+	sessionID, _ := GetContextValues(ctx)
+	return fileRecordKey{sessionID: sessionID, path: path}
 }
 
-func getLastReadTime(path string) time.Time {
+func getLastReadTime(ctx context.Context, path string) time.Time {
 	fileRecordMutex.RLock()
 	defer fileRecordMutex.RUnlock()
 
-	record, exists := fileRecords[path]
+	record, exists := fileRecords[fileRecordKeyFor(ctx, path)]
 	if !exists {
 		return time.Time{}
 	}
 	return record.readTime
 }
 
-func recordFileWrite(path string) {
-	fileRecordMutex.Lock()
-	defer fileRecordMutex.Unlock()
+// ModifiedSinceRead reports whether the file at path changed on disk after
+// the agent last read it in the session, which makes the agent's next edit of
+// it fail. Files the agent has not read are never reported. The content is
+// compared too, since editors may preserve the modification time.
+func ModifiedSinceRead(sessionID, path string) bool {
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, sessionID)
+	lastRead := getLastReadTime(ctx, path)
+	if lastRead.IsZero() {
+		return false
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	if info.ModTime().After(lastRead) {
+		return true
+	}
+	content, err := os.ReadFile(path)
+	return err == nil && contentChangedSinceRead(ctx, path, content)
+}
+
+// autoReadForEdit records a read of a file the agent has not read yet, so it
+// can be edited without a View call first. It only does so when auto-reading
+// is enabled and the file is small and in a trusted path, and reports whether
+// the file counts as read.
+func autoReadForEdit(ctx context.Context, path string, size int64) bool {
+	if !getLastReadTime(ctx, path).IsZero() {
+		return true
+	}
+	cfg := config.Get()
+	if cfg == nil || !cfg.AutoRead.Enabled || size > int64(cfg.AutoRead.MaxSizeKB)*1024 {
+		return false
+	}
+	rel, err := filepath.Rel(config.WorkingDirectory(), path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	if len(cfg.AutoRead.Paths) > 0 {
+		trusted := false
+		for _, pattern := range cfg.AutoRead.Paths {
+			if matched, _ := doublestar.Match(filepath.ToSlash(pattern), filepath.ToSlash(rel)); matched {
+				trusted = true
+				break
+			}
+		}
+		if !trusted {
+			return false
+		}
+	}
+	logging.Debug("Reading file automatically before editing it", "path", path)
+	recordFileRead(ctx, path)
+	return true
+}
+
+// checkWritable returns an error if path is outside the write root. Files
+// outside it can still be read but the agent may not change them. Symlinks
+// are resolved first, so a link inside the root cannot point the write out
+// of it. Only the file tools are checked; bash commands are held to the root
+// by the bwrap sandbox alone.
+func checkWritable(path string) error {
+	root := config.WriteRoot()
+	rel, err := filepath.Rel(resolveSymlinks(root), resolveSymlinks(path))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%s is outside the write root %s. Files outside it can be read but not changed", path, root)
+	}
+	return nil
+}
+
+// resolveSymlinks returns path with its symlinks resolved. Files that do not
+// exist yet are resolved through their closest existing parent directory.
+func resolveSymlinks(path string) string {
+	path = filepath.Clean(path)
+	var missing []string
+	for {
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...)
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return filepath.Join(append([]string{path}, missing...)...)
+		}
+		missing = append([]string{filepath.Base(path)}, missing...)
+		path = parent
+	}
+}
 
-	record, exists := fileRecords[path]
+func recordFileWrite(ctx context.Context, path string) {
+	key := fileRecordKeyFor(ctx, path)
+	fileRecordMutex.Lock()
+	record, exists := fileRecords[key]
 	if !exists {
 		record = fileRecord{path: path}
 	}
 	record.writeTime = time.Now()
-	fileRecords[path] = record
+	fileRecords[key] = record
+	fileRecordMutex.Unlock()
+
+	saveFileRecord(ctx, record)
+}
+
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// contentChangedSinceRead reports whether content, as currently on disk,
+// differs from the content of the file at its last read.
+func contentChangedSinceRead(ctx context.Context, path string, content []byte) bool {
+	fileRecordMutex.RLock()
+	record, exists := fileRecords[fileRecordKeyFor(ctx, path)]
+	fileRecordMutex.RUnlock()
+
+	if !exists || record.readHash == "" {
+		return false
+	}
+	return contentHash(content) != record.readHash
+}
+
+// changedBeforeWrite reports whether the file at path no longer holds
+// content, the content a change was computed from. Permission prompts can
+// stay open for a while, so the file is checked again right before it is
+// written to not overwrite changes made in an editor in the meantime. Like
+// the read hash, this does not depend on the modification time.
+func changedBeforeWrite(path string, content []byte) bool {
+	current, err := readFileWithRetry(path)
+	if err != nil {
+		return false
+	}
+	return contentHash(current) != contentHash(content)
+}
+
+func contentConflictMessage(path string) string {
+	return fmt.Sprintf("file %s has changed on disk since it was last read: its content differs from what was read, possibly because another session or an editor modified it. Read the file again before modifying it", path)
 }
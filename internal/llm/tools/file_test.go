@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryFileRecordStore struct {
+	records map[string]FileRecord
+}
+
+func (s *memoryFileRecordStore) ListFileRecords(_ context.Context, sessionID string) ([]FileRecord, error) {
+	var records []FileRecord
+	for _, record := range s.records {
+		if record.SessionID == sessionID {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+func (s *memoryFileRecordStore) SaveFileRecord(_ context.Context, record FileRecord) error {
+	s.records[record.SessionID+"\x00"+record.Path] = record
+	return nil
+}
+
+// resetFileRecords forgets everything held in memory, as a restart does.
+func resetFileRecords() {
+	fileRecordMutex.Lock()
+	defer fileRecordMutex.Unlock()
+	fileRecords = make(map[fileRecordKey]fileRecord)
+	restoredSessions = make(map[string]bool)
+}
+
+func TestRestoreFileRecordsAfterRestart(t *testing.T) {
+	store := &memoryFileRecordStore{records: make(map[string]FileRecord)}
+	SetFileRecordStore(store)
+	t.Cleanup(func() {
+		SetFileRecordStore(nil)
+		resetFileRecords()
+	})
+
+	path := filepath.Join(t.TempDir(), "main.go")
+	require.NoError(t, os.WriteFile(path, []byte("package main\n"), 0o644))
+
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "session")
+	recordFileRead(ctx, path)
+	readTime := getLastReadTime(ctx, path)
+	require.False(t, readTime.IsZero())
+
+	resetFileRecords()
+	assert.True(t, getLastReadTime(ctx, path).IsZero())
+
+	require.NoError(t, RestoreFileRecords(ctx, "session"))
+	assert.True(t, readTime.Equal(getLastReadTime(ctx, path)))
+	assert.False(t, ModifiedSinceRead("session", path))
+	assert.False(t, contentChangedSinceRead(ctx, path, []byte("package main\n")))
+	assert.True(t, contentChangedSinceRead(ctx, path, []byte("package other\n")))
+
+	otherCtx := context.WithValue(context.Background(), SessionIDContextKey, "other")
+	assert.True(t, getLastReadTime(otherCtx, path).IsZero(), "reads of another session do not count")
+
+	resetFileRecords()
+	require.NoError(t, RestoreFileRecords(otherCtx, "other"))
+	assert.True(t, getLastReadTime(otherCtx, path).IsZero(), "records of another session are not restored")
+}
+
+func TestModifiedSinceReadWithPreservedModTime(t *testing.T) {
+	t.Cleanup(resetFileRecords)
+
+	path := filepath.Join(t.TempDir(), "main.go")
+	require.NoError(t, os.WriteFile(path, []byte("package main\n"), 0o644))
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "session")
+	recordFileRead(ctx, path)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.False(t, ModifiedSinceRead("session", path))
+
+	// An editor that keeps the modification time still changes the content.
+	require.NoError(t, os.WriteFile(path, []byte("package edited\n"), 0o644))
+	require.NoError(t, os.Chtimes(path, info.ModTime(), info.ModTime()))
+	assert.True(t, ModifiedSinceRead("session", path))
+	assert.True(t, changedBeforeWrite(path, []byte("package main\n")))
+	assert.False(t, changedBeforeWrite(path, []byte("package edited\n")))
+}
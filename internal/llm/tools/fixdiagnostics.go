@@ -0,0 +1,483 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/diff"
+	"github.com/opencode-ai/opencode/internal/history"
+	"github.com/opencode-ai/opencode/internal/lsp"
+	"github.com/opencode-ai/opencode/internal/lsp/protocol"
+	"github.com/opencode-ai/opencode/internal/lsp/util"
+	"github.com/opencode-ai/opencode/internal/permission"
+)
+
+type FixDiagnosticsParams struct {
+	FilePaths []string `json:"file_paths"`
+}
+
+type FixDiagnosticsResponseMetadata struct {
+	Files        []RefactorFileDiff `json:"files"`
+	FilesChanged []string           `json:"files_changed"`
+	Applied      []string           `json:"applied"`
+	Manual       []string           `json:"manual"`
+	Additions    int                `json:"additions"`
+	Removals     int                `json:"removals"`
+}
+
+type fixDiagnosticsTool struct {
+	lspClients  map[string]*lsp.Client
+	permissions permission.Service
+	files       history.Service
+}
+
+const (
+	FixDiagnosticsToolName    = "fix_diagnostics"
+	MaxFixDiagnosticsFiles    = 10
+	fixDiagnosticsDescription = `Applies the safe fixes the language server offers for the diagnostics of one or more files, in a single reviewed change.
+
+WHEN TO USE THIS TOOL:
+- Use after the Diagnostics tool reports unused imports, unused variables or unorganized imports
+- Helpful to clean up a file in one step instead of one edit per diagnostic
+
+HOW TO USE:
+- Provide the files to clean up in file_paths
+- The user approves a single combined preview with the diff of every file
+
+FEATURES:
+- Organizes imports and applies the language server's fixes that remove unused code
+- Reports which diagnostics were fixed and which need manual attention
+- Files are written atomically; if any write fails, every file already written is restored
+- Reports the diagnostics left after the fixes
+
+LIMITATIONS:
+- Only works for languages with a configured language server that offers code actions
+- Fixes other than import organization and unused code removal are never applied; they are listed for manual attention
+- At most 10 files per call
+- Every file must have been read with the View tool first and must not have changed since
+
+TIPS:
+- Fix what is left with the Edit tool, using the list of diagnostics that need manual attention`
+)
+
+// safeFixTitles are the lowercase title prefixes of quick fixes that only
+// remove unused code, which are applied without looking at them one by one.
+var safeFixTitles = []string{
+	"remove unused",
+	"remove all unused",
+	"delete unused",
+	"remove unnecessary",
+	"remove import",
+}
+
+// diagnosticFix is a code action chosen for a file, with the diagnostics it
+// fixes.
+type diagnosticFix struct {
+	title       string
+	diagnostics []protocol.Diagnostic
+	edits       []protocol.TextEdit
+}
+
+func NewFixDiagnosticsTool(lspClients map[string]*lsp.Client, permissions permission.Service, files history.Service) BaseTool {
+	return &fixDiagnosticsTool{
+		lspClients:  lspClients,
+		permissions: permissions,
+		files:       files,
+	}
+}
+
+func (f *fixDiagnosticsTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        FixDiagnosticsToolName,
+		Description: fixDiagnosticsDescription,
+		Parameters: map[string]any{
+			"file_paths": map[string]any{
+				"type":        "array",
+				"description": "The absolute paths of the files to fix",
+				"items": map[string]any{
+					"type": "string",
+				},
+			},
+		},
+		Required: []string{"file_paths"},
+	}
+}
+
+func (f *fixDiagnosticsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params FixDiagnosticsParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+
+	if len(params.FilePaths) == 0 {
+		return NewTextErrorResponse("file_paths is required"), nil
+	}
+	if len(params.FilePaths) > MaxFixDiagnosticsFiles {
+		return NewTextErrorResponse(fmt.Sprintf("at most %d files can be fixed at once, got %d", MaxFixDiagnosticsFiles, len(params.FilePaths))), nil
+	}
+	if len(f.lspClients) == 0 {
+		return NewTextErrorResponse("no LSP clients available"), nil
+	}
+
+	sessionID, messageID := GetContextValues(ctx)
+	if sessionID == "" || messageID == "" {
+		return ToolResponse{}, fmt.Errorf("session ID and message ID are required for fixing diagnostics")
+	}
+
+	var plan []*refactorFile
+	var applied, manual []string
+	for _, filePath := range params.FilePaths {
+		if !filepath.IsAbs(filePath) {
+			filePath = filepath.Join(config.WorkingDirectory(), filePath)
+		}
+		if slices.ContainsFunc(plan, func(file *refactorFile) bool { return file.path == filePath }) {
+			continue
+		}
+		if err := checkWritable(filePath); err != nil {
+			return NewTextErrorResponse(err.Error()), nil
+		}
+		fileInfo, err := os.Stat(filePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return NewTextErrorResponse(fmt.Sprintf("file not found: %s", filePath)), nil
+			}
+			return ToolResponse{}, fmt.Errorf("failed to access file: %w", err)
+		}
+		if fileInfo.IsDir() {
+			return NewTextErrorResponse(fmt.Sprintf("path is a directory, not a file: %s", filePath)), nil
+		}
+		if !autoReadForEdit(ctx, filePath, fileInfo.Size()) {
+			return NewTextErrorResponse(fmt.Sprintf("you must read the file %s before fixing it. Use the View tool first", filePath)), nil
+		}
+		lastRead := getLastReadTime(ctx, filePath)
+		if modTime := fileInfo.ModTime(); modTime.After(lastRead) {
+			return NewTextErrorResponse(fmt.Sprintf("file %s has been modified since it was last read (mod time: %s, last read: %s)",
+				filePath, modTime.Format(time.RFC3339), lastRead.Format(time.RFC3339))), nil
+		}
+		content, err := readFileWithRetry(filePath)
+		if err != nil {
+			return ToolResponse{}, fmt.Errorf("failed to read file: %w", err)
+		}
+		if contentChangedSinceRead(ctx, filePath, content) {
+			return NewTextErrorResponse(contentConflictMessage(filePath)), nil
+		}
+
+		waitForLspDiagnostics(ctx, filePath, f.lspClients)
+		fixes, unfixed := f.chooseFixes(ctx, filePath, string(content))
+		manual = append(manual, unfixed...)
+
+		var edits []protocol.TextEdit
+		for _, fix := range fixes {
+			edits = append(edits, fix.edits...)
+			for _, diagnostic := range fix.diagnostics {
+				applied = append(applied, fmt.Sprintf("%s: %s (%s)", diagnosticLocation(filePath, diagnostic), diagnostic.Message, fix.title))
+			}
+			if len(fix.diagnostics) == 0 {
+				applied = append(applied, fmt.Sprintf("%s: %s", filePath, fix.title))
+			}
+		}
+		if len(edits) == 0 {
+			continue
+		}
+		newContent, err := util.ApplyTextEditsToContent(string(content), edits)
+		if err != nil {
+			return ToolResponse{}, fmt.Errorf("failed to apply fixes to %s: %w", filePath, err)
+		}
+		if newContent == string(content) {
+			continue
+		}
+		plan = append(plan, &refactorFile{
+			path:       filePath,
+			oldContent: string(content),
+			newContent: newContent,
+			mode:       fileInfo.Mode().Perm(),
+		})
+	}
+
+	if len(plan) == 0 {
+		result := "No safe fixes are available. No changes made."
+		if len(manual) > 0 {
+			result += "\n\nNeeds manual attention:\n" + strings.Join(manual, "\n")
+		}
+		return WithResponseMetadata(NewTextResponse(result), FixDiagnosticsResponseMetadata{Manual: manual}), nil
+	}
+
+	fileDiffs := make([]RefactorFileDiff, 0, len(plan))
+	changedFiles := make([]string, 0, len(plan))
+	totalAdditions, totalRemovals := 0, 0
+	for _, file := range plan {
+		fileDiff, additions, removals := diff.GenerateDiff(file.oldContent, file.newContent, file.path)
+		fileDiffs = append(fileDiffs, RefactorFileDiff{
+			FilePath: file.path,
+			Diff:     fileDiff,
+		})
+		changedFiles = append(changedFiles, file.path)
+		totalAdditions += additions
+		totalRemovals += removals
+	}
+
+	description := fmt.Sprintf("Apply %d diagnostic fixes to %d files", len(applied), len(plan))
+	p := f.permissions.Request(
+		permission.CreatePermissionRequest{
+			SessionID:   sessionID,
+			Path:        config.WorkingDirectory(),
+			ToolName:    FixDiagnosticsToolName,
+			Action:      "write",
+			Description: description,
+			Params: RefactorPermissionsParams{
+				Description: description,
+				Files:       fileDiffs,
+			},
+			LinesChanged: totalAdditions + totalRemovals,
+		},
+	)
+	if !p {
+		return ToolResponse{}, permission.ErrorPermissionDenied
+	}
+
+	if err := applyRefactor(plan); err != nil {
+		return NewTextErrorResponse(applyFailedMessage("fixes", err)), nil
+	}
+
+	for _, file := range plan {
+		recordRefactorHistory(ctx, f.files, sessionID, file)
+		recordFileWrite(ctx, file.path)
+		recordFileRead(ctx, file.path)
+	}
+
+	for _, filePath := range changedFiles {
+		waitForLspDiagnostics(ctx, filePath, f.lspClients)
+	}
+
+	result := fmt.Sprintf("%d files changed, %d additions, %d removals\n\nApplied:\n%s",
+		len(changedFiles), totalAdditions, totalRemovals, strings.Join(applied, "\n"))
+	if len(manual) > 0 {
+		result += "\n\nNeeds manual attention:\n" + strings.Join(manual, "\n")
+	}
+
+	diagnosticsText := ""
+	for _, filePath := range changedFiles {
+		diagnosticsText += getDiagnostics(filePath, f.lspClients)
+	}
+	if diagnosticsText != "" {
+		result += "\n\nDiagnostics:\n" + diagnosticsText
+	}
+
+	return WithResponseMetadata(
+		NewTextResponse(result),
+		FixDiagnosticsResponseMetadata{
+			Files:        fileDiffs,
+			FilesChanged: changedFiles,
+			Applied:      applied,
+			Manual:       manual,
+			Additions:    totalAdditions,
+			Removals:     totalRemovals,
+		}), nil
+}
+
+// chooseFixes asks the language servers for code actions for every
+// diagnostic of filePath and returns the safe ones whose edits do not
+// overlap, along with a line for each diagnostic that is left to fix by
+// hand.
+func (f *fixDiagnosticsTool) chooseFixes(ctx context.Context, filePath, content string) ([]diagnosticFix, []string) {
+	uri := protocol.URIFromPath(filePath)
+	var fixes []diagnosticFix
+	var manual []string
+	// accept adds fix unless its edits conflict with a fix already chosen.
+	// A fix with the same edits as a chosen one, such as a quick fix that
+	// removes an import also removed by organizing imports, is merged.
+	accept := func(fix diagnosticFix) bool {
+		for i, chosen := range fixes {
+			if slices.Equal(chosen.edits, fix.edits) {
+				fixes[i].diagnostics = append(fixes[i].diagnostics, fix.diagnostics...)
+				return true
+			}
+			for _, a := range chosen.edits {
+				for _, b := range fix.edits {
+					if util.RangesOverlap(a.Range, b.Range) {
+						return false
+					}
+				}
+			}
+		}
+		fixes = append(fixes, fix)
+		return true
+	}
+
+	for _, client := range f.lspClients {
+		diagnostics := client.GetDiagnostics()[uri]
+		if len(diagnostics) == 0 {
+			continue
+		}
+
+		fixed := make([]bool, len(diagnostics))
+		if action, ok := organizeImportsAction(ctx, client, uri, content, diagnostics); ok {
+			if edits, ok := fileEdits(action, uri); ok && len(edits) > 0 {
+				fix := diagnosticFix{title: action.Title, edits: edits}
+				for i, diagnostic := range diagnostics {
+					if actionFixes(action, diagnostic) {
+						fix.diagnostics = append(fix.diagnostics, diagnostic)
+						fixed[i] = true
+					}
+				}
+				accept(fix)
+			}
+		}
+
+		for i, diagnostic := range diagnostics {
+			if fixed[i] {
+				continue
+			}
+			reason := "no fix offered"
+			actions := diagnosticActions(ctx, client, uri, diagnostic)
+			var offered []string
+			for _, action := range actions {
+				if !isSafeFix(action) {
+					offered = append(offered, action.Title)
+					continue
+				}
+				edits, ok := fileEdits(action, uri)
+				if !ok {
+					reason = fmt.Sprintf("%q changes other files or needs the language server to run a command", action.Title)
+					continue
+				}
+				if accept(diagnosticFix{title: action.Title, diagnostics: []protocol.Diagnostic{diagnostic}, edits: edits}) {
+					fixed[i] = true
+					break
+				}
+				reason = fmt.Sprintf("%q conflicts with another fix", action.Title)
+			}
+			if fixed[i] {
+				continue
+			}
+			if len(offered) > 0 && reason == "no fix offered" {
+				reason = "offered fixes: " + strings.Join(offered, "; ")
+			}
+			manual = append(manual, fmt.Sprintf("%s: %s (%s)", diagnosticLocation(filePath, diagnostic), diagnostic.Message, reason))
+		}
+	}
+	return fixes, manual
+}
+
+// organizeImportsAction returns the organize imports action of the file,
+// if the server offers one.
+func organizeImportsAction(ctx context.Context, client *lsp.Client, uri protocol.DocumentUri, content string, diagnostics []protocol.Diagnostic) (protocol.CodeAction, bool) {
+	lines := uint32(strings.Count(content, "\n"))
+	actions := codeActions(ctx, client, protocol.CodeActionParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		Range: protocol.Range{
+			End: protocol.Position{Line: lines},
+		},
+		Context: protocol.CodeActionContext{
+			Diagnostics: diagnostics,
+			Only:        []protocol.CodeActionKind{protocol.SourceOrganizeImports},
+		},
+	})
+	for _, action := range actions {
+		if strings.HasPrefix(string(action.Kind), string(protocol.SourceOrganizeImports)) {
+			return action, true
+		}
+	}
+	return protocol.CodeAction{}, false
+}
+
+// diagnosticActions returns the quick fixes offered for a diagnostic.
+func diagnosticActions(ctx context.Context, client *lsp.Client, uri protocol.DocumentUri, diagnostic protocol.Diagnostic) []protocol.CodeAction {
+	return codeActions(ctx, client, protocol.CodeActionParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		Range:        diagnostic.Range,
+		Context: protocol.CodeActionContext{
+			Diagnostics: []protocol.Diagnostic{diagnostic},
+			Only:        []protocol.CodeActionKind{protocol.QuickFix},
+		},
+	})
+}
+
+// codeActions requests code actions, resolving the ones the server sends
+// without their edits. Bare commands and disabled actions are dropped.
+func codeActions(ctx context.Context, client *lsp.Client, params protocol.CodeActionParams) []protocol.CodeAction {
+	result, err := client.CodeAction(ctx, params)
+	if err != nil {
+		return nil
+	}
+	var actions []protocol.CodeAction
+	for _, item := range result {
+		action, ok := item.Value.(protocol.CodeAction)
+		if !ok || action.Disabled != nil {
+			continue
+		}
+		if action.Edit == nil && action.Data != nil {
+			if resolved, err := client.ResolveCodeAction(ctx, action); err == nil {
+				action = resolved
+			}
+		}
+		actions = append(actions, action)
+	}
+	return actions
+}
+
+// isSafeFix reports whether an action only organizes imports or removes
+// unused code.
+func isSafeFix(action protocol.CodeAction) bool {
+	if strings.HasPrefix(string(action.Kind), string(protocol.SourceOrganizeImports)) {
+		return true
+	}
+	title := strings.ToLower(action.Title)
+	for _, prefix := range safeFixTitles {
+		if strings.HasPrefix(title, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// actionFixes reports whether action names diagnostic as fixed, or, for an
+// action that names none, whether the diagnostic is about imports.
+func actionFixes(action protocol.CodeAction, diagnostic protocol.Diagnostic) bool {
+	if len(action.Diagnostics) > 0 {
+		return slices.ContainsFunc(action.Diagnostics, func(d protocol.Diagnostic) bool {
+			return d.Range == diagnostic.Range && d.Message == diagnostic.Message
+		})
+	}
+	return strings.Contains(strings.ToLower(diagnostic.Message), "import")
+}
+
+// fileEdits returns the text edits of action to uri. It fails when the
+// action changes other files, creates, renames or deletes files, or has no
+// edit at all.
+func fileEdits(action protocol.CodeAction, uri protocol.DocumentUri) ([]protocol.TextEdit, bool) {
+	if action.Edit == nil || action.Command != nil {
+		return nil, false
+	}
+	var edits []protocol.TextEdit
+	for changeURI, changes := range action.Edit.Changes {
+		if changeURI != uri {
+			return nil, false
+		}
+		edits = append(edits, changes...)
+	}
+	for _, change := range action.Edit.DocumentChanges {
+		if change.TextDocumentEdit == nil || change.TextDocumentEdit.TextDocument.URI != uri {
+			return nil, false
+		}
+		for _, edit := range change.TextDocumentEdit.Edits {
+			textEdit, err := edit.AsTextEdit()
+			if err != nil {
+				return nil, false
+			}
+			edits = append(edits, textEdit)
+		}
+	}
+	return edits, true
+}
+
+func diagnosticLocation(filePath string, diagnostic protocol.Diagnostic) string {
+	return fmt.Sprintf("%s:%d:%d", filePath, diagnostic.Range.Start.Line+1, diagnostic.Range.Start.Character+1)
+}
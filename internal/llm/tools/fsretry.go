@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// Transient filesystem errors, such as a file briefly locked by an editor or
+// an antivirus scanner, are retried a few times with exponential backoff
+// before a tool gives up.
+var (
+	fsRetryAttempts = 4
+	fsRetryDelay    = 25 * time.Millisecond
+)
+
+// isTransientFSError reports whether err is likely to go away on its own.
+// Genuine errors such as a missing file or denied permission never are.
+func isTransientFSError(err error) bool {
+	if err == nil || errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) || errors.Is(err, fs.ErrExist) {
+		return false
+	}
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		// ERROR_SHARING_VIOLATION and ERROR_LOCK_VIOLATION: another process
+		// holds the file open.
+		return errno == 32 || errno == 33
+	}
+	return errno == syscall.EAGAIN || errno == syscall.EBUSY || errno == syscall.EINTR || errno == syscall.ETXTBSY
+}
+
+// retryTransientFS runs op, running it again while it fails with a transient
+// error, up to fsRetryAttempts times in total.
+func retryTransientFS(op func() error) error {
+	delay := fsRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil || attempt >= fsRetryAttempts || !isTransientFSError(err) {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+func readFileWithRetry(path string) ([]byte, error) {
+	var content []byte
+	err := retryTransientFS(func() error {
+		var err error
+		content, err = os.ReadFile(path)
+		return err
+	})
+	return content, err
+}
+
+func writeFileWithRetry(path string, data []byte, perm os.FileMode) error {
+	return retryTransientFS(func() error {
+		return os.WriteFile(path, data, perm)
+	})
+}
+
+func openFileWithRetry(path string) (*os.File, error) {
+	var file *os.File
+	err := retryTransientFS(func() error {
+		var err error
+		file, err = os.Open(path)
+		return err
+	})
+	return file, err
+}
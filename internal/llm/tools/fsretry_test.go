@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryTransientFS(t *testing.T) {
+	delay := fsRetryDelay
+	fsRetryDelay = time.Millisecond
+	t.Cleanup(func() { fsRetryDelay = delay })
+
+	tests := []struct {
+		name      string
+		failures  int
+		err       error
+		wantCalls int
+		wantErr   bool
+	}{
+		{"succeeds first time", 0, nil, 1, false},
+		{"recovers from transient error", 2, &fs.PathError{Op: "open", Path: "a.go", Err: syscall.EAGAIN}, 3, false},
+		{"gives up after attempts", 100, &fs.PathError{Op: "write", Path: "a.go", Err: syscall.EBUSY}, fsRetryAttempts, true},
+		{"no retry on not exist", 100, &fs.PathError{Op: "open", Path: "a.go", Err: syscall.ENOENT}, 1, true},
+		{"no retry on permission denied", 100, &fs.PathError{Op: "open", Path: "a.go", Err: syscall.EACCES}, 1, true},
+		{"no retry on other errors", 100, os.ErrClosed, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := retryTransientFS(func() error {
+				calls++
+				if calls <= tt.failures {
+					return tt.err
+				}
+				return nil
+			})
+			assert.Equal(t, tt.wantCalls, calls)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
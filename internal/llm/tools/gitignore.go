@@ -0,0 +1,212 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/opencode-ai/opencode/internal/config"
+)
+
+// gitignoreRule is a line of a .gitignore file, as a doublestar pattern
+// relative to the directory of the file.
+type gitignoreRule struct {
+	pattern string
+	negate  bool
+	dirOnly bool
+}
+
+// gitignore holds the rules of a .gitignore file.
+type gitignore struct {
+	rules []gitignoreRule
+}
+
+// loadGitignore reads the .gitignore file of dir. It returns nil when there
+// is none.
+func loadGitignore(dir string) *gitignore {
+	content, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return nil
+	}
+	return parseGitignore(string(content))
+}
+
+func parseGitignore(content string) *gitignore {
+	g := &gitignore{}
+	for line := range strings.SplitSeq(content, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var rule gitignoreRule
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			// An escaped leading "#" or "!" is literal.
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		// Patterns with a slash other than a trailing one are relative to
+		// the directory of the file, others match at any depth.
+		if strings.Contains(line, "/") {
+			line = strings.TrimPrefix(line, "/")
+		} else {
+			line = "**/" + line
+		}
+		if !doublestar.ValidatePattern(line) {
+			continue
+		}
+		rule.pattern = line
+		g.rules = append(g.rules, rule)
+	}
+	return g
+}
+
+// match reports whether the path rel, relative to the directory of the
+// .gitignore file, is ignored and whether any rule matched it at all. The
+// last rule that matches wins, so negated rules can bring back paths ignored
+// by earlier ones.
+func (g *gitignore) match(rel string, isDir bool) (ignored, matched bool) {
+	if g == nil {
+		return false, false
+	}
+	rel = filepath.ToSlash(rel)
+	for _, rule := range g.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if ok, _ := doublestar.Match(rule.pattern, rel); ok {
+			ignored, matched = !rule.negate, true
+		}
+	}
+	return ignored, matched
+}
+
+// gitignoreFile is a cached .gitignore file with the modification time it was
+// read at. A nil rules means the directory has no .gitignore.
+type gitignoreFile struct {
+	rules   *gitignore
+	modTime time.Time
+}
+
+// ignoreMatcher decides which paths below a working directory the ls, grep
+// and glob tools skip: those ignored by the .gitignore files of the
+// directory and its subdirectories, and those matching the extra patterns of
+// the tools.ignore setting, which are read like a .gitignore at the root.
+type ignoreMatcher struct {
+	root          string
+	extraPatterns []string
+	extra         *gitignore
+
+	mu    sync.Mutex
+	files map[string]gitignoreFile
+}
+
+var (
+	ignoreMatchersMu sync.Mutex
+	ignoreMatchers   = make(map[string]*ignoreMatcher)
+)
+
+// getIgnoreMatcher returns the matcher of the working directory root. It is
+// cached, and reads a .gitignore file again when its modification time
+// changes.
+func getIgnoreMatcher(root string) *ignoreMatcher {
+	var extra []string
+	if cfg := config.Get(); cfg != nil {
+		extra = cfg.Tools.Ignore
+	}
+
+	ignoreMatchersMu.Lock()
+	defer ignoreMatchersMu.Unlock()
+	m, ok := ignoreMatchers[root]
+	if !ok || !slices.Equal(m.extraPatterns, extra) {
+		m = newIgnoreMatcher(root, extra)
+		ignoreMatchers[root] = m
+	}
+	return m
+}
+
+func newIgnoreMatcher(root string, extra []string) *ignoreMatcher {
+	m := &ignoreMatcher{
+		root:          root,
+		extraPatterns: slices.Clone(extra),
+		files:         make(map[string]gitignoreFile),
+	}
+	if len(extra) > 0 {
+		m.extra = parseGitignore(strings.Join(extra, "\n"))
+	}
+	return m
+}
+
+// Ignored reports whether path is skipped. Paths outside the root are never
+// skipped, and paths inside an ignored directory always are.
+func (m *ignoreMatcher) Ignored(path string, isDir bool) bool {
+	if m == nil {
+		return false
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.root, path)
+	}
+	rel, err := filepath.Rel(m.root, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for i := 1; i < len(parts); i++ {
+		if m.ignored(parts[:i], true) {
+			return true
+		}
+	}
+	return m.ignored(parts, isDir)
+}
+
+// ignored applies the rules of the root and of the .gitignore files of every
+// directory between the root and the path, the deepest last so it wins.
+func (m *ignoreMatcher) ignored(parts []string, isDir bool) bool {
+	rel := strings.Join(parts, "/")
+	ignored, _ := m.extra.match(rel, isDir)
+	for i := 0; i < len(parts); i++ {
+		dir := strings.Join(parts[:i], "/")
+		rules := m.gitignore(dir)
+		if rules == nil {
+			continue
+		}
+		if ok, matched := rules.match(strings.Join(parts[i:], "/"), isDir); matched {
+			ignored = ok
+		}
+	}
+	return ignored
+}
+
+// gitignore returns the rules of the .gitignore file of dir, relative to the
+// root, reading it again when it changed since it was cached.
+func (m *ignoreMatcher) gitignore(dir string) *gitignore {
+	path := filepath.Join(m.root, filepath.FromSlash(dir), ".gitignore")
+	var modTime time.Time
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cached, ok := m.files[dir]; ok && cached.modTime.Equal(modTime) {
+		return cached.rules
+	}
+	var rules *gitignore
+	if !modTime.IsZero() {
+		rules = loadGitignore(filepath.Dir(path))
+	}
+	m.files[dir] = gitignoreFile{rules: rules, modTime: modTime}
+	return rules
+}
@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	root := t.TempDir()
+	writeFile := func(rel, content string) {
+		path := filepath.Join(root, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	writeFile(".gitignore", "# build output\n/dist/\n*.log\n")
+	writeFile("web/.gitignore", "generated/\n!keep.log\n")
+
+	m := newIgnoreMatcher(root, []string{"fixtures/**"})
+
+	tests := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"dist", true, true},
+		{"dist/app.js", false, true},
+		{"web/dist", true, false},
+		{"server.log", false, true},
+		{"web/keep.log", false, false},
+		{"web/other.log", false, true},
+		{"web/generated/api.ts", false, true},
+		{"generated/api.ts", false, false},
+		{"testdata/fixtures/a.json", false, false},
+		{"fixtures/a.json", false, true},
+		{"main.go", false, false},
+		{filepath.Join(root, "server.log"), false, true},
+		{filepath.Dir(root), true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.ignored, m.Ignored(tt.path, tt.isDir))
+		})
+	}
+
+	t.Run("reloads a changed gitignore", func(t *testing.T) {
+		assert.False(t, m.Ignored("main.go", false))
+		writeFile(".gitignore", "*.go\n")
+		later := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(filepath.Join(root, ".gitignore"), later, later))
+		assert.True(t, m.Ignored("main.go", false))
+		assert.False(t, m.Ignored("server.log", false))
+	})
+}
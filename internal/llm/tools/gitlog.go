@@ -0,0 +1,242 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/git"
+)
+
+type GitLogSearchParams struct {
+	Pickaxe string `json:"pickaxe"`
+	Regex   string `json:"regex"`
+	Message string `json:"message"`
+	Author  string `json:"author"`
+	Path    string `json:"path"`
+	Since   string `json:"since"`
+	Limit   int    `json:"limit"`
+	NoDiff  bool   `json:"no_diff"`
+}
+
+type GitLogSearchResponseMetadata struct {
+	Commits int  `json:"commits"`
+	Limited bool `json:"limited"`
+}
+
+type gitLogSearchTool struct{}
+
+const (
+	GitLogSearchToolName    = "git_log_search"
+	defaultGitLogLimit      = 10
+	maxGitLogLimit          = 50
+	maxGitLogDiffLines      = 80
+	maxGitLogOutputLength   = 60000
+	gitLogSearchTimeout     = 30 * time.Second
+	gitLogRecordSeparator   = "\x1e"
+	gitLogFieldSeparator    = "\x1f"
+	gitLogSearchDescription = `Searches the git history for the commits that changed some code, with their diffs.
+
+WHEN TO USE THIS TOOL:
+- Use to find when a piece of code was introduced or removed
+- Use to find the commit that changed a behavior, a file or a function
+- Helpful for understanding why code looks the way it does before changing it
+
+HOW TO USE:
+- Set pickaxe to find commits that added or removed a string (git log -S)
+- Set regex to find commits whose diff has added or removed lines matching a regular expression (git log -G)
+- Set message to search commit messages, and author to filter by author name or email
+- Set path to limit the search to a file or directory, and since to a time range (e.g. "2 weeks ago", "2024-01-01")
+- At least one of pickaxe, regex, message, author or path is required
+- Optionally set limit (default 10, max 50) and no_diff to only list the commits
+
+FEATURES:
+- Returns the hash, date, author and subject of each matching commit, newest first
+- With pickaxe or regex, only the diffs of the files that match are shown
+- Diffs are truncated to 80 lines per commit
+
+LIMITATIONS:
+- Only works inside a git repository
+- Pickaxe and regex searches read every commit and can be slow in large repositories; narrow them with path or since
+- Searches the history of the current branch only
+
+TIPS:
+- Use pickaxe with a function or variable name to find when it appeared
+- Combine message and path to find the commit behind a change in a file
+- Use the Bash tool with "git show <hash>" to see a full commit`
+)
+
+func NewGitLogSearchTool() BaseTool {
+	return &gitLogSearchTool{}
+}
+
+func (g *gitLogSearchTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        GitLogSearchToolName,
+		Description: gitLogSearchDescription,
+		Parameters: map[string]any{
+			"pickaxe": map[string]any{
+				"type":        "string",
+				"description": "Find commits that change the number of occurrences of this string (git log -S)",
+			},
+			"regex": map[string]any{
+				"type":        "string",
+				"description": "Find commits with added or removed lines matching this regex (git log -G)",
+			},
+			"message": map[string]any{
+				"type":        "string",
+				"description": "Find commits whose message matches this pattern (case-insensitive)",
+			},
+			"author": map[string]any{
+				"type":        "string",
+				"description": "Only commits by authors matching this name or email",
+			},
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Only commits that touch this file or directory",
+			},
+			"since": map[string]any{
+				"type":        "string",
+				"description": "Only commits more recent than this date, e.g. \"2 weeks ago\"",
+			},
+			"limit": map[string]any{
+				"type":        "number",
+				"description": "Maximum number of commits to return (default 10, max 50)",
+			},
+			"no_diff": map[string]any{
+				"type":        "boolean",
+				"description": "Only list the commits without their diffs",
+			},
+		},
+		Required: []string{},
+	}
+}
+
+func (g *gitLogSearchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params GitLogSearchParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+	if params.Pickaxe == "" && params.Regex == "" && params.Message == "" && params.Author == "" && params.Path == "" {
+		return NewTextErrorResponse("at least one of pickaxe, regex, message, author or path is required"), nil
+	}
+	if params.Pickaxe != "" && params.Regex != "" {
+		return NewTextErrorResponse("pickaxe and regex cannot be used together"), nil
+	}
+	if params.Limit <= 0 {
+		params.Limit = defaultGitLogLimit
+	} else if params.Limit > maxGitLogLimit {
+		params.Limit = maxGitLogLimit
+	}
+	if !git.Available() {
+		return NewTextErrorResponse("git was not found in $PATH"), nil
+	}
+
+	args := []string{
+		"log",
+		"--no-color",
+		"--date=short",
+		"--format=" + gitLogRecordSeparator + strings.Join([]string{"%h", "%ad", "%an <%ae>", "%s"}, gitLogFieldSeparator),
+		// One more than the limit tells whether there are more commits.
+		fmt.Sprintf("--max-count=%d", params.Limit+1),
+	}
+	if !params.NoDiff {
+		args = append(args, "--patch")
+	}
+	switch {
+	case params.Pickaxe != "":
+		args = append(args, "-S", params.Pickaxe)
+	case params.Regex != "":
+		args = append(args, "-G", params.Regex)
+	}
+	if params.Message != "" {
+		args = append(args, "--regexp-ignore-case", "--grep="+params.Message)
+	}
+	if params.Author != "" {
+		args = append(args, "--author="+params.Author)
+	}
+	if params.Since != "" {
+		args = append(args, "--since="+params.Since)
+	}
+	if params.Path != "" {
+		path := params.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(config.WorkingDirectory(), path)
+		}
+		args = append(args, "--", path)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, gitLogSearchTimeout)
+	defer cancel()
+	output, err := git.Run(ctx, config.WorkingDirectory(), args...)
+	if err != nil {
+		switch {
+		case errors.Is(err, git.ErrNotRepository):
+			return NewTextErrorResponse("the working directory is not inside a git repository"), nil
+		case errors.Is(err, context.DeadlineExceeded):
+			return NewTextErrorResponse("the search took too long; narrow it down with path or since"), nil
+		case errors.Is(err, context.Canceled):
+			return ToolResponse{}, err
+		}
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	commits := strings.Split(output, gitLogRecordSeparator)[1:]
+	if len(commits) == 0 {
+		return WithResponseMetadata(NewTextResponse("No matching commits found"), GitLogSearchResponseMetadata{}), nil
+	}
+	limited := len(commits) > params.Limit
+	if limited {
+		commits = commits[:params.Limit]
+	}
+
+	var b strings.Builder
+	for i, commit := range commits {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(formatGitLogCommit(commit))
+	}
+	if limited {
+		fmt.Fprintf(&b, "\n(Results are limited to %d commits. Narrow the search or raise the limit to see more.)\n", params.Limit)
+	}
+
+	return WithResponseMetadata(
+		NewTextResponse(truncateWithContinuation(GitLogSearchToolName, b.String(), maxGitLogOutputLength)),
+		GitLogSearchResponseMetadata{
+			Commits: len(commits),
+			Limited: limited,
+		},
+	), nil
+}
+
+// formatGitLogCommit formats a record of the git log output: a header line
+// with the fields, followed by the diff, which is truncated.
+func formatGitLogCommit(record string) string {
+	header, diff, _ := strings.Cut(record, "\n")
+	fields := strings.Split(header, gitLogFieldSeparator)
+	for len(fields) < 4 {
+		fields = append(fields, "")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "commit %s (%s, %s)\n    %s\n", fields[0], fields[1], fields[2], fields[3])
+	diff = strings.Trim(diff, "\n")
+	if diff == "" {
+		return b.String()
+	}
+	lines := strings.Split(diff, "\n")
+	if len(lines) > maxGitLogDiffLines {
+		more := len(lines) - maxGitLogDiffLines
+		lines = append(lines[:maxGitLogDiffLines], fmt.Sprintf("... (%d more diff lines)", more))
+	}
+	b.WriteString("\n")
+	b.WriteString(strings.Join(lines, "\n"))
+	b.WriteString("\n")
+	return b.String()
+}
@@ -5,8 +5,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 
@@ -16,8 +18,10 @@ import (
 )
 
 const (
-	GlobToolName    = "glob"
-	globDescription = `Fast file pattern matching tool that finds files by name and pattern, returning matching paths sorted by modification time (newest first).
+	GlobToolName     = "glob"
+	defaultGlobLimit = 100
+	maxGlobLimit     = 1000
+	globDescription  = `Fast file pattern matching tool that finds files by name and pattern, returning matching paths sorted by modification time (newest first).
 
 WHEN TO USE THIS TOOL:
 - Use when you need to find files by name patterns or extensions
@@ -28,6 +32,7 @@ HOW TO USE:
 - Provide a glob pattern to match against file paths
 - Optionally specify a starting directory (defaults to current working directory)
 - Results are sorted with most recently modified files first
+- Optionally set limit to return more or fewer files (default 100, at most 1000)
 
 GLOB PATTERN SYNTAX:
 - '*' matches any sequence of non-separator characters
@@ -43,9 +48,10 @@ COMMON PATTERN EXAMPLES:
 - '*.{html,css,js}' - Find all HTML, CSS, and JS files
 
 LIMITATIONS:
-- Results are limited to 100 files (newest first)
+- Results are limited to 100 files (newest first) unless limit is set
 - Does not search file contents (use Grep tool for that)
 - Hidden files (starting with '.') are skipped
+- Files ignored by the project's .gitignore files or the configured ignore patterns are skipped
 
 TIPS:
 - For the most useful results, combine with the Grep tool: first find files with Glob, then search their contents with Grep
@@ -56,6 +62,7 @@ TIPS:
 type GlobParams struct {
 	Pattern string `json:"pattern"`
 	Path    string `json:"path"`
+	Limit   int    `json:"limit"`
 }
 
 type GlobResponseMetadata struct {
@@ -82,6 +89,10 @@ func (g *globTool) Info() ToolInfo {
 				"type":        "string",
 				"description": "The directory to search in. Defaults to the current working directory.",
 			},
+			"limit": map[string]any{
+				"type":        "integer",
+				"description": "The maximum number of files to return (default 100, at most 1000)",
+			},
 		},
 		Required: []string{"pattern"},
 	}
@@ -102,7 +113,14 @@ func (g *globTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 		searchPath = config.WorkingDirectory()
 	}
 
-	files, truncated, err := globFiles(params.Pattern, searchPath, 100)
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultGlobLimit
+	}
+	limit = min(limit, maxGlobLimit)
+
+	ignored := getIgnoreMatcher(config.WorkingDirectory())
+	files, truncated, err := globFiles(params.Pattern, searchPath, ignored, limit)
 	if err != nil {
 		return ToolResponse{}, fmt.Errorf("error finding files: %w", err)
 	}
@@ -113,7 +131,7 @@ func (g *globTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	} else {
 		output = strings.Join(files, "\n")
 		if truncated {
-			output += "\n\n(Results are truncated. Consider using a more specific path or pattern.)"
+			output += fmt.Sprintf("\n\n(Results are truncated to the %d most recently modified files. Consider using a more specific path or pattern, or a higher limit.)", limit)
 		}
 	}
 
@@ -126,50 +144,76 @@ func (g *globTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	), nil
 }
 
-func globFiles(pattern, searchPath string, limit int) ([]string, bool, error) {
+func globFiles(pattern, searchPath string, ignored *ignoreMatcher, limit int) ([]string, bool, error) {
 	cmdRg := fileutil.GetRgCmd(pattern)
 	if cmdRg != nil {
 		cmdRg.Dir = searchPath
-		matches, err := runRipgrep(cmdRg, searchPath, limit)
+		matches, truncated, err := runRipgrep(cmdRg, searchPath, ignored, limit)
 		if err == nil {
-			return matches, len(matches) >= limit && limit > 0, nil
+			return matches, truncated, nil
 		}
 		logging.Warn(fmt.Sprintf("Ripgrep execution failed: %v. Falling back to doublestar.", err))
 	}
 
-	return fileutil.GlobWithDoublestar(pattern, searchPath, limit)
+	// All matches are needed to apply the ignore rules before the limit.
+	matches, _, err := fileutil.GlobWithDoublestar(pattern, searchPath, 0)
+	if err != nil {
+		return nil, false, err
+	}
+	matches = slices.DeleteFunc(matches, func(path string) bool {
+		return ignored.Ignored(path, false)
+	})
+	truncated := limit > 0 && len(matches) > limit
+	if truncated {
+		matches = matches[:limit]
+	}
+	return matches, truncated, nil
 }
 
-func runRipgrep(cmd *exec.Cmd, searchRoot string, limit int) ([]string, error) {
+func runRipgrep(cmd *exec.Cmd, searchRoot string, ignored *ignoreMatcher, limit int) ([]string, bool, error) {
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		if ee, ok := err.(*exec.ExitError); ok && ee.ExitCode() == 1 {
-			return nil, nil
+			return nil, false, nil
 		}
-		return nil, fmt.Errorf("ripgrep: %w\n%s", err, out)
+		return nil, false, fmt.Errorf("ripgrep: %w\n%s", err, out)
 	}
 
-	var matches []string
+	var matches []fileutil.FileInfo
 	for _, p := range bytes.Split(out, []byte{0}) {
 		if len(p) == 0 {
 			continue
 		}
-		absPath := string(p)
+		path := string(p)
+		absPath := path
 		if !filepath.IsAbs(absPath) {
 			absPath = filepath.Join(searchRoot, absPath)
 		}
-		if fileutil.SkipHidden(absPath) {
+		// Only the part below the search root decides what is skipped.
+		if rel, err := filepath.Rel(searchRoot, absPath); err == nil {
+			path = rel
+		}
+		if fileutil.SkipHidden(path) || ignored.Ignored(absPath, false) {
+			continue
+		}
+		info, err := os.Stat(absPath)
+		if err != nil {
 			continue
 		}
-		matches = append(matches, absPath)
+		matches = append(matches, fileutil.FileInfo{Path: absPath, ModTime: info.ModTime()})
 	}
 
 	sort.SliceStable(matches, func(i, j int) bool {
-		return len(matches[i]) < len(matches[j])
+		return matches[i].ModTime.After(matches[j].ModTime)
 	})
-
-	if limit > 0 && len(matches) > limit {
+	truncated := limit > 0 && len(matches) > limit
+	if truncated {
 		matches = matches[:limit]
 	}
-	return matches, nil
+
+	results := make([]string, len(matches))
+	for i, m := range matches {
+		results[i] = m.Path
+	}
+	return results, truncated, nil
 }
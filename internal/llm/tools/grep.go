@@ -19,10 +19,14 @@ import (
 )
 
 type GrepParams struct {
-	Pattern     string `json:"pattern"`
-	Path        string `json:"path"`
-	Include     string `json:"include"`
-	LiteralText bool   `json:"literal_text"`
+	Pattern         string `json:"pattern"`
+	Path            string `json:"path"`
+	Include         string `json:"include"`
+	LiteralText     bool   `json:"literal_text"`
+	ContextLines    int    `json:"context_lines"`
+	ContextBefore   int    `json:"context_before"`
+	ContextAfter    int    `json:"context_after"`
+	FunctionContext bool   `json:"function_context"`
 }
 
 type grepMatch struct {
@@ -41,6 +45,7 @@ type grepTool struct{}
 
 const (
 	GrepToolName    = "grep"
+	MaxGrepMatches  = 100
 	grepDescription = `Fast content search tool that finds files containing specific text or patterns, returning matching file paths sorted by modification time (newest first).
 
 WHEN TO USE THIS TOOL:
@@ -54,6 +59,9 @@ HOW TO USE:
 - Optionally specify a starting directory (defaults to current working directory)
 - Optionally provide an include pattern to filter which files to search
 - Results are sorted with most recently modified files first
+- Optionally set context_lines to show that many lines before and after each match
+- Optionally set context_before or context_after to show a different number of lines on one side
+- Optionally set function_context=true to show the whole function around each match
 
 REGEX PATTERN SYNTAX (when literal_text=false):
 - Supports standard regular expression syntax
@@ -67,16 +75,19 @@ COMMON INCLUDE PATTERN EXAMPLES:
 - '*.go' - Only search Go files
 
 LIMITATIONS:
-- Results are limited to 100 files (newest first)
+- Results are limited to 100 matching lines (newest files first)
 - Performance depends on the number of files being searched
 - Very large binary files may be skipped
 - Hidden files (starting with '.') are skipped
+- Files ignored by the project's .gitignore files or the configured ignore patterns are skipped
+- function_context understands Go, Python and brace-delimited languages (JavaScript, TypeScript, Java, C, C++, C#, Rust, ...); elsewhere, and for functions over 150 lines, it falls back to context_lines
 
 TIPS:
 - For faster, more targeted searches, first use Glob to find relevant files, then use Grep
 - When doing iterative exploration that may require multiple rounds of searching, consider using the Agent tool instead
 - Always check if results are truncated and refine your search pattern if needed
-- Use literal_text=true when searching for exact text containing special characters like dots, parentheses, etc.`
+- Use literal_text=true when searching for exact text containing special characters like dots, parentheses, etc.
+- Use function_context=true when you need to understand or edit the code around a match, instead of viewing each file`
 )
 
 func NewGrepTool() BaseTool {
@@ -104,6 +115,22 @@ func (g *grepTool) Info() ToolInfo {
 				"type":        "boolean",
 				"description": "If true, the pattern will be treated as literal text with special regex characters escaped. Default is false.",
 			},
+			"context_lines": map[string]any{
+				"type":        "integer",
+				"description": "Number of lines to show before and after each match (at most 20). Default is 0, or 3 with function_context.",
+			},
+			"context_before": map[string]any{
+				"type":        "integer",
+				"description": "Number of lines to show before each match, overriding context_lines (at most 20).",
+			},
+			"context_after": map[string]any{
+				"type":        "integer",
+				"description": "Number of lines to show after each match, overriding context_lines (at most 20).",
+			},
+			"function_context": map[string]any{
+				"type":        "boolean",
+				"description": "If true, show the whole enclosing function of each match, falling back to context_lines when it cannot be determined. Default is false.",
+			},
 		},
 		Required: []string{"pattern"},
 	}
@@ -142,7 +169,8 @@ func (g *grepTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 		searchPath = config.WorkingDirectory()
 	}
 
-	matches, truncated, err := searchFiles(searchPattern, searchPath, params.Include, 100)
+	ignored := getIgnoreMatcher(config.WorkingDirectory())
+	matches, truncated, err := searchFiles(ctx, searchPattern, searchPath, params.Include, ignored, MaxGrepMatches)
 	if err != nil {
 		return ToolResponse{}, fmt.Errorf("error searching files: %w", err)
 	}
@@ -153,19 +181,30 @@ func (g *grepTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	} else {
 		output = fmt.Sprintf("Found %d matches\n", len(matches))
 
-		currentFile := ""
-		for _, match := range matches {
-			if currentFile != match.path {
-				if currentFile != "" {
-					output += "\n"
+		before, after := params.ContextLines, params.ContextLines
+		if params.ContextBefore > 0 {
+			before = params.ContextBefore
+		}
+		if params.ContextAfter > 0 {
+			after = params.ContextAfter
+		}
+		if before > 0 || after > 0 || params.FunctionContext {
+			output += formatMatchesWithContext(matches, before, after, params.FunctionContext)
+		} else {
+			currentFile := ""
+			for _, match := range matches {
+				if currentFile != match.path {
+					if currentFile != "" {
+						output += "\n"
+					}
+					currentFile = match.path
+					output += fmt.Sprintf("%s:\n", match.path)
+				}
+				if match.lineNum > 0 {
+					output += fmt.Sprintf("  Line %d: %s\n", match.lineNum, match.lineText)
+				} else {
+					output += fmt.Sprintf("  %s\n", match.path)
 				}
-				currentFile = match.path
-				output += fmt.Sprintf("%s:\n", match.path)
-			}
-			if match.lineNum > 0 {
-				output += fmt.Sprintf("  Line %d: %s\n", match.lineNum, match.lineText)
-			} else {
-				output += fmt.Sprintf("  %s\n", match.path)
 			}
 		}
 
@@ -175,7 +214,7 @@ func (g *grepTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	}
 
 	return WithResponseMetadata(
-		NewTextResponse(output),
+		NewTextResponse(truncateWithContinuation(GrepToolName, output, MaxOutputLength)),
 		GrepResponseMetadata{
 			NumberOfMatches: len(matches),
 			Truncated:       truncated,
@@ -183,16 +222,20 @@ func (g *grepTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	), nil
 }
 
-func searchFiles(pattern, rootPath, include string, limit int) ([]grepMatch, bool, error) {
-	matches, err := searchWithRipgrep(pattern, rootPath, include)
+func searchFiles(ctx context.Context, pattern, rootPath, include string, ignored *ignoreMatcher, limit int) ([]grepMatch, bool, error) {
+	matches, err := searchWithRipgrep(ctx, pattern, rootPath, include, ignored)
 	if err != nil {
-		matches, err = searchFilesWithRegex(pattern, rootPath, include)
+		if ctx.Err() != nil {
+			return nil, false, ctx.Err()
+		}
+		matches, err = searchFilesWithRegex(ctx, pattern, rootPath, include, ignored, limit)
 		if err != nil {
 			return nil, false, err
 		}
 	}
 
-	sort.Slice(matches, func(i, j int) bool {
+	// Keep the lines of a file in order, since the output groups them.
+	sort.SliceStable(matches, func(i, j int) bool {
 		return matches[i].modTime.After(matches[j].modTime)
 	})
 
@@ -204,7 +247,7 @@ func searchFiles(pattern, rootPath, include string, limit int) ([]grepMatch, boo
 	return matches, truncated, nil
 }
 
-func searchWithRipgrep(pattern, path, include string) ([]grepMatch, error) {
+func searchWithRipgrep(ctx context.Context, pattern, path, include string, ignored *ignoreMatcher) ([]grepMatch, error) {
 	_, err := exec.LookPath("rg")
 	if err != nil {
 		return nil, fmt.Errorf("ripgrep not found: %w", err)
@@ -217,7 +260,7 @@ func searchWithRipgrep(pattern, path, include string) ([]grepMatch, error) {
 	}
 	args = append(args, path)
 
-	cmd := exec.Command("rg", args...)
+	cmd := exec.CommandContext(ctx, "rg", args...)
 	output, err := cmd.Output()
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
@@ -246,6 +289,9 @@ func searchWithRipgrep(pattern, path, include string) ([]grepMatch, error) {
 			continue
 		}
 		lineText := parts[2]
+		if ignored.Ignored(filePath, false) {
+			continue
+		}
 
 		fileInfo, err := os.Stat(filePath)
 		if err != nil {
@@ -263,7 +309,12 @@ func searchWithRipgrep(pattern, path, include string) ([]grepMatch, error) {
 	return matches, nil
 }
 
-func searchFilesWithRegex(pattern, rootPath, include string) ([]grepMatch, error) {
+// searchFilesWithRegex is the fallback when ripgrep is not installed. Like
+// ripgrep, it reports every matching line, skips hidden and commonly ignored
+// directories and matches include against the file name, or against the path
+// relative to rootPath when include contains a slash. It also skips the paths
+// ignored skips, which ripgrep leaves to the caller.
+func searchFilesWithRegex(ctx context.Context, pattern, rootPath, include string, ignored *ignoreMatcher, limit int) ([]grepMatch, error) {
 	matches := []grepMatch{}
 
 	regex, err := regexp.Compile(pattern)
@@ -274,7 +325,7 @@ func searchFilesWithRegex(pattern, rootPath, include string) ([]grepMatch, error
 	var includePattern *regexp.Regexp
 	if include != "" {
 		regexPattern := globToRegex(include)
-		includePattern, err = regexp.Compile(regexPattern)
+		includePattern, err = regexp.Compile("^" + regexPattern + "$")
 		if err != nil {
 			return nil, fmt.Errorf("invalid include pattern: %w", err)
 		}
@@ -284,35 +335,50 @@ func searchFilesWithRegex(pattern, rootPath, include string) ([]grepMatch, error
 		if err != nil {
 			return nil // Skip errors
 		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 
+		// Only the part below rootPath decides what is skipped, so that a
+		// search inside, say, /tmp still finds files.
+		rel, err := filepath.Rel(rootPath, path)
+		if err != nil {
+			return nil
+		}
 		if info.IsDir() {
-			return nil // Skip directories
+			if rel != "." && (fileutil.SkipHidden(rel) || ignored.Ignored(path, true)) {
+				return filepath.SkipDir
+			}
+			return nil
 		}
 
-		if fileutil.SkipHidden(path) {
+		if fileutil.SkipHidden(rel) || ignored.Ignored(path, false) {
 			return nil
 		}
 
-		if includePattern != nil && !includePattern.MatchString(path) {
-			return nil
+		if includePattern != nil {
+			name := info.Name()
+			if strings.Contains(include, "/") {
+				name = filepath.ToSlash(rel)
+			}
+			if !includePattern.MatchString(name) {
+				return nil
+			}
 		}
 
-		match, lineNum, lineText, err := fileContainsPattern(path, regex)
+		fileMatches, err := fileMatchingLines(path, regex)
 		if err != nil {
 			return nil // Skip files we can't read
 		}
 
-		if match {
-			matches = append(matches, grepMatch{
-				path:     path,
-				modTime:  info.ModTime(),
-				lineNum:  lineNum,
-				lineText: lineText,
-			})
-
-			if len(matches) >= 200 {
-				return filepath.SkipAll
-			}
+		for _, m := range fileMatches {
+			m.modTime = info.ModTime()
+			matches = append(matches, m)
+		}
+		// Collect more than limit so that the newest files can still be
+		// picked when the results are sorted.
+		if len(matches) >= 2*limit {
+			return filepath.SkipAll
 		}
 
 		return nil
@@ -324,24 +390,30 @@ func searchFilesWithRegex(pattern, rootPath, include string) ([]grepMatch, error
 	return matches, nil
 }
 
-func fileContainsPattern(filePath string, pattern *regexp.Regexp) (bool, int, string, error) {
+// fileMatchingLines returns the lines of filePath that match pattern.
+func fileMatchingLines(filePath string, pattern *regexp.Regexp) ([]grepMatch, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
-		return false, 0, "", err
+		return nil, err
 	}
 	defer file.Close()
 
+	var matches []grepMatch
 	scanner := bufio.NewScanner(file)
 	lineNum := 0
 	for scanner.Scan() {
 		lineNum++
 		line := scanner.Text()
 		if pattern.MatchString(line) {
-			return true, lineNum, line, nil
+			matches = append(matches, grepMatch{
+				path:     filePath,
+				lineNum:  lineNum,
+				lineText: line,
+			})
 		}
 	}
 
-	return false, 0, "", scanner.Err()
+	return matches, scanner.Err()
 }
 
 func globToRegex(glob string) string {
@@ -0,0 +1,37 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrepFallbackContext(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(path, []byte("one\ntwo\nthree\nmatch\nfive\nsix\nseven\n"), 0o644))
+
+	matches, err := searchFilesWithRegex(context.Background(), "match", dir, "", nil, MaxGrepMatches)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+
+	tests := []struct {
+		name          string
+		before, after int
+		expected      string
+	}{
+		{"before and after", 1, 1, "  Lines 3-5:\n        3|three\n  >     4|match\n        5|five\n"},
+		{"before only", 2, 0, "  Lines 2-4:\n        2|two\n        3|three\n  >     4|match\n"},
+		{"after only", 0, 2, "  Lines 4-6:\n  >     4|match\n        5|five\n        6|six\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := formatMatchesWithContext(matches, tt.before, tt.after, false)
+			assert.Equal(t, path+":\n"+tt.expected, output)
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package tools
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/language"
+)
+
+const (
+	defaultContextLines     = 3
+	maxContextLines         = 20
+	maxFunctionContextLines = 150
+)
+
+var (
+	// functionHeaderRe matches lines that can start a function in brace
+	// languages: a parameter list, optionally followed by the opening brace.
+	functionHeaderRe = regexp.MustCompile(`\)[^;{}()]*(\{|=>\s*\{)?\s*$`)
+	controlFlowRe    = regexp.MustCompile(`^\s*(\}\s*)?(if|else|for|foreach|while|do|switch|catch|try|return|using|lock|match|with)\b`)
+	pythonBlockRe    = regexp.MustCompile(`^(\s*)(async\s+def|def|class)\s`)
+)
+
+// contextRange is a 1-based, inclusive range of lines shown around matches.
+type contextRange struct {
+	start, end int
+	function   bool
+}
+
+// matchContext returns the lines to show around a match on lineNum. With
+// function set it returns the enclosing function, falling back to lines
+// before and after the match when the function cannot be determined.
+func matchContext(path string, lines []string, lineNum, before, after int, function bool) contextRange {
+	if function {
+		if start, end, ok := enclosingFunction(path, lines, lineNum); ok {
+			return contextRange{start: start, end: end, function: true}
+		}
+	}
+	return contextRange{
+		start: max(1, lineNum-before),
+		end:   min(len(lines), lineNum+after),
+	}
+}
+
+func enclosingFunction(path string, lines []string, lineNum int) (int, int, bool) {
+	var start, end int
+	var ok bool
+	switch lang := language.Detect(path); {
+	case lang == language.Go:
+		start, end, ok = enclosingGoDecl(lines, lineNum)
+	case lang == language.Python:
+		start, end, ok = enclosingPythonBlock(lines, lineNum)
+	case lang.BraceSyntax():
+		start, end, ok = enclosingBraceBlock(lines, lineNum)
+	}
+	if !ok || end-start+1 > maxFunctionContextLines {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// enclosingGoDecl returns the top-level declaration containing lineNum.
+func enclosingGoDecl(lines []string, lineNum int) (int, int, bool) {
+	fset := token.NewFileSet()
+	// Parse errors still leave the declarations that could be parsed.
+	file, _ := parser.ParseFile(fset, "", strings.Join(lines, "\n"), parser.SkipObjectResolution)
+	if file == nil {
+		return 0, 0, false
+	}
+	for _, decl := range file.Decls {
+		start, end := fset.Position(decl.Pos()).Line, fset.Position(decl.End()).Line
+		if start <= lineNum && lineNum <= end {
+			return start, end, true
+		}
+	}
+	return 0, 0, false
+}
+
+// enclosingPythonBlock returns the innermost def or class whose indented
+// body contains lineNum.
+func enclosingPythonBlock(lines []string, lineNum int) (int, int, bool) {
+	indent := indentation(lines[lineNum-1])
+	for i := lineNum - 1; i >= 0 && lineNum-i <= maxFunctionContextLines; i-- {
+		m := pythonBlockRe.FindStringSubmatch(lines[i])
+		if m == nil || (i != lineNum-1 && len(m[1]) >= indent) {
+			continue
+		}
+		if end := pythonBlockEnd(lines, i, len(m[1])); end >= lineNum {
+			return i + 1, end, true
+		}
+	}
+	return 0, 0, false
+}
+
+// pythonBlockEnd returns the 1-based last line of the block whose header is
+// at line index start, found from the indentation of the lines after it.
+func pythonBlockEnd(lines []string, start, indent int) int {
+	end := start + 1
+	for j := start + 1; j < len(lines); j++ {
+		if strings.TrimSpace(lines[j]) == "" {
+			continue
+		}
+		if indentation(lines[j]) <= indent {
+			break
+		}
+		end = j + 1
+	}
+	return end
+}
+
+// enclosingBraceBlock returns the innermost function-like block containing
+// lineNum, found by looking for a header line above it and matching braces.
+func enclosingBraceBlock(lines []string, lineNum int) (int, int, bool) {
+	for i := lineNum - 1; i >= 0 && lineNum-i <= maxFunctionContextLines; i-- {
+		line := lines[i]
+		if !functionHeaderRe.MatchString(line) || controlFlowRe.MatchString(line) {
+			continue
+		}
+		end, ok := blockEnd(lines, i, maxFunctionContextLines)
+		if ok && end >= lineNum {
+			return i + 1, end, true
+		}
+	}
+	return 0, 0, false
+}
+
+// blockEnd returns the 1-based line closing the first brace opened on or
+// after line index start, looking at most maxLines lines ahead.
+func blockEnd(lines []string, start, maxLines int) (int, bool) {
+	depth, opened := 0, false
+	for i := start; i < len(lines) && i-start <= maxLines; i++ {
+		for _, delta := range braceDeltas(lines[i]) {
+			depth += delta
+			if delta > 0 {
+				opened = true
+			}
+			if opened && depth == 0 {
+				return i + 1, true
+			}
+		}
+		// The body has to start right after the header.
+		if !opened && i > start+1 {
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// braceDeltas returns +1 and -1 for each brace on the line, ignoring braces in
+// string literals and line comments.
+func braceDeltas(line string) []int {
+	var deltas []int
+	var quote rune
+	escaped := false
+	for i, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if r == '\\' {
+				escaped = true
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'' || r == '`':
+			quote = r
+		case r == '/' && strings.HasPrefix(line[i:], "//"):
+			return deltas
+		case r == '{':
+			deltas = append(deltas, 1)
+		case r == '}':
+			deltas = append(deltas, -1)
+		}
+	}
+	return deltas
+}
+
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// formatMatchesWithContext renders the matches grouped by file, each with the
+// lines around it or its enclosing function.
+func formatMatchesWithContext(matches []grepMatch, before, after int, function bool) string {
+	if before <= 0 && after <= 0 {
+		before, after = defaultContextLines, defaultContextLines
+	}
+	before, after = min(max(before, 0), maxContextLines), min(max(after, 0), maxContextLines)
+
+	var paths []string
+	byPath := make(map[string][]grepMatch)
+	for _, match := range matches {
+		if _, ok := byPath[match.path]; !ok {
+			paths = append(paths, match.path)
+		}
+		byPath[match.path] = append(byPath[match.path], match)
+	}
+
+	var sb strings.Builder
+	for i, path := range paths {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "%s:\n", path)
+
+		fileMatches := byPath[path]
+		content, err := readFileWithRetry(path)
+		lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+		ranges := make([]contextRange, 0, len(fileMatches))
+		matched := make(map[int]bool)
+		for _, match := range fileMatches {
+			if err != nil || match.lineNum <= 0 || match.lineNum > len(lines) {
+				fmt.Fprintf(&sb, "  Line %d: %s\n", match.lineNum, match.lineText)
+				continue
+			}
+			ranges = append(ranges, matchContext(path, lines, match.lineNum, before, after, function))
+			matched[match.lineNum] = true
+		}
+		sort.Slice(ranges, func(i, j int) bool {
+			return ranges[i].start < ranges[j].start
+		})
+		sb.WriteString(formatMatchContext(lines, ranges, matched))
+	}
+	return sb.String()
+}
+
+// formatMatchContext renders the context ranges of the matches in one file,
+// merging ranges that overlap and marking the matching lines.
+func formatMatchContext(lines []string, ranges []contextRange, matched map[int]bool) string {
+	merged := make([]contextRange, 0, len(ranges))
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && r.start <= merged[n-1].end+1 && r.end >= merged[n-1].start-1 {
+			merged[n-1].start = min(merged[n-1].start, r.start)
+			merged[n-1].end = max(merged[n-1].end, r.end)
+			merged[n-1].function = merged[n-1].function || r.function
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	var sb strings.Builder
+	for _, r := range merged {
+		kind := ""
+		if r.function {
+			kind = " (enclosing function)"
+		}
+		fmt.Fprintf(&sb, "  Lines %d-%d%s:\n", r.start, r.end, kind)
+		for n := r.start; n <= r.end; n++ {
+			marker := " "
+			if matched[n] {
+				marker = ">"
+			}
+			fmt.Fprintf(&sb, "  %s%6d|%s\n", marker, n, lines[n-1])
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,581 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/fileutil"
+)
+
+type ImportGraphParams struct {
+	Target     string `json:"target"`
+	Direction  string `json:"direction"`
+	Transitive bool   `json:"transitive"`
+}
+
+type ImportGraphResponseMetadata struct {
+	Kind    string `json:"kind"`
+	Nodes   int    `json:"nodes"`
+	Edges   int    `json:"edges"`
+	Results int    `json:"results"`
+}
+
+type importGraphTool struct{}
+
+const (
+	ImportGraphToolName    = "import_graph"
+	maxImportGraphFiles    = 5000
+	maxImportGraphFileSize = 512 * 1024
+	maxImportGraphLines    = 200
+	goListTimeout          = time.Minute
+	importGraphDescription = `Builds the import graph of the project and answers which packages or files depend on which.
+
+WHEN TO USE THIS TOOL:
+- Use to see what a package or file depends on, or what imports it, before changing it
+- Helpful to estimate the impact of a change without many grep calls
+- Call without a target for an overview of the project's structure
+
+HOW TO USE:
+- target: a Go import path (or its suffix, e.g. "internal/config"), a directory or a file path
+- direction: "dependencies" (what the target imports, the default) or "dependents" (what imports the target)
+- transitive: true to follow the graph all the way instead of listing direct edges only
+
+FEATURES:
+- Go modules: package graph from "go list", including test imports
+- JavaScript, TypeScript and Python: file graph from parsed import statements, with relative and project-local imports resolved to files
+- The graph is cached and only updated for files that changed
+
+LIMITATIONS:
+- Non-Go imports are parsed textually; dynamic imports and path aliases are not resolved
+- Imports that cannot be resolved within the project are reported as external
+- Output is limited to 200 lines
+
+TIPS:
+- Use dependents with transitive=true to find everything a change may affect
+- Use the View tool on the listed files to confirm how an import is used`
+)
+
+// importGraph is the internal import graph of a project. Nodes are Go
+// packages or, for other languages, files relative to the project root.
+type importGraph struct {
+	kind     string
+	root     string
+	imports  map[string][]string
+	external map[string][]string
+	dirs     map[string]string
+	prefix   string
+}
+
+func NewImportGraphTool() BaseTool {
+	return &importGraphTool{}
+}
+
+func (g *importGraphTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ImportGraphToolName,
+		Description: importGraphDescription,
+		Parameters: map[string]any{
+			"target": map[string]any{
+				"type":        "string",
+				"description": "The package, directory or file to query. Omit for an overview of the graph.",
+			},
+			"direction": map[string]any{
+				"type":        "string",
+				"description": "Whether to list what the target imports (dependencies) or what imports it (dependents)",
+				"enum":        []string{"dependencies", "dependents"},
+			},
+			"transitive": map[string]any{
+				"type":        "boolean",
+				"description": "Follow the graph transitively instead of listing direct edges only",
+			},
+		},
+		Required: []string{},
+	}
+}
+
+func (g *importGraphTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params ImportGraphParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+	switch params.Direction {
+	case "":
+		params.Direction = "dependencies"
+	case "dependencies", "dependents":
+	default:
+		return NewTextErrorResponse("direction must be dependencies or dependents"), nil
+	}
+
+	graph, err := loadImportGraph(ctx, config.WorkingDirectory())
+	if err != nil {
+		return ToolResponse{}, err
+	}
+	if len(graph.imports) == 0 {
+		return NewTextErrorResponse("no Go packages or JavaScript, TypeScript or Python files found in the project"), nil
+	}
+
+	metadata := ImportGraphResponseMetadata{
+		Kind:  graph.kind,
+		Nodes: len(graph.imports),
+		Edges: graph.edgeCount(),
+	}
+
+	target := strings.TrimSpace(params.Target)
+	if target == "" {
+		return WithResponseMetadata(NewTextResponse(graph.summary()), metadata), nil
+	}
+
+	node, ok := graph.resolve(target)
+	if !ok {
+		return NewTextErrorResponse(fmt.Sprintf("%s is not a node of the import graph. Call import_graph without a target to list them", target)), nil
+	}
+
+	edges := graph.imports
+	if params.Direction == "dependents" {
+		edges = graph.reverse()
+	}
+	results := edges[node]
+	if params.Transitive {
+		results = reachable(edges, node)
+	}
+	metadata.Results = len(results)
+
+	return WithResponseMetadata(NewTextResponse(graph.describe(node, params.Direction, params.Transitive, results)), metadata), nil
+}
+
+var (
+	goGraphCache      = make(map[string]goGraphCacheEntry)
+	fileImportsCache  = make(map[string]fileImportsCacheEntry)
+	importGraphMu     sync.Mutex
+	jsImportPattern   = regexp.MustCompile(`(?m)(?:\bfrom\s*|\bimport\s*\(?\s*|\brequire\s*\(\s*)['"]([^'"\n]+)['"]`)
+	pyFromPattern     = regexp.MustCompile(`(?m)^\s*from\s+([.\w]+)\s+import\s+(\([^)]*\)|[\w\t ,]*)`)
+	pyImportPattern   = regexp.MustCompile(`(?m)^\s*import\s+([\w.]+(?:\s*,\s*[\w.]+)*)`)
+	jsImportSuffixes  = []string{"", ".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs", "/index.ts", "/index.tsx", "/index.js", "/index.jsx"}
+	importGraphSuffix = map[string]bool{".js": true, ".jsx": true, ".ts": true, ".tsx": true, ".mjs": true, ".cjs": true, ".py": true}
+)
+
+type goGraphCacheEntry struct {
+	fingerprint uint64
+	graph       *importGraph
+}
+
+// fileImportsCacheEntry holds the import specifiers parsed from a file, so
+// only files that changed are parsed again.
+type fileImportsCacheEntry struct {
+	size    int64
+	modTime time.Time
+	specs   []string
+}
+
+// loadImportGraph returns the import graph of the project at root, using go
+// list for Go modules and import parsing otherwise.
+func loadImportGraph(ctx context.Context, root string) (*importGraph, error) {
+	if _, err := os.Stat(filepath.Join(root, "go.mod")); err == nil {
+		if _, err := exec.LookPath("go"); err == nil {
+			return loadGoImportGraph(ctx, root)
+		}
+	}
+	return loadFileImportGraph(root)
+}
+
+func loadGoImportGraph(ctx context.Context, root string) (*importGraph, error) {
+	fingerprint, err := goSourcesFingerprint(root)
+	if err != nil {
+		return nil, fmt.Errorf("error scanning Go sources: %w", err)
+	}
+
+	importGraphMu.Lock()
+	defer importGraphMu.Unlock()
+	if cached, ok := goGraphCache[root]; ok && cached.fingerprint == fingerprint {
+		return cached.graph, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, goListTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "go", "list", "-e", "-json=ImportPath,Dir,Imports,TestImports,XTestImports,Module", "./...")
+	cmd.Dir = root
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error running go list: %w", err)
+	}
+
+	type goPackage struct {
+		ImportPath   string
+		Dir          string
+		Imports      []string
+		TestImports  []string
+		XTestImports []string
+		Module       *struct{ Path string }
+	}
+	var packages []goPackage
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for {
+		var pkg goPackage
+		if err := decoder.Decode(&pkg); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("error parsing go list output: %w", err)
+		}
+		packages = append(packages, pkg)
+	}
+
+	graph := &importGraph{
+		kind:     "go",
+		root:     root,
+		imports:  make(map[string][]string, len(packages)),
+		external: make(map[string][]string, len(packages)),
+		dirs:     make(map[string]string, len(packages)),
+	}
+	for _, pkg := range packages {
+		graph.imports[pkg.ImportPath] = nil
+		graph.dirs[pkg.Dir] = pkg.ImportPath
+		if pkg.Module != nil && graph.prefix == "" {
+			graph.prefix = pkg.Module.Path + "/"
+		}
+	}
+	for _, pkg := range packages {
+		internal, external := map[string]bool{}, map[string]bool{}
+		for _, imp := range append(append(pkg.Imports, pkg.TestImports...), pkg.XTestImports...) {
+			if imp == pkg.ImportPath {
+				continue
+			}
+			if _, ok := graph.imports[imp]; ok {
+				internal[imp] = true
+			} else {
+				external[imp] = true
+			}
+		}
+		graph.imports[pkg.ImportPath] = sortedKeys(internal)
+		graph.external[pkg.ImportPath] = sortedKeys(external)
+	}
+
+	goGraphCache[root] = goGraphCacheEntry{fingerprint: fingerprint, graph: graph}
+	return graph, nil
+}
+
+func loadFileImportGraph(root string) (*importGraph, error) {
+	importGraphMu.Lock()
+	defer importGraphMu.Unlock()
+
+	files := make(map[string][]string)
+	err := filepath.WalkDir(root, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, relErr := filepath.Rel(root, filePath)
+		if relErr != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if rel != "." && fileutil.SkipHidden(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !importGraphSuffix[filepath.Ext(filePath)] {
+			return nil
+		}
+		if len(files) >= maxImportGraphFiles {
+			return fs.SkipAll
+		}
+		info, err := d.Info()
+		if err != nil || info.Size() > maxImportGraphFileSize {
+			return nil
+		}
+
+		cached, ok := fileImportsCache[filePath]
+		if !ok || cached.size != info.Size() || !cached.modTime.Equal(info.ModTime()) {
+			content, err := os.ReadFile(filePath)
+			if err != nil {
+				return nil
+			}
+			cached = fileImportsCacheEntry{
+				size:    info.Size(),
+				modTime: info.ModTime(),
+				specs:   parseImportSpecs(filePath, string(content)),
+			}
+			fileImportsCache[filePath] = cached
+		}
+		files[rel] = cached.specs
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error scanning files: %w", err)
+	}
+
+	graph := &importGraph{
+		kind:     "files",
+		root:     root,
+		imports:  make(map[string][]string, len(files)),
+		external: make(map[string][]string, len(files)),
+	}
+	for rel, specs := range files {
+		internal, external := map[string]bool{}, map[string]bool{}
+		for _, spec := range specs {
+			if target, ok := resolveImportSpec(files, rel, spec); ok {
+				if target != rel {
+					internal[target] = true
+				}
+			} else {
+				external[spec] = true
+			}
+		}
+		graph.imports[rel] = sortedKeys(internal)
+		graph.external[rel] = sortedKeys(external)
+	}
+	return graph, nil
+}
+
+// parseImportSpecs extracts the import specifiers of a JavaScript,
+// TypeScript or Python file.
+func parseImportSpecs(filePath, content string) []string {
+	var specs []string
+	if filepath.Ext(filePath) == ".py" {
+		for _, match := range pyFromPattern.FindAllStringSubmatch(content, -1) {
+			if strings.Trim(match[1], ".") != "" {
+				specs = append(specs, match[1])
+				continue
+			}
+			// "from . import name" imports sibling modules.
+			for name := range strings.SplitSeq(strings.Trim(match[2], "()"), ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					specs = append(specs, match[1]+strings.Fields(name)[0])
+				}
+			}
+		}
+		for _, match := range pyImportPattern.FindAllStringSubmatch(content, -1) {
+			for module := range strings.SplitSeq(match[1], ",") {
+				specs = append(specs, strings.TrimSpace(module))
+			}
+		}
+		return specs
+	}
+	for _, match := range jsImportPattern.FindAllStringSubmatch(content, -1) {
+		specs = append(specs, match[1])
+	}
+	return specs
+}
+
+// resolveImportSpec maps an import specifier of the file from to a file of
+// the project, if it refers to one.
+func resolveImportSpec(files map[string][]string, from, spec string) (string, bool) {
+	exists := func(candidate string) bool {
+		_, ok := files[candidate]
+		return ok
+	}
+
+	if filepath.Ext(from) == ".py" {
+		dots := len(spec) - len(strings.TrimLeft(spec, "."))
+		base := ""
+		if dots > 0 {
+			base = path.Dir(from)
+			for range dots - 1 {
+				base = path.Dir(base)
+			}
+		}
+		module := strings.ReplaceAll(strings.TrimLeft(spec, "."), ".", "/")
+		for _, candidate := range []string{module + ".py", module + "/__init__.py"} {
+			candidate = path.Clean(path.Join(base, candidate))
+			if exists(candidate) {
+				return candidate, true
+			}
+		}
+		return "", false
+	}
+
+	if !strings.HasPrefix(spec, "./") && !strings.HasPrefix(spec, "../") {
+		return "", false
+	}
+	base := path.Join(path.Dir(from), spec)
+	for _, suffix := range jsImportSuffixes {
+		if candidate := path.Clean(base + suffix); exists(candidate) {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// resolve finds the node for a target given as an import path, a suffix of
+// one, a directory or a file.
+func (g *importGraph) resolve(target string) (string, bool) {
+	if _, ok := g.imports[target]; ok {
+		return target, true
+	}
+
+	absPath := target
+	if !filepath.IsAbs(absPath) {
+		absPath = filepath.Join(g.root, absPath)
+	}
+	if g.kind == "go" {
+		dir := absPath
+		if info, err := os.Stat(absPath); err == nil && !info.IsDir() {
+			dir = filepath.Dir(absPath)
+		}
+		if node, ok := g.dirs[filepath.Clean(dir)]; ok {
+			return node, true
+		}
+		trimmed := strings.Trim(target, "/")
+		for node := range g.imports {
+			if strings.HasSuffix(node, "/"+trimmed) {
+				return node, true
+			}
+		}
+		return "", false
+	}
+
+	if rel, err := filepath.Rel(g.root, absPath); err == nil {
+		if _, ok := g.imports[filepath.ToSlash(rel)]; ok {
+			return filepath.ToSlash(rel), true
+		}
+	}
+	return "", false
+}
+
+func (g *importGraph) reverse() map[string][]string {
+	reversed := make(map[string][]string, len(g.imports))
+	for node, imports := range g.imports {
+		for _, imp := range imports {
+			reversed[imp] = append(reversed[imp], node)
+		}
+	}
+	for node := range reversed {
+		sort.Strings(reversed[node])
+	}
+	return reversed
+}
+
+func (g *importGraph) edgeCount() int {
+	edges := 0
+	for _, imports := range g.imports {
+		edges += len(imports)
+	}
+	return edges
+}
+
+// display shortens Go import paths by the module path.
+func (g *importGraph) display(node string) string {
+	if node+"/" == g.prefix {
+		return "."
+	}
+	if g.prefix != "" {
+		return strings.TrimPrefix(node, g.prefix)
+	}
+	return node
+}
+
+func (g *importGraph) displayAll(nodes []string) string {
+	names := make([]string, len(nodes))
+	for i, node := range nodes {
+		names[i] = g.display(node)
+	}
+	return strings.Join(names, ", ")
+}
+
+func (g *importGraph) nodeName() string {
+	if g.kind == "go" {
+		return "packages"
+	}
+	return "files"
+}
+
+func (g *importGraph) summary() string {
+	var lines []string
+	if g.kind == "go" {
+		lines = append(lines, fmt.Sprintf("Go package graph: %d packages, %d internal imports", len(g.imports), g.edgeCount()))
+	} else {
+		lines = append(lines, fmt.Sprintf("File import graph: %d files, %d internal imports", len(g.imports), g.edgeCount()))
+	}
+	if g.prefix != "" {
+		lines = append(lines, fmt.Sprintf("Package paths are relative to %s", strings.TrimSuffix(g.prefix, "/")))
+	}
+
+	reversed := g.reverse()
+	mostImported := sortedKeys(reversed)
+	sort.Slice(mostImported, func(i, j int) bool {
+		a, b := mostImported[i], mostImported[j]
+		if len(reversed[a]) != len(reversed[b]) {
+			return len(reversed[a]) > len(reversed[b])
+		}
+		return a < b
+	})
+	if len(mostImported) > 0 {
+		lines = append(lines, "", "Most imported:")
+		for _, node := range mostImported[:min(10, len(mostImported))] {
+			lines = append(lines, fmt.Sprintf("  %s (%d dependents)", g.display(node), len(reversed[node])))
+		}
+	}
+
+	lines = append(lines, "", fmt.Sprintf("Internal imports (%s -> imported %s):", strings.TrimSuffix(g.nodeName(), "s"), g.nodeName()))
+	for _, node := range sortedKeys(g.imports) {
+		if imports := g.imports[node]; len(imports) > 0 {
+			lines = append(lines, fmt.Sprintf("  %s -> %s", g.display(node), g.displayAll(imports)))
+		}
+	}
+	return truncateLines(ImportGraphToolName, strings.Join(lines, "\n"), maxImportGraphLines)
+}
+
+func (g *importGraph) describe(node, direction string, transitive bool, results []string) string {
+	scope := "direct"
+	if transitive {
+		scope = "transitive"
+	}
+	var lines []string
+	if direction == "dependents" {
+		lines = append(lines, fmt.Sprintf("%s is imported by %d %s (%s):", g.display(node), len(results), g.nodeName(), scope))
+	} else {
+		lines = append(lines, fmt.Sprintf("%s imports %d project %s (%s):", g.display(node), len(results), g.nodeName(), scope))
+	}
+	for _, result := range results {
+		lines = append(lines, "  "+g.display(result))
+	}
+	if direction == "dependencies" && len(g.external[node]) > 0 {
+		lines = append(lines, fmt.Sprintf("External imports (%d): %s", len(g.external[node]), strings.Join(g.external[node], ", ")))
+	}
+	return truncateLines(ImportGraphToolName, strings.Join(lines, "\n"), maxImportGraphLines)
+}
+
+// reachable returns every node reachable from start, sorted.
+func reachable(edges map[string][]string, start string) []string {
+	seen := map[string]bool{start: true}
+	queue := []string{start}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, next := range edges[node] {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	delete(seen, start)
+	return sortedKeys(seen)
+}
+
+func sortedKeys[V any](set map[string]V) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
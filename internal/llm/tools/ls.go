@@ -6,20 +6,27 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/opencode-ai/opencode/internal/config"
 )
 
 type LSParams struct {
-	Path   string   `json:"path"`
-	Ignore []string `json:"ignore"`
+	Path           string   `json:"path"`
+	Ignore         []string `json:"ignore"`
+	IncludeIgnored bool     `json:"include_ignored"`
+	Limit          int      `json:"limit"`
+	Sort           string   `json:"sort"`
+	ShowSizes      bool     `json:"show_sizes"`
 }
 
 type TreeNode struct {
 	Name     string      `json:"name"`
 	Path     string      `json:"path"`
 	Type     string      `json:"type"` // "file" or "directory"
+	Size     int64       `json:"size,omitempty"`
 	Children []*TreeNode `json:"children,omitempty"`
 }
 
@@ -33,6 +40,10 @@ type lsTool struct{}
 const (
 	LSToolName    = "ls"
 	MaxLSFiles    = 1000
+	maxLSLimit    = 5000
+	lsSortName    = "name"
+	lsSortMtime   = "mtime"
+	lsSortSize    = "size"
 	lsDescription = `Directory listing tool that shows files and subdirectories in a tree structure, helping you explore and understand the project organization.
 
 WHEN TO USE THIS TOOL:
@@ -42,19 +53,24 @@ WHEN TO USE THIS TOOL:
 
 HOW TO USE:
 - Provide a path to list (defaults to current working directory)
-- Optionally specify glob patterns to ignore
-- Results are displayed in a tree structure
+- Optionally specify glob patterns to ignore; they match a file's name or its path relative to the working directory, e.g. "*.log", "vendor/**" or "*/testdata"
+- Set include_ignored to also list files ignored by the project's .gitignore files or the configured ignore patterns
+- Optionally set limit to list fewer or more entries (default 1000, max 5000)
+- Optionally set sort to "name" (default), "mtime" (newest first) or "size" (largest first)
+- Set show_sizes to show the size of each file
+- Results are displayed in a tree structure, directories before files
 
 FEATURES:
 - Displays a hierarchical view of files and directories
 - Automatically skips hidden files/directories (starting with '.')
 - Skips common system directories like __pycache__
-- Can filter out files matching specific patterns
+- Skips files ignored by the project's .gitignore files or the configured ignore patterns
+- Can filter out files matching specific patterns, with ** for any number of directories
 
 LIMITATIONS:
-- Results are limited to 1000 files
-- Very large directories will be truncated
-- Does not show file sizes or permissions
+- Results are limited to 1000 files unless a higher limit is set
+- Very large directories will be truncated; sorting only orders the entries that are listed
+- Does not show permissions
 - Cannot recursively list all directories in a large project
 
 TIPS:
@@ -78,11 +94,28 @@ func (l *lsTool) Info() ToolInfo {
 			},
 			"ignore": map[string]any{
 				"type":        "array",
-				"description": "List of glob patterns to ignore",
+				"description": "List of glob patterns to ignore, matched against names and paths relative to the working directory",
 				"items": map[string]any{
 					"type": "string",
 				},
 			},
+			"limit": map[string]any{
+				"type":        "number",
+				"description": "The maximum number of files and directories to list (default 1000, max 5000)",
+			},
+			"sort": map[string]any{
+				"type":        "string",
+				"description": "The order of the entries of each directory: name (default), mtime (newest first) or size (largest first)",
+				"enum":        []string{lsSortName, lsSortMtime, lsSortSize},
+			},
+			"show_sizes": map[string]any{
+				"type":        "boolean",
+				"description": "Show the size of each file (default false)",
+			},
+			"include_ignored": map[string]any{
+				"type":        "boolean",
+				"description": "List files ignored by the project's .gitignore files or the configured ignore patterns too (default false)",
+			},
 		},
 		Required: []string{"path"},
 	}
@@ -107,16 +140,43 @@ func (l *lsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 		return NewTextErrorResponse(fmt.Sprintf("path does not exist: %s", searchPath)), nil
 	}
 
-	files, truncated, err := listDirectory(searchPath, params.Ignore, MaxLSFiles)
+	switch params.Sort {
+	case "":
+		params.Sort = lsSortName
+	case lsSortName, lsSortMtime, lsSortSize:
+	default:
+		return NewTextErrorResponse(fmt.Sprintf("invalid sort %q, use name, mtime or size", params.Sort)), nil
+	}
+	limit := params.Limit
+	if limit <= 0 {
+		limit = MaxLSFiles
+	}
+	limit = min(limit, maxLSLimit)
+
+	rootDir := config.WorkingDirectory()
+	var ignored *ignoreMatcher
+	if !params.IncludeIgnored {
+		ignored = getIgnoreMatcher(rootDir)
+	}
+	files, truncated, err := listDirectory(searchPath, rootDir, params.Ignore, ignored, limit)
 	if err != nil {
 		return ToolResponse{}, fmt.Errorf("error listing directory: %w", err)
 	}
 
-	tree := createFileTree(files)
-	output := printTree(tree, searchPath)
+	infos := statPaths(files)
+	sortPaths(files, params.Sort, infos)
+	tree, nodes := buildFileTree(files)
+	if params.ShowSizes {
+		for _, path := range files {
+			if info, ok := infos[strings.TrimSuffix(path, string(filepath.Separator))]; ok && !info.IsDir() {
+				nodes[treeKey(path)].Size = info.Size()
+			}
+		}
+	}
+	output := printTree(tree, searchPath, params.ShowSizes)
 
 	if truncated {
-		output = fmt.Sprintf("There are more than %d files in the directory. Use a more specific path or use the Glob tool to find specific files. The first %d files and directories are included below:\n\n%s", MaxLSFiles, MaxLSFiles, output)
+		output = fmt.Sprintf("There are more than %d files in the directory. Use a more specific path or use the Glob tool to find specific files. The first %d files and directories are included below:\n\n%s", limit, limit, output)
 	}
 
 	return WithResponseMetadata(
@@ -128,7 +188,9 @@ func (l *lsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 	), nil
 }
 
-func listDirectory(initialPath string, ignorePatterns []string, limit int) ([]string, bool, error) {
+// listDirectory walks initialPath, skipping the paths that match
+// ignorePatterns, which are relative to rootDir, or that ignored skips.
+func listDirectory(initialPath, rootDir string, ignorePatterns []string, ignored *ignoreMatcher, limit int) ([]string, bool, error) {
 	var results []string
 	truncated := false
 
@@ -137,7 +199,7 @@ func listDirectory(initialPath string, ignorePatterns []string, limit int) ([]st
 			return nil // Skip files we don't have permission to access
 		}
 
-		if shouldSkip(path, ignorePatterns) {
+		if shouldSkip(path, rootDir, ignorePatterns) || (path != initialPath && ignored.Ignored(path, info.IsDir())) {
 			if info.IsDir() {
 				return filepath.SkipDir
 			}
@@ -165,7 +227,7 @@ func listDirectory(initialPath string, ignorePatterns []string, limit int) ([]st
 	return results, truncated, nil
 }
 
-func shouldSkip(path string, ignorePatterns []string) bool {
+func shouldSkip(path, rootDir string, ignorePatterns []string) bool {
 	base := filepath.Base(path)
 
 	if base != "." && strings.HasPrefix(base, ".") {
@@ -213,17 +275,94 @@ func shouldSkip(path string, ignorePatterns []string) bool {
 		}
 	}
 
+	rel, err := filepath.Rel(rootDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		rel = ""
+	}
+	rel = filepath.ToSlash(rel)
 	for _, pattern := range ignorePatterns {
-		matched, err := filepath.Match(pattern, base)
-		if err == nil && matched {
+		pattern = filepath.ToSlash(pattern)
+		if matched, _ := doublestar.Match(pattern, base); matched {
 			return true
 		}
+		if rel != "" {
+			if matched, _ := doublestar.Match(pattern, rel); matched {
+				return true
+			}
+		}
 	}
 
 	return false
 }
 
+// statPaths returns the file info of the listed paths, keyed by the path
+// without the trailing separator of directories.
+func statPaths(paths []string) map[string]os.FileInfo {
+	infos := make(map[string]os.FileInfo, len(paths))
+	for _, path := range paths {
+		path = strings.TrimSuffix(path, string(filepath.Separator))
+		if info, err := os.Lstat(path); err == nil {
+			infos[path] = info
+		}
+	}
+	return infos
+}
+
+// sortPaths orders the listed paths so the entries of every directory come
+// directories first, then by mode: by name, newest first or largest first.
+// Ties are broken by name, so the order is always the same.
+func sortPaths(paths []string, mode string, infos map[string]os.FileInfo) {
+	sep := string(filepath.Separator)
+	sort.SliceStable(paths, func(i, j int) bool {
+		a := strings.Split(strings.TrimSuffix(paths[i], sep), sep)
+		b := strings.Split(strings.TrimSuffix(paths[j], sep), sep)
+		n := 0
+		for n < len(a) && n < len(b) && a[n] == b[n] {
+			n++
+		}
+		if n == len(a) || n == len(b) {
+			// A directory comes before its entries.
+			return len(a) < len(b)
+		}
+		infoA, infoB := infos[strings.Join(a[:n+1], sep)], infos[strings.Join(b[:n+1], sep)]
+		if infoA != nil && infoB != nil {
+			if infoA.IsDir() != infoB.IsDir() {
+				return infoA.IsDir()
+			}
+			switch mode {
+			case lsSortMtime:
+				if !infoA.ModTime().Equal(infoB.ModTime()) {
+					return infoA.ModTime().After(infoB.ModTime())
+				}
+			case lsSortSize:
+				if !infoA.IsDir() && infoA.Size() != infoB.Size() {
+					return infoA.Size() > infoB.Size()
+				}
+			}
+		}
+		return a[n] < b[n]
+	})
+}
+
 func createFileTree(sortedPaths []string) []*TreeNode {
+	root, _ := buildFileTree(sortedPaths)
+	return root
+}
+
+// treeKey returns the key of the node of path in the map of buildFileTree.
+func treeKey(path string) string {
+	var parts []string
+	for _, part := range strings.Split(path, string(filepath.Separator)) {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return filepath.Join(parts...)
+}
+
+// buildFileTree returns the tree of the paths, in their order, and its nodes
+// keyed by treeKey.
+func buildFileTree(sortedPaths []string) ([]*TreeNode, map[string]*TreeNode) {
 	root := []*TreeNode{}
 	pathMap := make(map[string]*TreeNode)
 
@@ -283,34 +422,47 @@ func createFileTree(sortedPaths []string) []*TreeNode {
 		}
 	}
 
-	return root
+	return root, pathMap
 }
 
-func printTree(tree []*TreeNode, rootPath string) string {
+func printTree(tree []*TreeNode, rootPath string, showSizes bool) string {
 	var result strings.Builder
 
 	result.WriteString(fmt.Sprintf("- %s%s\n", rootPath, string(filepath.Separator)))
 
 	for _, node := range tree {
-		printNode(&result, node, 1)
+		printNode(&result, node, 1, showSizes)
 	}
 
 	return result.String()
 }
 
-func printNode(builder *strings.Builder, node *TreeNode, level int) {
+func printNode(builder *strings.Builder, node *TreeNode, level int, showSizes bool) {
 	indent := strings.Repeat("  ", level)
 
 	nodeName := node.Name
 	if node.Type == "directory" {
 		nodeName += string(filepath.Separator)
+	} else if showSizes {
+		nodeName += fmt.Sprintf(" (%s)", formatFileSize(node.Size))
 	}
 
 	fmt.Fprintf(builder, "%s- %s\n", indent, nodeName)
 
 	if node.Type == "directory" && len(node.Children) > 0 {
 		for _, child := range node.Children {
-			printNode(builder, child, level+1)
+			printNode(builder, child, level+1, showSizes)
 		}
 	}
 }
+
+func formatFileSize(bytes int64) string {
+	switch {
+	case bytes >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1024*1024))
+	case bytes >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(bytes)/1024)
+	default:
+		return fmt.Sprintf("%d B", bytes)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -272,11 +273,29 @@ func TestShouldSkip(t *testing.T) {
 			ignorePatterns: []string{"ignore_*.txt"},
 			expected:       false,
 		},
+		{
+			name:           "ignored by path pattern",
+			path:           "/path/vendor/lib/lib.go",
+			ignorePatterns: []string{"vendor/**"},
+			expected:       true,
+		},
+		{
+			name:           "ignored by nested directory pattern",
+			path:           "/path/pkg/testdata",
+			ignorePatterns: []string{"*/testdata"},
+			expected:       true,
+		},
+		{
+			name:           "path pattern relative to the root",
+			path:           "/path/to/vendor/lib.go",
+			ignorePatterns: []string{"vendor/**"},
+			expected:       false,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result := shouldSkip(tc.path, tc.ignorePatterns)
+			result := shouldSkip(tc.path, "/path", tc.ignorePatterns)
 			assert.Equal(t, tc.expected, result)
 		})
 	}
@@ -355,7 +374,7 @@ func TestPrintTree(t *testing.T) {
 		},
 	}
 	
-	result := printTree(tree, "/root")
+	result := printTree(tree, "/root", false)
 	
 	// Check the output format
 	assert.Contains(t, result, "- /root/")
@@ -402,7 +421,7 @@ func TestListDirectory(t *testing.T) {
 	}
 
 	t.Run("lists files with no limit", func(t *testing.T) {
-		files, truncated, err := listDirectory(tempDir, []string{}, 1000)
+		files, truncated, err := listDirectory(tempDir, tempDir, []string{}, nil, 1000)
 		require.NoError(t, err)
 		assert.False(t, truncated)
 		
@@ -428,14 +447,14 @@ func TestListDirectory(t *testing.T) {
 	})
 
 	t.Run("respects limit and returns truncated flag", func(t *testing.T) {
-		files, truncated, err := listDirectory(tempDir, []string{}, 2)
+		files, truncated, err := listDirectory(tempDir, tempDir, []string{}, nil, 2)
 		require.NoError(t, err)
 		assert.True(t, truncated)
 		assert.Len(t, files, 2)
 	})
 
 	t.Run("respects ignore patterns", func(t *testing.T) {
-		files, truncated, err := listDirectory(tempDir, []string{"*.txt"}, 1000)
+		files, truncated, err := listDirectory(tempDir, tempDir, []string{"*.txt"}, nil, 1000)
 		require.NoError(t, err)
 		assert.False(t, truncated)
 		
@@ -454,4 +473,74 @@ func TestListDirectory(t *testing.T) {
 		}
 		assert.True(t, containsDir)
 	})
-}
\ No newline at end of file
+
+	t.Run("prunes nested node_modules directories", func(t *testing.T) {
+		nested := filepath.Join(tempDir, "dir1", "subdir1", "app", "node_modules", "pkg")
+		require.NoError(t, os.MkdirAll(nested, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(nested, "index.js"), []byte("test content"), 0644))
+		defer os.RemoveAll(filepath.Join(tempDir, "dir1", "subdir1", "app"))
+
+		files, _, err := listDirectory(tempDir, tempDir, []string{}, nil, 1000)
+		require.NoError(t, err)
+		assert.Contains(t, files, filepath.Join(tempDir, "dir1", "subdir1", "app")+string(filepath.Separator))
+		for _, file := range files {
+			assert.NotContains(t, file, "node_modules")
+		}
+	})
+
+	t.Run("honors gitignore rules", func(t *testing.T) {
+		gitignorePath := filepath.Join(tempDir, ".gitignore")
+		require.NoError(t, os.WriteFile(gitignorePath, []byte("*.txt\n!file1.txt\nsubdir1/\n"), 0644))
+		defer os.Remove(gitignorePath)
+
+		files, _, err := listDirectory(tempDir, tempDir, []string{}, newIgnoreMatcher(tempDir, nil), 1000)
+		require.NoError(t, err)
+		assert.Contains(t, files, filepath.Join(tempDir, "file1.txt"))
+		assert.NotContains(t, files, filepath.Join(tempDir, "file2.txt"))
+		assert.NotContains(t, files, filepath.Join(tempDir, "dir1", "file3.txt"))
+		assert.NotContains(t, files, filepath.Join(tempDir, "dir1", "subdir1")+string(filepath.Separator))
+	})
+}
+
+func TestSortPaths(t *testing.T) {
+	tempDir := t.TempDir()
+	now := time.Now()
+	files := []struct {
+		path    string
+		size    int
+		modTime time.Time
+	}{
+		{"b.txt", 10, now.Add(-time.Hour)},
+		{"a.txt", 30, now.Add(-2 * time.Hour)},
+		{"c.txt", 20, now},
+		{"zdir/d.txt", 1, now},
+	}
+	for _, f := range files {
+		path := filepath.Join(tempDir, f.path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(strings.Repeat("x", f.size)), 0644))
+		require.NoError(t, os.Chtimes(path, f.modTime, f.modTime))
+	}
+
+	tests := []struct {
+		mode string
+		want []string
+	}{
+		{lsSortName, []string{"zdir/", "zdir/d.txt", "a.txt", "b.txt", "c.txt"}},
+		{lsSortMtime, []string{"zdir/", "zdir/d.txt", "c.txt", "b.txt", "a.txt"}},
+		{lsSortSize, []string{"zdir/", "zdir/d.txt", "a.txt", "c.txt", "b.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			paths, _, err := listDirectory(tempDir, tempDir, []string{}, nil, 1000)
+			require.NoError(t, err)
+			sortPaths(paths, tt.mode, statPaths(paths))
+
+			got := make([]string, len(paths))
+			for i, path := range paths {
+				got[i] = strings.TrimPrefix(filepath.ToSlash(path), filepath.ToSlash(tempDir)+"/")
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/memory"
+)
+
+type MemoryParams struct {
+	Operation string `json:"operation"`
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+}
+
+type MemoryResponseMetadata struct {
+	Operation string `json:"operation"`
+	Key       string `json:"key,omitempty"`
+	Count     int    `json:"count"`
+}
+
+type memoryTool struct {
+	memory memory.Service
+}
+
+const (
+	MemoryToolName    = "memory"
+	memoryDescription = `Session scratchpad for notes you want to keep across turns, such as decisions, user preferences and open TODOs.
+
+WHEN TO USE THIS TOOL:
+- Use when you learn something you will need later in a long task (e.g. "user prefers tabs")
+- Helpful for tracking follow-ups (e.g. "TODO: update tests for the parser")
+- Use to recall notes after older messages have been summarized away
+
+HOW TO USE:
+- operation "set": store a value under a key, replacing any previous value
+- operation "get": read the value stored under a key
+- operation "list": list every note of the current session
+- operation "delete": remove a note that is no longer relevant
+
+FEATURES:
+- Notes are stored per session and survive conversation compaction
+- The current notes are included with every user message in a compact form
+
+LIMITATIONS:
+- Notes are plain text; keep them short and to the point
+- Notes are not shared between sessions
+- Keys starting with "_" are reserved, the plan of the plan tool is not a note
+
+TIPS:
+- Use short, descriptive keys so notes are easy to update later
+- Delete notes once the corresponding work is done to keep the list focused`
+)
+
+func NewMemoryTool(memory memory.Service) BaseTool {
+	return &memoryTool{
+		memory: memory,
+	}
+}
+
+func (m *memoryTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        MemoryToolName,
+		Description: memoryDescription,
+		Parameters: map[string]any{
+			"operation": map[string]any{
+				"type":        "string",
+				"description": "The operation to perform",
+				"enum":        []string{"set", "get", "list", "delete"},
+			},
+			"key": map[string]any{
+				"type":        "string",
+				"description": "The key of the note (required for set, get and delete)",
+			},
+			"value": map[string]any{
+				"type":        "string",
+				"description": "The value to store (required for set)",
+			},
+		},
+		Required: []string{"operation"},
+	}
+}
+
+func (m *memoryTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params MemoryParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+
+	sessionID, _ := GetContextValues(ctx)
+	if sessionID == "" {
+		return ToolResponse{}, fmt.Errorf("session_id is required")
+	}
+
+	key := strings.TrimSpace(params.Key)
+	if params.Operation != "list" && key == "" {
+		return NewTextErrorResponse(fmt.Sprintf("key is required for the %s operation", params.Operation)), nil
+	}
+	if memory.IsReserved(key) {
+		return NewTextErrorResponse(fmt.Sprintf("keys starting with %q are reserved, choose another key", memory.ReservedPrefix)), nil
+	}
+
+	metadata := MemoryResponseMetadata{
+		Operation: params.Operation,
+		Key:       key,
+	}
+
+	switch params.Operation {
+	case "set":
+		if params.Value == "" {
+			return NewTextErrorResponse("value is required for the set operation"), nil
+		}
+		if _, err := m.memory.Set(ctx, sessionID, key, params.Value); err != nil {
+			return ToolResponse{}, fmt.Errorf("error saving note: %w", err)
+		}
+		metadata.Count = 1
+		return WithResponseMetadata(NewTextResponse(fmt.Sprintf("Saved note %q", key)), metadata), nil
+	case "get":
+		entry, err := m.memory.Get(ctx, sessionID, key)
+		if errors.Is(err, sql.ErrNoRows) {
+			return NewTextErrorResponse(fmt.Sprintf("No note found for key %q", key)), nil
+		}
+		if err != nil {
+			return ToolResponse{}, fmt.Errorf("error reading note: %w", err)
+		}
+		metadata.Count = 1
+		return WithResponseMetadata(NewTextResponse(entry.Value), metadata), nil
+	case "list":
+		all, err := m.memory.List(ctx, sessionID)
+		if err != nil {
+			return ToolResponse{}, fmt.Errorf("error listing notes: %w", err)
+		}
+		entries := make([]memory.Entry, 0, len(all))
+		for _, entry := range all {
+			if !memory.IsReserved(entry.Key) {
+				entries = append(entries, entry)
+			}
+		}
+		metadata.Count = len(entries)
+		if len(entries) == 0 {
+			return WithResponseMetadata(NewTextResponse("No notes saved for this session"), metadata), nil
+		}
+		return WithResponseMetadata(NewTextResponse(memory.Format(entries)), metadata), nil
+	case "delete":
+		err := m.memory.Delete(ctx, sessionID, key)
+		if errors.Is(err, sql.ErrNoRows) {
+			return NewTextErrorResponse(fmt.Sprintf("No note found for key %q", key)), nil
+		}
+		if err != nil {
+			return ToolResponse{}, fmt.Errorf("error deleting note: %w", err)
+		}
+		return WithResponseMetadata(NewTextResponse(fmt.Sprintf("Deleted note %q", key)), metadata), nil
+	default:
+		return NewTextErrorResponse(fmt.Sprintf("unknown operation %q, expected one of set, get, list or delete", params.Operation)), nil
+	}
+}
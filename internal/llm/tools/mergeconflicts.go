@@ -0,0 +1,324 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/fileutil"
+)
+
+type MergeConflictsParams struct {
+	Path      string `json:"path"`
+	ShowSides bool   `json:"show_sides"`
+}
+
+type MergeConflictsResponseMetadata struct {
+	Files     int  `json:"files"`
+	Conflicts int  `json:"conflicts"`
+	Truncated bool `json:"truncated"`
+}
+
+type mergeConflictsTool struct{}
+
+const (
+	MergeConflictsToolName        = "merge_conflicts"
+	maxMergeConflictFileSize      = 1024 * 1024 // 1MB
+	maxMergeConflictsReported     = 50
+	maxMergeConflictSideLines     = 100
+	conflictMarkerLength          = 7
+	mergeConflictsToolDescription = `Finds unresolved merge conflict markers (<<<<<<<, =======, >>>>>>>) in a file or directory and reports where each conflict is.
+
+WHEN TO USE THIS TOOL:
+- Use after a merge, rebase or stash pop to find every conflict left to resolve
+- Use to check that no conflict markers remain once you are done
+
+HOW TO USE:
+- Optionally provide a file or directory (path); defaults to the current working directory
+- Set show_sides to true to get both sides of each conflict with line numbers
+
+FEATURES:
+- Reports the line range and branch labels of every conflict
+- Understands diff3-style conflicts with a common ancestor section (|||||||)
+- Reports conflicts whose closing marker is missing
+- With show_sides, files whose conflicts are shown in full are marked as read so they can be resolved with the Edit tool right away
+
+LIMITATIONS:
+- Hidden files and common generated or dependency directories are skipped
+- Binary files and files over 1MB are skipped
+- At most 50 conflicts are reported per call, and long sides are shortened
+
+TIPS:
+- Resolve a conflict with the Edit tool by replacing the whole block, from the <<<<<<< line to the >>>>>>> line, with the merged code
+- Run the tool again without show_sides to confirm every conflict is resolved`
+)
+
+// mergeConflict is one conflict block in a file. Line numbers are 1-based;
+// base is empty unless the conflict has a common ancestor section.
+type mergeConflict struct {
+	startLine   int
+	endLine     int
+	oursLabel   string
+	theirsLabel string
+	ours        []string
+	base        []string
+	theirs      []string
+	hasBase     bool
+	terminated  bool
+}
+
+func NewMergeConflictsTool() BaseTool {
+	return &mergeConflictsTool{}
+}
+
+func (m *mergeConflictsTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        MergeConflictsToolName,
+		Description: mergeConflictsToolDescription,
+		Parameters: map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "The file or directory to scan. Defaults to the current working directory.",
+			},
+			"show_sides": map[string]any{
+				"type":        "boolean",
+				"description": "Include the content of both sides of each conflict",
+			},
+		},
+		Required: []string{},
+	}
+}
+
+func (m *mergeConflictsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params MergeConflictsParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		}
+	}
+
+	root := params.Path
+	if root == "" {
+		root = config.WorkingDirectory()
+	} else if !filepath.IsAbs(root) {
+		root = filepath.Join(config.WorkingDirectory(), root)
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewTextErrorResponse(fmt.Sprintf("path not found: %s", root)), nil
+		}
+		return ToolResponse{}, fmt.Errorf("failed to access path: %w", err)
+	}
+
+	var files []string
+	if info.IsDir() {
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if rel, relErr := filepath.Rel(root, path); relErr == nil && rel != "." && fileutil.SkipHidden(filepath.ToSlash(rel)) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.Type().IsRegular() {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return ToolResponse{}, fmt.Errorf("error scanning files: %w", err)
+		}
+	} else {
+		files = []string{root}
+	}
+
+	var output strings.Builder
+	metadata := MergeConflictsResponseMetadata{}
+	for _, path := range files {
+		if ctx.Err() != nil {
+			return ToolResponse{}, ctx.Err()
+		}
+		conflicts, err := scanMergeConflicts(path)
+		if err != nil || len(conflicts) == 0 {
+			continue
+		}
+		if metadata.Conflicts >= maxMergeConflictsReported {
+			metadata.Truncated = true
+			break
+		}
+		// The file only counts as read when every conflict in it is shown
+		// whole, otherwise an edit could be based on sides the model never saw.
+		shownInFull := true
+		if len(conflicts) > maxMergeConflictsReported-metadata.Conflicts {
+			conflicts = conflicts[:maxMergeConflictsReported-metadata.Conflicts]
+			metadata.Truncated = true
+			shownInFull = false
+		}
+		metadata.Files++
+		metadata.Conflicts += len(conflicts)
+
+		fmt.Fprintf(&output, "%s:\n", path)
+		for _, conflict := range conflicts {
+			output.WriteString(conflict.describe(params.ShowSides))
+			shownInFull = shownInFull && !conflict.shortened()
+		}
+		output.WriteString("\n")
+		if params.ShowSides && shownInFull {
+			recordFileRead(ctx, path)
+		}
+	}
+
+	if metadata.Conflicts == 0 {
+		return WithResponseMetadata(NewTextResponse("No merge conflict markers found"), metadata), nil
+	}
+
+	result := fmt.Sprintf("Found %d conflicts in %d files:\n\n%s", metadata.Conflicts, metadata.Files, strings.TrimRight(output.String(), "\n"))
+	if metadata.Truncated {
+		result += fmt.Sprintf("\n\n(Results are truncated to %d conflicts. Resolve these first or scan a narrower path.)", maxMergeConflictsReported)
+	}
+	return WithResponseMetadata(NewTextResponse(result), metadata), nil
+}
+
+// scanMergeConflicts returns the conflict blocks in a text file.
+func scanMergeConflicts(path string) ([]mergeConflict, error) {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > maxMergeConflictFileSize {
+		return nil, err
+	}
+	content, err := readFileWithRetry(path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.IndexByte(content[:min(len(content), 8000)], 0) >= 0 || !bytes.Contains(content, []byte("<<<<<<<")) {
+		return nil, nil
+	}
+	return parseMergeConflicts(string(content)), nil
+}
+
+// parseMergeConflicts finds conflict blocks in content. A block that is not
+// closed before the end of the content is returned unterminated.
+func parseMergeConflicts(content string) []mergeConflict {
+	const (
+		outside = iota
+		inOurs
+		inBase
+		inTheirs
+	)
+
+	var conflicts []mergeConflict
+	var current *mergeConflict
+	state := outside
+	lines := strings.Split(strings.TrimSuffix(strings.ReplaceAll(content, "\r\n", "\n"), "\n"), "\n")
+	for i, line := range lines {
+		lineNum := i + 1
+		if label, ok := conflictMarker(line, '<'); ok && state == outside {
+			current = &mergeConflict{startLine: lineNum, oursLabel: label}
+			state = inOurs
+			continue
+		}
+		if state == outside {
+			continue
+		}
+		if _, ok := conflictMarker(line, '|'); ok && state == inOurs {
+			current.hasBase = true
+			state = inBase
+			continue
+		}
+		if label, ok := conflictMarker(line, '='); ok && label == "" && (state == inOurs || state == inBase) {
+			state = inTheirs
+			continue
+		}
+		if label, ok := conflictMarker(line, '>'); ok && state == inTheirs {
+			current.theirsLabel = label
+			current.endLine = lineNum
+			current.terminated = true
+			conflicts = append(conflicts, *current)
+			current = nil
+			state = outside
+			continue
+		}
+		switch state {
+		case inOurs:
+			current.ours = append(current.ours, line)
+		case inBase:
+			current.base = append(current.base, line)
+		case inTheirs:
+			current.theirs = append(current.theirs, line)
+		}
+	}
+	if current != nil {
+		current.endLine = len(lines)
+		conflicts = append(conflicts, *current)
+	}
+	return conflicts
+}
+
+// conflictMarker reports whether line is a conflict marker made of seven
+// marker characters, returning the label that follows it.
+func conflictMarker(line string, marker byte) (string, bool) {
+	line = strings.TrimRight(line, "\r")
+	if len(line) < conflictMarkerLength || strings.Count(line[:conflictMarkerLength], string(marker)) != conflictMarkerLength {
+		return "", false
+	}
+	rest := line[conflictMarkerLength:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+// shortened reports whether describe leaves out lines of a side.
+func (c mergeConflict) shortened() bool {
+	return len(c.ours) > maxMergeConflictSideLines || len(c.base) > maxMergeConflictSideLines || len(c.theirs) > maxMergeConflictSideLines
+}
+
+func (c mergeConflict) describe(showSides bool) string {
+	var sb strings.Builder
+	ours, theirs := c.oursLabel, c.theirsLabel
+	if ours == "" {
+		ours = "ours"
+	}
+	if theirs == "" {
+		theirs = "theirs"
+	}
+	if c.terminated {
+		fmt.Fprintf(&sb, "  lines %d-%d: %s <-> %s\n", c.startLine, c.endLine, ours, theirs)
+	} else {
+		fmt.Fprintf(&sb, "  line %d: %s, conflict is never closed with >>>>>>>\n", c.startLine, ours)
+	}
+	if !showSides {
+		return sb.String()
+	}
+
+	line := c.startLine + 1
+	writeSide := func(name string, lines []string) {
+		fmt.Fprintf(&sb, "    %s (%d lines):\n", name, len(lines))
+		for i, text := range lines {
+			if i == maxMergeConflictSideLines {
+				fmt.Fprintf(&sb, "    ... %d more lines\n", len(lines)-i)
+				break
+			}
+			fmt.Fprintf(&sb, "    %6d|%s\n", line+i, text)
+		}
+		// Skip the side and the marker that ends it.
+		line += len(lines) + 1
+	}
+	writeSide(ours, c.ours)
+	if c.hasBase {
+		writeSide("common ancestor", c.base)
+	}
+	if c.terminated || len(c.theirs) > 0 {
+		writeSide(theirs, c.theirs)
+	}
+	return sb.String()
+}
@@ -0,0 +1,281 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/diff"
+	"github.com/opencode-ai/opencode/internal/history"
+	"github.com/opencode-ai/opencode/internal/lsp"
+	"github.com/opencode-ai/opencode/internal/permission"
+)
+
+type MultiEditOperation struct {
+	OldString  string `json:"old_string"`
+	NewString  string `json:"new_string"`
+	ReplaceAll bool   `json:"replace_all"`
+}
+
+type MultiEditParams struct {
+	FilePath string               `json:"file_path"`
+	Edits    []MultiEditOperation `json:"edits"`
+}
+
+type multiEditTool struct {
+	lspClients  map[string]*lsp.Client
+	permissions permission.Service
+	files       history.Service
+}
+
+const (
+	MultiEditToolName    = "multiedit"
+	multiEditDescription = `Makes several text replacements in a single file in one step. The edits are applied in order, each to the result of the previous ones, and the file is only written if all of them succeed.
+
+WHEN TO USE THIS TOOL:
+- Use instead of the Edit tool when a file needs more than one change
+- Helpful for renaming something throughout a file together with other changes
+- Use to create a new file and refine it in the same call
+
+HOW TO USE:
+- Provide the absolute file_path and a list of edits
+- Each edit has old_string, new_string and optionally replace_all
+- old_string must match the file exactly, including whitespace and indentation, as it is after the earlier edits
+- Without replace_all, old_string must appear exactly once; with replace_all, every occurrence is replaced
+- To create a new file, leave old_string of the first edit empty; its new_string becomes the content
+
+FEATURES:
+- All edits are shown and approved as a single diff
+- If any edit fails, nothing is written and the failing edit is reported
+- Reports diagnostics for the file after the edits
+
+LIMITATIONS:
+- Edits a single file; use the Refactor tool for changes across files
+- The file must have been read with the View tool before it is edited
+
+TIPS:
+- Keep edits in the order they appear in the file so each old_string is easy to verify
+- An earlier edit can change text a later edit looks for; plan the old_strings accordingly`
+)
+
+func NewMultiEditTool(lspClients map[string]*lsp.Client, permissions permission.Service, files history.Service) BaseTool {
+	return &multiEditTool{
+		lspClients:  lspClients,
+		permissions: permissions,
+		files:       files,
+	}
+}
+
+func (m *multiEditTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        MultiEditToolName,
+		Description: multiEditDescription,
+		Parameters: map[string]any{
+			"file_path": map[string]any{
+				"type":        "string",
+				"description": "The absolute path to the file to modify",
+			},
+			"edits": map[string]any{
+				"type":        "array",
+				"description": "The replacements to apply in order",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"old_string": map[string]any{
+							"type":        "string",
+							"description": "The text to replace",
+						},
+						"new_string": map[string]any{
+							"type":        "string",
+							"description": "The text to replace it with",
+						},
+						"replace_all": map[string]any{
+							"type":        "boolean",
+							"description": "Replace every occurrence of old_string (default false)",
+						},
+					},
+					"required": []string{"old_string", "new_string"},
+				},
+			},
+		},
+		Required: []string{"file_path", "edits"},
+	}
+}
+
+func (m *multiEditTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params MultiEditParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+	if params.FilePath == "" {
+		return NewTextErrorResponse("file_path is required"), nil
+	}
+	if len(params.Edits) == 0 {
+		return NewTextErrorResponse("at least one edit is required"), nil
+	}
+	if !filepath.IsAbs(params.FilePath) {
+		params.FilePath = filepath.Join(config.WorkingDirectory(), params.FilePath)
+	}
+	if err := checkWritable(params.FilePath); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	sessionID, messageID := GetContextValues(ctx)
+	if sessionID == "" || messageID == "" {
+		return ToolResponse{}, fmt.Errorf("session ID and message ID are required for editing a file")
+	}
+
+	oldContent, exists, response, err := m.currentContent(ctx, params)
+	if err != nil || response.IsError {
+		return response, err
+	}
+
+	newContent, err := applyMultiEdits(oldContent, params.Edits, !exists)
+	if err != nil {
+		return NewTextErrorResponse(err.Error() + ". No edits were applied"), nil
+	}
+	if exists && newContent == oldContent {
+		return NewTextErrorResponse("the edits do not change the file. No changes made."), nil
+	}
+
+	diff, additions, removals := diff.GenerateDiff(oldContent, newContent, params.FilePath)
+	permissionPath := filepath.Dir(params.FilePath)
+	if rootDir := config.WorkingDirectory(); strings.HasPrefix(params.FilePath, rootDir) {
+		permissionPath = rootDir
+	}
+	description := fmt.Sprintf("Apply %d edits to file %s", len(params.Edits), params.FilePath)
+	if !exists {
+		description = fmt.Sprintf("Create file %s", params.FilePath)
+	}
+	p := m.permissions.Request(
+		permission.CreatePermissionRequest{
+			SessionID:   sessionID,
+			Path:        permissionPath,
+			ToolName:    MultiEditToolName,
+			Action:      "write",
+			Description: description,
+			Params: EditPermissionsParams{
+				FilePath: params.FilePath,
+				Diff:     diff,
+			},
+			LinesChanged: additions + removals,
+		},
+	)
+	if !p {
+		return ToolResponse{}, permission.ErrorPermissionDenied
+	}
+
+	if !exists {
+		if err := os.MkdirAll(filepath.Dir(params.FilePath), 0o755); err != nil {
+			return ToolResponse{}, fmt.Errorf("failed to create parent directories: %w", err)
+		}
+	} else if changedBeforeWrite(params.FilePath, []byte(oldContent)) {
+		return NewTextErrorResponse(contentConflictMessage(params.FilePath)), nil
+	}
+	if err := writeFileWithRetry(params.FilePath, []byte(newContent), 0o644); err != nil {
+		return ToolResponse{}, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	recordRefactorHistory(ctx, m.files, sessionID, &refactorFile{
+		path:       params.FilePath,
+		oldContent: oldContent,
+		newContent: newContent,
+	})
+	recordFileWrite(ctx, params.FilePath)
+	recordFileRead(ctx, params.FilePath)
+
+	result := fmt.Sprintf("Applied %d edits to file: %s", len(params.Edits), params.FilePath)
+	if !exists {
+		result = fmt.Sprintf("File created with %d edits: %s", len(params.Edits), params.FilePath)
+	}
+	waitForLspDiagnostics(ctx, params.FilePath, m.lspClients)
+	text := fmt.Sprintf("<result>\n%s\n</result>\n", result)
+	text += getDiagnostics(params.FilePath, m.lspClients)
+	text += autoBuildCheck(ctx, params.FilePath)
+
+	return WithResponseMetadata(
+		NewTextResponse(text),
+		EditResponseMetadata{
+			Diff:      diff,
+			Additions: additions,
+			Removals:  removals,
+		},
+	), nil
+}
+
+// currentContent reads the file the edits apply to, running the same checks
+// as the Edit tool. A file that does not exist is only accepted when the
+// first edit creates it.
+func (m *multiEditTool) currentContent(ctx context.Context, params MultiEditParams) (string, bool, ToolResponse, error) {
+	fileInfo, err := os.Stat(params.FilePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return "", false, ToolResponse{}, fmt.Errorf("failed to access file: %w", err)
+		}
+		if params.Edits[0].OldString != "" {
+			return "", false, NewTextErrorResponse(fmt.Sprintf("file not found: %s", params.FilePath)), nil
+		}
+		return "", false, ToolResponse{}, nil
+	}
+	if fileInfo.IsDir() {
+		return "", true, NewTextErrorResponse(fmt.Sprintf("path is a directory, not a file: %s", params.FilePath)), nil
+	}
+
+	if !autoReadForEdit(ctx, params.FilePath, fileInfo.Size()) {
+		return "", true, NewTextErrorResponse("you must read the file before editing it. Use the View tool first"), nil
+	}
+	modTime := fileInfo.ModTime()
+	lastRead := getLastReadTime(ctx, params.FilePath)
+	if modTime.After(lastRead) {
+		return "", true, NewTextErrorResponse(
+			fmt.Sprintf("file %s has been modified since it was last read (mod time: %s, last read: %s)",
+				params.FilePath, modTime.Format(time.RFC3339), lastRead.Format(time.RFC3339),
+			)), nil
+	}
+
+	content, err := readFileWithRetry(params.FilePath)
+	if err != nil {
+		return "", true, ToolResponse{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	if contentChangedSinceRead(ctx, params.FilePath, content) {
+		return "", true, NewTextErrorResponse(contentConflictMessage(params.FilePath)), nil
+	}
+	return string(content), true, ToolResponse{}, nil
+}
+
+// applyMultiEdits applies the edits in order to content, validating each
+// against the result of the previous ones. With create, the first edit must
+// have an empty old_string and provides the initial content.
+func applyMultiEdits(content string, edits []MultiEditOperation, create bool) (string, error) {
+	for i, edit := range edits {
+		n := i + 1
+		if edit.OldString == "" {
+			if i == 0 && create {
+				content = edit.NewString
+				continue
+			}
+			return "", fmt.Errorf("edit %d: old_string is empty; only the first edit of a new file may omit it", n)
+		}
+		if edit.OldString == edit.NewString {
+			return "", fmt.Errorf("edit %d: old_string and new_string are the same", n)
+		}
+		count := strings.Count(content, edit.OldString)
+		switch {
+		case count == 0:
+			return "", fmt.Errorf("edit %d: old_string not found in file after the previous edits. Make sure it matches exactly, including whitespace and line breaks", n)
+		case count > 1 && !edit.ReplaceAll:
+			return "", fmt.Errorf("edit %d: old_string appears %d times in the file. Provide more context for a unique match or set replace_all", n, count)
+		}
+		if edit.ReplaceAll {
+			content = strings.ReplaceAll(content, edit.OldString, edit.NewString)
+		} else {
+			content = strings.Replace(content, edit.OldString, edit.NewString, 1)
+		}
+	}
+	return content, nil
+}
@@ -0,0 +1,167 @@
+package tools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+// networkCacheEntry is a cached response of a network tool (fetch and
+// sourcegraph), stored as one JSON file per request.
+type networkCacheEntry struct {
+	Key     string    `json:"key"`
+	Created time.Time `json:"created"`
+	Content string    `json:"content"`
+}
+
+// networkCacheMu serializes writes and size enforcement of the cache.
+var networkCacheMu sync.Mutex
+
+func networkCacheDir() string {
+	return filepath.Join(config.Get().Data.Directory, "cache", "network")
+}
+
+func networkCacheTTL() time.Duration {
+	return time.Duration(config.Get().NetworkCache.TTLMinutes) * time.Minute
+}
+
+func networkCachePath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(networkCacheDir(), hex.EncodeToString(sum[:])+".json")
+}
+
+// getCachedNetworkResponse returns the cached response for key if there is
+// one younger than the configured TTL, along with the time it was stored.
+func getCachedNetworkResponse(key string) (string, time.Time, bool) {
+	ttl := networkCacheTTL()
+	if ttl <= 0 {
+		return "", time.Time{}, false
+	}
+
+	path := networkCachePath(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	var entry networkCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Key != key {
+		return "", time.Time{}, false
+	}
+	if time.Since(entry.Created) > ttl {
+		os.Remove(path)
+		return "", time.Time{}, false
+	}
+	return entry.Content, entry.Created, true
+}
+
+// cacheNetworkResponse stores content for key and evicts the oldest entries
+// when the cache grows over its configured size. Failures are only logged.
+func cacheNetworkResponse(key, content string) {
+	if networkCacheTTL() <= 0 {
+		return
+	}
+
+	data, err := json.Marshal(networkCacheEntry{
+		Key:     key,
+		Created: time.Now(),
+		Content: content,
+	})
+	if err != nil {
+		return
+	}
+
+	networkCacheMu.Lock()
+	defer networkCacheMu.Unlock()
+
+	if err := os.MkdirAll(networkCacheDir(), 0o755); err != nil {
+		logging.Warn("failed to create network cache directory", "error", err)
+		return
+	}
+	if err := os.WriteFile(networkCachePath(key), data, 0o644); err != nil {
+		logging.Warn("failed to write network cache entry", "error", err)
+		return
+	}
+	pruneNetworkCache(int64(config.Get().NetworkCache.MaxSizeMB) * 1024 * 1024)
+}
+
+// pruneNetworkCache removes expired entries, then the oldest ones until the
+// cache fits in maxSize bytes.
+func pruneNetworkCache(maxSize int64) {
+	entries, err := os.ReadDir(networkCacheDir())
+	if err != nil {
+		return
+	}
+
+	type cachedFile struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	files := make([]cachedFile, 0, len(entries))
+	var total int64
+	ttl := networkCacheTTL()
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		path := filepath.Join(networkCacheDir(), entry.Name())
+		if time.Since(info.ModTime()) > ttl {
+			os.Remove(path)
+			continue
+		}
+		files = append(files, cachedFile{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	}
+	if maxSize <= 0 || total <= maxSize {
+		return
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	for _, file := range files {
+		if total <= maxSize {
+			break
+		}
+		if os.Remove(file.path) == nil {
+			total -= file.size
+		}
+	}
+}
+
+// ClearNetworkCache removes every cached fetch and sourcegraph response and
+// returns how many were removed.
+func ClearNetworkCache() (int, error) {
+	networkCacheMu.Lock()
+	defer networkCacheMu.Unlock()
+
+	entries, err := os.ReadDir(networkCacheDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read network cache: %w", err)
+	}
+	removed := 0
+	for _, entry := range entries {
+		if err := os.Remove(filepath.Join(networkCacheDir(), entry.Name())); err == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// cachedNote tells the model a response was served from the cache.
+func cachedNote(created time.Time) string {
+	return fmt.Sprintf("\n\n(Cached response from %s ago)", time.Since(created).Round(time.Second))
+}
@@ -0,0 +1,260 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/diff"
+	"github.com/opencode-ai/opencode/internal/history"
+	"github.com/opencode-ai/opencode/internal/language"
+	"github.com/opencode-ai/opencode/internal/lsp"
+	"github.com/opencode-ai/opencode/internal/lsp/protocol"
+	"github.com/opencode-ai/opencode/internal/lsp/util"
+	"github.com/opencode-ai/opencode/internal/permission"
+)
+
+type OrganizeImportsParams struct {
+	FilePath string `json:"file_path"`
+}
+
+type organizeImportsTool struct {
+	lspClients  map[string]*lsp.Client
+	permissions permission.Service
+	files       history.Service
+}
+
+const (
+	OrganizeImportsToolName    = "organize_imports"
+	organizeImportsTimeout     = 30 * time.Second
+	organizeImportsDescription = `Sorts, groups and prunes the imports of a file, adding the ones that are missing where the tooling can tell.
+
+WHEN TO USE THIS TOOL:
+- Use after an edit that added or removed uses of a package
+- Use to fix "imported and not used" or missing import errors in one step
+- Helpful instead of editing import blocks by hand
+
+HOW TO USE:
+- Provide the file_path of the file to organize
+- The user approves the change with a diff preview, like an edit
+
+FEATURES:
+- Go files are organized with goimports when it is installed
+- Other files, and Go files without goimports, use the organize imports action of the language server
+- Reports when the imports are already organized and nothing needs to change
+- Reports the diagnostics of the file after the change
+
+LIMITATIONS:
+- Needs goimports for Go, or a configured language server that can organize imports
+- Only the given file is changed; one file per call
+- The file must have been read with the View tool first and must not have changed since
+
+TIPS:
+- Call it once after a series of edits rather than after each one
+- Use fix_diagnostics to also remove unused variables and other unused code`
+)
+
+func NewOrganizeImportsTool(lspClients map[string]*lsp.Client, permissions permission.Service, files history.Service) BaseTool {
+	return &organizeImportsTool{
+		lspClients:  lspClients,
+		permissions: permissions,
+		files:       files,
+	}
+}
+
+func (o *organizeImportsTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        OrganizeImportsToolName,
+		Description: organizeImportsDescription,
+		Parameters: map[string]any{
+			"file_path": map[string]any{
+				"type":        "string",
+				"description": "The absolute path to the file to organize",
+			},
+		},
+		Required: []string{"file_path"},
+	}
+}
+
+func (o *organizeImportsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params OrganizeImportsParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+	if params.FilePath == "" {
+		return NewTextErrorResponse("file_path is required"), nil
+	}
+	filePath := params.FilePath
+	if !filepath.IsAbs(filePath) {
+		filePath = filepath.Join(config.WorkingDirectory(), filePath)
+	}
+	if err := checkWritable(filePath); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	sessionID, messageID := GetContextValues(ctx)
+	if sessionID == "" || messageID == "" {
+		return ToolResponse{}, fmt.Errorf("session ID and message ID are required for organizing imports")
+	}
+
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewTextErrorResponse(fmt.Sprintf("file not found: %s", filePath)), nil
+		}
+		return ToolResponse{}, fmt.Errorf("failed to access file: %w", err)
+	}
+	if fileInfo.IsDir() {
+		return NewTextErrorResponse(fmt.Sprintf("path is a directory, not a file: %s", filePath)), nil
+	}
+	if !autoReadForEdit(ctx, filePath, fileInfo.Size()) {
+		return NewTextErrorResponse("you must read the file before organizing its imports. Use the View tool first"), nil
+	}
+	lastRead := getLastReadTime(ctx, filePath)
+	if modTime := fileInfo.ModTime(); modTime.After(lastRead) {
+		return NewTextErrorResponse(fmt.Sprintf("file %s has been modified since it was last read (mod time: %s, last read: %s)",
+			filePath, modTime.Format(time.RFC3339), lastRead.Format(time.RFC3339))), nil
+	}
+	content, err := readFileWithRetry(filePath)
+	if err != nil {
+		return ToolResponse{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	if contentChangedSinceRead(ctx, filePath, content) {
+		return NewTextErrorResponse(contentConflictMessage(filePath)), nil
+	}
+	oldContent := string(content)
+
+	var newContent, organizer string
+	if _, lookErr := exec.LookPath("goimports"); lookErr == nil && language.Detect(filePath) == language.Go {
+		newContent, err = runGoimports(ctx, filePath, content)
+		if err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("goimports failed: %s", err)), nil
+		}
+		organizer = "goimports"
+	} else {
+		var ok bool
+		newContent, organizer, ok, err = o.organizeWithLsp(ctx, filePath, oldContent)
+		if err != nil {
+			return ToolResponse{}, err
+		}
+		if !ok {
+			hint := "configure a language server that can organize imports for this file"
+			if language.Detect(filePath) == language.Go {
+				hint = "install goimports or configure gopls"
+			}
+			return NewTextErrorResponse(fmt.Sprintf("no tool available to organize the imports of %s: %s", filePath, hint)), nil
+		}
+	}
+
+	if newContent == oldContent {
+		return NewTextResponse(fmt.Sprintf("The imports of %s are already organized. No changes made.", filePath)), nil
+	}
+
+	diff, additions, removals := diff.GenerateDiff(oldContent, newContent, filePath)
+	permissionPath := filepath.Dir(filePath)
+	if rootDir := config.WorkingDirectory(); strings.HasPrefix(filePath, rootDir) {
+		permissionPath = rootDir
+	}
+	p := o.permissions.Request(
+		permission.CreatePermissionRequest{
+			SessionID:   sessionID,
+			Path:        permissionPath,
+			ToolName:    OrganizeImportsToolName,
+			Action:      "write",
+			Description: fmt.Sprintf("Organize imports in file %s", filePath),
+			Params: EditPermissionsParams{
+				FilePath: filePath,
+				Diff:     diff,
+			},
+			LinesChanged: additions + removals,
+		},
+	)
+	if !p {
+		return ToolResponse{}, permission.ErrorPermissionDenied
+	}
+
+	if changedBeforeWrite(filePath, content) {
+		return NewTextErrorResponse(contentConflictMessage(filePath)), nil
+	}
+	if err := writeFileWithRetry(filePath, []byte(newContent), fileInfo.Mode().Perm()); err != nil {
+		return ToolResponse{}, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	recordRefactorHistory(ctx, o.files, sessionID, &refactorFile{
+		path:       filePath,
+		oldContent: oldContent,
+		newContent: newContent,
+	})
+	recordFileWrite(ctx, filePath)
+	recordFileRead(ctx, filePath)
+
+	waitForLspDiagnostics(ctx, filePath, o.lspClients)
+	text := fmt.Sprintf("<result>\nOrganized imports with %s in file: %s\n</result>\n", organizer, filePath)
+	text += getDiagnostics(filePath, o.lspClients)
+
+	return WithResponseMetadata(
+		NewTextResponse(text),
+		EditResponseMetadata{
+			Diff:      diff,
+			Additions: additions,
+			Removals:  removals,
+		},
+	), nil
+}
+
+// runGoimports returns content as organized by goimports. The file's
+// directory is passed as the source directory, so imports of the module
+// and of sibling files resolve like they do for the file on disk.
+func runGoimports(ctx context.Context, filePath string, content []byte) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, organizeImportsTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "goimports", "-srcdir", filepath.Dir(filePath))
+	cmd.Dir = filepath.Dir(filePath)
+	cmd.Stdin = bytes.NewReader(content)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s", msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// organizeWithLsp applies the organize imports action of the first language
+// server of the file that offers one. It reports false when none does.
+func (o *organizeImportsTool) organizeWithLsp(ctx context.Context, filePath, content string) (string, string, bool, error) {
+	clients := lspClientsForFile(filePath, o.lspClients)
+	if len(clients) == 0 {
+		return "", "", false, nil
+	}
+	notifyLspOpenFile(ctx, filePath, clients)
+
+	uri := protocol.URIFromPath(filePath)
+	for name, client := range clients {
+		action, ok := organizeImportsAction(ctx, client, uri, content, []protocol.Diagnostic{})
+		if !ok {
+			continue
+		}
+		edits, ok := fileEdits(action, uri)
+		if !ok {
+			continue
+		}
+		newContent, err := util.ApplyTextEditsToContent(content, edits)
+		if err != nil {
+			return "", "", false, fmt.Errorf("failed to apply the imports of %s: %w", name, err)
+		}
+		return newContent, name, true, nil
+	}
+	return "", "", false, nil
+}
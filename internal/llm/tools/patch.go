@@ -105,10 +105,6 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 			absPath = filepath.Join(wd, absPath)
 		}
 
-		if getLastReadTime(absPath).IsZero() {
-			return NewTextErrorResponse(fmt.Sprintf("you must read the file %s before patching it. Use the FileRead tool first", filePath)), nil
-		}
-
 		fileInfo, err := os.Stat(absPath)
 		if err != nil {
 			if os.IsNotExist(err) {
@@ -121,14 +117,26 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 			return NewTextErrorResponse(fmt.Sprintf("path is a directory, not a file: %s", absPath)), nil
 		}
 
+		if !autoReadForEdit(ctx, absPath, fileInfo.Size()) {
+			return NewTextErrorResponse(fmt.Sprintf("you must read the file %s before patching it. Use the FileRead tool first", filePath)), nil
+		}
+
 		modTime := fileInfo.ModTime()
-		lastRead := getLastReadTime(absPath)
+		lastRead := getLastReadTime(ctx, absPath)
 		if modTime.After(lastRead) {
 			return NewTextErrorResponse(
 				fmt.Sprintf("file %s has been modified since it was last read (mod time: %s, last read: %s)",
 					absPath, modTime.Format(time.RFC3339), lastRead.Format(time.RFC3339),
 				)), nil
 		}
+
+		content, err := readFileWithRetry(absPath)
+		if err != nil {
+			return ToolResponse{}, fmt.Errorf("failed to read file: %w", err)
+		}
+		if contentChangedSinceRead(ctx, absPath, content) {
+			return NewTextErrorResponse(contentConflictMessage(absPath)), nil
+		}
 	}
 
 	// Check for new files to ensure they don't already exist
@@ -157,7 +165,7 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 			absPath = filepath.Join(wd, absPath)
 		}
 
-		content, err := os.ReadFile(absPath)
+		content, err := readFileWithRetry(absPath)
 		if err != nil {
 			return ToolResponse{}, fmt.Errorf("failed to read file %s: %w", absPath, err)
 		}
@@ -180,6 +188,21 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		return NewTextErrorResponse(fmt.Sprintf("failed to create commit from patch: %s", err)), nil
 	}
 
+	for path, change := range commit.Changes {
+		paths := []string{path}
+		if change.MovePath != nil {
+			paths = append(paths, *change.MovePath)
+		}
+		for _, p := range paths {
+			if !filepath.IsAbs(p) {
+				p = filepath.Join(config.WorkingDirectory(), p)
+			}
+			if err := checkWritable(p); err != nil {
+				return NewTextErrorResponse(err.Error()), nil
+			}
+		}
+	}
+
 	// Get session ID and message ID
 	sessionID, messageID := GetContextValues(ctx)
 	if sessionID == "" || messageID == "" {
@@ -191,7 +214,7 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		switch change.Type {
 		case diff.ActionAdd:
 			dir := filepath.Dir(path)
-			patchDiff, _, _ := diff.GenerateDiff("", *change.NewContent, path)
+			patchDiff, additions, removals := diff.GenerateDiff("", *change.NewContent, path)
 			p := p.permissions.Request(
 				permission.CreatePermissionRequest{
 					SessionID:   sessionID,
@@ -203,6 +226,7 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 						FilePath: path,
 						Diff:     patchDiff,
 					},
+					LinesChanged: additions + removals,
 				},
 			)
 			if !p {
@@ -217,7 +241,7 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 			if change.NewContent != nil {
 				newContent = *change.NewContent
 			}
-			patchDiff, _, _ := diff.GenerateDiff(currentContent, newContent, path)
+			patchDiff, additions, removals := diff.GenerateDiff(currentContent, newContent, path)
 			dir := filepath.Dir(path)
 			p := p.permissions.Request(
 				permission.CreatePermissionRequest{
@@ -230,6 +254,7 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 						FilePath: path,
 						Diff:     patchDiff,
 					},
+					LinesChanged: additions + removals,
 				},
 			)
 			if !p {
@@ -237,7 +262,7 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 			}
 		case diff.ActionDelete:
 			dir := filepath.Dir(path)
-			patchDiff, _, _ := diff.GenerateDiff(*change.OldContent, "", path)
+			patchDiff, additions, removals := diff.GenerateDiff(*change.OldContent, "", path)
 			p := p.permissions.Request(
 				permission.CreatePermissionRequest{
 					SessionID:   sessionID,
@@ -249,6 +274,7 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 						FilePath: path,
 						Diff:     patchDiff,
 					},
+					LinesChanged: additions + removals,
 				},
 			)
 			if !p {
@@ -257,6 +283,16 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		}
 	}
 
+	for filePath, content := range currentFiles {
+		absPath := filePath
+		if !filepath.IsAbs(absPath) {
+			absPath = filepath.Join(config.WorkingDirectory(), absPath)
+		}
+		if changedBeforeWrite(absPath, []byte(content)) {
+			return NewTextErrorResponse(contentConflictMessage(absPath)), nil
+		}
+	}
+
 	// Apply the changes to the filesystem
 	err = diff.ApplyCommit(commit, func(path string, content string) error {
 		absPath := path
@@ -271,7 +307,7 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 			return fmt.Errorf("failed to create parent directories for %s: %w", absPath, err)
 		}
 
-		return os.WriteFile(absPath, []byte(content), 0o644)
+		return writeFileWithRetry(absPath, []byte(content), 0o644)
 	}, func(path string) error {
 		absPath := path
 		if !filepath.IsAbs(absPath) {
@@ -341,8 +377,8 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		}
 
 		// Record file operations
-		recordFileWrite(absPath)
-		recordFileRead(absPath)
+		recordFileWrite(ctx, absPath)
+		recordFileRead(ctx, absPath)
 	}
 
 	// Run LSP diagnostics on all changed files
@@ -357,6 +393,7 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 	for _, filePath := range changedFiles {
 		diagnosticsText += getDiagnostics(filePath, p.lspClients)
 	}
+	diagnosticsText += autoBuildCheck(ctx, changedFiles...)
 
 	if diagnosticsText != "" {
 		result += "\n\nDiagnostics:\n" + diagnosticsText
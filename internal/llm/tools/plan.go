@@ -0,0 +1,146 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/memory"
+)
+
+type PlanStep struct {
+	Step   string `json:"step"`
+	Status string `json:"status"`
+}
+
+type PlanParams struct {
+	Steps []PlanStep `json:"steps"`
+}
+
+type PlanResponseMetadata struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+}
+
+type planTool struct {
+	memory memory.Service
+}
+
+const (
+	PlanToolName    = "plan"
+	planDescription = `Records the plan for the current task. The plan is pinned at the top of the transcript for the user and included with every message so you can track progress against it.
+
+WHEN TO USE THIS TOOL:
+- Use at the start of a task that takes several steps, before making changes
+- Use again whenever a step is started or completed, or the plan changes
+- Skip it for simple questions and single-step changes
+
+HOW TO USE:
+- Always send the complete list of steps; it replaces the previous plan
+- Each step has a short description and a status: "pending", "in_progress" or "done"
+- Keep at most one step "in_progress" at a time
+
+FEATURES:
+- The plan is stored per session and survives conversation compaction
+- The user sees the plan and its progress while you work
+
+LIMITATIONS:
+- Steps are plain text; keep them to one line each
+- Send an empty list of steps to remove the plan once it no longer applies
+
+TIPS:
+- Aim for 3 to 8 concrete, verifiable steps
+- Update the plan as soon as you finish a step instead of batching updates`
+)
+
+func NewPlanTool(memory memory.Service) BaseTool {
+	return &planTool{
+		memory: memory,
+	}
+}
+
+func (p *planTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        PlanToolName,
+		Description: planDescription,
+		Parameters: map[string]any{
+			"steps": map[string]any{
+				"type":        "array",
+				"description": "The complete list of plan steps, in order",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"step": map[string]any{
+							"type":        "string",
+							"description": "A short description of the step",
+						},
+						"status": map[string]any{
+							"type":        "string",
+							"description": "The status of the step",
+							"enum":        []string{"pending", "in_progress", "done"},
+						},
+					},
+					"required": []string{"step", "status"},
+				},
+			},
+		},
+		Required: []string{"steps"},
+	}
+}
+
+func (p *planTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params PlanParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+
+	sessionID, _ := GetContextValues(ctx)
+	if sessionID == "" {
+		return ToolResponse{}, fmt.Errorf("session_id is required")
+	}
+
+	if len(params.Steps) == 0 {
+		err := p.memory.Delete(ctx, sessionID, memory.PlanKey)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return ToolResponse{}, fmt.Errorf("error removing plan: %w", err)
+		}
+		return WithResponseMetadata(NewTextResponse("Plan removed"), PlanResponseMetadata{}), nil
+	}
+
+	lines := make([]string, 0, len(params.Steps))
+	completed := 0
+	for i, step := range params.Steps {
+		text := strings.Join(strings.Fields(step.Step), " ")
+		if text == "" {
+			return NewTextErrorResponse(fmt.Sprintf("step %d has no description", i+1)), nil
+		}
+		marker := ""
+		switch step.Status {
+		case "pending", "":
+			marker = memory.PlanPending
+		case "in_progress":
+			marker = memory.PlanInProgress
+		case "done":
+			marker = memory.PlanDone
+			completed++
+		default:
+			return NewTextErrorResponse(fmt.Sprintf("step %d has unknown status %q, expected pending, in_progress or done", i+1, step.Status)), nil
+		}
+		lines = append(lines, fmt.Sprintf("%s %s", marker, text))
+	}
+
+	if _, err := p.memory.Set(ctx, sessionID, memory.PlanKey, strings.Join(lines, "\n")); err != nil {
+		return ToolResponse{}, fmt.Errorf("error saving plan: %w", err)
+	}
+
+	return WithResponseMetadata(
+		NewTextResponse(fmt.Sprintf("Plan updated: %d of %d steps done", completed, len(params.Steps))),
+		PlanResponseMetadata{
+			Total:     len(params.Steps),
+			Completed: completed,
+		},
+	), nil
+}
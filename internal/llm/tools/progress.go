@@ -0,0 +1,36 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/opencode-ai/opencode/internal/pubsub"
+)
+
+// ToolProgress is published while a tool call is running. Output holds the
+// latest output of the tool, if the tool is able to report it.
+type ToolProgress struct {
+	SessionID  string
+	ToolCallID string
+	Output     string
+}
+
+var progressBroker = pubsub.NewBroker[ToolProgress]()
+
+// SubscribeProgress returns a channel with the progress updates of every running tool call.
+func SubscribeProgress(ctx context.Context) <-chan pubsub.Event[ToolProgress] {
+	return progressBroker.Subscribe(ctx)
+}
+
+// ReportProgress publishes a progress update for the given tool call of the
+// session stored in the context.
+func ReportProgress(ctx context.Context, toolCallID, output string) {
+	sessionID, _ := GetContextValues(ctx)
+	if sessionID == "" || toolCallID == "" {
+		return
+	}
+	progressBroker.Publish(pubsub.UpdatedEvent, ToolProgress{
+		SessionID:  sessionID,
+		ToolCallID: toolCallID,
+		Output:     output,
+	})
+}
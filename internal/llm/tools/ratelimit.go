@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+)
+
+// networkLimiter spaces out requests of the network tools (fetch and
+// sourcegraph) so tool-heavy turns don't hammer external services. Requests
+// that arrive faster than the configured rate are queued in arrival order.
+type networkLimiter struct {
+	mu   sync.Mutex
+	next time.Time
+}
+
+var networkRequests = &networkLimiter{}
+
+// wait blocks until the caller may issue its request or ctx is done.
+func (l *networkLimiter) wait(ctx context.Context, requestsPerSecond float64) error {
+	if requestsPerSecond <= 0 {
+		return nil
+	}
+	interval := time.Duration(float64(time.Second) / requestsPerSecond)
+
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(interval)
+	l.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// waitForNetworkSlot waits for the shared network rate limiter using the
+// configured requests per second.
+func waitForNetworkSlot(ctx context.Context) error {
+	return networkRequests.wait(ctx, config.Get().NetworkRequestsPerSecond)
+}
@@ -0,0 +1,428 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/diff"
+	"github.com/opencode-ai/opencode/internal/history"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/lsp"
+	"github.com/opencode-ai/opencode/internal/permission"
+)
+
+type RefactorEdit struct {
+	FilePath   string `json:"file_path"`
+	OldString  string `json:"old_string"`
+	NewString  string `json:"new_string"`
+	ReplaceAll bool   `json:"replace_all"`
+}
+
+type RefactorParams struct {
+	Description string         `json:"description"`
+	Edits       []RefactorEdit `json:"edits"`
+}
+
+type RefactorFileDiff struct {
+	FilePath string `json:"file_path"`
+	Diff     string `json:"diff"`
+}
+
+type RefactorPermissionsParams struct {
+	Description string             `json:"description"`
+	Files       []RefactorFileDiff `json:"files"`
+}
+
+type RefactorResponseMetadata struct {
+	Description  string             `json:"description"`
+	Files        []RefactorFileDiff `json:"files"`
+	FilesChanged []string           `json:"files_changed"`
+	Additions    int                `json:"additions"`
+	Removals     int                `json:"removals"`
+}
+
+type refactorTool struct {
+	lspClients  map[string]*lsp.Client
+	permissions permission.Service
+	files       history.Service
+}
+
+// refactorFile is the planned change of a single file.
+type refactorFile struct {
+	path       string
+	oldContent string
+	newContent string
+	mode       os.FileMode
+}
+
+const (
+	RefactorToolName    = "refactor"
+	refactorDescription = `Applies a coordinated set of text replacements across multiple files as a single, all-or-nothing change.
+
+WHEN TO USE THIS TOOL:
+- Use for refactors that touch several files at once (renaming a function and its callers, changing a signature, moving a constant)
+- Helpful when the change only makes sense if every file is updated
+- Prefer the Edit tool for changes to a single location
+
+HOW TO USE:
+- Provide a short description of the transformation (e.g. "Rename ParseConfig to LoadConfig")
+- Provide the list of edits; each edit has a file_path, old_string and new_string
+- Several edits may target the same file; they are applied in order
+- Set replace_all on an edit to replace every occurrence of old_string in that file
+
+FEATURES:
+- All edits are computed and validated before anything is written
+- The user approves a single combined preview with the diff of every file
+- Files are written atomically; if any write fails, every file already written is restored
+- LSP diagnostics are reported for every changed file
+
+LIMITATIONS:
+- Only modifies existing files; use the Write tool to create files
+- Every file must have been read with the View tool first and must not have changed since
+- Without replace_all, old_string must match exactly once in the file
+
+TIPS:
+- Include enough context in old_string to make every match unique
+- Use the Grep tool first to find every place that needs to change
+- Keep one refactor per call so the preview stays reviewable`
+)
+
+func NewRefactorTool(lspClients map[string]*lsp.Client, permissions permission.Service, files history.Service) BaseTool {
+	return &refactorTool{
+		lspClients:  lspClients,
+		permissions: permissions,
+		files:       files,
+	}
+}
+
+func (r *refactorTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        RefactorToolName,
+		Description: refactorDescription,
+		Parameters: map[string]any{
+			"description": map[string]any{
+				"type":        "string",
+				"description": "A short description of the transformation",
+			},
+			"edits": map[string]any{
+				"type":        "array",
+				"description": "The edits to apply, in order",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"file_path": map[string]any{
+							"type":        "string",
+							"description": "The absolute path to the file to modify",
+						},
+						"old_string": map[string]any{
+							"type":        "string",
+							"description": "The text to replace",
+						},
+						"new_string": map[string]any{
+							"type":        "string",
+							"description": "The text to replace it with",
+						},
+						"replace_all": map[string]any{
+							"type":        "boolean",
+							"description": "Replace every occurrence of old_string in the file (default false)",
+						},
+					},
+					"required": []string{"file_path", "old_string", "new_string"},
+				},
+			},
+		},
+		Required: []string{"description", "edits"},
+	}
+}
+
+func (r *refactorTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params RefactorParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+
+	if len(params.Edits) == 0 {
+		return NewTextErrorResponse("at least one edit is required"), nil
+	}
+
+	sessionID, messageID := GetContextValues(ctx)
+	if sessionID == "" || messageID == "" {
+		return ToolResponse{}, fmt.Errorf("session ID and message ID are required for refactoring files")
+	}
+
+	plan, errResponse, err := r.planEdits(ctx, params.Edits)
+	if err != nil {
+		return ToolResponse{}, err
+	}
+	if errResponse != nil {
+		return *errResponse, nil
+	}
+
+	fileDiffs := make([]RefactorFileDiff, 0, len(plan))
+	changedFiles := make([]string, 0, len(plan))
+	totalAdditions, totalRemovals := 0, 0
+	for _, file := range plan {
+		fileDiff, additions, removals := diff.GenerateDiff(file.oldContent, file.newContent, file.path)
+		fileDiffs = append(fileDiffs, RefactorFileDiff{
+			FilePath: file.path,
+			Diff:     fileDiff,
+		})
+		changedFiles = append(changedFiles, file.path)
+		totalAdditions += additions
+		totalRemovals += removals
+	}
+
+	description := strings.TrimSpace(params.Description)
+	if description == "" {
+		description = fmt.Sprintf("Refactor %d files", len(plan))
+	}
+	p := r.permissions.Request(
+		permission.CreatePermissionRequest{
+			SessionID:   sessionID,
+			Path:        config.WorkingDirectory(),
+			ToolName:    RefactorToolName,
+			Action:      "write",
+			Description: description,
+			Params: RefactorPermissionsParams{
+				Description: description,
+				Files:       fileDiffs,
+			},
+			LinesChanged: totalAdditions + totalRemovals,
+		},
+	)
+	if !p {
+		return ToolResponse{}, permission.ErrorPermissionDenied
+	}
+
+	if err := applyRefactor(plan); err != nil {
+		return NewTextErrorResponse(applyFailedMessage("refactor", err)), nil
+	}
+
+	for _, file := range plan {
+		recordRefactorHistory(ctx, r.files, sessionID, file)
+		recordFileWrite(ctx, file.path)
+		recordFileRead(ctx, file.path)
+	}
+
+	for _, filePath := range changedFiles {
+		waitForLspDiagnostics(ctx, filePath, r.lspClients)
+	}
+
+	result := fmt.Sprintf("Refactor applied: %s. %d files changed, %d additions, %d removals",
+		description, len(changedFiles), totalAdditions, totalRemovals)
+
+	diagnosticsText := ""
+	for _, filePath := range changedFiles {
+		diagnosticsText += getDiagnostics(filePath, r.lspClients)
+	}
+	diagnosticsText += autoBuildCheck(ctx, changedFiles...)
+	if diagnosticsText != "" {
+		result += "\n\nDiagnostics:\n" + diagnosticsText
+	}
+
+	return WithResponseMetadata(
+		NewTextResponse(result),
+		RefactorResponseMetadata{
+			Description:  description,
+			Files:        fileDiffs,
+			FilesChanged: changedFiles,
+			Additions:    totalAdditions,
+			Removals:     totalRemovals,
+		}), nil
+}
+
+// planEdits validates every edit and computes the new content of each file
+// without touching the filesystem.
+func (r *refactorTool) planEdits(ctx context.Context, edits []RefactorEdit) ([]*refactorFile, *ToolResponse, error) {
+	errorResponse := func(format string, args ...any) ([]*refactorFile, *ToolResponse, error) {
+		response := NewTextErrorResponse(fmt.Sprintf(format, args...))
+		return nil, &response, nil
+	}
+
+	files := make(map[string]*refactorFile)
+	plan := make([]*refactorFile, 0)
+	for i, edit := range edits {
+		if edit.FilePath == "" {
+			return errorResponse("edit %d: file_path is required", i+1)
+		}
+		if edit.OldString == "" {
+			return errorResponse("edit %d: old_string is required, use the Write tool to create files", i+1)
+		}
+
+		filePath := edit.FilePath
+		if !filepath.IsAbs(filePath) {
+			filePath = filepath.Join(config.WorkingDirectory(), filePath)
+		}
+		if err := checkWritable(filePath); err != nil {
+			return errorResponse("edit %d: %s", i+1, err)
+		}
+
+		file, ok := files[filePath]
+		if !ok {
+			fileInfo, err := os.Stat(filePath)
+			if err != nil {
+				if os.IsNotExist(err) {
+					return errorResponse("edit %d: file not found: %s", i+1, filePath)
+				}
+				return nil, nil, fmt.Errorf("failed to access file: %w", err)
+			}
+			if fileInfo.IsDir() {
+				return errorResponse("edit %d: path is a directory, not a file: %s", i+1, filePath)
+			}
+
+			if !autoReadForEdit(ctx, filePath, fileInfo.Size()) {
+				return errorResponse("you must read the file %s before editing it. Use the View tool first", filePath)
+			}
+			lastRead := getLastReadTime(ctx, filePath)
+			if modTime := fileInfo.ModTime(); modTime.After(lastRead) {
+				return errorResponse("file %s has been modified since it was last read (mod time: %s, last read: %s)",
+					filePath, modTime.Format(time.RFC3339), lastRead.Format(time.RFC3339))
+			}
+
+			content, err := readFileWithRetry(filePath)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read file: %w", err)
+			}
+			if contentChangedSinceRead(ctx, filePath, content) {
+				return errorResponse("%s", contentConflictMessage(filePath))
+			}
+			file = &refactorFile{
+				path:       filePath,
+				oldContent: string(content),
+				newContent: string(content),
+				mode:       fileInfo.Mode().Perm(),
+			}
+			files[filePath] = file
+			plan = append(plan, file)
+		}
+
+		count := strings.Count(file.newContent, edit.OldString)
+		switch {
+		case count == 0:
+			return errorResponse("edit %d: old_string not found in %s. Make sure it matches exactly, including whitespace and line breaks, and accounts for earlier edits to the same file", i+1, filePath)
+		case count > 1 && !edit.ReplaceAll:
+			return errorResponse("edit %d: old_string appears %d times in %s. Provide more context or set replace_all", i+1, count, filePath)
+		}
+		file.newContent = strings.ReplaceAll(file.newContent, edit.OldString, edit.NewString)
+	}
+
+	changed := make([]*refactorFile, 0, len(plan))
+	for _, file := range plan {
+		if file.newContent != file.oldContent {
+			changed = append(changed, file)
+		}
+	}
+	if len(changed) == 0 {
+		return errorResponse("the edits do not change any file. No changes made.")
+	}
+	return changed, nil, nil
+}
+
+// applyRefactor writes every planned file. Each file is written to a
+// temporary file and renamed into place; if any step fails, files that were
+// already replaced are restored to their original content. Nothing is
+// written when any file changed since the plan was made.
+func applyRefactor(plan []*refactorFile) error {
+	for _, file := range plan {
+		if changedBeforeWrite(file.path, []byte(file.oldContent)) {
+			return errors.New(contentConflictMessage(file.path))
+		}
+	}
+
+	tempFiles := make([]string, 0, len(plan))
+	defer func() {
+		for _, tempFile := range tempFiles {
+			os.Remove(tempFile)
+		}
+	}()
+
+	for _, file := range plan {
+		temp, err := os.CreateTemp(filepath.Dir(file.path), "."+filepath.Base(file.path)+".refactor-*")
+		if err != nil {
+			return fmt.Errorf("failed to prepare %s: %w", file.path, err)
+		}
+		tempFiles = append(tempFiles, temp.Name())
+		_, err = temp.WriteString(file.newContent)
+		if closeErr := temp.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Chmod(temp.Name(), file.mode)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to prepare %s: %w", file.path, err)
+		}
+	}
+
+	for i, file := range plan {
+		if err := retryTransientFS(func() error { return os.Rename(tempFiles[i], file.path) }); err != nil {
+			err = fmt.Errorf("failed to write %s: %w", file.path, err)
+			var unrestored []string
+			for _, written := range plan[:i] {
+				if restoreErr := writeFileWithRetry(written.path, []byte(written.oldContent), written.mode); restoreErr != nil {
+					logging.Error("Failed to restore file after refactor failure", "file", written.path, "error", restoreErr)
+					unrestored = append(unrestored, written.path)
+				}
+			}
+			if len(unrestored) > 0 {
+				return &refactorRestoreError{err: err, paths: unrestored}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// refactorRestoreError is returned by applyRefactor when a write failed and
+// some of the files already written could not be restored. Those files keep
+// their new content.
+type refactorRestoreError struct {
+	err   error
+	paths []string
+}
+
+func (e *refactorRestoreError) Error() string {
+	return fmt.Sprintf("%s; could not restore %s", e.err, strings.Join(e.paths, ", "))
+}
+
+func (e *refactorRestoreError) Unwrap() error {
+	return e.err
+}
+
+// applyFailedMessage describes a failed applyRefactor in a tool result,
+// naming the files left changed if the rollback did not restore them all.
+func applyFailedMessage(what string, err error) string {
+	var restoreErr *refactorRestoreError
+	if errors.As(err, &restoreErr) {
+		return fmt.Sprintf("failed to apply %s: %s\nThese files could not be restored and keep their new content:\n%s",
+			what, restoreErr.err, strings.Join(restoreErr.paths, "\n"))
+	}
+	return fmt.Sprintf("failed to apply %s, no files were changed: %s", what, err)
+}
+
+func recordRefactorHistory(ctx context.Context, files history.Service, sessionID string, file *refactorFile) {
+	historyFile, err := files.GetByPathAndSession(ctx, file.path, sessionID)
+	if err != nil {
+		_, err = files.Create(ctx, sessionID, file.path, file.oldContent)
+		if err != nil {
+			logging.Debug("Error creating file history", "error", err)
+		}
+	} else if historyFile.Content != file.oldContent {
+		// User manually changed the content, store an intermediate version
+		_, err = files.CreateVersion(ctx, sessionID, file.path, file.oldContent)
+		if err != nil {
+			logging.Debug("Error creating file history version", "error", err)
+		}
+	}
+	_, err = files.CreateVersion(ctx, sessionID, file.path, file.newContent)
+	if err != nil {
+		logging.Debug("Error creating file history version", "error", err)
+	}
+}
@@ -0,0 +1,362 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/diff"
+	"github.com/opencode-ai/opencode/internal/fileutil"
+	"github.com/opencode-ai/opencode/internal/history"
+	"github.com/opencode-ai/opencode/internal/lsp"
+	"github.com/opencode-ai/opencode/internal/permission"
+)
+
+type RenameTextParams struct {
+	OldName string   `json:"old_name"`
+	NewName string   `json:"new_name"`
+	Path    string   `json:"path"`
+	Include string   `json:"include"`
+	Exclude []string `json:"exclude"`
+}
+
+type renameTextTool struct {
+	lspClients  map[string]*lsp.Client
+	permissions permission.Service
+	files       history.Service
+}
+
+const (
+	RenameTextToolName    = "rename_text"
+	maxRenameFiles        = 100
+	maxRenameFileSize     = 1024 * 1024 // 1MB
+	renameTextDescription = `Renames an identifier across the project by replacing every whole-word occurrence of it. This is a textual rename, not a semantic one.
+
+WHEN TO USE THIS TOOL:
+- Use to rename a function, type, variable or constant in projects without a language server
+- Helpful when the same name appears in many files (definitions, call sites, docs, tests)
+
+HOW TO USE:
+- Provide the current name (old_name) and the new name (new_name)
+- Optionally limit the search to a directory (path) and to files matching a pattern (include, e.g. "*.py")
+- Optionally exclude files or directories with glob patterns (exclude, e.g. ["docs/**", "*_generated.go"])
+
+FEATURES:
+- Matches whole words only: renaming "user" does not touch "username" or "get_user"
+- The user approves a single combined preview with the diff of every file
+- All files are written atomically; if any write fails, no file is changed
+- LSP diagnostics are reported for every changed file
+
+LIMITATIONS:
+- Textual, not semantic: unrelated identifiers with the same name, strings and comments are renamed too
+- Hidden files and common generated or dependency directories are skipped
+- Binary files and files over 1MB are skipped
+- At most 100 files can be changed in one call
+
+TIPS:
+- Use the Grep tool first to check where the name is used
+- Review the preview carefully; if the user denies it with a list of files to skip, call the tool again with those files in exclude
+- Prefer distinctive names; renaming short common words touches far more than intended`
+)
+
+func NewRenameTextTool(lspClients map[string]*lsp.Client, permissions permission.Service, files history.Service) BaseTool {
+	return &renameTextTool{
+		lspClients:  lspClients,
+		permissions: permissions,
+		files:       files,
+	}
+}
+
+func (r *renameTextTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        RenameTextToolName,
+		Description: renameTextDescription,
+		Parameters: map[string]any{
+			"old_name": map[string]any{
+				"type":        "string",
+				"description": "The identifier to rename",
+			},
+			"new_name": map[string]any{
+				"type":        "string",
+				"description": "The new name of the identifier",
+			},
+			"path": map[string]any{
+				"type":        "string",
+				"description": "The directory to search in. Defaults to the directory files may be changed in, usually the current working directory.",
+			},
+			"include": map[string]any{
+				"type":        "string",
+				"description": "File pattern to include (e.g. \"*.py\", \"*.{ts,tsx}\")",
+			},
+			"exclude": map[string]any{
+				"type":        "array",
+				"description": "Glob patterns of files or directories to leave untouched, relative to path",
+				"items": map[string]any{
+					"type": "string",
+				},
+			},
+		},
+		Required: []string{"old_name", "new_name"},
+	}
+}
+
+func (r *renameTextTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params RenameTextParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+
+	oldName := strings.TrimSpace(params.OldName)
+	newName := strings.TrimSpace(params.NewName)
+	if oldName == "" || newName == "" {
+		return NewTextErrorResponse("old_name and new_name are required"), nil
+	}
+	if oldName == newName {
+		return NewTextErrorResponse("old_name and new_name are the same"), nil
+	}
+
+	sessionID, messageID := GetContextValues(ctx)
+	if sessionID == "" || messageID == "" {
+		return ToolResponse{}, fmt.Errorf("session ID and message ID are required for renaming")
+	}
+
+	searchPath := params.Path
+	if searchPath == "" {
+		searchPath = config.WriteRoot()
+	} else if !filepath.IsAbs(searchPath) {
+		searchPath = filepath.Join(config.WorkingDirectory(), searchPath)
+	}
+
+	plan, occurrences, errResponse, err := planRename(ctx, searchPath, oldName, newName, params.Include, params.Exclude)
+	if err != nil {
+		return ToolResponse{}, err
+	}
+	if errResponse != nil {
+		return *errResponse, nil
+	}
+	for _, file := range plan {
+		if err := checkWritable(file.path); err != nil {
+			return NewTextErrorResponse(err.Error()), nil
+		}
+	}
+
+	fileDiffs := make([]RefactorFileDiff, 0, len(plan))
+	changedFiles := make([]string, 0, len(plan))
+	totalAdditions, totalRemovals := 0, 0
+	for _, file := range plan {
+		fileDiff, additions, removals := diff.GenerateDiff(file.oldContent, file.newContent, file.path)
+		fileDiffs = append(fileDiffs, RefactorFileDiff{
+			FilePath: file.path,
+			Diff:     fileDiff,
+		})
+		changedFiles = append(changedFiles, file.path)
+		totalAdditions += additions
+		totalRemovals += removals
+	}
+
+	description := fmt.Sprintf("Textual rename of %s to %s (%d occurrences in %d files). Not semantic, review every change.",
+		oldName, newName, occurrences, len(plan))
+	p := r.permissions.Request(
+		permission.CreatePermissionRequest{
+			SessionID:   sessionID,
+			Path:        searchPath,
+			ToolName:    RenameTextToolName,
+			Action:      "write",
+			Description: description,
+			Params: RefactorPermissionsParams{
+				Description: description,
+				Files:       fileDiffs,
+			},
+			LinesChanged: totalAdditions + totalRemovals,
+		},
+	)
+	if !p {
+		return ToolResponse{}, permission.ErrorPermissionDenied
+	}
+
+	if err := applyRefactor(plan); err != nil {
+		return NewTextErrorResponse(applyFailedMessage("rename", err)), nil
+	}
+
+	for _, file := range plan {
+		recordRefactorHistory(ctx, r.files, sessionID, file)
+		recordFileWrite(ctx, file.path)
+		recordFileRead(ctx, file.path)
+	}
+
+	for _, filePath := range changedFiles {
+		waitForLspDiagnostics(ctx, filePath, r.lspClients)
+	}
+
+	result := fmt.Sprintf("Renamed %s to %s: %d occurrences in %d files. This was a textual rename; check that no unrelated identifiers, strings or comments were changed.",
+		oldName, newName, occurrences, len(changedFiles))
+
+	diagnosticsText := ""
+	for _, filePath := range changedFiles {
+		diagnosticsText += getDiagnostics(filePath, r.lspClients)
+	}
+	diagnosticsText += autoBuildCheck(ctx, changedFiles...)
+	if diagnosticsText != "" {
+		result += "\n\nDiagnostics:\n" + diagnosticsText
+	}
+
+	return WithResponseMetadata(
+		NewTextResponse(result),
+		RefactorResponseMetadata{
+			Description:  description,
+			Files:        fileDiffs,
+			FilesChanged: changedFiles,
+			Additions:    totalAdditions,
+			Removals:     totalRemovals,
+		}), nil
+}
+
+// planRename finds every file under root containing oldName as a whole word
+// and computes its content with the name replaced.
+func planRename(ctx context.Context, root, oldName, newName, include string, exclude []string) ([]*refactorFile, int, *ToolResponse, error) {
+	errorResponse := func(format string, args ...any) ([]*refactorFile, int, *ToolResponse, error) {
+		response := NewTextErrorResponse(fmt.Sprintf(format, args...))
+		return nil, 0, &response, nil
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errorResponse("path not found: %s", root)
+		}
+		return nil, 0, nil, fmt.Errorf("failed to access path: %w", err)
+	}
+	if !info.IsDir() {
+		return errorResponse("path is not a directory: %s", root)
+	}
+
+	for _, pattern := range append([]string{include}, exclude...) {
+		if pattern != "" && !doublestar.ValidatePattern(pattern) {
+			return errorResponse("invalid pattern: %s", pattern)
+		}
+	}
+
+	plan := make([]*refactorFile, 0)
+	occurrences := 0
+	var modifiedSinceRead []string
+	oldBytes := []byte(oldName)
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, relErr := filepath.Rel(root, path)
+		if relErr != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if rel != "." && (fileutil.SkipHidden(rel) || matchesAny(exclude, rel)) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if include != "" {
+			if matched, _ := doublestar.Match(include, d.Name()); !matched {
+				if matched, _ := doublestar.Match(include, rel); !matched {
+					return nil
+				}
+			}
+		}
+
+		fileInfo, err := d.Info()
+		if err != nil || !fileInfo.Mode().IsRegular() || fileInfo.Size() > maxRenameFileSize {
+			return nil
+		}
+		content, err := readFileWithRetry(path)
+		if err != nil || !bytes.Contains(content, oldBytes) || bytes.IndexByte(content[:min(len(content), 8000)], 0) >= 0 {
+			return nil
+		}
+
+		newContent, count := replaceWholeWord(string(content), oldName, newName)
+		if count == 0 {
+			return nil
+		}
+		if lastRead := getLastReadTime(ctx, path); !lastRead.IsZero() && (fileInfo.ModTime().After(lastRead) || contentChangedSinceRead(ctx, path, content)) {
+			modifiedSinceRead = append(modifiedSinceRead, path)
+		}
+		occurrences += count
+		plan = append(plan, &refactorFile{
+			path:       path,
+			oldContent: string(content),
+			newContent: newContent,
+			mode:       fileInfo.Mode().Perm(),
+		})
+		if len(plan) > maxRenameFiles {
+			return fs.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("error searching files: %w", err)
+	}
+
+	switch {
+	case len(plan) == 0:
+		return errorResponse("no whole-word occurrences of %s found", oldName)
+	case len(plan) > maxRenameFiles:
+		return errorResponse("%s appears in more than %d files. Narrow the search with path, include or exclude", oldName, maxRenameFiles)
+	case len(modifiedSinceRead) > 0:
+		return errorResponse("these files were modified since they were last read, read them again before renaming:\n%s",
+			strings.Join(modifiedSinceRead, "\n"))
+	}
+	return plan, occurrences, nil, nil
+}
+
+// replaceWholeWord replaces every occurrence of old in content that is not
+// part of a longer identifier, returning the new content and the count.
+func replaceWholeWord(content, old, new string) (string, int) {
+	var sb strings.Builder
+	count := 0
+	rest := content
+	for {
+		idx := strings.Index(rest, old)
+		if idx < 0 {
+			break
+		}
+		end := idx + len(old)
+		before := len(content) - len(rest) + idx
+		if (before == 0 || !isIdentifierByte(content[before-1])) && (end == len(rest) || !isIdentifierByte(rest[end])) {
+			sb.WriteString(rest[:idx])
+			sb.WriteString(new)
+			count++
+		} else {
+			sb.WriteString(rest[:end])
+		}
+		rest = rest[end:]
+	}
+	sb.WriteString(rest)
+	return sb.String(), count
+}
+
+func isIdentifierByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= 0x80
+}
+
+func matchesAny(patterns []string, rel string) bool {
+	base := filepath.Base(rel)
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+		if matched, _ := doublestar.Match(pattern, rel); matched {
+			return true
+		}
+		if matched, _ := doublestar.Match(pattern, base); matched {
+			return true
+		}
+	}
+	return false
+}
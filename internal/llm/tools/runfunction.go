@@ -0,0 +1,358 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/tools/shell"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/permission"
+)
+
+type RunFunctionParams struct {
+	Path     string   `json:"path"`
+	Function string   `json:"function"`
+	Args     []string `json:"args"`
+	Setup    string   `json:"setup"`
+	Imports  []string `json:"imports"`
+	Timeout  int      `json:"timeout"`
+}
+
+type RunFunctionPermissionsParams struct {
+	Package string `json:"package"`
+	Harness string `json:"harness"`
+}
+
+type RunFunctionResponseMetadata struct {
+	Package  string `json:"package"`
+	Function string `json:"function"`
+	Harness  string `json:"harness"`
+	Passed   bool   `json:"passed"`
+	Duration int64  `json:"duration"`
+}
+
+type runFunctionTool struct {
+	permissions permission.Service
+}
+
+const (
+	RunFunctionToolName       = "run_function"
+	defaultRunFunctionTimeout = 60
+	maxRunFunctionTimeout     = 300
+	harnessTestName           = "TestOpencodeHarness"
+	runFunctionDescription    = `Calls a single Go function with the given arguments through a throwaway test and returns what it printed and returned.
+
+WHEN TO USE THIS TOOL:
+- Use to check empirically what a function does with specific inputs instead of reasoning about it
+- Helpful for "does this function do what I think" questions before or after changing it
+- Use to reproduce a bug in a single function with the inputs that trigger it
+
+HOW TO USE:
+- Provide the package directory (or a file in it) and the function name
+- Provide the arguments as Go expressions, e.g. ["\"a,b\"", "2", "[]int{1, 2}"]
+- For methods, create the receiver in setup (e.g. "p := NewParser()") and use "p.Parse" as the function
+- List any extra packages the arguments or setup need in imports
+- Optionally set a timeout in seconds (default 60, max 300)
+
+FEATURES:
+- The test is written into the package, so unexported functions can be called
+- Every return value is printed with %#v, errors with their message
+- Output printed by the function and panics are reported
+- The generated file is always removed afterwards
+
+LIMITATIONS:
+- Only works for Go code inside a module
+- The call runs with "go test", so the other test files of the package must compile
+- Imports that the generated code does not use make the build fail
+- Running the code requires the user's permission
+- The harness is written next to the package, so the package must be inside the write root
+
+TIPS:
+- Call the tool several times with different inputs to cover edge cases
+- Use setup to build complex arguments step by step`
+)
+
+func NewRunFunctionTool(permissions permission.Service) BaseTool {
+	return &runFunctionTool{
+		permissions: permissions,
+	}
+}
+
+func (r *runFunctionTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        RunFunctionToolName,
+		Description: runFunctionDescription,
+		Parameters: map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "The directory of the package, or a Go file in it",
+			},
+			"function": map[string]any{
+				"type":        "string",
+				"description": "The function to call, or variable.Method for a receiver created in setup",
+			},
+			"args": map[string]any{
+				"type":        "array",
+				"description": "The arguments as Go expressions",
+				"items": map[string]any{
+					"type": "string",
+				},
+			},
+			"setup": map[string]any{
+				"type":        "string",
+				"description": "Go statements to run before the call",
+			},
+			"imports": map[string]any{
+				"type":        "array",
+				"description": "Import paths needed by the arguments or setup",
+				"items": map[string]any{
+					"type": "string",
+				},
+			},
+			"timeout": map[string]any{
+				"type":        "number",
+				"description": "Timeout in seconds (default 60, max 300)",
+			},
+		},
+		Required: []string{"path", "function"},
+	}
+}
+
+func (r *runFunctionTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params RunFunctionParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+	if params.Path == "" {
+		return NewTextErrorResponse("path is required"), nil
+	}
+	params.Function = strings.TrimSpace(params.Function)
+	if params.Function == "" {
+		return NewTextErrorResponse("function is required"), nil
+	}
+	if params.Timeout <= 0 {
+		params.Timeout = defaultRunFunctionTimeout
+	} else if params.Timeout > maxRunFunctionTimeout {
+		params.Timeout = maxRunFunctionTimeout
+	}
+
+	sessionID, messageID := GetContextValues(ctx)
+	if sessionID == "" || messageID == "" {
+		return ToolResponse{}, fmt.Errorf("session ID and message ID are required to run a function")
+	}
+
+	dir := params.Path
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(config.WorkingDirectory(), dir)
+	}
+	if info, err := os.Stat(dir); err != nil {
+		if os.IsNotExist(err) {
+			return NewTextErrorResponse(fmt.Sprintf("path not found: %s", params.Path)), nil
+		}
+		return ToolResponse{}, fmt.Errorf("error accessing path: %w", err)
+	} else if !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		return NewTextErrorResponse("the go toolchain was not found in $PATH"), nil
+	}
+
+	pkgName, results, err := goFunctionResults(dir, params.Function)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	harness, err := generateHarness(pkgName, params, results)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	harnessPath := filepath.Join(dir, fmt.Sprintf("opencode_harness_%d_test.go", time.Now().UnixNano()))
+	if err := checkWritable(harnessPath); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	p := r.permissions.Request(
+		permission.CreatePermissionRequest{
+			SessionID:   sessionID,
+			Path:        dir,
+			ToolName:    RunFunctionToolName,
+			Action:      "execute",
+			Description: fmt.Sprintf("Run %s in %s through a generated test", params.Function, dir),
+			Params: RunFunctionPermissionsParams{
+				Package: dir,
+				Harness: harness,
+			},
+		},
+	)
+	if !p {
+		return ToolResponse{}, permission.ErrorPermissionDenied
+	}
+
+	if err := os.WriteFile(harnessPath, []byte(harness), 0o644); err != nil {
+		return ToolResponse{}, fmt.Errorf("error writing harness: %w", err)
+	}
+	defer os.Remove(harnessPath)
+
+	runCtx, cancel := context.WithTimeout(ctx, time.Duration(params.Timeout)*time.Second)
+	defer cancel()
+	start := time.Now()
+	cmd := exec.CommandContext(runCtx, "go", "test", "-count=1", "-v", fmt.Sprintf("-timeout=%ds", params.Timeout), "-run", "^"+harnessTestName+"$", ".")
+	cmd.Dir = dir
+	// go test runs the compiled test binary as a child; killing only the go
+	// command on timeout would leave it running, so kill the whole group.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = time.Second
+	if env, err := shell.ProjectEnv(); err != nil {
+		logging.Warn("Env file not loaded", "error", err)
+	} else if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	output, runErr := cmd.CombinedOutput()
+	duration := time.Since(start)
+
+	metadata := RunFunctionResponseMetadata{
+		Package:  dir,
+		Function: params.Function,
+		Harness:  harness,
+		Passed:   runErr == nil,
+		Duration: duration.Milliseconds(),
+	}
+	if runCtx.Err() == context.DeadlineExceeded || bytes.Contains(output, []byte("panic: test timed out after")) {
+		return WithResponseMetadata(NewTextErrorResponse(fmt.Sprintf("the call did not finish within %d seconds\n\n%s", params.Timeout, truncateOutput(string(output)))), metadata), nil
+	}
+	if ctx.Err() != nil {
+		return ToolResponse{}, ctx.Err()
+	}
+	if runErr != nil && !bytes.Contains(output, []byte("=== RUN   "+harnessTestName)) {
+		return WithResponseMetadata(NewTextErrorResponse(fmt.Sprintf("the harness did not build:\n%s\nGenerated harness:\n%s", truncateOutput(string(output)), harness)), metadata), nil
+	}
+
+	result := harnessOutput(string(output))
+	if result == "" {
+		result = "no output"
+	}
+	return WithResponseMetadata(NewTextResponse(truncateOutput(result)), metadata), nil
+}
+
+// goFunctionResults finds the function called by the harness in the package
+// in dir and returns the package name and the number of values it returns,
+// which decides whether the call can be printed.
+func goFunctionResults(dir, function string) (string, int, error) {
+	name, method := function, false
+	if i := strings.LastIndex(function, "."); i >= 0 {
+		name, method = function[i+1:], true
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return "", 0, fmt.Errorf("error listing Go files: %w", err)
+	}
+	fset := token.NewFileSet()
+	pkgName := ""
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		pkgName = f.Name.Name
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Name.Name != name || (fn.Recv != nil) != method {
+				continue
+			}
+			results := 0
+			if fn.Type.Results != nil {
+				for _, field := range fn.Type.Results.List {
+					results += max(1, len(field.Names))
+				}
+			}
+			return pkgName, results, nil
+		}
+	}
+	if pkgName == "" {
+		return "", 0, fmt.Errorf("no Go package found in %s", dir)
+	}
+	return "", 0, fmt.Errorf("function %s not found in package %s", function, pkgName)
+}
+
+// generateHarness writes the test that calls the function and prints its
+// results.
+func generateHarness(pkgName string, params RunFunctionParams, results int) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %s\n\nimport (\n\t\"fmt\"\n\t\"testing\"\n", pkgName)
+	for _, imp := range params.Imports {
+		imp = strings.Trim(strings.TrimSpace(imp), `"`)
+		if imp == "" || imp == "fmt" || imp == "testing" {
+			continue
+		}
+		fmt.Fprintf(&b, "\t%q\n", imp)
+	}
+	b.WriteString(")\n\n")
+
+	fmt.Fprintf(&b, "func %s(t *testing.T) {\n", harnessTestName)
+	b.WriteString("\tdefer func() {\n\t\tif r := recover(); r != nil {\n\t\t\tfmt.Printf(\"panic: %v\\n\", r)\n\t\t}\n\t}()\n")
+	if setup := strings.TrimSpace(params.Setup); setup != "" {
+		b.WriteString(setup + "\n")
+	}
+	call := fmt.Sprintf("%s(%s)", params.Function, strings.Join(params.Args, ", "))
+	if results == 0 {
+		fmt.Fprintf(&b, "\t%s\n\tfmt.Println(\"returned no values\")\n", call)
+	} else {
+		fmt.Fprintf(&b, "\topencodeShowResults(%s)\n", call)
+	}
+	b.WriteString("}\n\n")
+
+	b.WriteString(`func opencodeShowResults(results ...any) {
+	for i, r := range results {
+		if err, ok := r.(error); ok {
+			fmt.Printf("result %d: error: %v\n", i, err)
+			continue
+		}
+		fmt.Printf("result %d: %#v\n", i, r)
+	}
+}
+`)
+
+	formatted, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return "", fmt.Errorf("the generated harness is not valid Go, check the arguments and setup: %s\n\n%s", err, b.String())
+	}
+	return string(formatted), nil
+}
+
+// harnessOutput drops the lines go test adds around the output of the call.
+func harnessOutput(output string) string {
+	var kept []string
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "=== RUN"),
+			strings.HasPrefix(line, "--- PASS"),
+			strings.HasPrefix(line, "--- FAIL"),
+			line == "PASS",
+			line == "FAIL",
+			strings.HasPrefix(line, "ok  "),
+			strings.HasPrefix(line, "FAIL\t"):
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
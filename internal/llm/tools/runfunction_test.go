@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/permission"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// allowAll is a permission service that grants every request.
+type allowAll struct {
+	permission.Service
+}
+
+func (allowAll) Request(permission.CreatePermissionRequest) bool { return true }
+
+func TestRunFunction(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not found")
+	}
+	// Keep the build cache, which defaults to a directory under $HOME.
+	goCache, err := exec.Command("go", "env", "GOCACHE").Output()
+	require.NoError(t, err)
+	t.Setenv("GOCACHE", strings.TrimSpace(string(goCache)))
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	workingDir := t.TempDir()
+	cfg, err := config.Load(workingDir, false)
+	require.NoError(t, err)
+	pkg := filepath.Join(cfg.WorkingDir, "calc")
+	require.NoError(t, os.MkdirAll(pkg, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.WorkingDir, "go.mod"), []byte("module example.com/calc\n\ngo 1.24\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(pkg, "calc.go"), []byte(`package calc
+
+func add(a, b int) int { return a + b }
+
+func hang() { select {} }
+`), 0o644))
+
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "session")
+	ctx = context.WithValue(ctx, MessageIDContextKey, "message")
+	tool := NewRunFunctionTool(allowAll{})
+	run := func(t *testing.T, params RunFunctionParams) ToolResponse {
+		input, err := json.Marshal(params)
+		require.NoError(t, err)
+		response, err := tool.Run(ctx, ToolCall{Name: RunFunctionToolName, Input: string(input)})
+		require.NoError(t, err)
+		return response
+	}
+
+	t.Run("success", func(t *testing.T) {
+		response := run(t, RunFunctionParams{Path: pkg, Function: "add", Args: []string{"2", "3"}})
+		assert.False(t, response.IsError, response.Content)
+		assert.Equal(t, "result 0: 5", response.Content)
+	})
+
+	t.Run("compile error", func(t *testing.T) {
+		response := run(t, RunFunctionParams{Path: pkg, Function: "add", Args: []string{`"2"`, "3"}})
+		assert.True(t, response.IsError)
+		assert.Contains(t, response.Content, "the harness did not build")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		start := time.Now()
+		response := run(t, RunFunctionParams{Path: pkg, Function: "hang", Timeout: 1})
+		assert.True(t, response.IsError)
+		assert.Contains(t, response.Content, "did not finish within 1 seconds")
+		assert.Less(t, time.Since(start), 30*time.Second)
+	})
+
+	t.Run("outside write root", func(t *testing.T) {
+		outside := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(outside, "calc.go"), []byte("package calc\n\nfunc add(a, b int) int { return a + b }\n"), 0o644))
+		response := run(t, RunFunctionParams{Path: outside, Function: "add", Args: []string{"2", "3"}})
+		assert.True(t, response.IsError)
+		assert.Contains(t, response.Content, "outside the write root")
+		entries, err := os.ReadDir(outside)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	matches, err := filepath.Glob(filepath.Join(pkg, "opencode_harness_*"))
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
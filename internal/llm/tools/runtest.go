@@ -0,0 +1,249 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/tools/shell"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+type RunTestParams struct {
+	Test    string `json:"test"`
+	Package string `json:"package"`
+	Timeout int    `json:"timeout"`
+}
+
+type RunTestResponseMetadata struct {
+	Test     string   `json:"test"`
+	Packages []string `json:"packages"`
+	Passed   bool     `json:"passed"`
+	Duration int64    `json:"duration"`
+}
+
+type runTestTool struct{}
+
+const (
+	RunTestToolName       = "run_test"
+	defaultRunTestTimeout = 120
+	maxRunTestTimeout     = 600
+	maxRunTestPackages    = 5
+	maxReportedTestLines  = 150
+	runTestDescription    = `Runs a single Go test, or one of its subtests, and reports whether it passes with its output.
+
+WHEN TO USE THIS TOOL:
+- Use to check that a targeted fix makes a failing test pass
+- Use to run the test that covers the code you just changed, without running the whole suite
+- Helpful for iterating quickly on a fix: edit, run the test, repeat
+
+HOW TO USE:
+- Provide the test name, e.g. "TestParse", or "TestParse/empty input" for a subtest
+- The package containing the test is found automatically; set package (a directory such as "./internal/parser") when the name is ambiguous or to save the search
+- Optionally set a timeout in seconds (default 120, max 600)
+
+FEATURES:
+- Runs "go test -count=1 -v -run" with the name anchored, so only that test runs and results are never cached
+- Reports pass or fail, the duration and the test output
+- Runs the test in every package that defines it, up to 5 packages
+
+LIMITATIONS:
+- Only works for Go modules
+- The package and its other test files must compile
+- Long outputs are truncated; use the continuation token to read the rest
+
+TIPS:
+- Use Grep to find the test that covers a function before running it
+- Run the test before your fix to see it fail, then after to see it pass
+- Use the build_check tool first when a change may break the build`
+)
+
+var goTestFuncPattern = regexp.MustCompile(`(?m)^func\s+(Test\w*)\s*\(`)
+
+func NewRunTestTool() BaseTool {
+	return &runTestTool{}
+}
+
+func (r *runTestTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        RunTestToolName,
+		Description: runTestDescription,
+		Parameters: map[string]any{
+			"test": map[string]any{
+				"type":        "string",
+				"description": "The test to run, e.g. TestParse or TestParse/subtest",
+			},
+			"package": map[string]any{
+				"type":        "string",
+				"description": "The directory of the package containing the test (found automatically when omitted)",
+			},
+			"timeout": map[string]any{
+				"type":        "number",
+				"description": "Timeout in seconds (default 120, max 600)",
+			},
+		},
+		Required: []string{"test"},
+	}
+}
+
+func (r *runTestTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params RunTestParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+	params.Test = strings.TrimSpace(params.Test)
+	if params.Test == "" {
+		return NewTextErrorResponse("test is required"), nil
+	}
+	topLevel, _, _ := strings.Cut(params.Test, "/")
+	if !strings.HasPrefix(topLevel, "Test") {
+		return NewTextErrorResponse(fmt.Sprintf("%q is not a test name; test functions start with Test", topLevel)), nil
+	}
+	if params.Timeout <= 0 {
+		params.Timeout = defaultRunTestTimeout
+	} else if params.Timeout > maxRunTestTimeout {
+		params.Timeout = maxRunTestTimeout
+	}
+
+	workingDir := config.WorkingDirectory()
+	if _, err := os.Stat(filepath.Join(workingDir, "go.mod")); err != nil {
+		return NewTextErrorResponse("run_test only supports Go modules, no go.mod found in the working directory"), nil
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		return NewTextErrorResponse("the go toolchain was not found in $PATH"), nil
+	}
+
+	var packages []string
+	if params.Package != "" {
+		dir := params.Package
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(workingDir, dir)
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return NewTextErrorResponse(fmt.Sprintf("package directory not found: %s", params.Package)), nil
+		}
+		packages = []string{goPackagePattern(workingDir, dir)}
+	} else {
+		dirs, err := findTestPackages(workingDir, topLevel)
+		if err != nil {
+			return ToolResponse{}, fmt.Errorf("error searching for the test: %w", err)
+		}
+		if len(dirs) == 0 {
+			return NewTextErrorResponse(fmt.Sprintf("no test named %s found in the module", topLevel)), nil
+		}
+		if len(dirs) > maxRunTestPackages {
+			return NewTextErrorResponse(fmt.Sprintf("%s is defined in %d packages, set package to pick one:\n%s", topLevel, len(dirs), strings.Join(dirs, "\n"))), nil
+		}
+		packages = dirs
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, time.Duration(params.Timeout)*time.Second)
+	defer cancel()
+	args := append([]string{"test", "-count=1", "-v", "-run", goTestRunPattern(params.Test)}, packages...)
+	cmd := exec.CommandContext(runCtx, "go", args...)
+	cmd.Dir = workingDir
+	if env, err := shell.ProjectEnv(); err != nil {
+		logging.Warn("Env file not loaded", "error", err)
+	} else if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	start := time.Now()
+	output, runErr := cmd.CombinedOutput()
+	duration := time.Since(start)
+	if ctx.Err() != nil {
+		return ToolResponse{}, ctx.Err()
+	}
+
+	metadata := RunTestResponseMetadata{
+		Test:     params.Test,
+		Packages: packages,
+		Passed:   runErr == nil,
+		Duration: duration.Milliseconds(),
+	}
+	out := strings.TrimSpace(string(output))
+	if runCtx.Err() == context.DeadlineExceeded {
+		return WithResponseMetadata(NewTextErrorResponse(fmt.Sprintf("the test did not finish within %d seconds\n\n%s", params.Timeout, truncateLines(RunTestToolName, out, maxReportedTestLines))), metadata), nil
+	}
+	if runErr != nil {
+		if _, ok := runErr.(*exec.ExitError); !ok {
+			return ToolResponse{}, fmt.Errorf("error running go test: %w", runErr)
+		}
+	}
+	if runErr == nil && strings.Contains(out, "testing: warning: no tests to run") {
+		return WithResponseMetadata(NewTextErrorResponse(fmt.Sprintf("no test matched %s in %s\n\n%s", params.Test, strings.Join(packages, " "), out)), metadata), nil
+	}
+
+	status := "PASS"
+	if runErr != nil {
+		status = "FAIL"
+	}
+	result := fmt.Sprintf("%s: %s in %s (%s)\n\n%s", status, params.Test, strings.Join(packages, " "), duration.Round(10*time.Millisecond), truncateLines(RunTestToolName, out, maxReportedTestLines))
+	return WithResponseMetadata(NewTextResponse(result), metadata), nil
+}
+
+// findTestPackages returns the packages under root, as ./relative patterns,
+// whose test files define the test function name.
+func findTestPackages(root, name string) ([]string, error) {
+	seen := make(map[string]bool)
+	var packages []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			base := d.Name()
+			if path != root && (strings.HasPrefix(base, ".") || base == "node_modules" || base == "testdata" || base == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		dir := filepath.Dir(path)
+		if !strings.HasSuffix(path, "_test.go") || seen[dir] {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		for _, match := range goTestFuncPattern.FindAllSubmatch(content, -1) {
+			if string(match[1]) == name {
+				seen[dir] = true
+				packages = append(packages, goPackagePattern(root, dir))
+				break
+			}
+		}
+		return nil
+	})
+	return packages, err
+}
+
+// goPackagePattern returns dir as a package pattern relative to root.
+func goPackagePattern(root, dir string) string {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return dir
+	}
+	if rel == "." {
+		return "."
+	}
+	return "./" + filepath.ToSlash(rel)
+}
+
+// goTestRunPattern anchors every level of a test name, so "TestA/b" only
+// runs subtest b of TestA and not TestAB. Go test replaces spaces in subtest
+// names with underscores.
+func goTestRunPattern(test string) string {
+	parts := strings.Split(test, "/")
+	for i, part := range parts {
+		parts[i] = "^" + regexp.QuoteMeta(strings.ReplaceAll(part, " ", "_")) + "$"
+	}
+	return strings.Join(parts, "/")
+}
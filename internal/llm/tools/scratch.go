@@ -0,0 +1,198 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+type ScratchParams struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+type ScratchResponseMetadata struct {
+	FilePath string `json:"file_path"`
+}
+
+type scratchTool struct{}
+
+const (
+	ScratchToolName    = "scratch"
+	scratchDescription = `Creates a temporary scratch file outside of the project tree and returns its absolute path.
+
+WHEN TO USE THIS TOOL:
+- Use when you need a temporary file to test a snippet or hold intermediate output
+- Helpful for experiments that should not end up in the user's project
+- Prefer this over creating temp files in the working directory
+
+HOW TO USE:
+- Optionally provide a name (the extension is preserved, e.g. "main.go" or "data.json")
+- Optionally provide initial content for the file
+- Use the returned path with the Write, Edit, View and Bash tools
+
+FEATURES:
+- Files are created in a dedicated scratch directory owned by the session
+- Every scratch file is deleted automatically when the session ends
+- Files are marked as read, so they can be edited right away
+
+LIMITATIONS:
+- Scratch files do not survive the end of the session
+- Do not use scratch files for anything the user asked you to keep
+
+TIPS:
+- Keep the file extension meaningful so language tooling can recognize the file
+- Move anything worth keeping into the project with the Write tool before finishing`
+)
+
+var (
+	scratchFiles      = make(map[string][]string)
+	scratchFilesMutex sync.Mutex
+)
+
+func NewScratchTool() BaseTool {
+	return &scratchTool{}
+}
+
+func (s *scratchTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ScratchToolName,
+		Description: scratchDescription,
+		Parameters: map[string]any{
+			"name": map[string]any{
+				"type":        "string",
+				"description": "Optional file name for the scratch file, used to keep a meaningful extension",
+			},
+			"content": map[string]any{
+				"type":        "string",
+				"description": "Optional initial content of the scratch file",
+			},
+		},
+		Required: []string{},
+	}
+}
+
+func (s *scratchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params ScratchParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+
+	sessionID, _ := GetContextValues(ctx)
+	if sessionID == "" {
+		return ToolResponse{}, fmt.Errorf("session_id is required")
+	}
+
+	dir := filepath.Join(scratchDirectory(), sessionID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return ToolResponse{}, fmt.Errorf("error creating scratch directory: %w", err)
+	}
+
+	pattern := "scratch-*"
+	if params.Name != "" {
+		pattern = "*-" + filepath.Base(params.Name)
+	}
+
+	file, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return ToolResponse{}, fmt.Errorf("error creating scratch file: %w", err)
+	}
+	filePath := file.Name()
+
+	_, err = file.WriteString(params.Content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(filePath)
+		return ToolResponse{}, fmt.Errorf("error writing scratch file: %w", err)
+	}
+
+	scratchFilesMutex.Lock()
+	scratchFiles[sessionID] = append(scratchFiles[sessionID], filePath)
+	scratchFilesMutex.Unlock()
+
+	recordFileWrite(ctx, filePath)
+	recordFileRead(ctx, filePath)
+
+	result := fmt.Sprintf("Scratch file created: %s\nIt will be deleted automatically when the session ends.", filePath)
+	return WithResponseMetadata(
+		NewTextResponse(result),
+		ScratchResponseMetadata{
+			FilePath: filePath,
+		},
+	), nil
+}
+
+// CleanupScratchFiles removes every scratch file created for the given session.
+func CleanupScratchFiles(sessionID string) {
+	scratchFilesMutex.Lock()
+	delete(scratchFiles, sessionID)
+	scratchFilesMutex.Unlock()
+
+	if sessionID == "" {
+		return
+	}
+	if err := os.RemoveAll(filepath.Join(scratchDirectory(), sessionID)); err != nil {
+		logging.Warn("Failed to remove scratch files", "session", sessionID, "error", err)
+	}
+}
+
+// CleanupAllScratchFiles removes the scratch files of every session.
+func CleanupAllScratchFiles() {
+	scratchFilesMutex.Lock()
+	sessionIDs := make([]string, 0, len(scratchFiles))
+	for sessionID := range scratchFiles {
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	scratchFilesMutex.Unlock()
+
+	for _, sessionID := range sessionIDs {
+		CleanupScratchFiles(sessionID)
+	}
+}
+
+// staleScratchAge is how long the scratch files of a session that still
+// exists are kept when a run did not exit cleanly. A newer directory may
+// belong to another instance running on the same data directory.
+const staleScratchAge = 24 * time.Hour
+
+// SweepScratchFiles removes the scratch directories left behind by runs that
+// did not exit cleanly: those of deleted sessions, for which exists reports
+// false, and those not written to for staleScratchAge.
+func SweepScratchFiles(exists func(sessionID string) bool) {
+	entries, err := os.ReadDir(scratchDirectory())
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if time.Since(info.ModTime()) < staleScratchAge && exists(entry.Name()) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(scratchDirectory(), entry.Name())); err != nil {
+			logging.Warn("Failed to remove stale scratch files", "session", entry.Name(), "error", err)
+		}
+	}
+}
+
+func scratchDirectory() string {
+	dataDir := config.Get().Data.Directory
+	if !filepath.IsAbs(dataDir) {
+		dataDir = filepath.Join(config.WorkingDirectory(), dataDir)
+	}
+	return filepath.Join(dataDir, "scratch")
+}
@@ -0,0 +1,36 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSweepScratchFiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	cfg, err := config.Load(t.TempDir(), false)
+	require.NoError(t, err)
+	dataDir := cfg.Data.Directory
+	cfg.Data.Directory = t.TempDir()
+	t.Cleanup(func() { cfg.Data.Directory = dataDir })
+
+	for _, id := range []string{"live", "deleted", "old"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(scratchDirectory(), id), 0o755))
+	}
+	old := time.Now().Add(-2 * staleScratchAge)
+	require.NoError(t, os.Chtimes(filepath.Join(scratchDirectory(), "old"), old, old))
+
+	SweepScratchFiles(func(sessionID string) bool { return sessionID != "deleted" })
+
+	entries, err := os.ReadDir(scratchDirectory())
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "live", entries[0].Name())
+}
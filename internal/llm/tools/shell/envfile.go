@@ -0,0 +1,116 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/config"
+)
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ProjectEnv returns the variables of the project's env file as KEY=VALUE
+// pairs, ready to be appended to a command's environment. It returns nothing
+// when no env file is configured.
+func ProjectEnv() ([]string, error) {
+	cfg := config.Get()
+	if cfg == nil || cfg.Shell.EnvFile == "" {
+		return nil, nil
+	}
+	path := cfg.Shell.EnvFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(config.WorkingDirectory(), path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+	vars, err := ParseEnvFile(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", cfg.Shell.EnvFile, err)
+	}
+	return vars, nil
+}
+
+// ParseEnvFile parses the KEY=VALUE lines of a .env file. Blank lines,
+// comments and an "export " prefix are ignored. Values may be single quoted,
+// taken literally, or double quoted, where \n, \t, \" and \\ are unescaped;
+// a # after whitespace starts a comment in unquoted values.
+func ParseEnvFile(content string) ([]string, error) {
+	var vars []string
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !envNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", i+1)
+		}
+		value, err := parseEnvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		vars = append(vars, name+"="+value)
+	}
+	return vars, nil
+}
+
+func parseEnvValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	switch quote := value[0]; quote {
+	case '\'':
+		end := strings.IndexByte(value[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quote")
+		}
+		return value[1 : end+1], nil
+	case '"':
+		var b strings.Builder
+		for i := 1; i < len(value); i++ {
+			c := value[i]
+			switch {
+			case c == '"':
+				return b.String(), nil
+			case c == '\\' && i+1 < len(value):
+				i++
+				switch value[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				case '"', '\\':
+					b.WriteByte(value[i])
+				default:
+					b.WriteByte('\\')
+					b.WriteByte(value[i])
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", fmt.Errorf("unterminated quote")
+	}
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value, nil
+}
+
+// RedactEnv lists the names of the variables with their values hidden, to
+// show which variables were loaded without exposing secrets.
+func RedactEnv(vars []string) string {
+	names := make([]string, 0, len(vars))
+	for _, kv := range vars {
+		name, _, _ := strings.Cut(kv, "=")
+		names = append(names, name+"=***")
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,172 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/logging"
+)
+
+// Sandbox restricts what the commands run by the bash tool can touch. The
+// persistent shell is started through the configured sandbox, so every
+// command it runs inherits the restrictions.
+type Sandbox interface {
+	// Available returns an error explaining why the sandbox cannot be used
+	// on this system, or nil if it can.
+	Available() error
+	// Command returns the command that starts the shell inside the sandbox.
+	Command(shellPath string, shellArgs []string, cwd string, cfg config.SandboxConfig) *exec.Cmd
+}
+
+var (
+	sandboxesMu sync.RWMutex
+	sandboxes   = map[string]Sandbox{
+		"env":   envSandbox{},
+		"bwrap": bwrapSandbox{},
+	}
+	sandboxWarnOnce sync.Once
+)
+
+// RegisterSandbox makes a sandbox backend available under name, replacing
+// any backend registered with the same name.
+func RegisterSandbox(name string, sandbox Sandbox) {
+	sandboxesMu.Lock()
+	defer sandboxesMu.Unlock()
+	sandboxes[name] = sandbox
+}
+
+// defaultSandboxEnv are the environment variables kept in a sandbox on top
+// of the ones allowed in the configuration.
+var defaultSandboxEnv = []string{
+	"HOME",
+	"LANG",
+	"LC_ALL",
+	"LC_CTYPE",
+	"LOGNAME",
+	"PATH",
+	"SHELL",
+	"TERM",
+	"TMPDIR",
+	"TZ",
+	"USER",
+}
+
+// shellCommand returns the command starting the persistent shell, inside the
+// configured sandbox when there is one. sandboxed reports whether a sandbox
+// is used. When the sandbox is unknown or unavailable the shell runs
+// directly and a warning is logged.
+func shellCommand(shellPath string, shellArgs []string, cwd string) (cmd *exec.Cmd, sandboxed bool) {
+	var cfg config.SandboxConfig
+	if c := config.Get(); c != nil {
+		cfg = c.Shell.Sandbox
+	}
+
+	if cfg.Backend != "" && cfg.Backend != "none" {
+		sandboxesMu.RLock()
+		sandbox, ok := sandboxes[cfg.Backend]
+		sandboxesMu.RUnlock()
+
+		var err error
+		if !ok {
+			err = fmt.Errorf("unknown sandbox backend %q", cfg.Backend)
+		} else {
+			err = sandbox.Available()
+		}
+		if err == nil {
+			cmd = sandbox.Command(shellPath, shellArgs, cwd, cfg)
+			cmd.Env = append(cmd.Env, "GIT_EDITOR=true")
+			return cmd, true
+		}
+		sandboxWarnOnce.Do(func() {
+			logging.WarnPersist(fmt.Sprintf("Shell sandbox unavailable, running commands without it: %v", err))
+		})
+	}
+
+	cmd = exec.Command(shellPath, shellArgs...)
+	cmd.Dir = cwd
+	cmd.Env = append(os.Environ(), "GIT_EDITOR=true")
+	return cmd, false
+}
+
+// sandboxEnv returns the environment with only the allowed variables.
+func sandboxEnv(cfg config.SandboxConfig) []string {
+	allowed := make(map[string]bool)
+	for _, name := range append(defaultSandboxEnv, cfg.AllowEnv...) {
+		allowed[name] = true
+	}
+	env := make([]string, 0, len(allowed))
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if allowed[name] {
+			env = append(env, kv)
+		}
+	}
+	sort.Strings(env)
+	return env
+}
+
+// envSandbox only scrubs the environment. It is available everywhere.
+type envSandbox struct{}
+
+func (envSandbox) Available() error {
+	return nil
+}
+
+func (envSandbox) Command(shellPath string, shellArgs []string, cwd string, cfg config.SandboxConfig) *exec.Cmd {
+	cmd := exec.Command(shellPath, shellArgs...)
+	cmd.Dir = cwd
+	cmd.Env = sandboxEnv(cfg)
+	return cmd
+}
+
+// bwrapSandbox runs the shell with bubblewrap: the file system is read-only
+// except for the write root, the temporary directory and the configured
+// writable paths, the environment is scrubbed and, unless allowed, there is
+// no network.
+type bwrapSandbox struct{}
+
+func (bwrapSandbox) Available() error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("bwrap is only supported on Linux")
+	}
+	if _, err := exec.LookPath("bwrap"); err != nil {
+		return fmt.Errorf("bwrap not found in PATH")
+	}
+	return nil
+}
+
+func (bwrapSandbox) Command(shellPath string, shellArgs []string, cwd string, cfg config.SandboxConfig) *exec.Cmd {
+	args := []string{
+		"--ro-bind", "/", "/",
+		"--dev", "/dev",
+		"--bind", os.TempDir(), os.TempDir(),
+		"--bind", config.WriteRoot(), config.WriteRoot(),
+	}
+	for _, path := range cfg.Writable {
+		if _, err := os.Stat(path); err == nil {
+			args = append(args, "--bind", path, path)
+		}
+	}
+	args = append(args,
+		"--unshare-user-try",
+		"--unshare-ipc",
+		"--unshare-uts",
+		"--unshare-cgroup-try",
+	)
+	if !cfg.Network {
+		args = append(args, "--unshare-net")
+	}
+	args = append(args, "--die-with-parent", "--chdir", cwd, "--", shellPath)
+	args = append(args, shellArgs...)
+
+	cmd := exec.Command("bwrap", args...)
+	cmd.Dir = cwd
+	cmd.Env = sandboxEnv(cfg)
+	return cmd
+}
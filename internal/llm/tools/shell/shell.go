@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/logging"
 )
 
 type PersistentShell struct {
@@ -20,6 +21,9 @@ type PersistentShell struct {
 	stdin        *os.File
 	isAlive      bool
 	cwd          string
+	// pid is the process ID of the shell, which differs from the one of cmd
+	// when the shell runs inside a sandbox.
+	pid          int
 	mu           sync.Mutex
 	commandQueue chan *commandExecution
 }
@@ -29,6 +33,7 @@ type commandExecution struct {
 	timeout    time.Duration
 	resultChan chan commandResult
 	ctx        context.Context
+	onOutput   func(stdout, stderr string)
 }
 
 type commandResult struct {
@@ -39,23 +44,59 @@ type commandResult struct {
 	err         error
 }
 
+// shellRecoveryTimeout is how long a shell gets to finish an interrupted
+// command once its children are killed, before it is replaced.
+const shellRecoveryTimeout = 2 * time.Second
+
 var (
-	shellInstance     *PersistentShell
-	shellInstanceOnce sync.Once
+	shellsMu sync.Mutex
+	shells   = make(map[string]*PersistentShell)
 )
 
-func GetPersistentShell(workingDir string) *PersistentShell {
-	shellInstanceOnce.Do(func() {
-		shellInstance = newPersistentShell(workingDir)
-	})
+// GetPersistentShell returns the shell of the session, so the working
+// directory and exported variables carry across its commands. A new shell
+// starts in workingDir, or in the last directory of the one it replaces. It
+// returns nil when the shell cannot be started.
+func GetPersistentShell(sessionID, workingDir string) *PersistentShell {
+	shellsMu.Lock()
+	defer shellsMu.Unlock()
+
+	shell := shells[sessionID]
+	switch {
+	case shell == nil:
+		shell = newPersistentShell(workingDir)
+	case !shell.isAlive:
+		shell = newPersistentShell(shell.cwd)
+	default:
+		return shell
+	}
+	if shell != nil {
+		shells[sessionID] = shell
+	}
+	return shell
+}
+
+// ResetShell kills the shell of the session, along with any command it is
+// running, so the next command starts a fresh one in the working directory.
+func ResetShell(sessionID string) {
+	shellsMu.Lock()
+	defer shellsMu.Unlock()
 
-	if shellInstance == nil {
-		shellInstance = newPersistentShell(workingDir)
-	} else if !shellInstance.isAlive {
-		shellInstance = newPersistentShell(shellInstance.cwd)
+	if shell, ok := shells[sessionID]; ok {
+		shell.kill()
+		delete(shells, sessionID)
 	}
+}
+
+// CloseAll kills the shells of all sessions.
+func CloseAll() {
+	shellsMu.Lock()
+	defer shellsMu.Unlock()
 
-	return shellInstance
+	for sessionID, shell := range shells {
+		shell.kill()
+		delete(shells, sessionID)
+	}
 }
 
 func newPersistentShell(cwd string) *PersistentShell {
@@ -83,16 +124,21 @@ func newPersistentShell(cwd string) *PersistentShell {
 		shellArgs = []string{"-l"}
 	}
 
-	cmd := exec.Command(shellPath, shellArgs...)
-	cmd.Dir = cwd
+	cmd, sandboxed := shellCommand(shellPath, shellArgs, cwd)
+	// The env file is opted into per project, so its variables are kept
+	// even in a sandbox.
+	if env, err := ProjectEnv(); err != nil {
+		logging.WarnPersist(fmt.Sprintf("Env file not loaded: %v", err))
+	} else if len(env) > 0 {
+		cmd.Env = append(cmd.Env, env...)
+		logging.InfoPersist(fmt.Sprintf("Loaded %d variables from %s: %s", len(env), cfg.Shell.EnvFile, RedactEnv(env)))
+	}
 
 	stdinPipe, err := cmd.StdinPipe()
 	if err != nil {
 		return nil
 	}
 
-	cmd.Env = append(os.Environ(), "GIT_EDITOR=true")
-
 	err = cmd.Start()
 	if err != nil {
 		return nil
@@ -103,8 +149,12 @@ func newPersistentShell(cwd string) *PersistentShell {
 		stdin:        stdinPipe.(*os.File),
 		isAlive:      true,
 		cwd:          cwd,
+		pid:          cmd.Process.Pid,
 		commandQueue: make(chan *commandExecution, 10),
 	}
+	if sandboxed {
+		shell.pid = shell.shellPid(cmd.Process.Pid)
+	}
 
 	go func() {
 		defer func() {
@@ -129,14 +179,42 @@ func newPersistentShell(cwd string) *PersistentShell {
 	return shell
 }
 
+// shellPid asks the shell for its process ID, falling back to fallback when
+// it does not answer in time.
+func (s *PersistentShell) shellPid(fallback int) int {
+	pidFile := filepath.Join(os.TempDir(), fmt.Sprintf("opencode-pid-%d", time.Now().UnixNano()))
+	defer os.Remove(pidFile)
+
+	if _, err := s.stdin.Write([]byte(fmt.Sprintf("echo $$ > %s\n", shellQuote(pidFile)))); err != nil {
+		return fallback
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		var pid int
+		if _, err := fmt.Sscanf(readFileOrEmpty(pidFile), "%d", &pid); err == nil && pid > 0 {
+			return pid
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return fallback
+}
+
 func (s *PersistentShell) processCommands() {
 	for cmd := range s.commandQueue {
-		result := s.execCommand(cmd.command, cmd.timeout, cmd.ctx)
+		result := s.execCommand(cmd.command, cmd.timeout, cmd.ctx, cmd.onOutput)
 		cmd.resultChan <- result
 	}
 }
 
-func (s *PersistentShell) execCommand(command string, timeout time.Duration, ctx context.Context) commandResult {
+// outputInterval is how often the output of a running command is reported.
+const (
+	outputInterval = 500 * time.Millisecond
+	// maxOutputTail is how much of the end of stdout and stderr is passed
+	// to onOutput, so long outputs are not read again every interval.
+	maxOutputTail = 16 * 1024
+)
+
+func (s *PersistentShell) execCommand(command string, timeout time.Duration, ctx context.Context, onOutput func(stdout, stderr string)) commandResult {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -186,6 +264,8 @@ echo $EXEC_EXIT_CODE > %s
 	interrupted := false
 
 	startTime := time.Now()
+	lastOutput := startTime
+	var lastOutputSize int64
 
 	done := make(chan bool)
 	go func() {
@@ -203,6 +283,14 @@ echo $EXEC_EXIT_CODE > %s
 					return
 				}
 
+				if onOutput != nil && time.Since(lastOutput) >= outputInterval {
+					lastOutput = time.Now()
+					if size := fileSize(stdoutFile) + fileSize(stderrFile); size != lastOutputSize {
+						lastOutputSize = size
+						onOutput(readFileTail(stdoutFile, maxOutputTail), readFileTail(stderrFile, maxOutputTail))
+					}
+				}
+
 				if timeout > 0 {
 					elapsed := time.Since(startTime)
 					if elapsed > timeout {
@@ -234,6 +322,12 @@ echo $EXEC_EXIT_CODE > %s
 	if newCwd != "" {
 		s.cwd = strings.TrimSpace(newCwd)
 	}
+	if interrupted && exitCodeStr == "" && !waitForFile(statusFile, shellRecoveryTimeout) {
+		// The shell itself is stuck, e.g. in a builtin loop that killing its
+		// children cannot stop, so it is replaced on the next command.
+		logging.Warn("Shell did not recover from an interrupted command, restarting it")
+		s.kill()
+	}
 
 	return commandResult{
 		stdout:      stdout,
@@ -248,7 +342,7 @@ func (s *PersistentShell) killChildren() {
 		return
 	}
 
-	pgrepCmd := exec.Command("pgrep", "-P", fmt.Sprintf("%d", s.cmd.Process.Pid))
+	pgrepCmd := exec.Command("pgrep", "-P", fmt.Sprintf("%d", s.pid))
 	output, err := pgrepCmd.Output()
 	if err != nil {
 		return
@@ -268,7 +362,23 @@ func (s *PersistentShell) killChildren() {
 	}
 }
 
+// kill stops the shell and its children without waiting for the command it
+// is running.
+func (s *PersistentShell) kill() {
+	s.killChildren()
+	if s.cmd != nil && s.cmd.Process != nil {
+		s.cmd.Process.Kill()
+	}
+	s.isAlive = false
+}
+
 func (s *PersistentShell) Exec(ctx context.Context, command string, timeoutMs int) (string, string, int, bool, error) {
+	return s.ExecWithOutput(ctx, command, timeoutMs, nil)
+}
+
+// ExecWithOutput works like Exec and periodically calls onOutput with the end
+// of the stdout and stderr produced so far while the command is running.
+func (s *PersistentShell) ExecWithOutput(ctx context.Context, command string, timeoutMs int, onOutput func(stdout, stderr string)) (string, string, int, bool, error) {
 	if !s.isAlive {
 		return "", "Shell is not alive", 1, false, errors.New("shell is not alive")
 	}
@@ -281,6 +391,7 @@ func (s *PersistentShell) Exec(ctx context.Context, command string, timeoutMs in
 		timeout:    timeout,
 		resultChan: resultChan,
 		ctx:        ctx,
+		onOutput:   onOutput,
 	}
 
 	result := <-resultChan
@@ -313,6 +424,42 @@ func readFileOrEmpty(path string) string {
 	return string(content)
 }
 
+// readFileTail returns at most the last n bytes of the file, starting at a
+// line boundary when it does not return the whole file.
+func readFileTail(path string, n int64) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return ""
+	}
+	offset := max(0, info.Size()-n)
+	buf := make([]byte, info.Size()-offset)
+	read, _ := f.ReadAt(buf, offset)
+	tail := string(buf[:read])
+	if offset > 0 {
+		if i := strings.IndexByte(tail, '\n'); i >= 0 {
+			tail = tail[i+1:]
+		}
+	}
+	return tail
+}
+
+// waitForFile reports whether path exists and is not empty within timeout.
+func waitForFile(path string, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if fileExists(path) && fileSize(path) > 0 {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
 func fileExists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
@@ -7,13 +7,18 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
 )
 
 type SourcegraphParams struct {
 	Query         string `json:"query"`
 	Count         int    `json:"count,omitempty"`
+	All           bool   `json:"all,omitempty"`
 	ContextWindow int    `json:"context_window,omitempty"`
 	Timeout       int    `json:"timeout,omitempty"`
 }
@@ -29,7 +34,18 @@ type sourcegraphTool struct {
 
 const (
 	SourcegraphToolName        = "sourcegraph"
-	sourcegraphToolDescription = `Search code across public repositories using Sourcegraph's GraphQL API.
+	defaultSourcegraphEndpoint = "https://sourcegraph.com"
+	sourcegraphGraphQLPath     = "/.api/graphql"
+	defaultSourcegraphCount    = 10
+	// fallbackSourcegraphMaxResults applies when the configured maximum is
+	// not set.
+	fallbackSourcegraphMaxResults = 100
+	maxSourcegraphOutputLength    = 60000
+	// maxSourcegraphResponseSize bounds the response read from the API, a
+	// larger one is refused rather than held in memory.
+	maxSourcegraphResponseSize = 20 * 1024 * 1024
+	maxSourcegraphErrorBody    = 1000
+	sourcegraphToolDescription = `Search code across public repositories using Sourcegraph's GraphQL API. When a private Sourcegraph instance is configured, its repositories are searched instead.
 
 WHEN TO USE THIS TOOL:
 - Use when you need to find code examples or implementations across public repositories
@@ -38,7 +54,7 @@ WHEN TO USE THIS TOOL:
 
 HOW TO USE:
 - Provide a search query using Sourcegraph's query syntax
-- Optionally specify the number of results to return (default: 10)
+- Optionally specify the number of results to return (default: 10), or set all to get as many as allowed
 - Optionally set a timeout for the request
 
 QUERY SYNTAX:
@@ -113,10 +129,11 @@ BOOLEAN OPERATORS:
 - "term1 and (term2 or term3)" - Grouping with parentheses
 
 LIMITATIONS:
-- Only searches public repositories
+- Only searches public repositories, unless a private instance is configured
 - Rate limits may apply
 - Complex queries may take longer to execute
-- Maximum of 20 results per query
+- The number of results per search is capped by the configuration (100 by default)
+- Large result sets are split into parts that can be read with the continuation token
 
 TIPS:
 - Use specific file extensions to narrow results
@@ -144,7 +161,11 @@ func (t *sourcegraphTool) Info() ToolInfo {
 			},
 			"count": map[string]any{
 				"type":        "number",
-				"description": "Optional number of results to return (default: 10, max: 20)",
+				"description": "Optional number of results to return (default: 10)",
+			},
+			"all": map[string]any{
+				"type":        "boolean",
+				"description": "Return as many results as the configured maximum allows",
 			},
 			"context_window": map[string]any{
 				"type":        "number",
@@ -169,10 +190,11 @@ func (t *sourcegraphTool) Run(ctx context.Context, call ToolCall) (ToolResponse,
 		return NewTextErrorResponse("Query parameter is required"), nil
 	}
 
-	if params.Count <= 0 {
-		params.Count = 10
-	} else if params.Count > 20 {
-		params.Count = 20 // Limit to 20 results
+	maxResults := sourcegraphMaxResults()
+	if params.All || params.Count > maxResults {
+		params.Count = maxResults
+	} else if params.Count <= 0 {
+		params.Count = defaultSourcegraphCount
 	}
 
 	if params.ContextWindow <= 0 {
@@ -199,7 +221,7 @@ func (t *sourcegraphTool) Run(ctx context.Context, call ToolCall) (ToolResponse,
 	request := graphqlRequest{
 		Query: "query Search($query: String!) { search(query: $query, version: V2, patternType: keyword ) { results { matchCount, limitHit, resultCount, approximateResultCount, missing { name }, timedout { name }, indexUnavailable, results { __typename, ... on FileMatch { repository { name }, file { path, url, content }, lineMatches { preview, lineNumber, offsetAndLengths } } } } } }",
 	}
-	request.Variables.Query = params.Query
+	request.Variables.Query = withSourcegraphCount(params.Query, params.Count)
 
 	graphqlQueryBytes, err := json.Marshal(request)
 	if err != nil {
@@ -207,10 +229,28 @@ func (t *sourcegraphTool) Run(ctx context.Context, call ToolCall) (ToolResponse,
 	}
 	graphqlQuery := string(graphqlQueryBytes)
 
+	endpoint, token := sourcegraphEndpoint()
+
+	// The raw response is cached so the context window can differ between
+	// otherwise identical searches.
+	cacheKey := "sourcegraph\x00" + endpoint + "\x00" + graphqlQuery
+	if cached, created, ok := getCachedNetworkResponse(cacheKey); ok {
+		var result map[string]any
+		if err := json.Unmarshal([]byte(cached), &result); err == nil {
+			if formattedResults, err := formatSourcegraphResults(result, params.ContextWindow, params.Count); err == nil {
+				return NewTextResponse(truncateWithContinuation(SourcegraphToolName, formattedResults+cachedNote(created), maxSourcegraphOutputLength)), nil
+			}
+		}
+	}
+
+	if err := waitForNetworkSlot(ctx); err != nil {
+		return ToolResponse{}, fmt.Errorf("waiting for rate limiter: %w", err)
+	}
+
 	req, err := http.NewRequestWithContext(
 		ctx,
 		"POST",
-		"https://sourcegraph.com/.api/graphql",
+		endpoint,
 		bytes.NewBuffer([]byte(graphqlQuery)),
 	)
 	if err != nil {
@@ -219,6 +259,9 @@ func (t *sourcegraphTool) Run(ctx context.Context, call ToolCall) (ToolResponse,
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "opencode/1.0")
+	if token != "" {
+		req.Header.Set("Authorization", "token "+token)
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -226,33 +269,89 @@ func (t *sourcegraphTool) Run(ctx context.Context, call ToolCall) (ToolResponse,
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		if token == "" {
+			return NewTextErrorResponse(fmt.Sprintf("Request failed with status code: %d. The Sourcegraph instance requires an access token; set tools.sourcegraph.token in the configuration", resp.StatusCode)), nil
+		}
+		return NewTextErrorResponse(fmt.Sprintf("Request failed with status code: %d. The configured Sourcegraph access token was rejected", resp.StatusCode)), nil
+	}
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxSourcegraphErrorBody))
 		if len(body) > 0 {
 			return NewTextErrorResponse(fmt.Sprintf("Request failed with status code: %d, response: %s", resp.StatusCode, string(body))), nil
 		}
 
 		return NewTextErrorResponse(fmt.Sprintf("Request failed with status code: %d", resp.StatusCode)), nil
 	}
-	body, err := io.ReadAll(resp.Body)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSourcegraphResponseSize+1))
 	if err != nil {
 		return ToolResponse{}, fmt.Errorf("failed to read response body: %w", err)
 	}
+	if len(body) > maxSourcegraphResponseSize {
+		return NewTextErrorResponse("The Sourcegraph response is too large. Narrow the query or request fewer results"), nil
+	}
 
 	var result map[string]any
 	if err = json.Unmarshal(body, &result); err != nil {
 		return ToolResponse{}, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
+	if _, hasErrors := result["errors"]; !hasErrors {
+		cacheNetworkResponse(cacheKey, string(body))
+	}
 
-	formattedResults, err := formatSourcegraphResults(result, params.ContextWindow)
+	formattedResults, err := formatSourcegraphResults(result, params.ContextWindow, params.Count)
 	if err != nil {
 		return NewTextErrorResponse("Failed to format results: " + err.Error()), nil
 	}
 
-	return NewTextResponse(formattedResults), nil
+	return NewTextResponse(truncateWithContinuation(SourcegraphToolName, formattedResults, maxSourcegraphOutputLength)), nil
+}
+
+// sourcegraphEndpoint returns the GraphQL URL of the configured Sourcegraph
+// instance and its access token, falling back to the public instance without
+// a token. The endpoint may be given as the instance URL or the GraphQL URL.
+func sourcegraphEndpoint() (string, string) {
+	endpoint, token := defaultSourcegraphEndpoint, ""
+	if cfg := config.Get(); cfg != nil {
+		if e := strings.TrimSpace(cfg.Tools.Sourcegraph.Endpoint); e != "" {
+			endpoint = e
+		}
+		token = strings.TrimSpace(cfg.Tools.Sourcegraph.Token)
+	}
+	endpoint = strings.TrimRight(endpoint, "/")
+	if !strings.HasSuffix(endpoint, sourcegraphGraphQLPath) {
+		endpoint += sourcegraphGraphQLPath
+	}
+	return endpoint, token
+}
+
+var sourcegraphCountFilter = regexp.MustCompile(`(^|\s)count:\S+`)
+
+// sourcegraphMaxResults returns the configured bound on the results of a
+// search.
+func sourcegraphMaxResults() int {
+	if cfg := config.Get(); cfg != nil && cfg.Tools.Sourcegraph.MaxResults > 0 {
+		return cfg.Tools.Sourcegraph.MaxResults
+	}
+	return fallbackSourcegraphMaxResults
+}
+
+// withSourcegraphCount asks Sourcegraph for count results. The search API
+// has no cursor to page through results, so the whole set is requested at
+// once. A count: filter already in the query is kept when it asks for at
+// most count results and replaced otherwise, count:all included.
+func withSourcegraphCount(query string, count int) string {
+	if match := sourcegraphCountFilter.FindString(query); match != "" {
+		_, value, _ := strings.Cut(match, "count:")
+		if n, err := strconv.Atoi(value); err == nil && n > 0 && n <= count {
+			return query
+		}
+		query = strings.TrimSpace(sourcegraphCountFilter.ReplaceAllString(query, "$1"))
+	}
+	return fmt.Sprintf("%s count:%d", query, count)
 }
 
-func formatSourcegraphResults(result map[string]any, contextWindow int) (string, error) {
+func formatSourcegraphResults(result map[string]any, contextWindow, maxResults int) (string, error) {
 	var buffer strings.Builder
 
 	if errors, ok := result["errors"].([]any); ok && len(errors) > 0 {
@@ -290,7 +389,7 @@ func formatSourcegraphResults(result map[string]any, contextWindow int) (string,
 	buffer.WriteString(fmt.Sprintf("Found %d matches across %d results\n", int(matchCount), int(resultCount)))
 
 	if limitHit {
-		buffer.WriteString("(Result limit reached, try a more specific query)\n")
+		buffer.WriteString("(Result limit reached, raise count or try a more specific query)\n")
 	}
 
 	buffer.WriteString("\n")
@@ -301,8 +400,8 @@ func formatSourcegraphResults(result map[string]any, contextWindow int) (string,
 		return buffer.String(), nil
 	}
 
-	maxResults := 10
 	if len(results) > maxResults {
+		buffer.WriteString(fmt.Sprintf("Showing the first %d of %d results\n\n", maxResults, len(results)))
 		results = results[:maxResults]
 	}
 
@@ -2,15 +2,19 @@ package tools
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/format"
 	"github.com/opencode-ai/opencode/internal/lsp"
 )
 
@@ -49,13 +53,12 @@ HOW TO USE:
 FEATURES:
 - Displays file contents with line numbers for easy reference
 - Can read from any position in a file using the offset parameter
-- Handles large files by limiting the number of lines read
+- Handles large files by returning their head with a note on how to read the rest
 - Automatically truncates very long lines for better display
 - Suggests similar file names when the requested file isn't found
 
 LIMITATIONS:
-- Maximum file size is 250KB
-- Default reading limit is 2000 lines
+- A single read returns at most 2000 lines and 250KB by default
 - Lines longer than 2000 characters are truncated
 - Cannot display binary files or images
 - Images can be identified but not displayed
@@ -87,7 +90,7 @@ func (v *viewTool) Info() ToolInfo {
 			},
 			"limit": map[string]any{
 				"type":        "integer",
-				"description": "The number of lines to read (defaults to 2000)",
+				"description": "The number of lines to read (defaults to the configured maximum, 2000 lines)",
 			},
 		},
 		Required: []string{"file_path"},
@@ -148,15 +151,9 @@ func (v *viewTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 		return NewTextErrorResponse(fmt.Sprintf("Path is a directory, not a file: %s", filePath)), nil
 	}
 
-	// Check file size
-	if fileInfo.Size() > MaxReadSize {
-		return NewTextErrorResponse(fmt.Sprintf("File is too large (%d bytes). Maximum size is %d bytes",
-			fileInfo.Size(), MaxReadSize)), nil
-	}
-
-	// Set default limit if not provided
-	if params.Limit <= 0 {
-		params.Limit = DefaultReadLimit
+	maxBytes, maxLines := viewLimits()
+	if params.Limit <= 0 || params.Limit > maxLines {
+		params.Limit = maxLines
 	}
 
 	// Check if it's an image file
@@ -166,8 +163,16 @@ func (v *viewTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 		return NewTextErrorResponse(fmt.Sprintf("This is an image file of type: %s\nUse a different tool to process images", imageType)), nil
 	}
 
+	binary, contentType, err := isBinaryFile(filePath)
+	if err != nil {
+		return ToolResponse{}, fmt.Errorf("error reading file: %w", err)
+	}
+	if binary {
+		return NewTextErrorResponse(fmt.Sprintf("This is a binary file (%s) and cannot be shown as text: %s\nUse the Bash tool with a command such as file, xxd or strings to inspect it", contentType, filePath)), nil
+	}
+
 	// Read the file content
-	content, lineCount, err := readTextFile(filePath, params.Offset, params.Limit)
+	content, lineCount, err := readTextFile(filePath, params.Offset, params.Limit, maxBytes)
 	if err != nil {
 		return ToolResponse{}, fmt.Errorf("error reading file: %w", err)
 	}
@@ -178,13 +183,21 @@ func (v *viewTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	output += addLineNumbers(content, params.Offset+1)
 
 	// Add a note if the content was truncated
-	if lineCount > params.Offset+len(strings.Split(content, "\n")) {
-		output += fmt.Sprintf("\n\n(File has more lines. Use 'offset' parameter to read beyond line %d)",
-			params.Offset+len(strings.Split(content, "\n")))
+	shown := 0
+	if content != "" {
+		shown = len(strings.Split(content, "\n"))
+	}
+	if last := params.Offset + shown; lineCount > last {
+		reason := ""
+		if shown < params.Limit {
+			reason = fmt.Sprintf(" The output was cut at %dKB.", maxBytes/1024)
+		}
+		output += fmt.Sprintf("\n\n(File has %d lines, showing lines %d-%d.%s Use 'offset' and 'limit' to read beyond line %d)",
+			lineCount, params.Offset+1, last, reason, last)
 	}
 	output += "\n</file>\n"
 	output += getDiagnostics(filePath, v.lspClients)
-	recordFileRead(filePath)
+	recordFileRead(ctx, filePath)
 	return WithResponseMetadata(
 		NewTextResponse(output),
 		ViewResponseMetadata{
@@ -219,8 +232,25 @@ func addLineNumbers(content string, startLine int) string {
 	return strings.Join(result, "\n")
 }
 
-func readTextFile(filePath string, offset, limit int) (string, int, error) {
-	file, err := os.Open(filePath)
+// viewLimits returns the configured number of bytes and lines a read may
+// return.
+func viewLimits() (int, int) {
+	maxBytes, maxLines := MaxReadSize, DefaultReadLimit
+	if cfg := config.Get(); cfg != nil {
+		if cfg.Tools.View.MaxSizeKB > 0 {
+			maxBytes = cfg.Tools.View.MaxSizeKB * 1024
+		}
+		if cfg.Tools.View.MaxLines > 0 {
+			maxLines = cfg.Tools.View.MaxLines
+		}
+	}
+	return maxBytes, maxLines
+}
+
+// readTextFile returns up to limit lines from offset, stopping early once
+// they would exceed maxBytes, and the number of lines in the file.
+func readTextFile(filePath string, offset, limit, maxBytes int) (string, int, error) {
+	file, err := openFileWithRetry(filePath)
 	if err != nil {
 		return "", 0, err
 	}
@@ -247,12 +277,14 @@ func readTextFile(filePath string, offset, limit int) (string, int, error) {
 
 	var lines []string
 	lineCount = offset
+	size := 0
 
-	for scanner.Scan() && len(lines) < limit {
+	for len(lines) < limit && scanner.Scan() {
 		lineCount++
 		lineText := scanner.Text()
-		if len(lineText) > MaxLineLength {
-			lineText = lineText[:MaxLineLength] + "..."
+		size += len(lineText) + 1
+		if size > maxBytes && len(lines) > 0 {
+			break
 		}
 		lines = append(lines, lineText)
 	}
@@ -289,24 +321,103 @@ func isImageFile(filePath string) (bool, string) {
 	}
 }
 
+// binarySniffSize is how much of a file is looked at to tell whether it is
+// binary.
+const binarySniffSize = 8 * 1024
+
+// isBinaryFile reports whether the start of the file looks like binary data:
+// it has a NUL byte or is not valid UTF-8. It also returns the detected
+// content type.
+func isBinaryFile(filePath string) (bool, string, error) {
+	file, err := openFileWithRetry(filePath)
+	if err != nil {
+		return false, "", err
+	}
+	defer file.Close()
+
+	buf := make([]byte, binarySniffSize)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, "", err
+	}
+	buf = buf[:n]
+	contentType := http.DetectContentType(buf)
+	if bytes.IndexByte(buf, 0) >= 0 {
+		return true, contentType, nil
+	}
+	// A multi-byte character may be cut at the end of the sample.
+	if n == binarySniffSize {
+		buf = trimPartialRune(buf)
+	}
+	return !utf8.Valid(buf), contentType, nil
+}
+
+// trimPartialRune drops an incomplete UTF-8 sequence from the end of buf.
+func trimPartialRune(buf []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(buf); i++ {
+		if utf8.RuneStart(buf[len(buf)-i]) {
+			if !utf8.FullRune(buf[len(buf)-i:]) {
+				return buf[:len(buf)-i]
+			}
+			break
+		}
+	}
+	return buf
+}
+
+// LineScanner reads a file line by line. Lines longer than MaxLineLength,
+// such as minified code or lockfiles, are cut and end with "...", the rest
+// of the line is read and dropped without being held in memory.
 type LineScanner struct {
-	scanner *bufio.Scanner
+	reader *bufio.Reader
+	line   []byte
+	cut    bool
+	err    error
 }
 
 func NewLineScanner(r io.Reader) *LineScanner {
 	return &LineScanner{
-		scanner: bufio.NewScanner(r),
+		reader: bufio.NewReaderSize(r, 64*1024),
 	}
 }
 
 func (s *LineScanner) Scan() bool {
-	return s.scanner.Scan()
+	if s.err != nil {
+		return false
+	}
+	s.line, s.cut = s.line[:0], false
+	read := false
+	for {
+		chunk, isPrefix, err := s.reader.ReadLine()
+		if err != nil {
+			if err != io.EOF {
+				s.err = err
+			}
+			return read
+		}
+		read = true
+		// Keep a few bytes past the limit so the line can be cut on a
+		// character boundary.
+		if room := MaxLineLength + utf8.UTFMax - len(s.line); room > 0 {
+			s.line = append(s.line, chunk[:min(len(chunk), room)]...)
+		}
+		if len(chunk) > 0 && len(s.line) > MaxLineLength {
+			s.cut = true
+		}
+		if !isPrefix {
+			return true
+		}
+	}
 }
 
 func (s *LineScanner) Text() string {
-	return s.scanner.Text()
+	line := string(s.line)
+	if s.cut {
+		return line[:format.CutPoint(line, MaxLineLength)] + "..."
+	}
+	return line
 }
 
 func (s *LineScanner) Err() error {
-	return s.scanner.Err()
+	return s.err
 }
@@ -0,0 +1,32 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadTextFileLongLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundle.min.js")
+	long := strings.Repeat("x", MaxReadSize+100)
+	require.NoError(t, os.WriteFile(path, []byte("first\n"+long+"\r\nlast"), 0o644))
+
+	content, lineCount, err := readTextFile(path, 0, DefaultReadLimit, MaxReadSize)
+	require.NoError(t, err)
+	assert.Equal(t, 3, lineCount)
+
+	lines := strings.Split(content, "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "first", lines[0])
+	assert.Equal(t, strings.Repeat("x", MaxLineLength)+"...", lines[1])
+	assert.Equal(t, "last", lines[2])
+
+	content, lineCount, err = readTextFile(path, 2, DefaultReadLimit, MaxReadSize)
+	require.NoError(t, err)
+	assert.Equal(t, 3, lineCount)
+	assert.Equal(t, "last", content)
+}
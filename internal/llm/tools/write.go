@@ -116,20 +116,28 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		filePath = filepath.Join(config.WorkingDirectory(), filePath)
 	}
 
+	if err := checkWritable(filePath); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
 	fileInfo, err := os.Stat(filePath)
 	if err == nil {
 		if fileInfo.IsDir() {
 			return NewTextErrorResponse(fmt.Sprintf("Path is a directory, not a file: %s", filePath)), nil
 		}
 
+		autoReadForEdit(ctx, filePath, fileInfo.Size())
 		modTime := fileInfo.ModTime()
-		lastRead := getLastReadTime(filePath)
+		lastRead := getLastReadTime(ctx, filePath)
 		if modTime.After(lastRead) {
 			return NewTextErrorResponse(fmt.Sprintf("File %s has been modified since it was last read.\nLast modification: %s\nLast read: %s\n\nPlease read the file again before modifying it.",
 				filePath, modTime.Format(time.RFC3339), lastRead.Format(time.RFC3339))), nil
 		}
 
-		oldContent, readErr := os.ReadFile(filePath)
+		oldContent, readErr := readFileWithRetry(filePath)
+		if readErr == nil && contentChangedSinceRead(ctx, filePath, oldContent) {
+			return NewTextErrorResponse(contentConflictMessage(filePath)), nil
+		}
 		if readErr == nil && string(oldContent) == params.Content {
 			return NewTextErrorResponse(fmt.Sprintf("File %s already contains the exact content. No changes made.", filePath)), nil
 		}
@@ -144,7 +152,7 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 
 	oldContent := ""
 	if fileInfo != nil && !fileInfo.IsDir() {
-		oldBytes, readErr := os.ReadFile(filePath)
+		oldBytes, readErr := readFileWithRetry(filePath)
 		if readErr == nil {
 			oldContent = string(oldBytes)
 		}
@@ -177,13 +185,17 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 				FilePath: filePath,
 				Diff:     diff,
 			},
+			LinesChanged: additions + removals,
 		},
 	)
 	if !p {
 		return ToolResponse{}, permission.ErrorPermissionDenied
 	}
 
-	err = os.WriteFile(filePath, []byte(params.Content), 0o644)
+	if fileInfo != nil && changedBeforeWrite(filePath, []byte(oldContent)) {
+		return NewTextErrorResponse(contentConflictMessage(filePath)), nil
+	}
+	err = writeFileWithRetry(filePath, []byte(params.Content), 0o644)
 	if err != nil {
 		return ToolResponse{}, fmt.Errorf("error writing file: %w", err)
 	}
@@ -210,13 +222,14 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		logging.Debug("Error creating file history version", "error", err)
 	}
 
-	recordFileWrite(filePath)
-	recordFileRead(filePath)
+	recordFileWrite(ctx, filePath)
+	recordFileRead(ctx, filePath)
 	waitForLspDiagnostics(ctx, filePath, w.lspClients)
 
 	result := fmt.Sprintf("File successfully written: %s", filePath)
 	result = fmt.Sprintf("<result>\n%s\n</result>", result)
 	result += getDiagnostics(filePath, w.lspClients)
+	result += autoBuildCheck(ctx, filePath)
 	return WithResponseMetadata(NewTextResponse(result),
 		WriteResponseMetadata{
 			Diff:      diff,
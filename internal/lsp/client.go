@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/language"
 	"github.com/opencode-ai/opencode/internal/logging"
 	"github.com/opencode-ai/opencode/internal/lsp/protocol"
 )
@@ -448,9 +449,7 @@ func (c *Client) pingTypeScriptServer(ctx context.Context) error {
 
 	// If we have any open files, try to get document symbols for one
 	for uri := range c.openFiles {
-		filePath := strings.TrimPrefix(uri, "file://")
-		if strings.HasSuffix(filePath, ".ts") || strings.HasSuffix(filePath, ".js") ||
-			strings.HasSuffix(filePath, ".tsx") || strings.HasSuffix(filePath, ".jsx") {
+		if language.Detect(uri).Family() == language.TypeScript {
 			var symbols []protocol.DocumentSymbol
 			err := c.Call(ctx, "textDocument/documentSymbol", protocol.DocumentSymbolParams{
 				TextDocument: protocol.TextDocumentIdentifier{
@@ -475,8 +474,7 @@ func (c *Client) pingTypeScriptServer(ctx context.Context) error {
 			return nil
 		}
 
-		ext := filepath.Ext(path)
-		if ext == ".ts" || ext == ".js" || ext == ".tsx" || ext == ".jsx" {
+		if language.Detect(path).Family() == language.TypeScript {
 			// Found a TypeScript file, try to open it
 			if err := c.OpenFile(ctx, path); err == nil {
 				// Successfully opened, stop walking
@@ -520,9 +518,7 @@ func (c *Client) openTypeScriptFiles(ctx context.Context, workDir string) {
 			return filepath.SkipAll
 		}
 
-		// Check file extension
-		ext := filepath.Ext(path)
-		if ext == ".ts" || ext == ".tsx" || ext == ".js" || ext == ".jsx" {
+		if language.Detect(path).Family() == language.TypeScript {
 			// Try to open the file
 			if err := c.OpenFile(ctx, path); err == nil {
 				filesOpened++
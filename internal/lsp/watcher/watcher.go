@@ -12,6 +12,7 @@ import (
 	"github.com/bmatcuk/doublestar/v4"
 	"github.com/fsnotify/fsnotify"
 	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/language"
 	"github.com/opencode-ai/opencode/internal/logging"
 	"github.com/opencode-ai/opencode/internal/lsp"
 	"github.com/opencode-ai/opencode/internal/lsp/protocol"
@@ -896,25 +897,23 @@ func (w *WorkspaceWatcher) openMatchingFile(ctx context.Context, path string) {
 				return
 			}
 
-			// Check file extension for common source files
-			ext := strings.ToLower(filepath.Ext(path))
-
 			// Only preload source files for the specific language
+			lang := language.Detect(path)
 			shouldOpen := false
 
 			switch serverName {
 			case "typescript", "typescript-language-server", "tsserver", "vtsls":
-				shouldOpen = ext == ".ts" || ext == ".js" || ext == ".tsx" || ext == ".jsx"
+				shouldOpen = lang.Family() == language.TypeScript
 			case "gopls":
-				shouldOpen = ext == ".go"
+				shouldOpen = lang == language.Go
 			case "rust-analyzer":
-				shouldOpen = ext == ".rs"
+				shouldOpen = lang == language.Rust
 			case "python", "pyright", "pylsp":
-				shouldOpen = ext == ".py"
+				shouldOpen = lang == language.Python
 			case "clangd":
-				shouldOpen = ext == ".c" || ext == ".cpp" || ext == ".h" || ext == ".hpp"
+				shouldOpen = lang == language.C || lang == language.CPP
 			case "java", "jdtls":
-				shouldOpen = ext == ".java"
+				shouldOpen = lang == language.Java
 			default:
 				// For unknown servers, be conservative
 				shouldOpen = false
@@ -0,0 +1,166 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/pubsub"
+)
+
+// ReservedPrefix starts the keys of entries the agent keeps for itself, such
+// as the plan. The memory tool can neither see nor change them.
+const ReservedPrefix = "_"
+
+// PlanKey is the reserved key under which the plan of the current task is
+// stored, one step per line prefixed with its status marker.
+const PlanKey = ReservedPrefix + "plan"
+
+// IsReserved reports whether key belongs to an entry the agent keeps for
+// itself rather than a note.
+func IsReserved(key string) bool {
+	return strings.HasPrefix(key, ReservedPrefix)
+}
+
+// Plan step status markers.
+const (
+	PlanPending    = "[ ]"
+	PlanInProgress = "[>]"
+	PlanDone       = "[x]"
+)
+
+// PlanStep is a single step of a stored plan.
+type PlanStep struct {
+	Marker string
+	Text   string
+}
+
+// Entry is a single note the agent stored for a session.
+type Entry struct {
+	ID        string
+	SessionID string
+	Key       string
+	Value     string
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+type Service interface {
+	pubsub.Suscriber[Entry]
+	Set(ctx context.Context, sessionID, key, value string) (Entry, error)
+	Get(ctx context.Context, sessionID, key string) (Entry, error)
+	List(ctx context.Context, sessionID string) ([]Entry, error)
+	Delete(ctx context.Context, sessionID, key string) error
+}
+
+type service struct {
+	*pubsub.Broker[Entry]
+	q db.Querier
+}
+
+func NewService(q db.Querier) Service {
+	return &service{
+		Broker: pubsub.NewBroker[Entry](),
+		q:      q,
+	}
+}
+
+func (s *service) Set(ctx context.Context, sessionID, key, value string) (Entry, error) {
+	dbMemory, err := s.q.UpsertMemory(ctx, db.UpsertMemoryParams{
+		ID:        uuid.New().String(),
+		SessionID: sessionID,
+		Key:       key,
+		Value:     value,
+	})
+	if err != nil {
+		return Entry{}, err
+	}
+	entry := s.fromDBItem(dbMemory)
+	s.Publish(pubsub.UpdatedEvent, entry)
+	return entry, nil
+}
+
+func (s *service) Get(ctx context.Context, sessionID, key string) (Entry, error) {
+	dbMemory, err := s.q.GetMemory(ctx, db.GetMemoryParams{
+		SessionID: sessionID,
+		Key:       key,
+	})
+	if err != nil {
+		return Entry{}, err
+	}
+	return s.fromDBItem(dbMemory), nil
+}
+
+func (s *service) List(ctx context.Context, sessionID string) ([]Entry, error) {
+	dbMemories, err := s.q.ListMemoriesBySession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, len(dbMemories))
+	for i, dbMemory := range dbMemories {
+		entries[i] = s.fromDBItem(dbMemory)
+	}
+	return entries, nil
+}
+
+func (s *service) Delete(ctx context.Context, sessionID, key string) error {
+	entry, err := s.Get(ctx, sessionID, key)
+	if err != nil {
+		return err
+	}
+	err = s.q.DeleteMemory(ctx, db.DeleteMemoryParams{
+		SessionID: sessionID,
+		Key:       key,
+	})
+	if err != nil {
+		return err
+	}
+	s.Publish(pubsub.DeletedEvent, entry)
+	return nil
+}
+
+func (s *service) fromDBItem(item db.Memory) Entry {
+	return Entry{
+		ID:        item.ID,
+		SessionID: item.SessionID,
+		Key:       item.Key,
+		Value:     item.Value,
+		CreatedAt: item.CreatedAt,
+		UpdatedAt: item.UpdatedAt,
+	}
+}
+
+// Format renders the entries as a compact list suitable for the model context.
+func Format(entries []Entry) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	for _, entry := range entries {
+		sb.WriteString(fmt.Sprintf("- %s: %s\n", entry.Key, strings.ReplaceAll(entry.Value, "\n", " ")))
+	}
+	return sb.String()
+}
+
+// ParsePlan splits a stored plan into its steps. Lines without a known marker
+// are treated as pending steps.
+func ParsePlan(value string) []PlanStep {
+	steps := make([]PlanStep, 0)
+	for line := range strings.SplitSeq(value, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		step := PlanStep{Marker: PlanPending, Text: line}
+		for _, marker := range []string{PlanPending, PlanInProgress, PlanDone} {
+			if strings.HasPrefix(line, marker) {
+				step = PlanStep{Marker: marker, Text: strings.TrimSpace(strings.TrimPrefix(line, marker))}
+				break
+			}
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
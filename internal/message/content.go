@@ -15,6 +15,9 @@ const (
 	User      MessageRole = "user"
 	System    MessageRole = "system"
 	Tool      MessageRole = "tool"
+	// Review messages hold the feedback of the reviewer agent on a turn.
+	// They are shown to the user but never sent to the model.
+	Review MessageRole = "review"
 )
 
 type FinishReason string
@@ -108,6 +111,23 @@ type Finish struct {
 
 func (Finish) isPart() {}
 
+// Usage holds the tokens and the cost of the response of an assistant
+// message.
+type Usage struct {
+	InputTokens         int64   `json:"input_tokens"`
+	OutputTokens        int64   `json:"output_tokens"`
+	CacheCreationTokens int64   `json:"cache_creation_tokens"`
+	CacheReadTokens     int64   `json:"cache_read_tokens"`
+	Cost                float64 `json:"cost"`
+}
+
+// Tokens returns the tokens of the request and the response.
+func (u Usage) Tokens() int64 {
+	return u.InputTokens + u.OutputTokens + u.CacheCreationTokens + u.CacheReadTokens
+}
+
+func (Usage) isPart() {}
+
 type Message struct {
 	ID        string
 	Role      MessageRole
@@ -194,6 +214,16 @@ func (m *Message) FinishPart() *Finish {
 	return nil
 }
 
+// UsagePart returns the usage of the message, nil while it is not finished.
+func (m *Message) UsagePart() *Usage {
+	for _, part := range m.Parts {
+		if c, ok := part.(Usage); ok {
+			return &c
+		}
+	}
+	return nil
+}
+
 func (m *Message) FinishReason() FinishReason {
 	for _, part := range m.Parts {
 		if c, ok := part.(Finish); ok {
@@ -318,6 +348,17 @@ func (m *Message) AddFinish(reason FinishReason) {
 	m.Parts = append(m.Parts, Finish{Reason: reason, Time: time.Now().Unix()})
 }
 
+// SetUsage replaces the usage of the message.
+func (m *Message) SetUsage(usage Usage) {
+	for i, part := range m.Parts {
+		if _, ok := part.(Usage); ok {
+			m.Parts = slices.Delete(m.Parts, i, i+1)
+			break
+		}
+	}
+	m.Parts = append(m.Parts, usage)
+}
+
 func (m *Message) AddImageURL(url, detail string) {
 	m.Parts = append(m.Parts, ImageURLContent{URL: url, Detail: detail})
 }
@@ -26,6 +26,9 @@ type Service interface {
 	Get(ctx context.Context, id string) (Message, error)
 	List(ctx context.Context, sessionID string) ([]Message, error)
 	Delete(ctx context.Context, id string) error
+	// Copy adds a copy of msg to the session, keeping its times so the copy
+	// sorts like the original.
+	Copy(ctx context.Context, sessionID string, msg Message) (Message, error)
 	DeleteSessionMessages(ctx context.Context, sessionID string) error
 }
 
@@ -82,6 +85,37 @@ func (s *service) Create(ctx context.Context, sessionID string, params CreateMes
 	return message, nil
 }
 
+func (s *service) Copy(ctx context.Context, sessionID string, msg Message) (Message, error) {
+	partsJSON, err := marshallParts(msg.Parts)
+	if err != nil {
+		return Message{}, err
+	}
+	finishedAt := sql.NullInt64{}
+	if f := msg.FinishPart(); f != nil {
+		finishedAt.Int64 = f.Time
+		finishedAt.Valid = true
+	}
+	dbMessage, err := s.q.CopyMessage(ctx, db.CopyMessageParams{
+		ID:         uuid.New().String(),
+		SessionID:  sessionID,
+		Role:       string(msg.Role),
+		Parts:      string(partsJSON),
+		Model:      sql.NullString{String: string(msg.Model), Valid: msg.Model != ""},
+		CreatedAt:  msg.CreatedAt,
+		UpdatedAt:  msg.UpdatedAt,
+		FinishedAt: finishedAt,
+	})
+	if err != nil {
+		return Message{}, err
+	}
+	message, err := s.fromDBItem(dbMessage)
+	if err != nil {
+		return Message{}, err
+	}
+	s.Publish(pubsub.CreatedEvent, message)
+	return message, nil
+}
+
 func (s *service) DeleteSessionMessages(ctx context.Context, sessionID string) error {
 	messages, err := s.List(ctx, sessionID)
 	if err != nil {
@@ -170,6 +204,7 @@ const (
 	toolCallType   partType = "tool_call"
 	toolResultType partType = "tool_result"
 	finishType     partType = "finish"
+	usageType      partType = "usage"
 )
 
 type partWrapper struct {
@@ -198,6 +233,8 @@ func marshallParts(parts []ContentPart) ([]byte, error) {
 			typ = toolResultType
 		case Finish:
 			typ = finishType
+		case Usage:
+			typ = usageType
 		default:
 			return nil, fmt.Errorf("unknown part type: %T", part)
 		}
@@ -271,6 +308,12 @@ func unmarshallParts(data []byte) ([]ContentPart, error) {
 				return nil, err
 			}
 			parts = append(parts, part)
+		case usageType:
+			part := Usage{}
+			if err := json.Unmarshal(wrapper.Data, &part); err != nil {
+				return nil, err
+			}
+			parts = append(parts, part)
 		default:
 			return nil, fmt.Errorf("unknown part type: %s", wrapper.Type)
 		}
@@ -2,17 +2,23 @@ package permission
 
 import (
 	"errors"
+	"fmt"
 	"path/filepath"
 	"slices"
 	"sync"
 
 	"github.com/google/uuid"
 	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/logging"
 	"github.com/opencode-ai/opencode/internal/pubsub"
 )
 
 var ErrorPermissionDenied = errors.New("permission denied")
 
+// readOnlyActions are the requested actions that do not change anything,
+// allowed for unattended runs with allow-read-only permissions.
+var readOnlyActions = []string{"fetch"}
+
 type CreatePermissionRequest struct {
 	SessionID   string `json:"session_id"`
 	ToolName    string `json:"tool_name"`
@@ -20,6 +26,9 @@ type CreatePermissionRequest struct {
 	Action      string `json:"action"`
 	Params      any    `json:"params"`
 	Path        string `json:"path"`
+	// LinesChanged is the number of added and removed lines for file
+	// edits, used to always prompt for large edits.
+	LinesChanged int `json:"lines_changed,omitempty"`
 }
 
 type PermissionRequest struct {
@@ -30,6 +39,10 @@ type PermissionRequest struct {
 	Action      string `json:"action"`
 	Params      any    `json:"params"`
 	Path        string `json:"path"`
+	// LargeEdit is set when LinesChanged exceeds the configured threshold;
+	// such requests prompt even if the path was allowed for the session.
+	LinesChanged int  `json:"lines_changed,omitempty"`
+	LargeEdit    bool `json:"large_edit,omitempty"`
 }
 
 type Service interface {
@@ -37,8 +50,11 @@ type Service interface {
 	GrantPersistant(permission PermissionRequest)
 	Grant(permission PermissionRequest)
 	Deny(permission PermissionRequest)
+	DenyWithReason(permission PermissionRequest, reason string)
+	DenialReason(sessionID string) string
 	Request(opts CreatePermissionRequest) bool
 	AutoApproveSession(sessionID string)
+	SetUnattended(sessionID string, mode config.UnattendedPermissions)
 }
 
 type permissionService struct {
@@ -47,6 +63,16 @@ type permissionService struct {
 	sessionPermissions  []PermissionRequest
 	pendingRequests     sync.Map
 	autoApproveSessions []string
+
+	// unattended holds the permissions of the sessions that run without
+	// anyone to answer a prompt.
+	unattended   map[string]config.UnattendedPermissions
+	unattendedMu sync.Mutex
+
+	// denialReasons holds the reason given for the last denied request of
+	// each session until the agent picks it up.
+	denialReasons   map[string]string
+	denialReasonsMu sync.Mutex
 }
 
 func (s *permissionService) GrantPersistant(permission PermissionRequest) {
@@ -71,10 +97,35 @@ func (s *permissionService) Deny(permission PermissionRequest) {
 	}
 }
 
+// DenyWithReason denies the request and keeps the reason so it can be passed
+// back to the model.
+func (s *permissionService) DenyWithReason(permission PermissionRequest, reason string) {
+	s.denialReasonsMu.Lock()
+	s.denialReasons[permission.SessionID] = reason
+	s.denialReasonsMu.Unlock()
+	s.Deny(permission)
+}
+
+// DenialReason returns and clears the reason given for the last denied
+// request of the session, or an empty string if none was given.
+func (s *permissionService) DenialReason(sessionID string) string {
+	s.denialReasonsMu.Lock()
+	defer s.denialReasonsMu.Unlock()
+	reason := s.denialReasons[sessionID]
+	delete(s.denialReasons, sessionID)
+	return reason
+}
+
 func (s *permissionService) Request(opts CreatePermissionRequest) bool {
 	if slices.Contains(s.autoApproveSessions, opts.SessionID) {
 		return true
 	}
+	s.unattendedMu.Lock()
+	mode, unattended := s.unattended[opts.SessionID]
+	s.unattendedMu.Unlock()
+	if unattended {
+		return s.decideUnattended(opts, mode)
+	}
 	dir := filepath.Dir(opts.Path)
 	if dir == "." {
 		dir = config.WorkingDirectory()
@@ -87,11 +138,20 @@ func (s *permissionService) Request(opts CreatePermissionRequest) bool {
 		Description: opts.Description,
 		Action:      opts.Action,
 		Params:      opts.Params,
+
+		LinesChanged: opts.LinesChanged,
 	}
 
-	for _, p := range s.sessionPermissions {
-		if p.ToolName == permission.ToolName && p.Action == permission.Action && p.SessionID == permission.SessionID && p.Path == permission.Path {
-			return true
+	// Large edits always prompt, even when the path was allowed for the session.
+	if threshold := config.Get().LargeEditThreshold; threshold > 0 && opts.LinesChanged > threshold {
+		permission.LargeEdit = true
+	}
+
+	if !permission.LargeEdit {
+		for _, p := range s.sessionPermissions {
+			if p.ToolName == permission.ToolName && p.Action == permission.Action && p.SessionID == permission.SessionID && p.Path == permission.Path {
+				return true
+			}
 		}
 	}
 
@@ -111,9 +171,39 @@ func (s *permissionService) AutoApproveSession(sessionID string) {
 	s.autoApproveSessions = append(s.autoApproveSessions, sessionID)
 }
 
+// SetUnattended makes the requests of the session be decided by mode instead
+// of waiting for an answer that never comes.
+func (s *permissionService) SetUnattended(sessionID string, mode config.UnattendedPermissions) {
+	s.unattendedMu.Lock()
+	defer s.unattendedMu.Unlock()
+	s.unattended[sessionID] = mode
+}
+
+// decideUnattended answers a request of an unattended session. Denied requests
+// get a reason so the model continues without the action instead of ending
+// the run.
+func (s *permissionService) decideUnattended(opts CreatePermissionRequest, mode config.UnattendedPermissions) bool {
+	switch mode {
+	case config.UnattendedDenyAll:
+	case config.UnattendedAllowReadOnly:
+		if slices.Contains(readOnlyActions, opts.Action) {
+			return true
+		}
+	default:
+		return true
+	}
+	logging.Info("Denied permission in unattended run", "tool", opts.ToolName, "action", opts.Action, "mode", mode)
+	s.denialReasonsMu.Lock()
+	s.denialReasons[opts.SessionID] = fmt.Sprintf("this run is unattended with %s permissions, so %s is not allowed; finish the task without it", mode, opts.Action)
+	s.denialReasonsMu.Unlock()
+	return false
+}
+
 func NewPermissionService() Service {
 	return &permissionService{
 		Broker:             pubsub.NewBroker[PermissionRequest](),
 		sessionPermissions: make([]PermissionRequest, 0),
+		denialReasons:      make(map[string]string),
+		unattended:         make(map[string]config.UnattendedPermissions),
 	}
 }
@@ -3,21 +3,29 @@ package session
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/llm/models"
 	"github.com/opencode-ai/opencode/internal/pubsub"
 )
 
 type Session struct {
-	ID               string
-	ParentSessionID  string
+	ID              string
+	ParentSessionID string
+	// BranchedFrom is the session this one was branched from by editing one
+	// of its messages.
+	BranchedFrom     string
 	Title            string
+	TitleLocked      bool // set once the user renamed the session, generated titles no longer replace it
 	MessageCount     int64
 	PromptTokens     int64
 	CompletionTokens int64
 	SummaryMessageID string
 	Cost             float64
+	Model            models.ModelID // empty uses the model configured for the agent
 	CreatedAt        int64
 	UpdatedAt        int64
 }
@@ -27,9 +35,15 @@ type Service interface {
 	Create(ctx context.Context, title string) (Session, error)
 	CreateTitleSession(ctx context.Context, parentSessionID string) (Session, error)
 	CreateTaskSession(ctx context.Context, toolCallID, parentSessionID, title string) (Session, error)
+	// CreateBranch creates an empty session branched from the given one, on
+	// the same model.
+	CreateBranch(ctx context.Context, from Session) (Session, error)
 	Get(ctx context.Context, id string) (Session, error)
 	List(ctx context.Context) ([]Session, error)
 	Save(ctx context.Context, session Session) (Session, error)
+	// Rename sets the title of the session and keeps it from being replaced
+	// by a generated title.
+	Rename(ctx context.Context, id, title string) (Session, error)
 	Delete(ctx context.Context, id string) error
 }
 
@@ -65,6 +79,24 @@ func (s *service) CreateTaskSession(ctx context.Context, toolCallID, parentSessi
 	return session, nil
 }
 
+func (s *service) CreateBranch(ctx context.Context, from Session) (Session, error) {
+	dbSession, err := s.q.CreateSession(ctx, db.CreateSessionParams{
+		ID:           uuid.New().String(),
+		Title:        from.Title,
+		BranchedFrom: sql.NullString{String: from.ID, Valid: true},
+	})
+	if err != nil {
+		return Session{}, err
+	}
+	session := s.fromDBItem(dbSession)
+	s.Publish(pubsub.CreatedEvent, session)
+	if from.Model != "" {
+		session.Model = from.Model
+		return s.Save(ctx, session)
+	}
+	return session, nil
+}
+
 func (s *service) CreateTitleSession(ctx context.Context, parentSessionID string) (Session, error) {
 	dbSession, err := s.q.CreateSession(ctx, db.CreateSessionParams{
 		ID:              "title-" + parentSessionID,
@@ -84,6 +116,17 @@ func (s *service) Delete(ctx context.Context, id string) error {
 	if err != nil {
 		return err
 	}
+	// Task and title sessions are never listed on their own, they would be
+	// orphaned once their parent is gone.
+	children, err := s.q.ListChildSessions(ctx, sql.NullString{String: session.ID, Valid: true})
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		if err := s.Delete(ctx, child.ID); err != nil {
+			return err
+		}
+	}
 	err = s.q.DeleteSession(ctx, session.ID)
 	if err != nil {
 		return err
@@ -111,6 +154,10 @@ func (s *service) Save(ctx context.Context, session Session) (Session, error) {
 			Valid:  session.SummaryMessageID != "",
 		},
 		Cost: session.Cost,
+		Model: sql.NullString{
+			String: string(session.Model),
+			Valid:  session.Model != "",
+		},
 	})
 	if err != nil {
 		return Session{}, err
@@ -120,6 +167,23 @@ func (s *service) Save(ctx context.Context, session Session) (Session, error) {
 	return session, nil
 }
 
+func (s *service) Rename(ctx context.Context, id, title string) (Session, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return Session{}, fmt.Errorf("the title of a session cannot be empty")
+	}
+	dbSession, err := s.q.RenameSession(ctx, db.RenameSessionParams{
+		ID:    id,
+		Title: title,
+	})
+	if err != nil {
+		return Session{}, err
+	}
+	session := s.fromDBItem(dbSession)
+	s.Publish(pubsub.UpdatedEvent, session)
+	return session, nil
+}
+
 func (s *service) List(ctx context.Context) ([]Session, error) {
 	dbSessions, err := s.q.ListSessions(ctx)
 	if err != nil {
@@ -136,12 +200,15 @@ func (s service) fromDBItem(item db.Session) Session {
 	return Session{
 		ID:               item.ID,
 		ParentSessionID:  item.ParentSessionID.String,
+		BranchedFrom:     item.BranchedFrom.String,
 		Title:            item.Title,
+		TitleLocked:      item.TitleLocked,
 		MessageCount:     item.MessageCount,
 		PromptTokens:     item.PromptTokens,
 		CompletionTokens: item.CompletionTokens,
 		SummaryMessageID: item.SummaryMessageID.String,
 		Cost:             item.Cost,
+		Model:            models.ModelID(item.Model.String),
 		CreatedAt:        item.CreatedAt,
 		UpdatedAt:        item.UpdatedAt,
 	}
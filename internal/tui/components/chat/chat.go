@@ -17,6 +17,15 @@ import (
 type SendMsg struct {
 	Text        string
 	Attachments []message.Attachment
+	// BranchFrom is the ID of the user message the text replaces in a new
+	// branch of the session, empty to continue the session.
+	BranchFrom string
+}
+
+// RestorePromptMsg puts a prompt that was not sent back in the editor.
+type RestorePromptMsg struct {
+	Text        string
+	Attachments []message.Attachment
 }
 
 type SessionSelectedMsg = session.Session
@@ -25,6 +34,38 @@ type SessionClearedMsg struct{}
 
 type EditorFocusMsg bool
 
+// ResultFocusMsg reports whether a tool result in the transcript is focused,
+// in which case keys such as esc belong to the transcript.
+type ResultFocusMsg bool
+
+// GoToMessageMsg scrolls the transcript to the message with the given number.
+type GoToMessageMsg struct {
+	Number int
+}
+
+// DeleteMessageMsg deletes the message with the given number, with the
+// results of its tool calls.
+type DeleteMessageMsg struct {
+	Number int
+}
+
+// EditMessageMsg puts the user message with the given number in the editor,
+// to send an edited version in a new branch of the session.
+type EditMessageMsg struct {
+	Number int
+}
+
+// StartEditMsg puts a past user message in the editor. Sending it branches
+// the session at that message.
+type StartEditMsg struct {
+	MessageID   string
+	Text        string
+	Attachments []message.Attachment
+}
+
+// ToggleCodeWrapMsg switches code blocks between wrapping and horizontal scrolling.
+type ToggleCodeWrapMsg struct{}
+
 func header(width int) string {
 	return lipgloss.JoinVertical(
 		lipgloss.Top,
@@ -0,0 +1,86 @@
+package chat
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+)
+
+// codeScrollStep is how many columns code blocks move per scroll key press.
+const codeScrollStep = 8
+
+// codeBlockView controls how fenced code blocks are rendered in the transcript.
+// By default they are word wrapped like the rest of the markdown; in scroll
+// mode long lines are cut at the viewport edge and scrolled horizontally.
+type codeBlockView struct {
+	scroll bool
+	offset int
+}
+
+type markdownSegment struct {
+	text   string
+	isCode bool
+}
+
+// splitFencedBlocks splits markdown into prose and fenced code block segments.
+func splitFencedBlocks(content string) []markdownSegment {
+	var segments []markdownSegment
+	var current []string
+	fence := ""
+
+	flush := func(isCode bool) {
+		if len(current) > 0 {
+			segments = append(segments, markdownSegment{text: strings.Join(current, "\n"), isCode: isCode})
+			current = nil
+		}
+	}
+
+	for line := range strings.SplitSeq(content, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		switch {
+		case fence == "" && (strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")):
+			flush(false)
+			fence = trimmed[:len(trimmed)-len(strings.TrimLeft(trimmed, trimmed[:1]))]
+			current = append(current, line)
+		case fence != "" && strings.HasPrefix(trimmed, fence) && strings.TrimSpace(strings.TrimLeft(trimmed, fence[:1])) == "":
+			current = append(current, line)
+			flush(true)
+			fence = ""
+		default:
+			current = append(current, line)
+		}
+	}
+	// An unterminated fence, e.g. while a message is still streaming.
+	flush(fence != "")
+	return segments
+}
+
+// renderScrolledMarkdown renders markdown with code blocks left unwrapped and
+// cut to width starting at offset.
+func renderScrolledMarkdown(content string, width, offset int) string {
+	parts := make([]string, 0)
+	for _, segment := range splitFencedBlocks(content) {
+		if !segment.isCode {
+			rendered, _ := styles.GetMarkdownRenderer(width).Render(segment.text)
+			parts = append(parts, strings.TrimSuffix(rendered, "\n"))
+			continue
+		}
+
+		// A wrap width of zero disables wrapping. Glamour keeps a column
+		// free on the right of wrapped text, so code is cut one column short.
+		rendered, _ := styles.GetMarkdownRenderer(0).Render(segment.text)
+		visible := width - 1
+		lines := strings.Split(strings.TrimSuffix(rendered, "\n"), "\n")
+		for i, line := range lines {
+			lineWidth := ansi.StringWidth(line)
+			line = ansi.Cut(line, offset, offset+visible)
+			if lineWidth > offset+visible {
+				line = ansi.Truncate(line, visible-1, "") + "›"
+			}
+			lines[i] = line
+		}
+		parts = append(parts, strings.Join(lines, "\n"))
+	}
+	return strings.Join(parts, "\n")
+}
@@ -13,6 +13,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/opencode-ai/opencode/internal/app"
+	"github.com/opencode-ai/opencode/internal/config"
 	"github.com/opencode-ai/opencode/internal/logging"
 	"github.com/opencode-ai/opencode/internal/message"
 	"github.com/opencode-ai/opencode/internal/session"
@@ -31,6 +32,13 @@ type editorCmp struct {
 	textarea    textarea.Model
 	attachments []message.Attachment
 	deleteMode  bool
+
+	// editing is the ID of the past user message being edited. Sending
+	// branches the session at that message.
+	editing string
+
+	// resultFocused is set while a tool result in the transcript is focused.
+	resultFocused bool
 }
 
 type EditorKeyMaps struct {
@@ -51,7 +59,7 @@ type DeleteAttachmentKeyMaps struct {
 
 var editorMaps = EditorKeyMaps{
 	Send: key.NewBinding(
-		key.WithKeys("enter", "ctrl+s"),
+		key.WithKeys("enter"),
 		key.WithHelp("enter", "send message"),
 	),
 	OpenEditor: key.NewBinding(
@@ -124,11 +132,13 @@ func (m *editorCmp) send() tea.Cmd {
 		return util.ReportWarn("Agent is working, please wait...")
 	}
 
-	value := m.textarea.Value()
+	value := expandSnippets(m.textarea.Value(), config.Get().TUI.Snippets)
 	m.textarea.Reset()
 	attachments := m.attachments
 
 	m.attachments = nil
+	branchFrom := m.editing
+	m.editing = ""
 	if value == "" {
 		return nil
 	}
@@ -136,6 +146,7 @@ func (m *editorCmp) send() tea.Cmd {
 		util.CmdHandler(SendMsg{
 			Text:        value,
 			Attachments: attachments,
+			BranchFrom:  branchFrom,
 		}),
 	)
 }
@@ -154,6 +165,22 @@ func (m *editorCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case SessionSelectedMsg:
 		if msg.ID != m.session.ID {
 			m.session = msg
+			m.editing = ""
+		}
+		return m, nil
+	case ResultFocusMsg:
+		m.resultFocused = bool(msg)
+		return m, nil
+	case StartEditMsg:
+		m.editing = msg.MessageID
+		m.textarea.SetValue(msg.Text)
+		m.attachments = msg.Attachments
+		return m, nil
+	case RestorePromptMsg:
+		// Keep whatever was typed in the meantime.
+		if m.textarea.Value() == "" {
+			m.textarea.SetValue(msg.Text)
+			m.attachments = msg.Attachments
 		}
 		return m, nil
 	case dialog.AttachmentAddedMsg:
@@ -163,6 +190,10 @@ func (m *editorCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.attachments = append(m.attachments, msg.Attachment)
 	case tea.KeyMsg:
+		// The keys belong to the transcript while a tool result is focused.
+		if m.resultFocused {
+			return m, nil
+		}
 		if key.Matches(msg, DeleteKeyMaps.AttachmentDeleteMode) {
 			m.deleteMode = true
 			return m, nil
@@ -195,6 +226,12 @@ func (m *editorCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, m.openEditor()
 		}
 		if key.Matches(msg, DeleteKeyMaps.Escape) {
+			if m.editing != "" && !m.deleteMode {
+				m.editing = ""
+				m.textarea.Reset()
+				m.attachments = nil
+				return m, util.ReportInfo("Edit canceled")
+			}
 			m.deleteMode = false
 			return m, nil
 		}
@@ -225,13 +262,17 @@ func (m *editorCmp) View() string {
 		Bold(true).
 		Foreground(t.Primary())
 
+	prompt := ">"
+	if m.editing != "" {
+		prompt = "✎"
+	}
 	if len(m.attachments) == 0 {
-		return lipgloss.JoinHorizontal(lipgloss.Top, style.Render(">"), m.textarea.View())
+		return lipgloss.JoinHorizontal(lipgloss.Top, style.Render(prompt), m.textarea.View())
 	}
 	m.textarea.SetHeight(m.height - 1)
 	return lipgloss.JoinVertical(lipgloss.Top,
 		m.attachmentsContent(),
-		lipgloss.JoinHorizontal(lipgloss.Top, style.Render(">"),
+		lipgloss.JoinHorizontal(lipgloss.Top, style.Render(prompt),
 			m.textarea.View()),
 	)
 }
@@ -276,6 +317,7 @@ func (m *editorCmp) BindingKeys() []key.Binding {
 	bindings := []key.Binding{}
 	bindings = append(bindings, layout.KeyMapToSlice(editorMaps)...)
 	bindings = append(bindings, layout.KeyMapToSlice(DeleteKeyMaps)...)
+	bindings = append(bindings, snippetBindings()...)
 	return bindings
 }
 
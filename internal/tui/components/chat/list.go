@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"path/filepath"
+	"slices"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
@@ -11,6 +14,9 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/opencode-ai/opencode/internal/app"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/memory"
 	"github.com/opencode-ai/opencode/internal/message"
 	"github.com/opencode-ai/opencode/internal/pubsub"
 	"github.com/opencode-ai/opencode/internal/session"
@@ -24,6 +30,12 @@ type cacheItem struct {
 	width   int
 	content []uiMessage
 }
+
+// toolProgress is what is known about a tool call that is still running.
+type toolProgress struct {
+	startedAt time.Time
+	output    string
+}
 type messagesCmp struct {
 	app           *app.App
 	width, height int
@@ -36,14 +48,55 @@ type messagesCmp struct {
 	spinner       spinner.Model
 	rendering     bool
 	attachments   viewport.Model
+	toolProgress  map[string]toolProgress
+	view          transcriptView
+
+	// messageNumbers maps the number shown next to a message to its ID and
+	// messageOffsets maps message IDs to their first line in the viewport.
+	messageNumbers map[int]string
+	messageOffsets map[string]int
+
+	// plan is the session plan pinned above the transcript.
+	plan []memory.PlanStep
+
+	// While paused, message updates are still stored but the transcript is
+	// not re-rendered until rendering resumes.
+	paused        bool
+	pendingRender bool
+
+	// environment is shown on the start screen until it is dismissed.
+	environment      *environment
+	summaryDismissed bool
 }
 type renderFinishedMsg struct{}
 
+type toolProgressTickMsg struct{}
+
+func toolProgressTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return toolProgressTickMsg{}
+	})
+}
+
 type MessageKeys struct {
 	PageDown     key.Binding
 	PageUp       key.Binding
 	HalfPageUp   key.Binding
 	HalfPageDown key.Binding
+	ScrollLeft   key.Binding
+	ScrollRight  key.Binding
+	PauseRender  key.Binding
+	FocusResult  key.Binding
+}
+
+// ResultKeys are the keys used while a tool result is focused.
+type ResultKeys struct {
+	Down     key.Binding
+	Up       key.Binding
+	Next     key.Binding
+	Previous key.Binding
+	Expand   key.Binding
+	Exit     key.Binding
 }
 
 var messageKeys = MessageKeys{
@@ -63,10 +116,57 @@ var messageKeys = MessageKeys{
 		key.WithKeys("ctrl+d", "ctrl+d"),
 		key.WithHelp("ctrl+d", "½ page down"),
 	),
+	ScrollLeft: key.NewBinding(
+		key.WithKeys("shift+left"),
+		key.WithHelp("shift+←", "scroll code left"),
+	),
+	ScrollRight: key.NewBinding(
+		key.WithKeys("shift+right"),
+		key.WithHelp("shift+→", "scroll code right"),
+	),
+	PauseRender: key.NewBinding(
+		key.WithKeys("ctrl+g"),
+		key.WithHelp("ctrl+g", "pause/resume rendering"),
+	),
+	FocusResult: key.NewBinding(
+		key.WithKeys("ctrl+b"),
+		key.WithHelp("ctrl+b", "scroll a tool result"),
+	),
+}
+
+var resultKeys = ResultKeys{
+	Down: key.NewBinding(
+		key.WithKeys("j", "down"),
+		key.WithHelp("j/↓", "scroll result down"),
+	),
+	Up: key.NewBinding(
+		key.WithKeys("k", "up"),
+		key.WithHelp("k/↑", "scroll result up"),
+	),
+	Next: key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("tab", "next result"),
+	),
+	Previous: key.NewBinding(
+		key.WithKeys("shift+tab"),
+		key.WithHelp("shift+tab", "previous result"),
+	),
+	Expand: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "expand/collapse result"),
+	),
+	Exit: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "back to the transcript"),
+	),
 }
 
 func (m *messagesCmp) Init() tea.Cmd {
-	return tea.Batch(m.viewport.Init(), m.spinner.Tick)
+	cmds := []tea.Cmd{m.viewport.Init(), m.spinner.Tick, toolProgressTick()}
+	if config.Get().TUI.StartupSummary {
+		cmds = append(cmds, loadEnvironment)
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m *messagesCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -75,6 +175,13 @@ func (m *messagesCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case dialog.ThemeChangedMsg:
 		m.rerender()
 		return m, nil
+	case environmentMsg:
+		env := environment(msg)
+		m.environment = &env
+		return m, nil
+	case DismissStartupSummaryMsg:
+		m.summaryDismissed = true
+		return m, nil
 	case SessionSelectedMsg:
 		if msg.ID != m.session.ID {
 			cmd := m.SetSession(msg)
@@ -86,19 +193,90 @@ func (m *messagesCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.messages = make([]message.Message, 0)
 		m.currentMsgID = ""
 		m.rendering = false
+		m.paused = false
+		m.pendingRender = false
+		m.toolProgress = make(map[string]toolProgress)
+		m.loadPlan()
+		return m, m.clearResultFocus()
+	case pubsub.Event[memory.Entry]:
+		if msg.Payload.SessionID == m.session.ID && msg.Payload.Key == memory.PlanKey {
+			m.loadPlan()
+		}
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.view.resultFocus.toolCallID != "" {
+			if used, cmd := m.updateResultFocus(msg); used {
+				return m, cmd
+			}
+		} else if key.Matches(msg, messageKeys.FocusResult) {
+			return m, m.focusResult()
+		}
 		if key.Matches(msg, messageKeys.PageUp) || key.Matches(msg, messageKeys.PageDown) ||
 			key.Matches(msg, messageKeys.HalfPageUp) || key.Matches(msg, messageKeys.HalfPageDown) {
 			u, cmd := m.viewport.Update(msg)
 			m.viewport = u
 			cmds = append(cmds, cmd)
 		}
+		if m.view.codeBlocks.scroll && key.Matches(msg, messageKeys.ScrollLeft) && m.view.codeBlocks.offset > 0 {
+			m.view.codeBlocks.offset = max(0, m.view.codeBlocks.offset-codeScrollStep)
+			m.rerenderKeepingPosition()
+		}
+		if m.view.codeBlocks.scroll && key.Matches(msg, messageKeys.ScrollRight) {
+			m.view.codeBlocks.offset += codeScrollStep
+			m.rerenderKeepingPosition()
+		}
+		if key.Matches(msg, messageKeys.PauseRender) {
+			m.togglePause()
+		}
+
+	case GoToMessageMsg:
+		id, ok := m.messageNumbers[msg.Number]
+		if !ok {
+			return m, util.ReportWarn(fmt.Sprintf("Message #%d does not exist, the session has %d messages", msg.Number, len(m.messageNumbers)))
+		}
+		m.viewport.SetYOffset(m.messageOffsets[id])
+		return m, nil
+
+	case DeleteMessageMsg:
+		return m, m.deleteMessage(msg.Number)
+
+	case EditMessageMsg:
+		return m, m.editMessage(msg.Number)
+
+	case ToggleCodeWrapMsg:
+		m.view.codeBlocks.scroll = !m.view.codeBlocks.scroll
+		m.view.codeBlocks.offset = 0
+		m.rerenderKeepingPosition()
+		if m.view.codeBlocks.scroll {
+			return m, util.ReportInfo("Code blocks scroll horizontally (shift+←/→)")
+		}
+		return m, util.ReportInfo("Code blocks wrap")
 
 	case renderFinishedMsg:
 		m.rendering = false
 		m.viewport.GotoBottom()
+	case toolProgressTickMsg:
+		if len(m.toolProgress) > 0 {
+			if !m.IsAgentWorking() {
+				// The turn ended without results for these tools, e.g. on errors.
+				m.clearToolProgress()
+			}
+			m.rerenderRunningTools()
+		}
+		cmds = append(cmds, toolProgressTick())
+	case pubsub.Event[tools.ToolProgress]:
+		if msg.Payload.SessionID == m.session.ID {
+			p, ok := m.toolProgress[msg.Payload.ToolCallID]
+			if !ok {
+				p.startedAt = time.Now()
+			}
+			if msg.Payload.Output != "" {
+				p.output = msg.Payload.Output
+			}
+			m.toolProgress[msg.Payload.ToolCallID] = p
+			m.rerenderRunningTools()
+		}
 	case pubsub.Event[session.Session]:
 		if msg.Type == pubsub.UpdatedEvent && msg.Payload.ID == m.session.ID {
 			m.session = msg.Payload
@@ -111,6 +289,9 @@ func (m *messagesCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		needsRerender := false
 		if msg.Type == pubsub.CreatedEvent {
 			if msg.Payload.SessionID == m.session.ID {
+				for _, result := range msg.Payload.ToolResults() {
+					delete(m.toolProgress, result.ToolCallID)
+				}
 
 				messageExists := false
 				for _, v := range m.messages {
@@ -150,8 +331,25 @@ func (m *messagesCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					break
 				}
 			}
+		} else if msg.Type == pubsub.DeletedEvent && msg.Payload.SessionID == m.session.ID {
+			for i, v := range m.messages {
+				if v.ID == msg.Payload.ID {
+					m.messages = slices.Delete(m.messages, i, i+1)
+					delete(m.cachedContent, msg.Payload.ID)
+					if m.currentMsgID == msg.Payload.ID {
+						m.currentMsgID = ""
+						if len(m.messages) > 0 {
+							m.currentMsgID = m.messages[len(m.messages)-1].ID
+						}
+					}
+					needsRerender = true
+					break
+				}
+			}
 		}
-		if needsRerender {
+		if needsRerender && m.paused {
+			m.pendingRender = true
+		} else if needsRerender {
 			m.renderView()
 			if len(m.messages) > 0 {
 				if (msg.Type == pubsub.CreatedEvent) ||
@@ -168,6 +366,79 @@ func (m *messagesCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// deleteMessage deletes the message with the given number. Deleting an
+// assistant message also deletes the results of its tool calls, which the
+// providers reject without the calls.
+func (m *messagesCmp) deleteMessage(number int) tea.Cmd {
+	id, ok := m.messageNumbers[number]
+	if !ok {
+		return util.ReportWarn(fmt.Sprintf("Message #%d does not exist, the session has %d messages", number, len(m.messageNumbers)))
+	}
+	if m.IsAgentWorking() {
+		return util.ReportWarn("Cannot delete a message while the agent is working")
+	}
+
+	ids := []string{id}
+	callIDs := make(map[string]bool)
+	for _, msg := range m.messages {
+		if msg.ID == id {
+			for _, call := range msg.ToolCalls() {
+				callIDs[call.ID] = true
+			}
+		}
+	}
+	for _, msg := range m.messages {
+		for _, result := range msg.ToolResults() {
+			if callIDs[result.ToolCallID] {
+				ids = append(ids, msg.ID)
+				break
+			}
+		}
+	}
+
+	// The deleted events remove the messages from the transcript.
+	for _, id := range ids {
+		if err := m.app.Messages.Delete(context.Background(), id); err != nil {
+			return util.ReportError(fmt.Errorf("failed to delete message #%d: %w", number, err))
+		}
+	}
+	return util.ReportInfo(fmt.Sprintf("Deleted message #%d", number))
+}
+
+// editMessage puts the user message with the given number in the editor.
+// Sending it branches the session, so the transcript stays as it is.
+func (m *messagesCmp) editMessage(number int) tea.Cmd {
+	id, ok := m.messageNumbers[number]
+	if !ok {
+		return util.ReportWarn(fmt.Sprintf("Message #%d does not exist, the session has %d messages", number, len(m.messageNumbers)))
+	}
+	if m.IsAgentWorking() {
+		return util.ReportWarn("Cannot edit a message while the agent is working")
+	}
+	for _, msg := range m.messages {
+		if msg.ID != id {
+			continue
+		}
+		if msg.Role != message.User {
+			return util.ReportWarn(fmt.Sprintf("Message #%d is not one of your messages", number))
+		}
+		var attachments []message.Attachment
+		for _, binary := range msg.BinaryContent() {
+			attachments = append(attachments, message.Attachment{
+				FilePath: binary.Path,
+				FileName: filepath.Base(binary.Path),
+				MimeType: binary.MIMEType,
+				Content:  binary.Data,
+			})
+		}
+		return tea.Batch(
+			util.CmdHandler(StartEditMsg{MessageID: id, Text: msg.Content().String(), Attachments: attachments}),
+			util.ReportInfo(fmt.Sprintf("Editing message #%d, send to branch the session or esc to cancel", number)),
+		)
+	}
+	return nil
+}
+
 func (m *messagesCmp) IsAgentWorking() bool {
 	return m.app.CoderAgent.IsSessionBusy(m.session.ID)
 }
@@ -192,15 +463,29 @@ func (m *messagesCmp) renderView() {
 	if m.width == 0 {
 		return
 	}
+	m.messageOffsets = make(map[string]int)
+	m.messageNumbers = make(map[int]string)
+	lines := 0
+	number := 0
 	for inx, msg := range m.messages {
+		if msg.Role != message.User && msg.Role != message.Assistant && msg.Role != message.Review {
+			continue
+		}
+		number++
+		m.messageNumbers[number] = msg.ID
+		m.messageOffsets[msg.ID] = lines
+		rendered := len(m.uiMessages)
+
 		switch msg.Role {
 		case message.User:
 			if cache, ok := m.cachedContent[msg.ID]; ok && cache.width == m.width {
 				m.uiMessages = append(m.uiMessages, cache.content...)
-				continue
+				break
 			}
 			userMsg := renderUserMessage(
+				&m.view,
 				msg,
+				number,
 				msg.ID == m.currentMsgID,
 				m.width,
 				pos,
@@ -211,20 +496,23 @@ func (m *messagesCmp) renderView() {
 				content: []uiMessage{userMsg},
 			}
 			pos += userMsg.height + 1 // + 1 for spacing
-		case message.Assistant:
+		case message.Assistant, message.Review:
 			if cache, ok := m.cachedContent[msg.ID]; ok && cache.width == m.width {
 				m.uiMessages = append(m.uiMessages, cache.content...)
-				continue
+				break
 			}
 			isSummary := m.session.SummaryMessageID == msg.ID
 
 			assistantMessages := renderAssistantMessage(
 				msg,
 				inx,
+				number,
 				m.messages,
 				m.app.Messages,
 				m.currentMsgID,
 				isSummary,
+				m.toolProgress,
+				&m.view,
 				m.width,
 				pos,
 			)
@@ -237,6 +525,10 @@ func (m *messagesCmp) renderView() {
 				content: assistantMessages,
 			}
 		}
+
+		for _, uiMsg := range m.uiMessages[rendered:] {
+			lines += uiMsg.height + 1 // + 1 for spacing
+		}
 	}
 
 	messages := make([]string, 0)
@@ -302,6 +594,7 @@ func (m *messagesCmp) View() string {
 		Render(
 			lipgloss.JoinVertical(
 				lipgloss.Top,
+				m.pinnedPlan(),
 				m.viewport.View(),
 				m.working(),
 				m.help(),
@@ -377,7 +670,15 @@ func (m *messagesCmp) help() string {
 
 	text := ""
 
-	if m.app.CoderAgent.IsBusy() {
+	if m.paused {
+		text += lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			baseStyle.Foreground(t.Warning()).Bold(true).Render("rendering paused, "),
+			baseStyle.Foreground(t.TextMuted()).Bold(true).Render("press "),
+			baseStyle.Foreground(t.Text()).Bold(true).Render("ctrl+g"),
+			baseStyle.Foreground(t.TextMuted()).Bold(true).Render(" to resume"),
+		)
+	} else if m.app.CoderAgent.IsBusy() {
 		text += lipgloss.JoinHorizontal(
 			lipgloss.Left,
 			baseStyle.Foreground(t.TextMuted()).Bold(true).Render("press "),
@@ -403,16 +704,80 @@ func (m *messagesCmp) help() string {
 func (m *messagesCmp) initialScreen() string {
 	baseStyle := styles.BaseStyle()
 
+	sections := []string{header(m.width), ""}
+	if config.Get().TUI.StartupSummary && !m.summaryDismissed {
+		sections = append(sections, m.startupSummary(), "")
+	}
+	sections = append(sections, lspsConfigured(m.width))
 	return baseStyle.Width(m.width).Render(
-		lipgloss.JoinVertical(
-			lipgloss.Top,
-			header(m.width),
-			"",
-			lspsConfigured(m.width),
-		),
+		lipgloss.JoinVertical(lipgloss.Top, sections...),
 	)
 }
 
+// togglePause pauses or resumes rendering of the transcript. Updates that
+// arrive while paused are kept and rendered when rendering resumes.
+func (m *messagesCmp) togglePause() {
+	m.paused = !m.paused
+	if m.paused || !m.pendingRender {
+		return
+	}
+	m.pendingRender = false
+	m.renderView()
+	m.viewport.GotoBottom()
+}
+
+func (m *messagesCmp) clearToolProgress() {
+	for _, msg := range m.messages {
+		for _, call := range msg.ToolCalls() {
+			if _, ok := m.toolProgress[call.ID]; ok {
+				delete(m.cachedContent, msg.ID)
+			}
+		}
+	}
+	m.toolProgress = make(map[string]toolProgress)
+	m.renderView()
+}
+
+// rerenderRunningTools re-renders the messages with tool calls that are still
+// running, so their elapsed time and output stay up to date.
+func (m *messagesCmp) rerenderRunningTools() {
+	needsRerender := false
+	for _, msg := range m.messages {
+		for _, call := range msg.ToolCalls() {
+			if _, ok := m.toolProgress[call.ID]; ok {
+				delete(m.cachedContent, msg.ID)
+				needsRerender = true
+				break
+			}
+		}
+	}
+	if !needsRerender {
+		return
+	}
+	if m.paused {
+		m.pendingRender = true
+		return
+	}
+	atBottom := m.viewport.AtBottom()
+	m.renderView()
+	if atBottom {
+		m.viewport.GotoBottom()
+	}
+}
+
+// rerenderKeepingPosition re-renders every message while keeping the viewport
+// scrolled to the same place.
+func (m *messagesCmp) rerenderKeepingPosition() {
+	atBottom := m.viewport.AtBottom()
+	offset := m.viewport.YOffset
+	m.rerender()
+	if atBottom {
+		m.viewport.GotoBottom()
+	} else {
+		m.viewport.SetYOffset(offset)
+	}
+}
+
 func (m *messagesCmp) rerender() {
 	for _, msg := range m.messages {
 		delete(m.cachedContent, msg.ID)
@@ -420,6 +785,149 @@ func (m *messagesCmp) rerender() {
 	m.renderView()
 }
 
+// focusResult focuses the last tool result visible in the viewport, or the
+// last tool result of the session when none is visible.
+func (m *messagesCmp) focusResult() tea.Cmd {
+	ids := m.toolResultIDs()
+	if len(ids) == 0 {
+		return util.ReportInfo("There are no tool results to scroll")
+	}
+	id := ids[len(ids)-1]
+	for i := len(ids) - 1; i >= 0; i-- {
+		top, height, _ := m.uiMessageOffset(ids[i])
+		if top < m.viewport.YOffset+m.viewport.Height && top+height > m.viewport.YOffset {
+			id = ids[i]
+			break
+		}
+	}
+	return m.setResultFocus(id)
+}
+
+// updateResultFocus handles a key while a tool result is focused and reports
+// whether the key was used.
+func (m *messagesCmp) updateResultFocus(msg tea.KeyMsg) (bool, tea.Cmd) {
+	switch {
+	case key.Matches(msg, resultKeys.Expand):
+		id := m.view.resultFocus.toolCallID
+		if m.view.expanded[id] {
+			delete(m.view.expanded, id)
+		} else {
+			m.view.expanded[id] = true
+		}
+		m.rerenderToolCall(id)
+		// Keep the start of the result in view when it shrinks.
+		if top, _, ok := m.uiMessageOffset(id); ok && top < m.viewport.YOffset {
+			m.viewport.SetYOffset(top)
+		}
+	case key.Matches(msg, resultKeys.Down):
+		if m.view.expanded[m.view.resultFocus.toolCallID] {
+			// The whole result is shown; the transcript scrolls instead.
+			m.viewport.ScrollDown(1)
+			return true, nil
+		}
+		if m.view.resultFocus.offset+maxResultHeight < m.view.resultFocus.lines {
+			m.view.resultFocus.offset++
+			m.rerenderToolCall(m.view.resultFocus.toolCallID)
+		}
+	case key.Matches(msg, resultKeys.Up):
+		if m.view.expanded[m.view.resultFocus.toolCallID] {
+			m.viewport.ScrollUp(1)
+			return true, nil
+		}
+		if m.view.resultFocus.offset > 0 {
+			m.view.resultFocus.offset--
+			m.rerenderToolCall(m.view.resultFocus.toolCallID)
+		}
+	case key.Matches(msg, resultKeys.Next), key.Matches(msg, resultKeys.Previous):
+		step := 1
+		if key.Matches(msg, resultKeys.Previous) {
+			step = -1
+		}
+		ids := m.toolResultIDs()
+		current := slices.Index(ids, m.view.resultFocus.toolCallID)
+		if next := current + step; current >= 0 && next >= 0 && next < len(ids) {
+			m.setResultFocus(ids[next])
+		}
+	case key.Matches(msg, resultKeys.Exit):
+		return true, m.setResultFocus("")
+	default:
+		// Other keys are swallowed so they do not reach the editor, except
+		// the ones scrolling the whole transcript.
+		return !key.Matches(msg, messageKeys.PageUp, messageKeys.PageDown, messageKeys.HalfPageUp, messageKeys.HalfPageDown), nil
+	}
+	return true, nil
+}
+
+// setResultFocus moves the focus to the result of the given tool call, or
+// returns to the transcript when id is empty, and scrolls the result into
+// view. The returned command tells the other components whether the keys
+// now belong to the transcript.
+func (m *messagesCmp) setResultFocus(id string) tea.Cmd {
+	previous := m.view.resultFocus.toolCallID
+	m.view.resultFocus = resultFocusView{toolCallID: id}
+	m.rerenderToolCall(previous, id)
+	if id == "" {
+		return util.CmdHandler(ResultFocusMsg(false))
+	}
+	top, height, ok := m.uiMessageOffset(id)
+	if ok && (top < m.viewport.YOffset || top+height > m.viewport.YOffset+m.viewport.Height) {
+		m.viewport.SetYOffset(top)
+	}
+	return util.CmdHandler(ResultFocusMsg(true))
+}
+
+// clearResultFocus drops the focus without re-rendering, for when the
+// transcript is replaced.
+func (m *messagesCmp) clearResultFocus() tea.Cmd {
+	if m.view.resultFocus.toolCallID == "" {
+		return nil
+	}
+	m.view.resultFocus = resultFocusView{}
+	return util.CmdHandler(ResultFocusMsg(false))
+}
+
+// toolResultIDs returns the IDs of the tool calls with a successful result, in
+// transcript order.
+func (m *messagesCmp) toolResultIDs() []string {
+	var ids []string
+	for _, msg := range m.messages {
+		for _, call := range msg.ToolCalls() {
+			if result := findToolResponse(call.ID, m.messages); result != nil && !result.IsError {
+				ids = append(ids, call.ID)
+			}
+		}
+	}
+	return ids
+}
+
+// uiMessageOffset returns the first line and the height of the rendered
+// message with the given ID in the viewport.
+func (m *messagesCmp) uiMessageOffset(id string) (int, int, bool) {
+	lines := 0
+	for _, uiMsg := range m.uiMessages {
+		if uiMsg.ID == id {
+			return lines, uiMsg.height, true
+		}
+		lines += uiMsg.height + 1 // + 1 for spacing
+	}
+	return 0, 0, false
+}
+
+// rerenderToolCall re-renders the messages with the given tool calls while
+// keeping the viewport scrolled to the same place.
+func (m *messagesCmp) rerenderToolCall(ids ...string) {
+	for _, msg := range m.messages {
+		for _, call := range msg.ToolCalls() {
+			if call.ID != "" && slices.Contains(ids, call.ID) {
+				delete(m.cachedContent, msg.ID)
+			}
+		}
+	}
+	offset := m.viewport.YOffset
+	m.renderView()
+	m.viewport.SetYOffset(offset)
+}
+
 func (m *messagesCmp) SetSize(width, height int) tea.Cmd {
 	if m.width == width && m.height == height {
 		return nil
@@ -427,7 +935,7 @@ func (m *messagesCmp) SetSize(width, height int) tea.Cmd {
 	m.width = width
 	m.height = height
 	m.viewport.Width = width
-	m.viewport.Height = height - 2
+	m.viewport.Height = height - 2 - lipgloss.Height(m.pinnedPlan())
 	m.attachments.Width = width + 40
 	m.attachments.Height = 3
 	m.rerender()
@@ -443,9 +951,15 @@ func (m *messagesCmp) SetSession(session session.Session) tea.Cmd {
 		return nil
 	}
 	m.session = session
+	m.paused = false
+	m.pendingRender = false
+	m.toolProgress = make(map[string]toolProgress)
+	focusCmd := m.clearResultFocus()
+	m.view.expanded = make(map[string]bool)
+	m.loadPlan()
 	messages, err := m.app.Messages.List(context.Background(), session.ID)
 	if err != nil {
-		return util.ReportError(err)
+		return tea.Batch(focusCmd, util.ReportError(err))
 	}
 	m.messages = messages
 	if len(m.messages) > 0 {
@@ -453,10 +967,10 @@ func (m *messagesCmp) SetSession(session session.Session) tea.Cmd {
 	}
 	delete(m.cachedContent, m.currentMsgID)
 	m.rendering = true
-	return func() tea.Msg {
+	return tea.Batch(focusCmd, func() tea.Msg {
 		m.renderView()
 		return renderFinishedMsg{}
-	}
+	})
 }
 
 func (m *messagesCmp) BindingKeys() []key.Binding {
@@ -465,6 +979,10 @@ func (m *messagesCmp) BindingKeys() []key.Binding {
 		m.viewport.KeyMap.PageUp,
 		m.viewport.KeyMap.HalfPageUp,
 		m.viewport.KeyMap.HalfPageDown,
+		messageKeys.ScrollLeft,
+		messageKeys.ScrollRight,
+		messageKeys.PauseRender,
+		messageKeys.FocusResult,
 	}
 }
 
@@ -483,5 +1001,7 @@ func NewMessagesCmp(app *app.App) tea.Model {
 		viewport:      vp,
 		spinner:       s,
 		attachments:   attachmets,
+		toolProgress:  make(map[string]toolProgress),
+		view:          transcriptView{expanded: make(map[string]bool)},
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"path/filepath"
 	"strings"
 	"time"
@@ -12,6 +13,8 @@ import (
 	"github.com/charmbracelet/x/ansi"
 	"github.com/opencode-ai/opencode/internal/config"
 	"github.com/opencode-ai/opencode/internal/diff"
+	"github.com/opencode-ai/opencode/internal/format"
+	"github.com/opencode-ai/opencode/internal/language"
 	"github.com/opencode-ai/opencode/internal/llm/agent"
 	"github.com/opencode-ai/opencode/internal/llm/models"
 	"github.com/opencode-ai/opencode/internal/llm/tools"
@@ -38,13 +41,16 @@ type uiMessage struct {
 	content     string
 }
 
-func toMarkdown(content string, focused bool, width int) string {
+func toMarkdown(view *transcriptView, content string, focused bool, width int) string {
+	if view.codeBlocks.scroll {
+		return renderScrolledMarkdown(content, width, view.codeBlocks.offset)
+	}
 	r := styles.GetMarkdownRenderer(width)
 	rendered, _ := r.Render(content)
 	return rendered
 }
 
-func renderMessage(msg string, isUser bool, isFocused bool, width int, info ...string) string {
+func renderMessage(view *transcriptView, msg string, isUser bool, isFocused bool, width int, info ...string) string {
 	t := theme.CurrentTheme()
 
 	style := styles.BaseStyle().
@@ -60,7 +66,7 @@ func renderMessage(msg string, isUser bool, isFocused bool, width int, info ...s
 
 	// Apply markdown formatting and handle background color
 	parts := []string{
-		styles.ForceReplaceBackgroundWithLipgloss(toMarkdown(msg, isFocused, width), t.Background()),
+		styles.ForceReplaceBackgroundWithLipgloss(toMarkdown(view, msg, isFocused, width), t.Background()),
 	}
 
 	// Remove newline at the end
@@ -79,7 +85,7 @@ func renderMessage(msg string, isUser bool, isFocused bool, width int, info ...s
 	return rendered
 }
 
-func renderUserMessage(msg message.Message, isFocused bool, width int, position int) uiMessage {
+func renderUserMessage(view *transcriptView, msg message.Message, number int, isFocused bool, width int, position int) uiMessage {
 	var styledAttachments []string
 	t := theme.CurrentTheme()
 	attachmentStyles := styles.BaseStyle().
@@ -96,13 +102,16 @@ func renderUserMessage(msg message.Message, isFocused bool, width int, position
 		}
 		styledAttachments = append(styledAttachments, attachmentStyles.Render(filename))
 	}
-	content := ""
+	info := []string{}
 	if len(styledAttachments) > 0 {
-		attachmentContent := styles.BaseStyle().Width(width).Render(lipgloss.JoinHorizontal(lipgloss.Left, styledAttachments...))
-		content = renderMessage(msg.Content().String(), true, isFocused, width, attachmentContent)
-	} else {
-		content = renderMessage(msg.Content().String(), true, isFocused, width)
+		info = append(info, styles.BaseStyle().Width(width).Render(lipgloss.JoinHorizontal(lipgloss.Left, styledAttachments...)))
 	}
+	info = append(info, styles.BaseStyle().
+		Width(width-1).
+		Foreground(t.TextMuted()).
+		Render(fmt.Sprintf(" #%d", number)),
+	)
+	content := renderMessage(view, msg.Content().String(), true, isFocused, width, info...)
 	userMsg := uiMessage{
 		ID:          msg.ID,
 		messageType: userMessageType,
@@ -117,10 +126,13 @@ func renderUserMessage(msg message.Message, isFocused bool, width int, position
 func renderAssistantMessage(
 	msg message.Message,
 	msgIndex int,
+	number int,
 	allMessages []message.Message, // we need this to get tool results and the user message
 	messagesService message.Service, // We need this to get the task tool messages
 	focusedUIMessageId string,
 	isSummary bool,
+	progress map[string]toolProgress,
+	view *transcriptView,
 	width int,
 	position int,
 ) []uiMessage {
@@ -136,35 +148,35 @@ func renderAssistantMessage(
 	baseStyle := styles.BaseStyle()
 
 	// Add finish info if available
+	label := fmt.Sprintf(" #%d", number)
 	if finished {
+		status := ""
 		switch finishData.Reason {
 		case message.FinishReasonEndTurn:
-			took := formatTimestampDiff(msg.CreatedAt, finishData.Time)
-			info = append(info, baseStyle.
-				Width(width-1).
-				Foreground(t.TextMuted()).
-				Render(fmt.Sprintf(" %s (%s)", models.SupportedModels[msg.Model].Name, took)),
-			)
+			status = formatTimestampDiff(msg.CreatedAt, finishData.Time)
 		case message.FinishReasonCanceled:
-			info = append(info, baseStyle.
-				Width(width-1).
-				Foreground(t.TextMuted()).
-				Render(fmt.Sprintf(" %s (%s)", models.SupportedModels[msg.Model].Name, "canceled")),
-			)
+			status = "canceled"
 		case message.FinishReasonError:
-			info = append(info, baseStyle.
-				Width(width-1).
-				Foreground(t.TextMuted()).
-				Render(fmt.Sprintf(" %s (%s)", models.SupportedModels[msg.Model].Name, "error")),
-			)
+			status = "error"
 		case message.FinishReasonPermissionDenied:
-			info = append(info, baseStyle.
-				Width(width-1).
-				Foreground(t.TextMuted()).
-				Render(fmt.Sprintf(" %s (%s)", models.SupportedModels[msg.Model].Name, "permission denied")),
-			)
+			status = "permission denied"
+		}
+		if status != "" {
+			label = fmt.Sprintf(" #%d %s (%s)", number, models.SupportedModels[msg.Model].Name, status)
+		}
+	}
+	// The usage of the message goes to the right end of the line.
+	if usage := msg.UsagePart(); usage != nil {
+		usageText := formatMessageUsage(*usage)
+		if gap := width - 1 - lipgloss.Width(label) - lipgloss.Width(usageText); gap > 0 {
+			label += strings.Repeat(" ", gap) + usageText
 		}
 	}
+	info = append(info, baseStyle.
+		Width(width-1).
+		Foreground(t.TextMuted()).
+		Render(label),
+	)
 	if content != "" || (finished && finishData.Reason == message.FinishReasonEndTurn) {
 		if content == "" {
 			content = "*Finished without output*"
@@ -173,7 +185,7 @@ func renderAssistantMessage(
 			info = append(info, baseStyle.Width(width-1).Foreground(t.TextMuted()).Render(" (summary)"))
 		}
 
-		content = renderMessage(content, false, true, width, info...)
+		content = renderMessage(view, content, false, true, width, info...)
 		messages = append(messages, uiMessage{
 			ID:          msg.ID,
 			messageType: assistantMessageType,
@@ -185,7 +197,7 @@ func renderAssistantMessage(
 		position++ // for the space
 	} else if thinking && thinkingContent != "" {
 		// Render the thinking content
-		content = renderMessage(thinkingContent, false, msg.ID == focusedUIMessageId, width)
+		content = renderMessage(view, thinkingContent, false, msg.ID == focusedUIMessageId, width)
 	}
 
 	for i, toolCall := range msg.ToolCalls() {
@@ -195,6 +207,8 @@ func renderAssistantMessage(
 			messagesService,
 			focusedUIMessageId,
 			false,
+			progress,
+			view,
 			width,
 			i+1,
 		)
@@ -224,6 +238,10 @@ func toolName(name string) string {
 		return "Bash"
 	case tools.EditToolName:
 		return "Edit"
+	case tools.MultiEditToolName:
+		return "Multi-Edit"
+	case tools.OrganizeImportsToolName:
+		return "Organize Imports"
 	case tools.FetchToolName:
 		return "Fetch"
 	case tools.GlobToolName:
@@ -240,6 +258,36 @@ func toolName(name string) string {
 		return "Write"
 	case tools.PatchToolName:
 		return "Patch"
+	case tools.ScratchToolName:
+		return "Scratch"
+	case tools.MemoryToolName:
+		return "Memory"
+	case tools.RefactorToolName:
+		return "Refactor"
+	case tools.RenameTextToolName:
+		return "Rename"
+	case tools.FixDiagnosticsToolName:
+		return "Fix Diagnostics"
+	case tools.BuildCheckToolName:
+		return "Build Check"
+	case tools.RunTestToolName:
+		return "Run Test"
+	case tools.RunFunctionToolName:
+		return "Run Function"
+	case tools.ImportGraphToolName:
+		return "Import Graph"
+	case tools.GitLogSearchToolName:
+		return "Git Log"
+	case tools.ComplexityToolName:
+		return "Complexity"
+	case tools.MergeConflictsToolName:
+		return "Merge Conflicts"
+	case tools.ContinueToolName:
+		return "Continue"
+	case tools.EditorPanesToolName:
+		return "Editor Panes"
+	case tools.PlanToolName:
+		return "Plan"
 	}
 	return name
 }
@@ -252,6 +300,10 @@ func getToolAction(name string) string {
 		return "Building command..."
 	case tools.EditToolName:
 		return "Preparing edit..."
+	case tools.MultiEditToolName:
+		return "Preparing edits..."
+	case tools.OrganizeImportsToolName:
+		return "Organizing imports..."
 	case tools.FetchToolName:
 		return "Writing fetch..."
 	case tools.GlobToolName:
@@ -268,6 +320,36 @@ func getToolAction(name string) string {
 		return "Preparing write..."
 	case tools.PatchToolName:
 		return "Preparing patch..."
+	case tools.ScratchToolName:
+		return "Creating scratch file..."
+	case tools.MemoryToolName:
+		return "Updating memory..."
+	case tools.RefactorToolName:
+		return "Preparing refactor..."
+	case tools.RenameTextToolName:
+		return "Finding occurrences..."
+	case tools.FixDiagnosticsToolName:
+		return "Collecting fixes..."
+	case tools.BuildCheckToolName:
+		return "Building..."
+	case tools.RunTestToolName:
+		return "Running test..."
+	case tools.RunFunctionToolName:
+		return "Preparing harness..."
+	case tools.ImportGraphToolName:
+		return "Mapping imports..."
+	case tools.GitLogSearchToolName:
+		return "Searching history..."
+	case tools.ComplexityToolName:
+		return "Measuring complexity..."
+	case tools.MergeConflictsToolName:
+		return "Scanning for conflicts..."
+	case tools.ContinueToolName:
+		return "Reading truncated output..."
+	case tools.EditorPanesToolName:
+		return "Looking at editor panes..."
+	case tools.PlanToolName:
+		return "Updating plan..."
 	}
 	return "Working..."
 }
@@ -279,7 +361,7 @@ func renderParams(paramsWidth int, params ...string) string {
 	}
 	mainParam := params[0]
 	if len(mainParam) > paramsWidth {
-		mainParam = mainParam[:paramsWidth-3] + "..."
+		mainParam = mainParam[:format.CutPoint(mainParam, paramsWidth-3)] + "..."
 	}
 
 	if len(params) == 1 {
@@ -350,6 +432,16 @@ func renderToolParams(paramWidth int, toolCall message.ToolCall) string {
 		json.Unmarshal([]byte(toolCall.Input), &params)
 		filePath := removeWorkingDirPrefix(params.FilePath)
 		return renderParams(paramWidth, filePath)
+	case tools.MultiEditToolName:
+		var params tools.MultiEditParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		filePath := removeWorkingDirPrefix(params.FilePath)
+		return renderParams(paramWidth, filePath, "edits", fmt.Sprintf("%d", len(params.Edits)))
+	case tools.OrganizeImportsToolName:
+		var params tools.OrganizeImportsParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		filePath := removeWorkingDirPrefix(params.FilePath)
+		return renderParams(paramWidth, filePath)
 	case tools.FetchToolName:
 		var params tools.FetchParams
 		json.Unmarshal([]byte(toolCall.Input), &params)
@@ -391,6 +483,18 @@ func renderToolParams(paramWidth int, toolCall message.ToolCall) string {
 		if params.LiteralText {
 			toolParams = append(toolParams, "literal", "true")
 		}
+		if params.ContextLines > 0 {
+			toolParams = append(toolParams, "context", fmt.Sprintf("%d", params.ContextLines))
+		}
+		if params.ContextBefore > 0 {
+			toolParams = append(toolParams, "before", fmt.Sprintf("%d", params.ContextBefore))
+		}
+		if params.ContextAfter > 0 {
+			toolParams = append(toolParams, "after", fmt.Sprintf("%d", params.ContextAfter))
+		}
+		if params.FunctionContext {
+			toolParams = append(toolParams, "function", "true")
+		}
 		return renderParams(paramWidth, toolParams...)
 	case tools.LSToolName:
 		var params tools.LSParams
@@ -423,6 +527,135 @@ func renderToolParams(paramWidth int, toolCall message.ToolCall) string {
 		json.Unmarshal([]byte(toolCall.Input), &params)
 		filePath := removeWorkingDirPrefix(params.FilePath)
 		return renderParams(paramWidth, filePath)
+	case tools.ScratchToolName:
+		var params tools.ScratchParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		name := params.Name
+		if name == "" {
+			name = "scratch"
+		}
+		return renderParams(paramWidth, name)
+	case tools.MemoryToolName:
+		var params tools.MemoryParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		toolParams := []string{
+			params.Operation,
+		}
+		if params.Key != "" {
+			toolParams = append(toolParams, "key", params.Key)
+		}
+		return renderParams(paramWidth, toolParams...)
+	case tools.RefactorToolName:
+		var params tools.RefactorParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		return renderParams(paramWidth, params.Description, "edits", fmt.Sprintf("%d", len(params.Edits)))
+	case tools.RenameTextToolName:
+		var params tools.RenameTextParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		toolParams := []string{
+			params.OldName,
+			"to",
+			params.NewName,
+		}
+		if params.Path != "" {
+			toolParams = append(toolParams, "path", removeWorkingDirPrefix(params.Path))
+		}
+		if params.Include != "" {
+			toolParams = append(toolParams, "include", params.Include)
+		}
+		return renderParams(paramWidth, toolParams...)
+	case tools.FixDiagnosticsToolName:
+		var params tools.FixDiagnosticsParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		paths := make([]string, 0, len(params.FilePaths))
+		for _, path := range params.FilePaths {
+			paths = append(paths, removeWorkingDirPrefix(path))
+		}
+		return renderParams(paramWidth, strings.Join(paths, ", "))
+	case tools.BuildCheckToolName:
+		var params tools.BuildCheckParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		packages := params.Packages
+		if packages == "" {
+			packages = "./..."
+		}
+		return renderParams(paramWidth, packages)
+	case tools.RunTestToolName:
+		var params tools.RunTestParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		toolParams := []string{params.Test}
+		if params.Package != "" {
+			toolParams = append(toolParams, "package", params.Package)
+		}
+		return renderParams(paramWidth, toolParams...)
+	case tools.RunFunctionToolName:
+		var params tools.RunFunctionParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		call := fmt.Sprintf("%s(%s)", params.Function, strings.Join(params.Args, ", "))
+		return renderParams(paramWidth, call, "path", removeWorkingDirPrefix(params.Path))
+	case tools.PlanToolName:
+		var params tools.PlanParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		return renderParams(paramWidth, fmt.Sprintf("%d steps", len(params.Steps)))
+	case tools.GitLogSearchToolName:
+		var params tools.GitLogSearchParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		toolParams := []string{params.Pickaxe + params.Regex}
+		if params.Message != "" {
+			if toolParams[0] == "" {
+				toolParams[0] = params.Message
+			} else {
+				toolParams = append(toolParams, "message", params.Message)
+			}
+		}
+		if params.Author != "" {
+			toolParams = append(toolParams, "author", params.Author)
+		}
+		if params.Path != "" {
+			toolParams = append(toolParams, "path", removeWorkingDirPrefix(params.Path))
+		}
+		if params.Since != "" {
+			toolParams = append(toolParams, "since", params.Since)
+		}
+		return renderParams(paramWidth, toolParams...)
+	case tools.ImportGraphToolName:
+		var params tools.ImportGraphParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		if params.Target == "" {
+			return renderParams(paramWidth, "overview")
+		}
+		toolParams := []string{removeWorkingDirPrefix(params.Target)}
+		if params.Direction != "" {
+			toolParams = append(toolParams, "direction", params.Direction)
+		}
+		if params.Transitive {
+			toolParams = append(toolParams, "transitive", "true")
+		}
+		return renderParams(paramWidth, toolParams...)
+	case tools.ComplexityToolName:
+		var params tools.ComplexityParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		toolParams := []string{removeWorkingDirPrefix(params.FilePath)}
+		if params.Limit > 0 {
+			toolParams = append(toolParams, "limit", fmt.Sprintf("%d", params.Limit))
+		}
+		return renderParams(paramWidth, toolParams...)
+	case tools.ContinueToolName:
+		var params tools.ContinueParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		return renderParams(paramWidth, params.Token)
+	case tools.MergeConflictsToolName:
+		var params tools.MergeConflictsParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		path := "."
+		if params.Path != "" {
+			path = removeWorkingDirPrefix(params.Path)
+		}
+		toolParams := []string{path}
+		if params.ShowSides {
+			toolParams = append(toolParams, "show_sides", "true")
+		}
+		return renderParams(paramWidth, toolParams...)
 	default:
 		input := strings.ReplaceAll(toolCall.Input, "\n", " ")
 		params = renderParams(paramWidth, input)
@@ -430,46 +663,68 @@ func renderToolParams(paramWidth int, toolCall message.ToolCall) string {
 	return params
 }
 
+// truncateHeight keeps the first height lines of content, closing a code
+// block the cut leaves open.
 func truncateHeight(content string, height int) string {
 	lines := strings.Split(content, "\n")
 	if len(lines) > height {
-		return strings.Join(lines[:height], "\n")
+		return format.CloseFence(strings.Join(lines[:height], "\n"))
 	}
 	return content
 }
 
-func renderToolResponse(toolCall message.ToolCall, response message.ToolResult, width int) string {
+func renderToolResponse(view *transcriptView, toolCall message.ToolCall, response message.ToolResult, width int) string {
 	t := theme.CurrentTheme()
 	baseStyle := styles.BaseStyle()
 
 	if response.IsError {
-		errContent := fmt.Sprintf("Error: %s", strings.ReplaceAll(response.Content, "\n", " "))
-		errContent = ansi.Truncate(errContent, width-1, "...")
+		errContent := fmt.Sprintf("Error: %s", response.Content)
+		if !view.expanded[toolCall.ID] {
+			errContent = ansi.Truncate(strings.ReplaceAll(errContent, "\n", " "), width-1, "...")
+		}
 		return baseStyle.
 			Width(width).
 			Foreground(t.Error()).
 			Render(errContent)
 	}
 
-	resultContent := truncateHeight(response.Content, maxResultHeight)
+	resultContent := response.Content
+	switch toolCall.Name {
+	case tools.EditToolName, tools.MultiEditToolName, tools.RefactorToolName, tools.RenameTextToolName, tools.ViewToolName, tools.WriteToolName:
+		// These show their metadata or input instead of the result.
+	default:
+		resultContent = resultWindow(view, toolCall.ID, resultContent)
+	}
 	switch toolCall.Name {
 	case agent.AgentToolName:
 		return styles.ForceReplaceBackgroundWithLipgloss(
-			toMarkdown(resultContent, false, width),
+			toMarkdown(view, resultContent, false, width),
 			t.Background(),
 		)
 	case tools.BashToolName:
-		resultContent = fmt.Sprintf("```bash\n%s\n```", resultContent)
+		resultContent = format.Fence(resultContent, "bash")
 		return styles.ForceReplaceBackgroundWithLipgloss(
-			toMarkdown(resultContent, true, width),
+			toMarkdown(view, resultContent, true, width),
 			t.Background(),
 		)
-	case tools.EditToolName:
+	case tools.EditToolName, tools.MultiEditToolName, tools.OrganizeImportsToolName:
 		metadata := tools.EditResponseMetadata{}
 		json.Unmarshal([]byte(response.Metadata), &metadata)
-		truncDiff := truncateHeight(metadata.Diff, maxResultHeight)
-		formattedDiff, _ := diff.FormatDiff(truncDiff, diff.WithTotalWidth(width))
-		return formattedDiff
+		if metadata.Diff == "" {
+			// Nothing changed, e.g. imports that were already organized.
+			return baseStyle.Width(width).Foreground(t.TextMuted()).Render(resultContent)
+		}
+		return renderDiffResult(view, toolCall.ID, metadata.Diff, metadata.Additions, metadata.Removals, width)
+	case tools.RefactorToolName, tools.RenameTextToolName:
+		metadata := tools.RefactorResponseMetadata{}
+		json.Unmarshal([]byte(response.Metadata), &metadata)
+		diffs := make([]string, 0, len(metadata.Files))
+		for _, file := range metadata.Files {
+			formattedDiff, _ := diff.FormatDiff(file.Diff, diff.WithTotalWidth(width))
+			header := baseStyle.Width(width).Foreground(t.TextMuted()).Render(removeWorkingDirPrefix(file.FilePath))
+			diffs = append(diffs, header, formattedDiff)
+		}
+		return resultWindow(view, toolCall.ID, lipgloss.JoinVertical(lipgloss.Left, diffs...))
 	case tools.FetchToolName:
 		var params tools.FetchParams
 		json.Unmarshal([]byte(toolCall.Input), &params)
@@ -480,9 +735,9 @@ func renderToolResponse(toolCall message.ToolCall, response message.ToolResult,
 		case "html":
 			mdFormat = "html"
 		}
-		resultContent = fmt.Sprintf("```%s\n%s\n```", mdFormat, resultContent)
+		resultContent = format.Fence(resultContent, mdFormat)
 		return styles.ForceReplaceBackgroundWithLipgloss(
-			toMarkdown(resultContent, true, width),
+			toMarkdown(view, resultContent, true, width),
 			t.Background(),
 		)
 	case tools.GlobToolName:
@@ -496,15 +751,9 @@ func renderToolResponse(toolCall message.ToolCall, response message.ToolResult,
 	case tools.ViewToolName:
 		metadata := tools.ViewResponseMetadata{}
 		json.Unmarshal([]byte(response.Metadata), &metadata)
-		ext := filepath.Ext(metadata.FilePath)
-		if ext == "" {
-			ext = ""
-		} else {
-			ext = strings.ToLower(ext[1:])
-		}
-		resultContent = fmt.Sprintf("```%s\n%s\n```", ext, truncateHeight(metadata.Content, maxResultHeight))
+		resultContent = format.Fence(resultWindow(view, toolCall.ID, metadata.Content), language.Detect(metadata.FilePath).Fence())
 		return styles.ForceReplaceBackgroundWithLipgloss(
-			toMarkdown(resultContent, true, width),
+			toMarkdown(view, resultContent, true, width),
 			t.Background(),
 		)
 	case tools.WriteToolName:
@@ -512,32 +761,56 @@ func renderToolResponse(toolCall message.ToolCall, response message.ToolResult,
 		json.Unmarshal([]byte(toolCall.Input), &params)
 		metadata := tools.WriteResponseMetadata{}
 		json.Unmarshal([]byte(response.Metadata), &metadata)
-		ext := filepath.Ext(params.FilePath)
-		if ext == "" {
-			ext = ""
-		} else {
-			ext = strings.ToLower(ext[1:])
+		if metadata.Diff != "" {
+			return renderDiffResult(view, toolCall.ID, metadata.Diff, metadata.Additions, metadata.Removals, width)
 		}
-		resultContent = fmt.Sprintf("```%s\n%s\n```", ext, truncateHeight(params.Content, maxResultHeight))
+		// Results saved before writes reported a diff only have the content.
+		resultContent = format.Fence(resultWindow(view, toolCall.ID, params.Content), language.DetectContent(params.FilePath, []byte(params.Content)).Fence())
 		return styles.ForceReplaceBackgroundWithLipgloss(
-			toMarkdown(resultContent, true, width),
+			toMarkdown(view, resultContent, true, width),
 			t.Background(),
 		)
 	default:
-		resultContent = fmt.Sprintf("```text\n%s\n```", resultContent)
+		resultContent = format.Fence(resultContent, "text")
 		return styles.ForceReplaceBackgroundWithLipgloss(
-			toMarkdown(resultContent, true, width),
+			toMarkdown(view, resultContent, true, width),
 			t.Background(),
 		)
 	}
 }
 
+// renderDiffResult renders the diff of a file change under a +N -M summary
+// of the lines it adds and removes.
+func renderDiffResult(view *transcriptView, toolCallID, unifiedDiff string, additions, removals, width int) string {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+
+	summary := lipgloss.JoinHorizontal(lipgloss.Left,
+		baseStyle.Foreground(t.DiffAdded()).Render(fmt.Sprintf("+%d", additions)),
+		baseStyle.Render(" "),
+		baseStyle.Foreground(t.DiffRemoved()).Render(fmt.Sprintf("-%d", removals)),
+	)
+	header := baseStyle.Width(width).Render(summary)
+
+	if view.resultFocus.toolCallID == toolCallID || view.expanded[toolCallID] {
+		// A window in the middle of the diff cannot be parsed on its
+		// own, so the whole diff is formatted before scrolling it.
+		formattedDiff, _ := diff.FormatDiff(unifiedDiff, diff.WithTotalWidth(width))
+		return lipgloss.JoinVertical(lipgloss.Left, header, resultWindow(view, toolCallID, formattedDiff))
+	}
+	truncDiff := truncateHeight(unifiedDiff, maxResultHeight)
+	formattedDiff, _ := diff.FormatDiff(truncDiff, diff.WithTotalWidth(width))
+	return lipgloss.JoinVertical(lipgloss.Left, header, formattedDiff)
+}
+
 func renderToolMessage(
 	toolCall message.ToolCall,
 	allMessages []message.Message,
 	messagesService message.Service,
 	focusedUIMessageId string,
 	nested bool,
+	progress map[string]toolProgress,
+	view *transcriptView,
 	width int,
 	position int,
 ) uiMessage {
@@ -554,6 +827,10 @@ func renderToolMessage(
 		BorderStyle(lipgloss.ThickBorder()).
 		PaddingLeft(1).
 		BorderForeground(t.TextMuted())
+	focused := !nested && view.resultFocus.toolCallID == toolCall.ID
+	if focused {
+		style = style.BorderForeground(t.Primary())
+	}
 
 	response := findToolResponse(toolCall.ID, allMessages)
 	toolNameText := baseStyle.Foreground(t.TextMuted()).
@@ -568,8 +845,15 @@ func renderToolMessage(
 			Foreground(t.TextMuted()).
 			Render(fmt.Sprintf("%s", toolAction))
 
-		content := style.Render(lipgloss.JoinHorizontal(lipgloss.Left, toolNameText, progressText))
+		content := lipgloss.JoinHorizontal(lipgloss.Left, toolNameText, progressText)
+		if toolCall.Input != "" {
+			// Show the arguments as the model streams them.
+			content = lipgloss.JoinVertical(lipgloss.Left, content,
+				baseStyle.Width(width-2).Foreground(t.TextMuted()).Render(partialInput(toolCall.Input, width-2)))
+		}
+		content = style.Render(content)
 		toolMsg := uiMessage{
+			ID:          toolCall.ID,
 			messageType: toolMessageType,
 			position:    position,
 			height:      lipgloss.Height(content),
@@ -578,11 +862,18 @@ func renderToolMessage(
 		return toolMsg
 	}
 
-	params := renderToolParams(width-2-lipgloss.Width(toolNameText), toolCall)
+	paramWidth := width - 2 - lipgloss.Width(toolNameText)
+	if view.expanded[toolCall.ID] {
+		// Expanded parameters wrap instead of being cut.
+		paramWidth = math.MaxInt32
+	}
+	params := renderToolParams(paramWidth, toolCall)
 	responseContent := ""
 	if response != nil {
-		responseContent = renderToolResponse(toolCall, *response, width-2)
+		responseContent = renderToolResponse(view, toolCall, *response, width-2)
 		responseContent = strings.TrimSuffix(responseContent, "\n")
+	} else if p, ok := progress[toolCall.ID]; ok {
+		responseContent = renderToolProgress(p, width-2)
 	} else {
 		responseContent = baseStyle.
 			Italic(true).
@@ -617,13 +908,16 @@ func renderToolMessage(
 			toolCalls = append(toolCalls, v.ToolCalls()...)
 		}
 		for _, call := range toolCalls {
-			rendered := renderToolMessage(call, []message.Message{}, messagesService, focusedUIMessageId, true, width, 0)
+			rendered := renderToolMessage(call, []message.Message{}, messagesService, focusedUIMessageId, true, nil, view, width, 0)
 			parts = append(parts, rendered.content)
 		}
 	}
 	if responseContent != "" && !nested {
 		parts = append(parts, responseContent)
 	}
+	if focused && response != nil {
+		parts = append(parts, renderResultFocusHint(view, width-2))
+	}
 
 	content := style.Render(
 		lipgloss.JoinVertical(
@@ -638,6 +932,7 @@ func renderToolMessage(
 		)
 	}
 	toolMsg := uiMessage{
+		ID:          toolCall.ID,
 		messageType: toolMessageType,
 		position:    position,
 		height:      lipgloss.Height(content),
@@ -646,6 +941,74 @@ func renderToolMessage(
 	return toolMsg
 }
 
+// renderResultFocusHint shows which lines of the focused result are visible
+// and the keys to scroll it.
+func renderResultFocusHint(view *transcriptView, width int) string {
+	t := theme.CurrentTheme()
+	first := min(view.resultFocus.offset+1, view.resultFocus.lines)
+	last := min(view.resultFocus.offset+maxResultHeight, view.resultFocus.lines)
+	hint := fmt.Sprintf("lines %d-%d of %d · j/k scroll · e expand · tab next result · esc back", first, last, view.resultFocus.lines)
+	if view.expanded[view.resultFocus.toolCallID] {
+		hint = fmt.Sprintf("all %d lines · e collapse · tab next result · esc back", view.resultFocus.lines)
+	}
+	return styles.BaseStyle().
+		Width(width).
+		Foreground(t.TextMuted()).
+		Render(hint)
+}
+
+// partialInput returns the end of the arguments of a tool call that is still
+// being streamed, on a single line of at most width cells.
+func partialInput(input string, width int) string {
+	input = strings.Join(strings.Fields(input), " ")
+	if w := ansi.StringWidth(input); w > width {
+		input = ansi.TruncateLeft(input, w-width+3, "...")
+	}
+	return input
+}
+
+// renderToolProgress shows how long a tool has been running and the last
+// lines of output it reported, as many as the liveOutputLines option allows.
+func renderToolProgress(p toolProgress, width int) string {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+
+	elapsed := time.Since(p.startedAt).Round(time.Second)
+	parts := []string{
+		baseStyle.
+			Italic(true).
+			Width(width).
+			Foreground(t.TextMuted()).
+			Render(fmt.Sprintf("Running... %s", elapsed)),
+	}
+
+	output := strings.TrimRight(p.output, "\n")
+	if maxLines := config.Get().TUI.LiveOutputLines; output != "" && maxLines > 0 {
+		lines := strings.Split(output, "\n")
+		if len(lines) > maxLines {
+			lines = lines[len(lines)-maxLines:]
+		}
+		for i, line := range lines {
+			// Progress bars redraw their line with carriage returns; only
+			// the latest state is shown.
+			line = strings.TrimRight(line, "\r")
+			line = line[strings.LastIndex(line, "\r")+1:]
+			lines[i] = ansi.Truncate(strings.ReplaceAll(line, "\t", "    "), width, "...")
+		}
+		parts = append(parts, baseStyle.
+			Width(width).
+			Foreground(t.TextMuted()).
+			Render(strings.Join(lines, "\n")))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, parts...)
+}
+
+// formatMessageUsage formats the tokens and the cost of a message, e.g.
+// "12.3K tokens, $0.042".
+func formatMessageUsage(usage message.Usage) string {
+	return fmt.Sprintf("%s tokens, $%.3f", format.Tokens(usage.Tokens()), usage.Cost)
+}
+
 // Helper function to format the time difference between two Unix timestamps
 func formatTimestampDiff(start, end int64) string {
 	diffSeconds := float64(end-start) / 1000.0 // Convert to seconds
@@ -0,0 +1,92 @@
+package chat
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/memory"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/theme"
+)
+
+// maxPlanSteps is the number of plan steps shown in the pinned panel.
+const maxPlanSteps = 8
+
+// loadPlan reads the plan of the current session and resizes the viewport
+// around the pinned panel.
+func (m *messagesCmp) loadPlan() {
+	m.plan = nil
+	if m.session.ID != "" && m.app.Memory != nil {
+		entry, err := m.app.Memory.Get(context.Background(), m.session.ID, memory.PlanKey)
+		switch {
+		case err == nil:
+			m.plan = memory.ParsePlan(entry.Value)
+		case !errors.Is(err, sql.ErrNoRows):
+			logging.Warn("failed to load plan", "session", m.session.ID, "error", err)
+		}
+	}
+	m.viewport.Height = m.height - 2 - lipgloss.Height(m.pinnedPlan())
+}
+
+// pinnedPlan renders the session plan shown above the transcript.
+func (m *messagesCmp) pinnedPlan() string {
+	if len(m.plan) == 0 {
+		return ""
+	}
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+
+	done := 0
+	current := -1
+	for i, step := range m.plan {
+		switch step.Marker {
+		case memory.PlanDone:
+			done++
+		case memory.PlanInProgress:
+			if current == -1 {
+				current = i
+			}
+		}
+	}
+
+	// Keep the step in progress visible when the plan is too long.
+	start := 0
+	if current >= maxPlanSteps {
+		start = current - maxPlanSteps + 1
+	}
+	end := min(len(m.plan), start+maxPlanSteps)
+
+	lines := []string{
+		baseStyle.
+			Foreground(t.Primary()).
+			Bold(true).
+			Render(fmt.Sprintf("Plan (%d/%d)", done, len(m.plan))),
+	}
+	textWidth := max(m.width-6, 10)
+	for _, step := range m.plan[start:end] {
+		icon, style := "○", baseStyle.Foreground(t.TextMuted())
+		switch step.Marker {
+		case memory.PlanDone:
+			icon, style = "✓", baseStyle.Foreground(t.Success())
+		case memory.PlanInProgress:
+			icon, style = "▸", baseStyle.Foreground(t.Text()).Bold(true)
+		}
+		lines = append(lines, style.Render(fmt.Sprintf("%s %s", icon, ansi.Truncate(step.Text, textWidth, "…"))))
+	}
+	if hidden := len(m.plan) - (end - start); hidden > 0 {
+		lines = append(lines, baseStyle.Foreground(t.TextMuted()).Render(fmt.Sprintf("… %d more", hidden)))
+	}
+
+	return baseStyle.
+		Width(m.width).
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderBottom(true).
+		BorderForeground(t.BorderNormal()).
+		BorderBackground(t.Background()).
+		Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,73 @@
+package chat
+
+import (
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/format"
+)
+
+// resultFocusView is the tool result that is scrolled on its own. While a
+// result is focused the keys go to the transcript instead of the editor: j/k
+// scroll the result by line, tab moves to the next result and esc returns to
+// the transcript.
+type resultFocusView struct {
+	toolCallID string
+	offset     int
+	// lines is the number of lines of the focused result, known once it is
+	// rendered.
+	lines int
+}
+
+// transcriptView is the state of a transcript that changes how its messages
+// are rendered. It belongs to the messages component, which passes it down
+// to the render functions.
+type transcriptView struct {
+	// expanded holds the tool calls whose parameters and results are shown
+	// in full instead of cut to their first lines.
+	expanded map[string]bool
+
+	codeBlocks  codeBlockView
+	resultFocus resultFocusView
+}
+
+// resultWindow returns the part of a tool result that is shown: its first
+// maxResultHeight lines, or for the focused result the lines at its scroll
+// offset. Expanded results are shown whole.
+func resultWindow(view *transcriptView, toolCallID, content string) string {
+	if view.expanded[toolCallID] {
+		if view.resultFocus.toolCallID == toolCallID {
+			view.resultFocus.lines = strings.Count(content, "\n") + 1
+			view.resultFocus.offset = 0
+		}
+		return content
+	}
+	if view.resultFocus.toolCallID != toolCallID {
+		return truncateHeight(content, maxResultHeight)
+	}
+	lines := strings.Split(content, "\n")
+	view.resultFocus.lines = len(lines)
+	view.resultFocus.offset = min(view.resultFocus.offset, max(0, len(lines)-maxResultHeight))
+
+	window := strings.Join(lines[view.resultFocus.offset:min(len(lines), view.resultFocus.offset+maxResultHeight)], "\n")
+	// Reopen a code block that started above the window.
+	if opening := openingFence(lines[:view.resultFocus.offset]); opening != "" {
+		window = opening + "\n" + window
+	}
+	return format.CloseFence(window)
+}
+
+// openingFence returns the line that opened the code block still open after
+// lines, including its language, or an empty string.
+func openingFence(lines []string) string {
+	fence := format.OpenFence(strings.Join(lines, "\n"))
+	if fence == "" {
+		return ""
+	}
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(line, fence) && format.OpenFence(strings.Join(lines[:i], "\n")) == "" {
+			return line
+		}
+	}
+	return fence
+}
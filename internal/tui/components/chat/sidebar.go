@@ -3,14 +3,19 @@ package chat
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/opencode-ai/opencode/internal/config"
 	"github.com/opencode-ai/opencode/internal/diff"
 	"github.com/opencode-ai/opencode/internal/history"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
+	"github.com/opencode-ai/opencode/internal/memory"
 	"github.com/opencode-ai/opencode/internal/pubsub"
 	"github.com/opencode-ai/opencode/internal/session"
 	"github.com/opencode-ai/opencode/internal/tui/styles"
@@ -21,10 +26,63 @@ type sidebarCmp struct {
 	width, height int
 	session       session.Session
 	history       history.Service
+	memory        memory.Service
 	modFiles      map[string]struct {
 		additions int
 		removals  int
 	}
+	notes []memory.Entry
+	// stale holds the modified files that changed on disk since the agent
+	// last read them.
+	stale map[string]bool
+}
+
+// staleCheckInterval is how often the sidebar looks for modified files that
+// changed on disk behind the agent's back.
+const staleCheckInterval = 2 * time.Second
+
+// staleCheckMsg triggers a check of the modified files. It carries the
+// sidebar that scheduled it, so a replaced sidebar stops checking.
+type staleCheckMsg struct {
+	sidebar *sidebarCmp
+}
+
+func (m *sidebarCmp) scheduleStaleCheck() tea.Cmd {
+	return tea.Tick(staleCheckInterval, func(time.Time) tea.Msg {
+		return staleCheckMsg{sidebar: m}
+	})
+}
+
+// staleFilesMsg carries the modified files of a session that changed on
+// disk since the agent read them.
+type staleFilesMsg struct {
+	sidebar   *sidebarCmp
+	sessionID string
+	stale     map[string]bool
+}
+
+// checkStale looks for modified files that changed on disk since the agent
+// read them. The files are stat'ed and read, so this runs as a command
+// instead of on the update loop.
+func (m *sidebarCmp) checkStale() tea.Cmd {
+	sessionID := m.session.ID
+	paths := make([]string, 0, len(m.modFiles))
+	for path := range m.modFiles {
+		paths = append(paths, path)
+	}
+	return func() tea.Msg {
+		stale := make(map[string]bool)
+		for _, path := range paths {
+			absPath := path
+			if !filepath.IsAbs(absPath) {
+				absPath = filepath.Join(config.WorkingDirectory(), path)
+			}
+			if tools.ModifiedSinceRead(sessionID, absPath) {
+				stale[path] = true
+			}
+		}
+		return staleFilesMsg{sidebar: m, sessionID: sessionID, stale: stale}
+	}
 }
 
 func (m *sidebarCmp) Init() tea.Cmd {
@@ -41,22 +99,46 @@ func (m *sidebarCmp) Init() tea.Cmd {
 
 		// Load initial files and calculate diffs
 		m.loadModifiedFiles(ctx)
+		m.loadNotes(ctx)
 
 		// Return a command that will send file events to the Update method
-		return func() tea.Msg {
-			return <-filesCh
-		}
+		return tea.Batch(
+			func() tea.Msg {
+				return <-filesCh
+			},
+			m.scheduleStaleCheck(),
+		)
 	}
 	return nil
 }
 
 func (m *sidebarCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case staleCheckMsg:
+		if msg.sidebar != m {
+			return m, nil
+		}
+		return m, m.checkStale()
+	case staleFilesMsg:
+		if msg.sidebar != m {
+			return m, nil
+		}
+		if msg.sessionID == m.session.ID {
+			m.stale = msg.stale
+		}
+		// The next check is scheduled once this one is done, so slow
+		// checks do not pile up.
+		return m, m.scheduleStaleCheck()
 	case SessionSelectedMsg:
 		if msg.ID != m.session.ID {
 			m.session = msg
 			ctx := context.Background()
 			m.loadModifiedFiles(ctx)
+			m.loadNotes(ctx)
+		}
+	case pubsub.Event[memory.Entry]:
+		if msg.Payload.SessionID == m.session.ID {
+			m.loadNotes(context.Background())
 		}
 	case pubsub.Event[session.Session]:
 		if msg.Type == pubsub.UpdatedEvent {
@@ -99,6 +181,8 @@ func (m *sidebarCmp) View() string {
 				lspsConfigured(m.width),
 				" ",
 				m.modifiedFiles(),
+				" ",
+				m.memoryNotes(),
 			),
 		)
 }
@@ -124,7 +208,7 @@ func (m *sidebarCmp) sessionSection() string {
 	)
 }
 
-func (m *sidebarCmp) modifiedFile(filePath string, additions, removals int) string {
+func (m *sidebarCmp) modifiedFile(filePath string, additions, removals int, stale bool) string {
 	t := theme.CurrentTheme()
 	baseStyle := styles.BaseStyle()
 
@@ -158,6 +242,14 @@ func (m *sidebarCmp) modifiedFile(filePath string, additions, removals int) stri
 
 	filePathStr := baseStyle.Render(filePath)
 
+	badge := ""
+	if stale {
+		badge = baseStyle.
+			Foreground(t.Warning()).
+			PaddingLeft(1).
+			Render("⚠")
+	}
+
 	return baseStyle.
 		Width(m.width).
 		Render(
@@ -165,6 +257,7 @@ func (m *sidebarCmp) modifiedFile(filePath string, additions, removals int) stri
 				lipgloss.Left,
 				filePathStr,
 				stats,
+				badge,
 			),
 		)
 }
@@ -208,7 +301,13 @@ func (m *sidebarCmp) modifiedFiles() string {
 	var fileViews []string
 	for _, path := range paths {
 		stats := m.modFiles[path]
-		fileViews = append(fileViews, m.modifiedFile(path, stats.additions, stats.removals))
+		fileViews = append(fileViews, m.modifiedFile(path, stats.additions, stats.removals, m.stale[path]))
+	}
+	if len(m.stale) > 0 {
+		fileViews = append(fileViews, baseStyle.
+			Foreground(t.Warning()).
+			Width(m.width).
+			Render("⚠ changed on disk since the agent read it; its next edit will ask it to read the file again"))
 	}
 
 	return baseStyle.
@@ -225,6 +324,54 @@ func (m *sidebarCmp) modifiedFiles() string {
 		)
 }
 
+func (m *sidebarCmp) memoryNotes() string {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+
+	title := baseStyle.
+		Width(m.width).
+		Foreground(t.Primary()).
+		Bold(true).
+		Render("Memory:")
+
+	if len(m.notes) == 0 {
+		return baseStyle.
+			Width(m.width).
+			Render(
+				lipgloss.JoinVertical(
+					lipgloss.Top,
+					title,
+					baseStyle.Foreground(t.TextMuted()).Width(m.width).Render("No notes"),
+				),
+			)
+	}
+
+	noteViews := make([]string, 0, len(m.notes))
+	for _, note := range m.notes {
+		key := baseStyle.Foreground(t.Text()).Render(note.Key)
+		value := strings.ReplaceAll(note.Value, "\n", " ")
+		valueWidth := max(m.width-lipgloss.Width(key)-2, 0)
+		valueStr := baseStyle.
+			Foreground(t.TextMuted()).
+			Width(valueWidth).
+			Render(ansi.Truncate(value, valueWidth, "…"))
+		noteViews = append(noteViews, lipgloss.JoinHorizontal(lipgloss.Left, key, baseStyle.Render(": "), valueStr))
+	}
+
+	return baseStyle.
+		Width(m.width).
+		Render(
+			lipgloss.JoinVertical(
+				lipgloss.Top,
+				title,
+				lipgloss.JoinVertical(
+					lipgloss.Left,
+					noteViews...,
+				),
+			),
+		)
+}
+
 func (m *sidebarCmp) SetSize(width, height int) tea.Cmd {
 	m.width = width
 	m.height = height
@@ -235,26 +382,32 @@ func (m *sidebarCmp) GetSize() (int, int) {
 	return m.width, m.height
 }
 
-func NewSidebarCmp(session session.Session, history history.Service) tea.Model {
+func NewSidebarCmp(session session.Session, history history.Service, memory memory.Service) tea.Model {
 	return &sidebarCmp{
 		session: session,
 		history: history,
+		memory:  memory,
 	}
 }
 
-func (m *sidebarCmp) loadModifiedFiles(ctx context.Context) {
-	if m.history == nil || m.session.ID == "" {
+func (m *sidebarCmp) loadNotes(ctx context.Context) {
+	if m.memory == nil || m.session.ID == "" {
+		m.notes = nil
 		return
 	}
-
-	// Get all latest files for this session
-	latestFiles, err := m.history.ListLatestSessionFiles(ctx, m.session.ID)
+	notes, err := m.memory.List(ctx, m.session.ID)
 	if err != nil {
 		return
 	}
+	m.notes = notes
+}
+
+func (m *sidebarCmp) loadModifiedFiles(ctx context.Context) {
+	if m.history == nil || m.session.ID == "" {
+		return
+	}
 
-	// Get all files for this session (to find initial versions)
-	allFiles, err := m.history.ListBySession(ctx, m.session.ID)
+	changes, err := history.SessionChanges(ctx, m.history, m.session.ID)
 	if err != nil {
 		return
 	}
@@ -265,48 +418,13 @@ func (m *sidebarCmp) loadModifiedFiles(ctx context.Context) {
 		removals  int
 	})
 
-	// Process each latest file
-	for _, file := range latestFiles {
-		// Skip if this is the initial version (no changes to show)
-		if file.Version == history.InitialVersion {
-			continue
-		}
-
-		// Find the initial version for this specific file
-		var initialVersion history.File
-		for _, v := range allFiles {
-			if v.Path == file.Path && v.Version == history.InitialVersion {
-				initialVersion = v
-				break
-			}
-		}
-
-		// Skip if we can't find the initial version
-		if initialVersion.ID == "" {
-			continue
-		}
-		if initialVersion.Content == file.Content {
-			continue
-		}
-
-		// Calculate diff between initial and latest version
-		_, additions, removals := diff.GenerateDiff(initialVersion.Content, file.Content, file.Path)
-
-		// Only add to modified files if there are changes
-		if additions > 0 || removals > 0 {
-			// Remove working directory prefix from file path
-			displayPath := file.Path
-			workingDir := config.WorkingDirectory()
-			displayPath = strings.TrimPrefix(displayPath, workingDir)
-			displayPath = strings.TrimPrefix(displayPath, "/")
-
-			m.modFiles[displayPath] = struct {
-				additions int
-				removals  int
-			}{
-				additions: additions,
-				removals:  removals,
-			}
+	for _, change := range changes {
+		m.modFiles[getDisplayPath(change.Path)] = struct {
+			additions int
+			removals  int
+		}{
+			additions: change.Additions,
+			removals:  change.Removals,
 		}
 	}
 }
@@ -0,0 +1,51 @@
+package chat
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/opencode-ai/opencode/internal/config"
+)
+
+// snippetPattern matches a :name trigger at the start of the text or after whitespace.
+var snippetPattern = regexp.MustCompile(`(^|\s):([A-Za-z0-9_-]+)\b`)
+
+// expandSnippets replaces every :name trigger that matches a configured
+// snippet with the snippet text. Unknown triggers are left untouched.
+func expandSnippets(text string, snippets map[string]string) string {
+	if len(snippets) == 0 {
+		return text
+	}
+	return snippetPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := snippetPattern.FindStringSubmatch(match)
+		snippet, ok := snippets[parts[2]]
+		if !ok {
+			return match
+		}
+		return parts[1] + snippet
+	})
+}
+
+// snippetBindings lists the configured snippets so they show up in the help dialog.
+func snippetBindings() []key.Binding {
+	snippets := config.Get().TUI.Snippets
+	names := make([]string, 0, len(snippets))
+	for name := range snippets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	bindings := make([]key.Binding, 0, len(names))
+	for _, name := range names {
+		trigger := fmt.Sprintf(":%s", name)
+		bindings = append(bindings, key.NewBinding(
+			key.WithKeys(trigger),
+			key.WithHelp(trigger, ansi.Truncate(strings.Join(strings.Fields(snippets[name]), " "), 40, "...")),
+		))
+	}
+	return bindings
+}
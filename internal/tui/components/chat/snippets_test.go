@@ -0,0 +1,42 @@
+package chat
+
+import "testing"
+
+func TestExpandSnippets(t *testing.T) {
+	snippets := map[string]string{
+		"review": "Review the changes for bugs.",
+		"test":   "Add tests for the new code.",
+	}
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    ":review",
+			expected: "Review the changes for bugs.",
+		},
+		{
+			input:    "Fix the parser. :test",
+			expected: "Fix the parser. Add tests for the new code.",
+		},
+		{
+			input:    ":review\n:test",
+			expected: "Review the changes for bugs.\nAdd tests for the new code.",
+		},
+		{
+			input:    "Use :unknown as is",
+			expected: "Use :unknown as is",
+		},
+		{
+			input:    "See http://example.com:test and key:review",
+			expected: "See http://example.com:test and key:review",
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := expandSnippets(tc.input, snippets); got != tc.expected {
+			t.Errorf("expandSnippets(%q) = %q, expected %q", tc.input, got, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/fileutil"
+	"github.com/opencode-ai/opencode/internal/git"
+	"github.com/opencode-ai/opencode/internal/language"
+	"github.com/opencode-ai/opencode/internal/llm/agent"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/theme"
+)
+
+// DismissStartupSummaryMsg hides the environment summary of the start screen.
+type DismissStartupSummaryMsg struct{}
+
+const (
+	// maxSurveyedFiles bounds the walk that detects the languages of the
+	// working directory, so large trees do not slow down the start.
+	maxSurveyedFiles    = 5000
+	maxSummaryLanguages = 4
+)
+
+// environment is what the start screen reports about the environment the
+// agent works in, gathered once in the background.
+type environment struct {
+	branch    string
+	languages []language.ID
+}
+
+type environmentMsg environment
+
+// loadEnvironment finds the git branch and the main languages of the working
+// directory.
+func loadEnvironment() tea.Msg {
+	root := config.WorkingDirectory()
+	env := environment{languages: surveyLanguages(root)}
+	if git.Available() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if out, err := git.Run(ctx, root, "rev-parse", "--abbrev-ref", "HEAD"); err == nil {
+			env.branch = strings.TrimSpace(out)
+		}
+	}
+	return environmentMsg(env)
+}
+
+// surveyLanguages returns the languages of the files under root, the most
+// common first.
+func surveyLanguages(root string) []language.ID {
+	counts := make(map[language.ID]int)
+	files := 0
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		// Match the ignore list against the path below root only, so a
+		// project that lives under a directory such as /tmp is surveyed.
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		if rel != "." && fileutil.SkipHidden(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		files++
+		if files > maxSurveyedFiles {
+			return filepath.SkipAll
+		}
+		switch id := language.Detect(path); id {
+		case language.Unknown, language.Markdown, language.JSON, language.YAML, language.TOML, language.XML:
+			// Data and docs say little about the project.
+		default:
+			counts[id]++
+		}
+		return nil
+	})
+
+	languages := make([]language.ID, 0, len(counts))
+	for id := range counts {
+		languages = append(languages, id)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		if counts[languages[i]] != counts[languages[j]] {
+			return counts[languages[i]] > counts[languages[j]]
+		}
+		return languages[i] < languages[j]
+	})
+	if len(languages) > maxSummaryLanguages {
+		languages = languages[:maxSummaryLanguages]
+	}
+	return languages
+}
+
+// startupSummary shows the model, branch, languages and tools the agent
+// works with, so the user can check the configuration took effect.
+func (m *messagesCmp) startupSummary() string {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+
+	row := func(name, value string) string {
+		key := baseStyle.Foreground(t.Text()).Render(fmt.Sprintf("• %s: ", name))
+		value = ansi.Truncate(value, max(m.width-lipgloss.Width(key), 0), "…")
+		return baseStyle.Width(m.width).Render(
+			lipgloss.JoinHorizontal(lipgloss.Left, key, baseStyle.Foreground(t.TextMuted()).Render(value)),
+		)
+	}
+
+	rows := []string{
+		baseStyle.Width(m.width).Foreground(t.Primary()).Bold(true).Render("Environment"),
+	}
+	if m.app != nil && m.app.CoderAgent != nil {
+		model := m.app.CoderAgent.Model()
+		rows = append(rows, row("Model", fmt.Sprintf("%s (%s)", model.Name, model.Provider)))
+	}
+	if m.environment != nil {
+		if m.environment.branch != "" {
+			rows = append(rows, row("Branch", m.environment.branch))
+		}
+		if len(m.environment.languages) > 0 {
+			names := make([]string, len(m.environment.languages))
+			for i, id := range m.environment.languages {
+				names[i] = string(id)
+			}
+			rows = append(rows, row("Languages", strings.Join(names, ", ")))
+		}
+	}
+	if m.app != nil && m.app.CoderAgent != nil {
+		rows = append(rows, row("Tools", enabledTools(m.app.CoderAgent.Tools(m.session.ID))))
+	}
+	rows = append(rows, baseStyle.Width(m.width).Foreground(t.TextMuted()).Render("esc to dismiss"))
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+func enabledTools(states []agent.ToolState) string {
+	var names []string
+	for _, state := range states {
+		if state.Enabled {
+			names = append(names, state.Name)
+		}
+	}
+	return fmt.Sprintf("%d enabled: %s", len(names), strings.Join(names, ", "))
+}
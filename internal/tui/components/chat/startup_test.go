@@ -0,0 +1,33 @@
+package chat
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/language"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSurveyLanguages(t *testing.T) {
+	// The root sits under an ignored directory name; only the paths below
+	// it are matched against the ignore list.
+	root := filepath.Join(t.TempDir(), "tmp", "project")
+	files := map[string]string{
+		"main.go":             "package main\n",
+		"util.go":             "package main\n",
+		"scripts/run.py":      "print()\n",
+		"README.md":           "# project\n",
+		"vendor/lib/lib.py":   "print()\n",
+		"node_modules/x/a.py": "print()\n",
+		".hidden/b.py":        "print()\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	assert.Equal(t, []language.ID{language.Go, language.Python}, surveyLanguages(root))
+}
@@ -8,6 +8,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/llm/agent"
 	"github.com/opencode-ai/opencode/internal/llm/models"
 	"github.com/opencode-ai/opencode/internal/lsp"
 	"github.com/opencode-ai/opencode/internal/lsp/protocol"
@@ -28,6 +29,7 @@ type statusCmp struct {
 	width      int
 	messageTTL time.Duration
 	lspClients map[string]*lsp.Client
+	coder      agent.Service
 	session    session.Session
 }
 
@@ -118,8 +120,7 @@ func formatTokensAndCost(tokens, contextWindow int64, cost float64) string {
 
 func (m statusCmp) View() string {
 	t := theme.CurrentTheme()
-	modelID := config.Get().Agents[config.AgentCoder].Model
-	model := models.SupportedModels[modelID]
+	model := m.sessionModel()
 
 	// Initialize the help widget
 	status := getHelpWidget()
@@ -143,7 +144,10 @@ func (m statusCmp) View() string {
 		Background(t.BackgroundDarker()).
 		Render(m.projectDiagnostics())
 
-	availableWidht := max(0, m.width-lipgloss.Width(helpWidget)-lipgloss.Width(m.model())-lipgloss.Width(diagnostics)-tokenInfoWidth)
+	mode := m.askMode()
+	status += mode
+
+	availableWidht := max(0, m.width-lipgloss.Width(helpWidget)-lipgloss.Width(m.model())-lipgloss.Width(diagnostics)-tokenInfoWidth-lipgloss.Width(mode))
 
 	if m.info.Msg != "" {
 		infoStyle := styles.Padded().
@@ -266,16 +270,26 @@ func (m statusCmp) availableFooterMsgWidth(diagnostics, tokenInfo string) int {
 	return max(0, m.width-lipgloss.Width(helpWidget)-lipgloss.Width(m.model())-lipgloss.Width(diagnostics)-tokensWidth)
 }
 
-func (m statusCmp) model() string {
+// askMode renders a badge when the current session is in ask mode.
+func (m statusCmp) askMode() string {
+	if m.coder == nil || m.session.ID == "" || !m.coder.AskMode(m.session.ID) {
+		return ""
+	}
 	t := theme.CurrentTheme()
+	return styles.Padded().
+		Background(t.Info()).
+		Foreground(t.Background()).
+		Bold(true).
+		Render("ASK")
+}
 
-	cfg := config.Get()
+func (m statusCmp) model() string {
+	t := theme.CurrentTheme()
 
-	coder, ok := cfg.Agents[config.AgentCoder]
-	if !ok {
+	if _, ok := config.Get().Agents[config.AgentCoder]; !ok {
 		return "Unknown"
 	}
-	model := models.SupportedModels[coder.Model]
+	model := m.sessionModel()
 
 	return styles.Padded().
 		Background(t.Secondary()).
@@ -283,11 +297,21 @@ func (m statusCmp) model() string {
 		Render(model.Name)
 }
 
-func NewStatusCmp(lspClients map[string]*lsp.Client) StatusCmp {
+// sessionModel returns the model of the current session, or the configured
+// model when the session has none.
+func (m statusCmp) sessionModel() models.Model {
+	if model, ok := models.SupportedModels[m.session.Model]; ok {
+		return model
+	}
+	return models.SupportedModels[config.Get().Agents[config.AgentCoder].Model]
+}
+
+func NewStatusCmp(lspClients map[string]*lsp.Client, coder agent.Service) StatusCmp {
 	helpWidget = getHelpWidget()
 
 	return &statusCmp{
 		messageTTL: 10 * time.Second,
 		lspClients: lspClients,
+		coder:      coder,
 	}
 }
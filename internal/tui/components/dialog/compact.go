@@ -0,0 +1,186 @@
+package dialog
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/opencode-ai/opencode/internal/llm/agent"
+	"github.com/opencode-ai/opencode/internal/tui/layout"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/theme"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+// CompactApprovedMsg is sent when the user approves a summary preview
+type CompactApprovedMsg struct {
+	Preview agent.SummaryPreview
+}
+
+// CloseCompactDialogMsg is sent when the compact dialog is closed without
+// applying the summary
+type CloseCompactDialogMsg struct{}
+
+// CompactDialog shows what a manual compaction would summarize and lets the
+// user approve the generated summary before it is applied.
+type CompactDialog interface {
+	tea.Model
+	layout.Bindings
+	SetStats(messages int, tokens int64)
+	SetPreview(preview agent.SummaryPreview)
+}
+
+type compactDialogCmp struct {
+	messages int
+	tokens   int64
+	preview  *agent.SummaryPreview
+	viewport viewport.Model
+	width    int
+	height   int
+}
+
+type compactKeyMap struct {
+	Approve key.Binding
+	Discard key.Binding
+	Up      key.Binding
+	Down    key.Binding
+}
+
+var compactKeys = compactKeyMap{
+	Approve: key.NewBinding(
+		key.WithKeys("enter", "y"),
+		key.WithHelp("enter/y", "apply summary"),
+	),
+	Discard: key.NewBinding(
+		key.WithKeys("esc", "n"),
+		key.WithHelp("esc/n", "discard"),
+	),
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "scroll up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "scroll down"),
+	),
+}
+
+func (c *compactDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (c *compactDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, compactKeys.Approve):
+			if c.preview != nil {
+				return c, util.CmdHandler(CompactApprovedMsg{Preview: *c.preview})
+			}
+			return c, nil
+		case key.Matches(msg, compactKeys.Discard):
+			return c, util.CmdHandler(CloseCompactDialogMsg{})
+		default:
+			var cmd tea.Cmd
+			c.viewport, cmd = c.viewport.Update(msg)
+			return c, cmd
+		}
+	case tea.WindowSizeMsg:
+		c.width = msg.Width
+		c.height = msg.Height
+		c.renderSummary()
+	}
+	return c, nil
+}
+
+func (c *compactDialogCmp) dialogWidth() int {
+	return max(40, min(100, c.width*3/4))
+}
+
+func (c *compactDialogCmp) renderSummary() {
+	if c.preview == nil {
+		return
+	}
+	t := theme.CurrentTheme()
+	width := c.dialogWidth()
+	c.viewport.Width = width
+	c.viewport.Height = max(5, c.height/2)
+
+	rendered, err := styles.GetMarkdownRenderer(width - 2).Render(c.preview.Summary)
+	if err != nil {
+		rendered = c.preview.Summary
+	}
+	rendered = styles.ForceReplaceBackgroundWithLipgloss(rendered, t.Background())
+	c.viewport.SetContent(styles.BaseStyle().Width(width).Render(rendered))
+	c.viewport.GotoTop()
+}
+
+func (c *compactDialogCmp) View() string {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+	width := c.dialogWidth()
+
+	title := baseStyle.
+		Foreground(t.Primary()).
+		Bold(true).
+		Width(width).
+		Render("Compact Session")
+
+	stats := baseStyle.
+		Width(width).
+		Render(fmt.Sprintf("%d messages (~%d tokens in context) will be summarized. The full history stays in the session; only future requests start from the summary.", c.messages, c.tokens))
+
+	var body, help string
+	if c.preview == nil {
+		body = baseStyle.Foreground(t.TextMuted()).Width(width).Render("Generating summary...")
+		help = "esc cancel"
+	} else {
+		body = baseStyle.Render(c.viewport.View())
+		help = "enter/y apply • esc/n discard • ↑/↓ scroll"
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		baseStyle.Width(width).Render(""),
+		stats,
+		baseStyle.Width(width).Render(""),
+		body,
+		baseStyle.Width(width).Render(""),
+		baseStyle.Foreground(t.TextMuted()).Width(width).Render(help),
+	)
+
+	return baseStyle.Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderBackground(t.Background()).
+		BorderForeground(t.TextMuted()).
+		Width(lipgloss.Width(content) + 4).
+		Render(content)
+}
+
+// SetStats resets the dialog for a new compaction of the given size.
+func (c *compactDialogCmp) SetStats(messages int, tokens int64) {
+	c.messages = messages
+	c.tokens = tokens
+	c.preview = nil
+}
+
+// SetPreview shows the generated summary for approval.
+func (c *compactDialogCmp) SetPreview(preview agent.SummaryPreview) {
+	c.preview = &preview
+	c.messages = preview.Messages
+	c.renderSummary()
+}
+
+func (c *compactDialogCmp) BindingKeys() []key.Binding {
+	return layout.KeyMapToSlice(compactKeys)
+}
+
+// NewCompactDialogCmp creates a new compact dialog
+func NewCompactDialogCmp() CompactDialog {
+	return &compactDialogCmp{
+		viewport: viewport.New(0, 0),
+	}
+}
@@ -33,6 +33,8 @@ type CloseModelDialogMsg struct{}
 type ModelDialog interface {
 	tea.Model
 	layout.Bindings
+	// SetCurrentModel sets the model selected when the dialog opens.
+	SetCurrentModel(modelID models.ModelID)
 }
 
 type modelDialogCmp struct {
@@ -46,6 +48,9 @@ type modelDialogCmp struct {
 	scrollOffset    int
 	hScrollOffset   int
 	hScrollPossible bool
+
+	// current is the model in use, the configured one when empty.
+	current models.ModelID
 }
 
 type modelKeyMap struct {
@@ -268,9 +273,22 @@ func (m *modelDialogCmp) BindingKeys() []key.Binding {
 	return layout.KeyMapToSlice(modelKeys)
 }
 
+func (m *modelDialogCmp) SetCurrentModel(modelID models.ModelID) {
+	m.current = modelID
+	m.setupModels()
+}
+
+// currentModel returns the model in use.
+func (m *modelDialogCmp) currentModel() models.Model {
+	if model, ok := models.SupportedModels[m.current]; ok {
+		return model
+	}
+	return GetSelectedModel(config.Get())
+}
+
 func (m *modelDialogCmp) setupModels() {
 	cfg := config.Get()
-	modelInfo := GetSelectedModel(cfg)
+	modelInfo := m.currentModel()
 	m.availableProviders = getEnabledProviders(cfg)
 	m.hScrollPossible = len(m.availableProviders) > 1
 
@@ -323,9 +341,7 @@ func findProviderIndex(providers []models.ModelProvider, provider models.ModelPr
 }
 
 func (m *modelDialogCmp) setupModelsForProvider(provider models.ModelProvider) {
-	cfg := config.Get()
-	agentCfg := cfg.Agents[config.AgentCoder]
-	selectedModelId := agentCfg.Model
+	selectedModelId := m.currentModel().ID
 
 	m.provider = provider
 	m.models = getModelsForProvider(provider)
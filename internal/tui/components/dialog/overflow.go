@@ -0,0 +1,192 @@
+package dialog
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/opencode-ai/opencode/internal/llm/agent"
+	"github.com/opencode-ai/opencode/internal/tui/layout"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/theme"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+// OverflowAction is a way to get a prompt that does not fit in the context
+// window sent.
+type OverflowAction int
+
+const (
+	// OverflowCompact summarizes the session; the prompt is put back in the
+	// editor to be sent again afterwards.
+	OverflowCompact OverflowAction = iota
+	// OverflowDropOldest deletes the oldest turns until the prompt fits and
+	// sends it.
+	OverflowDropOldest
+	// OverflowNewSession sends the prompt in a new session.
+	OverflowNewSession
+)
+
+// ShowContextOverflowMsg is sent when a prompt was not sent because it would
+// exceed the context window of the model
+type ShowContextOverflowMsg struct {
+	Overflow *agent.ContextOverflowError
+}
+
+// ContextOverflowActionMsg is sent with the action the user picked
+type ContextOverflowActionMsg struct {
+	Action   OverflowAction
+	Overflow *agent.ContextOverflowError
+}
+
+// CloseContextOverflowDialogMsg is sent when the dialog is closed without
+// picking an action
+type CloseContextOverflowDialogMsg struct {
+	Overflow *agent.ContextOverflowError
+}
+
+// ContextOverflowDialog explains that a prompt does not fit in the context
+// window and offers ways to make room for it.
+type ContextOverflowDialog interface {
+	tea.Model
+	layout.Bindings
+	SetOverflow(overflow *agent.ContextOverflowError)
+	Overflow() *agent.ContextOverflowError
+}
+
+type contextOverflowDialogCmp struct {
+	overflow *agent.ContextOverflowError
+	selected int
+}
+
+type overflowOption struct {
+	action      OverflowAction
+	title       string
+	description string
+}
+
+var overflowOptions = []overflowOption{
+	{OverflowCompact, "Compact session", "Summarize the conversation, then send the prompt again"},
+	{OverflowDropOldest, "Drop oldest messages", "Delete the oldest turns until the prompt fits and send it"},
+	{OverflowNewSession, "Start new session", "Send the prompt in a new session"},
+}
+
+type overflowKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Select key.Binding
+	Cancel key.Binding
+}
+
+var overflowKeys = overflowKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "previous option"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "next option"),
+	),
+	Select: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "select"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "cancel"),
+	),
+}
+
+func (o *contextOverflowDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (o *contextOverflowDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, overflowKeys.Up):
+			o.selected = (o.selected - 1 + len(overflowOptions)) % len(overflowOptions)
+		case key.Matches(msg, overflowKeys.Down):
+			o.selected = (o.selected + 1) % len(overflowOptions)
+		case key.Matches(msg, overflowKeys.Select):
+			return o, util.CmdHandler(ContextOverflowActionMsg{
+				Action:   overflowOptions[o.selected].action,
+				Overflow: o.overflow,
+			})
+		case key.Matches(msg, overflowKeys.Cancel):
+			return o, util.CmdHandler(CloseContextOverflowDialogMsg{Overflow: o.overflow})
+		}
+	}
+	return o, nil
+}
+
+func (o *contextOverflowDialogCmp) View() string {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+	width := 60
+
+	title := baseStyle.
+		Foreground(t.Error()).
+		Bold(true).
+		Width(width).
+		Render("Context Window Exceeded")
+
+	explanation := ""
+	if o.overflow != nil {
+		explanation = fmt.Sprintf("The prompt was not sent: with the conversation it needs about %d tokens, but the model's context window is %d tokens.", o.overflow.Estimated, o.overflow.Limit)
+	}
+
+	lines := []string{
+		title,
+		baseStyle.Width(width).Render(""),
+		baseStyle.Width(width).Render(explanation),
+		baseStyle.Width(width).Render(""),
+	}
+	for i, option := range overflowOptions {
+		itemStyle := baseStyle.Width(width)
+		if i == o.selected {
+			itemStyle = itemStyle.
+				Background(t.Primary()).
+				Foreground(t.Background()).
+				Bold(true)
+		}
+		lines = append(lines,
+			itemStyle.Render(option.title),
+			baseStyle.Foreground(t.TextMuted()).Width(width).Render("  "+option.description),
+		)
+	}
+	lines = append(lines,
+		baseStyle.Width(width).Render(""),
+		baseStyle.Foreground(t.TextMuted()).Width(width).Render("↑/↓ choose • enter select • esc cancel"),
+	)
+
+	content := lipgloss.JoinVertical(lipgloss.Left, lines...)
+
+	return baseStyle.Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderBackground(t.Background()).
+		BorderForeground(t.TextMuted()).
+		Width(lipgloss.Width(content) + 4).
+		Render(content)
+}
+
+// SetOverflow resets the dialog for the given overflow.
+func (o *contextOverflowDialogCmp) SetOverflow(overflow *agent.ContextOverflowError) {
+	o.overflow = overflow
+	o.selected = 0
+}
+
+// Overflow returns the overflow the dialog is showing.
+func (o *contextOverflowDialogCmp) Overflow() *agent.ContextOverflowError {
+	return o.overflow
+}
+
+func (o *contextOverflowDialogCmp) BindingKeys() []key.Binding {
+	return layout.KeyMapToSlice(overflowKeys)
+}
+
+// NewContextOverflowDialogCmp creates a new context overflow dialog
+func NewContextOverflowDialogCmp() ContextOverflowDialog {
+	return &contextOverflowDialogCmp{}
+}
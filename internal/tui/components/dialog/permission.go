@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -30,6 +31,8 @@ const (
 type PermissionResponseMsg struct {
 	Permission permission.PermissionRequest
 	Action     PermissionAction
+	// Reason is the explanation given when denying, passed on to the model.
+	Reason string
 }
 
 // PermissionDialogCmp interface for permission dialog component
@@ -40,13 +43,30 @@ type PermissionDialogCmp interface {
 }
 
 type permissionsMapping struct {
-	Left         key.Binding
-	Right        key.Binding
-	EnterSpace   key.Binding
-	Allow        key.Binding
-	AllowSession key.Binding
-	Deny         key.Binding
-	Tab          key.Binding
+	Left           key.Binding
+	Right          key.Binding
+	EnterSpace     key.Binding
+	Allow          key.Binding
+	AllowSession   key.Binding
+	Deny           key.Binding
+	DenyWithReason key.Binding
+	Tab            key.Binding
+}
+
+type reasonKeyMap struct {
+	Submit key.Binding
+	Cancel key.Binding
+}
+
+var reasonKeys = reasonKeyMap{
+	Submit: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "deny with reason"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "back to options"),
+	),
 }
 
 var permissionsKeys = permissionsMapping{
@@ -63,16 +83,20 @@ var permissionsKeys = permissionsMapping{
 		key.WithHelp("enter/space", "confirm"),
 	),
 	Allow: key.NewBinding(
-		key.WithKeys("a"),
-		key.WithHelp("a", "allow"),
+		key.WithKeys("y"),
+		key.WithHelp("y", "allow"),
 	),
 	AllowSession: key.NewBinding(
-		key.WithKeys("s"),
-		key.WithHelp("s", "allow for session"),
+		key.WithKeys("a", "s"),
+		key.WithHelp("a", "allow for session"),
 	),
 	Deny: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "deny"),
+	),
+	DenyWithReason: key.NewBinding(
 		key.WithKeys("d"),
-		key.WithHelp("d", "deny"),
+		key.WithHelp("d", "deny with reason"),
 	),
 	Tab: key.NewBinding(
 		key.WithKeys("tab"),
@@ -89,6 +113,10 @@ type permissionDialogCmp struct {
 	contentViewPort viewport.Model
 	selectedOption  int // 0: Allow, 1: Allow for session, 2: Deny
 
+	// enteringReason is set while the user types the reason for a denial.
+	enteringReason bool
+	reasonInput    textinput.Model
+
 	diffCache     map[string]string
 	markdownCache map[string]string
 }
@@ -108,6 +136,9 @@ func (p *permissionDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		p.markdownCache = make(map[string]string)
 		p.diffCache = make(map[string]string)
 	case tea.KeyMsg:
+		if p.enteringReason {
+			return p, p.updateReason(msg)
+		}
 		switch {
 		case key.Matches(msg, permissionsKeys.Right) || key.Matches(msg, permissionsKeys.Tab):
 			p.selectedOption = (p.selectedOption + 1) % 3
@@ -122,6 +153,10 @@ func (p *permissionDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return p, util.CmdHandler(PermissionResponseMsg{Action: PermissionAllowForSession, Permission: p.permission})
 		case key.Matches(msg, permissionsKeys.Deny):
 			return p, util.CmdHandler(PermissionResponseMsg{Action: PermissionDeny, Permission: p.permission})
+		case key.Matches(msg, permissionsKeys.DenyWithReason):
+			p.enteringReason = true
+			p.reasonInput.SetValue("")
+			return p, p.reasonInput.Focus()
 		default:
 			// Pass other keys to viewport
 			viewPort, cmd := p.contentViewPort.Update(msg)
@@ -133,6 +168,23 @@ func (p *permissionDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return p, tea.Batch(cmds...)
 }
 
+func (p *permissionDialogCmp) updateReason(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, reasonKeys.Submit):
+		reason := strings.TrimSpace(p.reasonInput.Value())
+		p.enteringReason = false
+		p.reasonInput.Blur()
+		return util.CmdHandler(PermissionResponseMsg{Action: PermissionDeny, Permission: p.permission, Reason: reason})
+	case key.Matches(msg, reasonKeys.Cancel):
+		p.enteringReason = false
+		p.reasonInput.Blur()
+		return nil
+	}
+	var cmd tea.Cmd
+	p.reasonInput, cmd = p.reasonInput.Update(msg)
+	return cmd
+}
+
 func (p *permissionDialogCmp) selectCurrentOption() tea.Cmd {
 	var action PermissionAction
 
@@ -173,9 +225,14 @@ func (p *permissionDialogCmp) renderButtons() string {
 		denyStyle = denyStyle.Background(t.Primary()).Foreground(t.Background())
 	}
 
-	allowButton := allowStyle.Padding(0, 1).Render("Allow (a)")
-	allowSessionButton := allowSessionStyle.Padding(0, 1).Render("Allow for session (s)")
-	denyButton := denyStyle.Padding(0, 1).Render("Deny (d)")
+	if p.enteringReason {
+		return p.renderReasonInput()
+	}
+
+	allowButton := allowStyle.Padding(0, 1).Render("Allow (y)")
+	allowSessionButton := allowSessionStyle.Padding(0, 1).Render("Allow for session (a)")
+	denyButton := denyStyle.Padding(0, 1).Render("Deny (n)")
+	denyReasonButton := baseStyle.Background(t.Background()).Foreground(t.Primary()).Padding(0, 1).Render("Deny with reason (d)")
 
 	content := lipgloss.JoinHorizontal(
 		lipgloss.Left,
@@ -186,6 +243,10 @@ func (p *permissionDialogCmp) renderButtons() string {
 		denyButton,
 		spacerStyle.Render("  "),
 	)
+	// The reason shortcut is listed in the help when the dialog is too narrow.
+	if withReason := content + denyReasonButton + spacerStyle.Render("  "); lipgloss.Width(withReason) <= p.width {
+		content = withReason
+	}
 
 	remainingWidth := p.width - lipgloss.Width(content)
 	if remainingWidth > 0 {
@@ -194,6 +255,22 @@ func (p *permissionDialogCmp) renderButtons() string {
 	return content
 }
 
+func (p *permissionDialogCmp) renderReasonInput() string {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+
+	label := baseStyle.Foreground(t.TextMuted()).Bold(true).Render("Reason: ")
+	hint := baseStyle.Foreground(t.TextMuted()).Render("  enter deny · esc back")
+	p.reasonInput.Width = max(10, p.width-lipgloss.Width(label)-lipgloss.Width(hint)-6)
+	content := lipgloss.JoinHorizontal(
+		lipgloss.Left,
+		label,
+		p.reasonInput.View(),
+		hint,
+	)
+	return baseStyle.Width(p.width - 4).Render(content)
+}
+
 func (p *permissionDialogCmp) renderHeader() string {
 	t := theme.CurrentTheme()
 	baseStyle := styles.BaseStyle()
@@ -225,11 +302,22 @@ func (p *permissionDialogCmp) renderHeader() string {
 		baseStyle.Render(strings.Repeat(" ", p.width)),
 	}
 
+	if p.permission.LargeEdit {
+		headerParts = append(headerParts,
+			baseStyle.
+				Foreground(t.Warning()).
+				Bold(true).
+				Width(p.width).
+				Render(fmt.Sprintf("Large edit: %d lines changed, always confirmed", p.permission.LinesChanged)),
+			baseStyle.Render(strings.Repeat(" ", p.width)),
+		)
+	}
+
 	// Add tool-specific header information
 	switch p.permission.ToolName {
 	case tools.BashToolName:
 		headerParts = append(headerParts, baseStyle.Foreground(t.TextMuted()).Width(p.width).Bold(true).Render("Command"))
-	case tools.EditToolName:
+	case tools.EditToolName, tools.MultiEditToolName, tools.OrganizeImportsToolName:
 		params := p.permission.Params.(tools.EditPermissionsParams)
 		fileKey := baseStyle.Foreground(t.TextMuted()).Bold(true).Render("File")
 		filePath := baseStyle.
@@ -262,6 +350,23 @@ func (p *permissionDialogCmp) renderHeader() string {
 		)
 	case tools.FetchToolName:
 		headerParts = append(headerParts, baseStyle.Foreground(t.TextMuted()).Width(p.width).Bold(true).Render("URL"))
+	case tools.RunFunctionToolName:
+		headerParts = append(headerParts, baseStyle.Foreground(t.TextMuted()).Width(p.width).Bold(true).Render("Test harness"))
+	case tools.RefactorToolName, tools.RenameTextToolName, tools.FixDiagnosticsToolName:
+		params := p.permission.Params.(tools.RefactorPermissionsParams)
+		filesKey := baseStyle.Foreground(t.TextMuted()).Bold(true).Render("Files")
+		filesValue := baseStyle.
+			Foreground(t.Text()).
+			Width(p.width - lipgloss.Width(filesKey)).
+			Render(fmt.Sprintf(": %d", len(params.Files)))
+		headerParts = append(headerParts,
+			lipgloss.JoinHorizontal(
+				lipgloss.Left,
+				filesKey,
+				filesValue,
+			),
+			baseStyle.Render(strings.Repeat(" ", p.width)),
+		)
 	}
 
 	return lipgloss.NewStyle().Background(t.Background()).Render(lipgloss.JoinVertical(lipgloss.Left, headerParts...))
@@ -290,6 +395,28 @@ func (p *permissionDialogCmp) renderBashContent() string {
 	return ""
 }
 
+func (p *permissionDialogCmp) renderRunFunctionContent() string {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+
+	if pr, ok := p.permission.Params.(tools.RunFunctionPermissionsParams); ok {
+		content := fmt.Sprintf("```go\n%s\n```", pr.Harness)
+
+		renderedContent := p.GetOrSetMarkdown(p.permission.ID, func() (string, error) {
+			r := styles.GetMarkdownRenderer(p.width - 10)
+			s, err := r.Render(content)
+			return styles.ForceReplaceBackgroundWithLipgloss(s, t.Background()), err
+		})
+
+		finalContent := baseStyle.
+			Width(p.contentViewPort.Width).
+			Render(renderedContent)
+		p.contentViewPort.SetContent(finalContent)
+		return p.styleViewport()
+	}
+	return ""
+}
+
 func (p *permissionDialogCmp) renderEditContent() string {
 	if pr, ok := p.permission.Params.(tools.EditPermissionsParams); ok {
 		diff := p.GetOrSetDiff(p.permission.ID, func() (string, error) {
@@ -327,6 +454,34 @@ func (p *permissionDialogCmp) renderWriteContent() string {
 	return ""
 }
 
+func (p *permissionDialogCmp) renderRefactorContent() string {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+
+	if pr, ok := p.permission.Params.(tools.RefactorPermissionsParams); ok {
+		content := p.GetOrSetDiff(p.permission.ID, func() (string, error) {
+			parts := make([]string, 0, len(pr.Files)*2)
+			for _, file := range pr.Files {
+				formatted, err := diff.FormatDiff(file.Diff, diff.WithTotalWidth(p.contentViewPort.Width))
+				if err != nil {
+					return "", err
+				}
+				header := baseStyle.
+					Foreground(t.Primary()).
+					Bold(true).
+					Width(p.contentViewPort.Width).
+					Render(file.FilePath)
+				parts = append(parts, header, formatted)
+			}
+			return lipgloss.JoinVertical(lipgloss.Left, parts...), nil
+		})
+
+		p.contentViewPort.SetContent(content)
+		return p.styleViewport()
+	}
+	return ""
+}
+
 func (p *permissionDialogCmp) renderFetchContent() string {
 	t := theme.CurrentTheme()
 	baseStyle := styles.BaseStyle()
@@ -406,7 +561,7 @@ func (p *permissionDialogCmp) render() string {
 	switch p.permission.ToolName {
 	case tools.BashToolName:
 		contentFinal = p.renderBashContent()
-	case tools.EditToolName:
+	case tools.EditToolName, tools.MultiEditToolName, tools.OrganizeImportsToolName:
 		contentFinal = p.renderEditContent()
 	case tools.PatchToolName:
 		contentFinal = p.renderPatchContent()
@@ -414,6 +569,10 @@ func (p *permissionDialogCmp) render() string {
 		contentFinal = p.renderWriteContent()
 	case tools.FetchToolName:
 		contentFinal = p.renderFetchContent()
+	case tools.RunFunctionToolName:
+		contentFinal = p.renderRunFunctionContent()
+	case tools.RefactorToolName, tools.RenameTextToolName, tools.FixDiagnosticsToolName:
+		contentFinal = p.renderRefactorContent()
 	default:
 		contentFinal = p.renderDefaultContent()
 	}
@@ -445,6 +604,9 @@ func (p *permissionDialogCmp) View() string {
 }
 
 func (p *permissionDialogCmp) BindingKeys() []key.Binding {
+	if p.enteringReason {
+		return layout.KeyMapToSlice(reasonKeys)
+	}
 	return layout.KeyMapToSlice(permissionsKeys)
 }
 
@@ -456,12 +618,15 @@ func (p *permissionDialogCmp) SetSize() tea.Cmd {
 	case tools.BashToolName:
 		p.width = int(float64(p.windowSize.Width) * 0.4)
 		p.height = int(float64(p.windowSize.Height) * 0.3)
-	case tools.EditToolName:
+	case tools.EditToolName, tools.MultiEditToolName, tools.OrganizeImportsToolName:
 		p.width = int(float64(p.windowSize.Width) * 0.8)
 		p.height = int(float64(p.windowSize.Height) * 0.8)
 	case tools.WriteToolName:
 		p.width = int(float64(p.windowSize.Width) * 0.8)
 		p.height = int(float64(p.windowSize.Height) * 0.8)
+	case tools.RefactorToolName, tools.RenameTextToolName, tools.FixDiagnosticsToolName:
+		p.width = int(float64(p.windowSize.Width) * 0.8)
+		p.height = int(float64(p.windowSize.Height) * 0.8)
 	case tools.FetchToolName:
 		p.width = int(float64(p.windowSize.Width) * 0.4)
 		p.height = int(float64(p.windowSize.Height) * 0.3)
@@ -474,6 +639,8 @@ func (p *permissionDialogCmp) SetSize() tea.Cmd {
 
 func (p *permissionDialogCmp) SetPermissions(permission permission.PermissionRequest) tea.Cmd {
 	p.permission = permission
+	p.enteringReason = false
+	p.reasonInput.Blur()
 	return p.SetSize()
 }
 
@@ -513,8 +680,17 @@ func NewPermissionDialogCmp() PermissionDialogCmp {
 	// Create viewport for content
 	contentViewport := viewport.New(0, 0)
 
+	t := theme.CurrentTheme()
+	reasonInput := textinput.New()
+	reasonInput.Placeholder = "Tell the assistant what to do instead..."
+	reasonInput.Prompt = ""
+	reasonInput.PlaceholderStyle = reasonInput.PlaceholderStyle.Background(t.Background())
+	reasonInput.PromptStyle = reasonInput.PromptStyle.Background(t.Background())
+	reasonInput.TextStyle = reasonInput.TextStyle.Background(t.Background())
+
 	return &permissionDialogCmp{
 		contentViewPort: contentViewport,
+		reasonInput:     reasonInput,
 		selectedOption:  0, // Default to "Allow"
 		diffCache:       make(map[string]string),
 		markdownCache:   make(map[string]string),
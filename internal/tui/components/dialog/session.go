@@ -1,9 +1,15 @@
 package dialog
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/opencode-ai/opencode/internal/format"
 	"github.com/opencode-ai/opencode/internal/session"
 	"github.com/opencode-ai/opencode/internal/tui/layout"
 	"github.com/opencode-ai/opencode/internal/tui/styles"
@@ -16,6 +22,17 @@ type SessionSelectedMsg struct {
 	Session session.Session
 }
 
+// DeleteSessionMsg is sent when a session is deleted from the dialog
+type DeleteSessionMsg struct {
+	Session session.Session
+}
+
+// RenameSessionMsg is sent when a session is renamed from the dialog
+type RenameSessionMsg struct {
+	Session session.Session
+	Title   string
+}
+
 // CloseSessionDialogMsg is sent when the session dialog is closed
 type CloseSessionDialogMsg struct{}
 
@@ -25,50 +42,67 @@ type SessionDialog interface {
 	layout.Bindings
 	SetSessions(sessions []session.Session)
 	SetSelectedSession(sessionID string)
+	ClearQuery()
 }
 
 type sessionDialogCmp struct {
 	sessions          []session.Session
+	filtered          []session.Session
+	query             string
 	selectedIdx       int
 	width             int
 	height            int
 	selectedSessionID string
+	// confirmDelete is the ID of the session waiting for the delete key to
+	// be pressed again.
+	confirmDelete string
+	// renaming is set while the title of the selected session is edited.
+	renaming    bool
+	renameInput textinput.Model
+	// filtering is set while typed keys go to the query instead of moving
+	// the selection.
+	filtering bool
 }
 
 type sessionKeyMap struct {
 	Up     key.Binding
 	Down   key.Binding
 	Enter  key.Binding
+	Delete key.Binding
+	Rename key.Binding
+	Filter key.Binding
 	Escape key.Binding
-	J      key.Binding
-	K      key.Binding
 }
 
 var sessionKeys = sessionKeyMap{
 	Up: key.NewBinding(
-		key.WithKeys("up"),
-		key.WithHelp("↑", "previous session"),
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "previous session"),
 	),
 	Down: key.NewBinding(
-		key.WithKeys("down"),
-		key.WithHelp("↓", "next session"),
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "next session"),
 	),
 	Enter: key.NewBinding(
 		key.WithKeys("enter"),
 		key.WithHelp("enter", "select session"),
 	),
+	Delete: key.NewBinding(
+		key.WithKeys("ctrl+d"),
+		key.WithHelp("ctrl+d", "delete session"),
+	),
+	Rename: key.NewBinding(
+		key.WithKeys("ctrl+r"),
+		key.WithHelp("ctrl+r", "rename session"),
+	),
+	Filter: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "filter sessions"),
+	),
 	Escape: key.NewBinding(
 		key.WithKeys("esc"),
 		key.WithHelp("esc", "close"),
 	),
-	J: key.NewBinding(
-		key.WithKeys("j"),
-		key.WithHelp("j", "next session"),
-	),
-	K: key.NewBinding(
-		key.WithKeys("k"),
-		key.WithHelp("k", "previous session"),
-	),
 }
 
 func (s *sessionDialogCmp) Init() tea.Cmd {
@@ -78,23 +112,88 @@ func (s *sessionDialogCmp) Init() tea.Cmd {
 func (s *sessionDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if s.renaming {
+			if key.Matches(msg, sessionKeys.Enter) {
+				s.renaming = false
+				s.renameInput.Blur()
+				title := strings.TrimSpace(s.renameInput.Value())
+				sess := s.filtered[s.selectedIdx]
+				if title == "" || title == sess.Title {
+					return s, nil
+				}
+				return s, util.CmdHandler(RenameSessionMsg{Session: sess, Title: title})
+			}
+			if key.Matches(msg, sessionKeys.Escape) {
+				// Cancel the rename and keep the dialog open.
+				s.renaming = false
+				s.renameInput.Blur()
+				return s, nil
+			}
+			var cmd tea.Cmd
+			s.renameInput, cmd = s.renameInput.Update(msg)
+			return s, cmd
+		}
+		if !key.Matches(msg, sessionKeys.Delete) {
+			s.confirmDelete = ""
+		}
+		if s.filtering {
+			switch {
+			case key.Matches(msg, sessionKeys.Escape):
+				// Drop the filter and keep the dialog open.
+				s.filtering = false
+				s.setQuery("")
+				return s, nil
+			case msg.Type == tea.KeyBackspace:
+				if s.query != "" {
+					runes := []rune(s.query)
+					s.setQuery(string(runes[:len(runes)-1]))
+				}
+				return s, nil
+			case msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace:
+				s.setQuery(s.query + string(msg.Runes))
+				return s, nil
+			}
+		}
 		switch {
-		case key.Matches(msg, sessionKeys.Up) || key.Matches(msg, sessionKeys.K):
+		case key.Matches(msg, sessionKeys.Up):
 			if s.selectedIdx > 0 {
 				s.selectedIdx--
 			}
 			return s, nil
-		case key.Matches(msg, sessionKeys.Down) || key.Matches(msg, sessionKeys.J):
-			if s.selectedIdx < len(s.sessions)-1 {
+		case key.Matches(msg, sessionKeys.Down):
+			if s.selectedIdx < len(s.filtered)-1 {
 				s.selectedIdx++
 			}
 			return s, nil
 		case key.Matches(msg, sessionKeys.Enter):
-			if len(s.sessions) > 0 {
+			if len(s.filtered) > 0 {
 				return s, util.CmdHandler(SessionSelectedMsg{
-					Session: s.sessions[s.selectedIdx],
+					Session: s.filtered[s.selectedIdx],
 				})
 			}
+		case key.Matches(msg, sessionKeys.Delete):
+			if len(s.filtered) == 0 {
+				return s, nil
+			}
+			// Deleting takes a second press, as it cannot be undone.
+			sess := s.filtered[s.selectedIdx]
+			if s.confirmDelete != sess.ID {
+				s.confirmDelete = sess.ID
+				return s, nil
+			}
+			s.confirmDelete = ""
+			return s, util.CmdHandler(DeleteSessionMsg{Session: sess})
+		case key.Matches(msg, sessionKeys.Rename):
+			if len(s.filtered) == 0 {
+				return s, nil
+			}
+			s.renaming = true
+			s.renameInput.SetValue(s.filtered[s.selectedIdx].Title)
+			s.renameInput.CursorEnd()
+			return s, s.renameInput.Focus()
+		case key.Matches(msg, sessionKeys.Filter):
+			s.filtering = true
+			return s, nil
 		case key.Matches(msg, sessionKeys.Escape):
 			return s, util.CmdHandler(CloseSessionDialogMsg{})
 		}
@@ -105,6 +204,58 @@ func (s *sessionDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return s, nil
 }
 
+// setQuery filters the sessions by query, keeping the selected session
+// selected when it still matches.
+func (s *sessionDialogCmp) setQuery(query string) {
+	var selectedID string
+	if s.selectedIdx < len(s.filtered) {
+		selectedID = s.filtered[s.selectedIdx].ID
+	}
+	s.query = query
+	s.filter()
+	s.selectedIdx = 0
+	for i, sess := range s.filtered {
+		if sess.ID == selectedID {
+			s.selectedIdx = i
+			break
+		}
+	}
+}
+
+// filter keeps the sessions whose title contains the query, ignoring case.
+func (s *sessionDialogCmp) filter() {
+	query := strings.ToLower(strings.TrimSpace(s.query))
+	s.filtered = s.filtered[:0]
+	for _, sess := range s.sessions {
+		if query == "" || strings.Contains(strings.ToLower(sess.Title), query) {
+			s.filtered = append(s.filtered, sess)
+		}
+	}
+}
+
+// details returns the tokens, cost and last update of sess.
+func (s *sessionDialogCmp) details(sess session.Session, now time.Time) string {
+	return fmt.Sprintf("%s tokens  $%.2f  %s",
+		format.Tokens(sess.PromptTokens+sess.CompletionTokens),
+		sess.Cost,
+		format.Age(time.Unix(sess.UpdatedAt, 0), now),
+	)
+}
+
+// label returns the title of sess, marking branches with the session they
+// were branched from.
+func (s *sessionDialogCmp) label(sess session.Session) string {
+	if sess.BranchedFrom == "" {
+		return sess.Title
+	}
+	for _, from := range s.sessions {
+		if from.ID == sess.BranchedFrom && from.Title != sess.Title {
+			return fmt.Sprintf("↳ %s (branch of %s)", sess.Title, from.Title)
+		}
+	}
+	return fmt.Sprintf("↳ %s (branch)", sess.Title)
+}
+
 func (s *sessionDialogCmp) View() string {
 	t := theme.CurrentTheme()
 	baseStyle := styles.BaseStyle()
@@ -118,48 +269,58 @@ func (s *sessionDialogCmp) View() string {
 			Render("No sessions available")
 	}
 
-	// Calculate max width needed for session titles
-	maxWidth := 40 // Minimum width
+	// Calculate max width needed for session titles and their details
+	now := time.Now()
+	maxLabel, maxDetails := 0, 0
 	for _, sess := range s.sessions {
-		if len(sess.Title) > maxWidth-4 { // Account for padding
-			maxWidth = len(sess.Title) + 4
-		}
+		maxLabel = max(maxLabel, lipgloss.Width(s.label(sess)))
+		maxDetails = max(maxDetails, lipgloss.Width(s.details(sess, now)))
 	}
+	maxWidth := maxLabel + maxDetails + 6 // Account for padding and the gap
 
-	maxWidth = max(30, min(maxWidth, s.width-15)) // Limit width to avoid overflow
+	maxWidth = max(50, min(maxWidth, s.width-15)) // Limit width to avoid overflow
 
 	// Limit height to avoid taking up too much screen space
-	maxVisibleSessions := min(10, len(s.sessions))
+	maxVisibleSessions := min(10, len(s.filtered))
 
 	// Build the session list
 	sessionItems := make([]string, 0, maxVisibleSessions)
 	startIdx := 0
 
 	// If we have more sessions than can be displayed, adjust the start index
-	if len(s.sessions) > maxVisibleSessions {
+	if len(s.filtered) > maxVisibleSessions {
 		// Center the selected item when possible
 		halfVisible := maxVisibleSessions / 2
-		if s.selectedIdx >= halfVisible && s.selectedIdx < len(s.sessions)-halfVisible {
+		if s.selectedIdx >= halfVisible && s.selectedIdx < len(s.filtered)-halfVisible {
 			startIdx = s.selectedIdx - halfVisible
-		} else if s.selectedIdx >= len(s.sessions)-halfVisible {
-			startIdx = len(s.sessions) - maxVisibleSessions
+		} else if s.selectedIdx >= len(s.filtered)-halfVisible {
+			startIdx = len(s.filtered) - maxVisibleSessions
 		}
 	}
 
-	endIdx := min(startIdx+maxVisibleSessions, len(s.sessions))
+	endIdx := min(startIdx+maxVisibleSessions, len(s.filtered))
 
 	for i := startIdx; i < endIdx; i++ {
-		sess := s.sessions[i]
-		itemStyle := baseStyle.Width(maxWidth)
+		sess := s.filtered[i]
+		itemStyle := baseStyle
+		detailsStyle := baseStyle.Foreground(t.TextMuted())
 
 		if i == s.selectedIdx {
 			itemStyle = itemStyle.
 				Background(t.Primary()).
 				Foreground(t.Background()).
 				Bold(true)
+			detailsStyle = itemStyle.Bold(false)
 		}
 
-		sessionItems = append(sessionItems, itemStyle.Padding(0, 1).Render(sess.Title))
+		details := s.details(sess, now)
+		labelWidth := max(10, maxWidth-2-lipgloss.Width(details)-2)
+		label := truncateLabel(s.label(sess), labelWidth)
+		row := itemStyle.Width(labelWidth+2).Render(label) + detailsStyle.Render(details)
+		sessionItems = append(sessionItems, itemStyle.Width(maxWidth).Padding(0, 1).Render(row))
+	}
+	if len(s.filtered) == 0 {
+		sessionItems = append(sessionItems, baseStyle.Width(maxWidth).Padding(0, 1).Foreground(t.TextMuted()).Render("No matching sessions"))
 	}
 
 	title := baseStyle.
@@ -169,12 +330,39 @@ func (s *sessionDialogCmp) View() string {
 		Padding(0, 1).
 		Render("Switch Session")
 
+	query := baseStyle.Width(maxWidth).Padding(0, 1)
+	if s.query == "" {
+		query = query.Foreground(t.TextMuted())
+	}
+	queryLine := query.Render("> " + s.query)
+	if s.query == "" && s.filtering {
+		queryLine = query.Render("> Type to filter")
+	} else if s.query == "" {
+		queryLine = query.Render("> Press / to filter")
+	}
+	if s.renaming {
+		s.renameInput.Width = maxWidth - 12
+		queryLine = baseStyle.Width(maxWidth).Padding(0, 1).Render(
+			baseStyle.Foreground(t.Primary()).Render("Rename: ") + s.renameInput.View(),
+		)
+	}
+
+	footer := baseStyle.Width(maxWidth).Render("")
+	if s.confirmDelete != "" {
+		footer = baseStyle.
+			Foreground(t.Error()).
+			Width(maxWidth).
+			Padding(0, 1).
+			Render(fmt.Sprintf("Press %s again to delete this session", sessionKeys.Delete.Help().Key))
+	}
+
 	content := lipgloss.JoinVertical(
 		lipgloss.Left,
 		title,
+		queryLine,
 		baseStyle.Width(maxWidth).Render(""),
 		baseStyle.Width(maxWidth).Render(lipgloss.JoinVertical(lipgloss.Left, sessionItems...)),
-		baseStyle.Width(maxWidth).Render(""),
+		footer,
 	)
 
 	return baseStyle.Padding(1, 2).
@@ -185,16 +373,31 @@ func (s *sessionDialogCmp) View() string {
 		Render(content)
 }
 
+// truncateLabel shortens label to width cells, ending it with an ellipsis.
+func truncateLabel(label string, width int) string {
+	if lipgloss.Width(label) <= width {
+		return label
+	}
+	runes := []rune(label)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
 func (s *sessionDialogCmp) BindingKeys() []key.Binding {
 	return layout.KeyMapToSlice(sessionKeys)
 }
 
 func (s *sessionDialogCmp) SetSessions(sessions []session.Session) {
 	s.sessions = sessions
+	s.confirmDelete = ""
+	s.renaming = false
+	s.filter()
 
 	// If we have a selected session ID, find its index
 	if s.selectedSessionID != "" {
-		for i, sess := range sessions {
+		for i, sess := range s.filtered {
 			if sess.ID == s.selectedSessionID {
 				s.selectedIdx = i
 				return
@@ -210,8 +413,8 @@ func (s *sessionDialogCmp) SetSelectedSession(sessionID string) {
 	s.selectedSessionID = sessionID
 
 	// Update the selected index if sessions are already loaded
-	if len(s.sessions) > 0 {
-		for i, sess := range s.sessions {
+	if len(s.filtered) > 0 {
+		for i, sess := range s.filtered {
 			if sess.ID == sessionID {
 				s.selectedIdx = i
 				return
@@ -220,9 +423,22 @@ func (s *sessionDialogCmp) SetSelectedSession(sessionID string) {
 	}
 }
 
+// ClearQuery shows all sessions again.
+func (s *sessionDialogCmp) ClearQuery() {
+	s.filtering = false
+	s.setQuery("")
+}
+
 // NewSessionDialogCmp creates a new session switching dialog
 func NewSessionDialogCmp() SessionDialog {
+	t := theme.CurrentTheme()
+	renameInput := textinput.New()
+	renameInput.Prompt = ""
+	renameInput.CharLimit = 100
+	renameInput.PlaceholderStyle = renameInput.PlaceholderStyle.Background(t.Background())
+	renameInput.TextStyle = renameInput.TextStyle.Background(t.Background())
 	return &sessionDialogCmp{
+		renameInput:       renameInput,
 		sessions:          []session.Session{},
 		selectedIdx:       0,
 		selectedSessionID: "",
@@ -0,0 +1,161 @@
+package dialog
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/opencode-ai/opencode/internal/llm/agent"
+	"github.com/opencode-ai/opencode/internal/tui/layout"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/theme"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+// ShowStepLimitMsg is sent when a turn paused because it used up its step
+// budget
+type ShowStepLimitMsg struct {
+	Pause *agent.StepLimitPause
+}
+
+// StepLimitActionMsg is sent with the decision of the user; Continue lets the
+// agent go on with the task, otherwise the turn stays stopped
+type StepLimitActionMsg struct {
+	Continue bool
+	Pause    *agent.StepLimitPause
+}
+
+// StepLimitDialog shows the progress of a paused turn and asks whether the
+// agent should continue.
+type StepLimitDialog interface {
+	tea.Model
+	layout.Bindings
+	SetPause(pause *agent.StepLimitPause)
+}
+
+type stepLimitDialogCmp struct {
+	pause    *agent.StepLimitPause
+	selected int
+}
+
+var stepLimitOptions = []overflowOption{
+	{title: "Continue", description: "Let the agent go on with another step budget"},
+	{title: "Stop", description: "End the turn here; you can reply to steer the agent"},
+}
+
+type stepLimitKeyMap struct {
+	Up       key.Binding
+	Down     key.Binding
+	Select   key.Binding
+	Continue key.Binding
+	Stop     key.Binding
+}
+
+var stepLimitKeys = stepLimitKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "previous option"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "next option"),
+	),
+	Select: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "select"),
+	),
+	Continue: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "continue"),
+	),
+	Stop: key.NewBinding(
+		key.WithKeys("s", "esc"),
+		key.WithHelp("s/esc", "stop"),
+	),
+}
+
+func (s *stepLimitDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (s *stepLimitDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, stepLimitKeys.Up):
+			s.selected = (s.selected - 1 + len(stepLimitOptions)) % len(stepLimitOptions)
+		case key.Matches(msg, stepLimitKeys.Down):
+			s.selected = (s.selected + 1) % len(stepLimitOptions)
+		case key.Matches(msg, stepLimitKeys.Select):
+			return s, util.CmdHandler(StepLimitActionMsg{Continue: s.selected == 0, Pause: s.pause})
+		case key.Matches(msg, stepLimitKeys.Continue):
+			return s, util.CmdHandler(StepLimitActionMsg{Continue: true, Pause: s.pause})
+		case key.Matches(msg, stepLimitKeys.Stop):
+			return s, util.CmdHandler(StepLimitActionMsg{Continue: false, Pause: s.pause})
+		}
+	}
+	return s, nil
+}
+
+func (s *stepLimitDialogCmp) View() string {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+	width := 60
+
+	title := baseStyle.
+		Foreground(t.Warning()).
+		Bold(true).
+		Width(width).
+		Render("Step Limit Reached")
+
+	summary := ""
+	if s.pause != nil {
+		summary = s.pause.Summary()
+	}
+
+	lines := []string{
+		title,
+		baseStyle.Width(width).Render(""),
+		baseStyle.Width(width).Render(summary),
+		baseStyle.Width(width).Render(""),
+	}
+	for i, option := range stepLimitOptions {
+		itemStyle := baseStyle.Width(width)
+		if i == s.selected {
+			itemStyle = itemStyle.
+				Background(t.Primary()).
+				Foreground(t.Background()).
+				Bold(true)
+		}
+		lines = append(lines,
+			itemStyle.Render(option.title),
+			baseStyle.Foreground(t.TextMuted()).Width(width).Render("  "+option.description),
+		)
+	}
+	lines = append(lines,
+		baseStyle.Width(width).Render(""),
+		baseStyle.Foreground(t.TextMuted()).Width(width).Render("↑/↓ choose • enter select • c continue • s stop"),
+	)
+
+	content := lipgloss.JoinVertical(lipgloss.Left, lines...)
+
+	return baseStyle.Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderBackground(t.Background()).
+		BorderForeground(t.TextMuted()).
+		Width(lipgloss.Width(content) + 4).
+		Render(content)
+}
+
+// SetPause resets the dialog for the given pause.
+func (s *stepLimitDialogCmp) SetPause(pause *agent.StepLimitPause) {
+	s.pause = pause
+	s.selected = 0
+}
+
+func (s *stepLimitDialogCmp) BindingKeys() []key.Binding {
+	return layout.KeyMapToSlice(stepLimitKeys)
+}
+
+// NewStepLimitDialogCmp creates a new step limit dialog
+func NewStepLimitDialogCmp() StepLimitDialog {
+	return &stepLimitDialogCmp{}
+}
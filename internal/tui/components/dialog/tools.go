@@ -0,0 +1,193 @@
+package dialog
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/opencode-ai/opencode/internal/llm/agent"
+	"github.com/opencode-ai/opencode/internal/tui/layout"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/theme"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+// ToolToggledMsg is sent when a tool is enabled or disabled for the session
+type ToolToggledMsg struct {
+	Name    string
+	Enabled bool
+}
+
+// CloseToolsDialogMsg is sent when the tools dialog is closed
+type CloseToolsDialogMsg struct{}
+
+// ToolsDialog interface for the session tools dialog
+type ToolsDialog interface {
+	tea.Model
+	layout.Bindings
+	SetTools(tools []agent.ToolState)
+}
+
+type toolsDialogCmp struct {
+	tools       []agent.ToolState
+	selectedIdx int
+	width       int
+	height      int
+}
+
+type toolsKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Toggle key.Binding
+	Escape key.Binding
+	J      key.Binding
+	K      key.Binding
+}
+
+var toolsKeys = toolsKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up"),
+		key.WithHelp("↑", "previous tool"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down"),
+		key.WithHelp("↓", "next tool"),
+	),
+	Toggle: key.NewBinding(
+		key.WithKeys(" ", "enter"),
+		key.WithHelp("space/enter", "toggle tool"),
+	),
+	Escape: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "close"),
+	),
+	J: key.NewBinding(
+		key.WithKeys("j"),
+		key.WithHelp("j", "next tool"),
+	),
+	K: key.NewBinding(
+		key.WithKeys("k"),
+		key.WithHelp("k", "previous tool"),
+	),
+}
+
+func (t *toolsDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (t *toolsDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, toolsKeys.Up) || key.Matches(msg, toolsKeys.K):
+			if t.selectedIdx > 0 {
+				t.selectedIdx--
+			}
+			return t, nil
+		case key.Matches(msg, toolsKeys.Down) || key.Matches(msg, toolsKeys.J):
+			if t.selectedIdx < len(t.tools)-1 {
+				t.selectedIdx++
+			}
+			return t, nil
+		case key.Matches(msg, toolsKeys.Toggle):
+			if len(t.tools) > 0 {
+				tool := t.tools[t.selectedIdx]
+				return t, util.CmdHandler(ToolToggledMsg{
+					Name:    tool.Name,
+					Enabled: !tool.Enabled,
+				})
+			}
+		case key.Matches(msg, toolsKeys.Escape):
+			return t, util.CmdHandler(CloseToolsDialogMsg{})
+		}
+	case tea.WindowSizeMsg:
+		t.width = msg.Width
+		t.height = msg.Height
+	}
+	return t, nil
+}
+
+func (t *toolsDialogCmp) View() string {
+	currentTheme := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+
+	if len(t.tools) == 0 {
+		return baseStyle.Padding(1, 2).
+			Border(lipgloss.RoundedBorder()).
+			BorderBackground(currentTheme.Background()).
+			BorderForeground(currentTheme.TextMuted()).
+			Width(40).
+			Render("No tools available")
+	}
+
+	maxWidth := 40
+	for _, tool := range t.tools {
+		if len(tool.Name)+8 > maxWidth {
+			maxWidth = len(tool.Name) + 8
+		}
+	}
+	maxWidth = max(30, min(maxWidth, t.width-15))
+
+	enabledCount := 0
+	toolItems := make([]string, 0, len(t.tools))
+	for i, tool := range t.tools {
+		checkbox := "[ ]"
+		if tool.Enabled {
+			checkbox = "[x]"
+			enabledCount++
+		}
+
+		itemStyle := baseStyle.Width(maxWidth)
+		if i == t.selectedIdx {
+			itemStyle = itemStyle.
+				Background(currentTheme.Primary()).
+				Foreground(currentTheme.Background()).
+				Bold(true)
+		} else if !tool.Enabled {
+			itemStyle = itemStyle.Foreground(currentTheme.TextMuted())
+		}
+
+		toolItems = append(toolItems, itemStyle.Padding(0, 1).Render(fmt.Sprintf("%s %s", checkbox, tool.Name)))
+	}
+
+	title := baseStyle.
+		Foreground(currentTheme.Primary()).
+		Bold(true).
+		Width(maxWidth).
+		Padding(0, 1).
+		Render(fmt.Sprintf("Session Tools (%d/%d enabled)", enabledCount, len(t.tools)))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		baseStyle.Width(maxWidth).Render(""),
+		baseStyle.Width(maxWidth).Render(lipgloss.JoinVertical(lipgloss.Left, toolItems...)),
+		baseStyle.Width(maxWidth).Render(""),
+	)
+
+	return baseStyle.Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderBackground(currentTheme.Background()).
+		BorderForeground(currentTheme.TextMuted()).
+		Width(lipgloss.Width(content) + 4).
+		Render(content)
+}
+
+func (t *toolsDialogCmp) SetTools(tools []agent.ToolState) {
+	t.tools = tools
+	if t.selectedIdx >= len(tools) {
+		t.selectedIdx = max(0, len(tools)-1)
+	}
+}
+
+func (t *toolsDialogCmp) BindingKeys() []key.Binding {
+	return layout.KeyMapToSlice(toolsKeys)
+}
+
+// NewToolsDialogCmp creates a new session tools dialog
+func NewToolsDialogCmp() ToolsDialog {
+	return &toolsDialogCmp{
+		tools: []agent.ToolState{},
+	}
+}
@@ -2,13 +2,18 @@ package page
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
 
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/opencode-ai/opencode/internal/app"
 	"github.com/opencode-ai/opencode/internal/completions"
+	"github.com/opencode-ai/opencode/internal/history"
+	"github.com/opencode-ai/opencode/internal/llm/agent"
 	"github.com/opencode-ai/opencode/internal/message"
 	"github.com/opencode-ai/opencode/internal/session"
 	"github.com/opencode-ai/opencode/internal/tui/components/chat"
@@ -27,12 +32,14 @@ type chatPage struct {
 	session              session.Session
 	completionDialog     dialog.CompletionDialog
 	showCompletionDialog bool
+	resultFocused        bool
 }
 
 type ChatKeyMap struct {
 	ShowCompletionDialog key.Binding
 	NewSession           key.Binding
 	Cancel               key.Binding
+	CopyDiff             key.Binding
 }
 
 var keyMap = ChatKeyMap{
@@ -45,8 +52,12 @@ var keyMap = ChatKeyMap{
 		key.WithHelp("ctrl+n", "new session"),
 	),
 	Cancel: key.NewBinding(
-		key.WithKeys("esc"),
-		key.WithHelp("esc", "cancel"),
+		key.WithKeys("esc", "ctrl+x"),
+		key.WithHelp("esc/ctrl+x", "cancel"),
+	),
+	CopyDiff: key.NewBinding(
+		key.WithKeys("ctrl+y"),
+		key.WithHelp("ctrl+y", "copy session diff"),
 	),
 }
 
@@ -67,9 +78,20 @@ func (p *chatPage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case dialog.CompletionDialogCloseMsg:
 		p.showCompletionDialog = false
 	case chat.SendMsg:
+		if msg.BranchFrom != "" {
+			cmd, err := p.branchSession(msg.BranchFrom)
+			if err != nil {
+				return p, tea.Batch(
+					util.ReportError(err),
+					util.CmdHandler(chat.RestorePromptMsg{Text: msg.Text, Attachments: msg.Attachments}),
+				)
+			}
+			cmds = append(cmds, cmd)
+		}
 		cmd := p.sendMessage(msg.Text, msg.Attachments)
 		if cmd != nil {
-			return p, cmd
+			cmds = append(cmds, cmd)
+			return p, tea.Batch(cmds...)
 		}
 	case dialog.CommandRunCustomMsg:
 		// Check if the agent is busy before executing custom commands
@@ -92,6 +114,13 @@ func (p *chatPage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if cmd != nil {
 			return p, cmd
 		}
+	case chat.ResultFocusMsg:
+		p.resultFocused = bool(msg)
+	case chat.SessionClearedMsg:
+		if p.session.ID != "" {
+			p.session = session.Session{}
+			cmds = append(cmds, p.clearSidebar())
+		}
 	case chat.SessionSelectedMsg:
 		if p.session.ID == "" {
 			cmd := p.setSidebar()
@@ -101,6 +130,11 @@ func (p *chatPage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		p.session = msg
 	case tea.KeyMsg:
+		// While a tool result is focused, esc leaves it instead of
+		// cancelling the agent, and the other keys scroll it.
+		if p.resultFocused {
+			break
+		}
 		switch {
 		case key.Matches(msg, keyMap.ShowCompletionDialog):
 			p.showCompletionDialog = true
@@ -112,12 +146,18 @@ func (p *chatPage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				util.CmdHandler(chat.SessionClearedMsg{}),
 			)
 		case key.Matches(msg, keyMap.Cancel):
-			if p.session.ID != "" {
+			if p.session.ID != "" && p.app.CoderAgent.IsSessionBusy(p.session.ID) {
 				// Cancel the current session's generation process
 				// This allows users to interrupt long-running operations
 				p.app.CoderAgent.Cancel(p.session.ID)
-				return p, nil
+				return p, util.ReportInfo("Response cancelled")
+			}
+			if p.session.ID == "" {
+				cmds = append(cmds, util.CmdHandler(chat.DismissStartupSummaryMsg{}))
 			}
+			// Otherwise esc goes on to the editor, e.g. to cancel an edit.
+		case key.Matches(msg, keyMap.CopyDiff):
+			return p, p.copySessionDiff()
 		}
 	}
 	if p.showCompletionDialog {
@@ -142,7 +182,7 @@ func (p *chatPage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (p *chatPage) setSidebar() tea.Cmd {
 	sidebarContainer := layout.NewContainer(
-		chat.NewSidebarCmp(p.session, p.app.History),
+		chat.NewSidebarCmp(p.session, p.app.History, p.app.Memory),
 		layout.WithPadding(1, 1, 1, 1),
 	)
 	return tea.Batch(p.layout.SetRightPanel(sidebarContainer), sidebarContainer.Init())
@@ -152,6 +192,22 @@ func (p *chatPage) clearSidebar() tea.Cmd {
 	return p.layout.ClearRightPanel()
 }
 
+// branchSession switches to a new branch of the session with the messages
+// before the user message messageID, which is replaced by the message sent
+// next. The original session stays as it is.
+func (p *chatPage) branchSession(messageID string) (tea.Cmd, error) {
+	branch, err := p.app.BranchSession(context.Background(), p.session.ID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	p.session = branch
+	return tea.Batch(
+		p.setSidebar(),
+		util.CmdHandler(chat.SessionSelectedMsg(branch)),
+		util.ReportInfo("Branched the session, the original is unchanged"),
+	), nil
+}
+
 func (p *chatPage) sendMessage(text string, attachments []message.Attachment) tea.Cmd {
 	var cmds []tea.Cmd
 	if p.session.ID == "" {
@@ -169,6 +225,11 @@ func (p *chatPage) sendMessage(text string, attachments []message.Attachment) te
 	}
 
 	_, err := p.app.CoderAgent.Run(context.Background(), p.session.ID, text, attachments...)
+	var overflow *agent.ContextOverflowError
+	if errors.As(err, &overflow) {
+		cmds = append(cmds, util.CmdHandler(dialog.ShowContextOverflowMsg{Overflow: overflow}))
+		return tea.Batch(cmds...)
+	}
 	if err != nil {
 		return util.ReportError(err)
 	}
@@ -205,6 +266,48 @@ func (p *chatPage) View() string {
 	return layoutView
 }
 
+// copySessionDiff copies the unified diff of every file changed in the
+// session to the clipboard.
+func (p *chatPage) copySessionDiff() tea.Cmd {
+	if p.session.ID == "" {
+		return util.ReportWarn("No active session to copy the diff of")
+	}
+	sessionID := p.session.ID
+	return func() tea.Msg {
+		sessionDiff, changes, err := history.SessionDiff(context.Background(), p.app.History, sessionID)
+		if err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+		}
+		if len(changes) == 0 {
+			return util.InfoMsg{Type: util.InfoTypeWarn, Msg: "No files were changed in this session"}
+		}
+		if err := clipboard.WriteAll(sessionDiff); err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: fmt.Sprintf("Failed to copy to clipboard: %v", err)}
+		}
+
+		additions, removals := 0, 0
+		for _, change := range changes {
+			additions += change.Additions
+			removals += change.Removals
+		}
+		return util.InfoMsg{
+			Type: util.InfoTypeInfo,
+			Msg:  fmt.Sprintf("Session diff copied to clipboard: %d files, +%d -%d, %s", len(changes), additions, removals, formatSize(len(sessionDiff))),
+		}
+	}
+}
+
+func formatSize(bytes int) string {
+	switch {
+	case bytes >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1024*1024))
+	case bytes >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(bytes)/1024)
+	default:
+		return fmt.Sprintf("%d B", bytes)
+	}
+}
+
 func (p *chatPage) BindingKeys() []key.Binding {
 	bindings := layout.KeyMapToSlice(keyMap)
 	bindings = append(bindings, p.messages.BindingKeys()...)
@@ -0,0 +1,110 @@
+package page
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/opencode-ai/opencode/internal/app"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/llm/agent"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/session"
+	"github.com/opencode-ai/opencode/internal/tui/components/chat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// idleAgent is an agent that is never busy and records the sessions it is
+// asked to run.
+type idleAgent struct {
+	agent.Service
+	runs []string
+}
+
+func (a *idleAgent) IsSessionBusy(string) bool { return false }
+
+func (a *idleAgent) IsBusy() bool { return false }
+
+func (a *idleAgent) Run(_ context.Context, sessionID string, _ string, _ ...message.Attachment) (<-chan agent.AgentEvent, error) {
+	a.runs = append(a.runs, sessionID)
+	return make(chan agent.AgentEvent), nil
+}
+
+// update feeds msg to the page and then the messages its commands produce,
+// the way the program loop would, except for batches of other commands.
+func update(p tea.Model, msg tea.Msg) tea.Model {
+	p, cmd := p.Update(msg)
+	for _, m := range runCmd(cmd) {
+		if _, ok := m.(chat.SendMsg); ok {
+			p = update(p, m)
+		}
+	}
+	return p
+}
+
+func runCmd(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		var msgs []tea.Msg
+		for _, c := range msg {
+			msgs = append(msgs, runCmd(c)...)
+		}
+		return msgs
+	case nil:
+		return nil
+	default:
+		return []tea.Msg{msg}
+	}
+}
+
+func TestCancelEditKeepsSession(t *testing.T) {
+	cfg, err := config.Load(t.TempDir(), false)
+	require.NoError(t, err)
+	cfg.Data.Directory = t.TempDir()
+	conn, err := db.Connect()
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	ctx := context.Background()
+	q := db.New(conn)
+	coder := &idleAgent{}
+	a := &app.App{
+		Sessions:   session.NewService(q),
+		Messages:   message.NewService(q),
+		CoderAgent: coder,
+	}
+	sess, err := a.Sessions.Create(ctx, "original")
+	require.NoError(t, err)
+	first, err := a.Messages.Create(ctx, sess.ID, message.CreateMessageParams{
+		Role:  message.User,
+		Parts: []message.ContentPart{message.TextContent{Text: "first"}},
+	})
+	require.NoError(t, err)
+	_, err = a.Messages.Create(ctx, sess.ID, message.CreateMessageParams{
+		Role:  message.Assistant,
+		Parts: []message.ContentPart{message.TextContent{Text: "answer"}},
+	})
+	require.NoError(t, err)
+
+	var p tea.Model = NewChatPage(a)
+	p = update(p, chat.SessionSelectedMsg(sess))
+	p = update(p, chat.StartEditMsg{MessageID: first.ID, Text: "edited"})
+	p = update(p, tea.KeyMsg{Type: tea.KeyEsc})
+	p = update(p, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("next")})
+	update(p, tea.KeyMsg{Type: tea.KeyEnter})
+
+	assert.Equal(t, []string{sess.ID}, coder.runs, "the message is sent to the original session")
+	sessions, err := a.Sessions.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, sessions, 1, "no branch is created")
+	msgs, err := a.Messages.List(ctx, sess.ID)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	assert.Equal(t, "first", msgs[0].Content().Text)
+	assert.Equal(t, "answer", msgs[1].Content().Text)
+}
@@ -1,9 +1,14 @@
 package styles
 
 import (
+	"sync"
+
+	chromastyles "github.com/alecthomas/chroma/v2/styles"
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/glamour/ansi"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/logging"
 	"github.com/opencode-ai/opencode/internal/tui/theme"
 )
 
@@ -14,21 +19,86 @@ func boolPtr(b bool) *bool       { return &b }
 func stringPtr(s string) *string { return &s }
 func uintPtr(u uint) *uint       { return &u }
 
-// returns a glamour TermRenderer configured with the current theme
-func GetMarkdownRenderer(width int) *glamour.TermRenderer {
+// MarkdownRenderer renders markdown with the current theme. Renderers are
+// shared, so Render is safe for concurrent use.
+type MarkdownRenderer struct {
+	mu sync.Mutex
+	r  *glamour.TermRenderer
+}
+
+// Render renders the given markdown.
+func (m *MarkdownRenderer) Render(in string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.r.Render(in)
+}
+
+type markdownRendererKey struct {
+	theme     string
+	codeTheme string
+	dark      bool
+	width     int
+}
+
+const maxCachedMarkdownRenderers = 32
+
+var (
+	markdownRenderersMu sync.Mutex
+	markdownRenderers   = make(map[markdownRendererKey]*MarkdownRenderer)
+	unknownCodeTheme    string
+)
+
+// GetMarkdownRenderer returns a renderer configured with the current theme
+// and code theme, reusing the one created earlier for the same width.
+func GetMarkdownRenderer(width int) *MarkdownRenderer {
+	markdownRenderersMu.Lock()
+	defer markdownRenderersMu.Unlock()
+
+	key := markdownRendererKey{
+		theme:     theme.CurrentThemeName(),
+		codeTheme: codeTheme(),
+		dark:      lipgloss.HasDarkBackground(),
+		width:     width,
+	}
+	if r, ok := markdownRenderers[key]; ok {
+		return r
+	}
+	if len(markdownRenderers) >= maxCachedMarkdownRenderers {
+		clear(markdownRenderers)
+	}
 	r, _ := glamour.NewTermRenderer(
-		glamour.WithStyles(generateMarkdownStyleConfig()),
+		glamour.WithStyles(generateMarkdownStyleConfig(key.codeTheme)),
 		glamour.WithWordWrap(width),
 	)
-	return r
+	markdownRenderers[key] = &MarkdownRenderer{r: r}
+	return markdownRenderers[key]
+}
+
+// codeTheme returns the configured syntax highlighting theme for code
+// blocks, or an empty string to use the colors of the current theme. It must
+// be called with markdownRenderersMu held.
+func codeTheme() string {
+	cfg := config.Get()
+	if cfg == nil || cfg.TUI.CodeTheme == "" {
+		return ""
+	}
+	if _, ok := chromastyles.Registry[cfg.TUI.CodeTheme]; !ok {
+		if unknownCodeTheme != cfg.TUI.CodeTheme {
+			logging.Warn("Unknown code theme, using the colors of the current theme", "codeTheme", cfg.TUI.CodeTheme)
+			unknownCodeTheme = cfg.TUI.CodeTheme
+		}
+		return ""
+	}
+	return cfg.TUI.CodeTheme
 }
 
 // creates an ansi.StyleConfig for markdown rendering
-// using adaptive colors from the provided theme.
-func generateMarkdownStyleConfig() ansi.StyleConfig {
+// using adaptive colors from the provided theme. Code blocks use the named
+// chroma style instead of the theme's syntax colors when chromaTheme is set.
+func generateMarkdownStyleConfig(chromaTheme string) ansi.StyleConfig {
 	t := theme.CurrentTheme()
 
-	return ansi.StyleConfig{
+	style := ansi.StyleConfig{
 		Document: ansi.StyleBlock{
 			StylePrimitive: ansi.StylePrimitive{
 				BlockPrefix: "",
@@ -272,6 +342,12 @@ func generateMarkdownStyleConfig() ansi.StyleConfig {
 			},
 		},
 	}
+
+	if chromaTheme != "" {
+		style.CodeBlock.Chroma = nil
+		style.CodeBlock.Theme = chromaTheme
+	}
+	return style
 }
 
 // adaptiveColorToString converts a lipgloss.AdaptiveColor to the appropriate
@@ -2,15 +2,22 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
 
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/opencode-ai/opencode/internal/app"
 	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/export"
+	"github.com/opencode-ai/opencode/internal/format"
 	"github.com/opencode-ai/opencode/internal/llm/agent"
+	"github.com/opencode-ai/opencode/internal/llm/tools"
 	"github.com/opencode-ai/opencode/internal/logging"
 	"github.com/opencode-ai/opencode/internal/permission"
 	"github.com/opencode-ai/opencode/internal/pubsub"
@@ -33,10 +40,61 @@ type keyMap struct {
 	Filepicker    key.Binding
 	Models        key.Binding
 	SwitchTheme   key.Binding
+	Compact       key.Binding
 }
 
 type startCompactSessionMsg struct{}
 
+// startCompactPreviewMsg starts a manual compaction that is applied only
+// after the user approves the generated summary.
+type startCompactPreviewMsg struct{}
+
+type compactPreviewMsg struct {
+	preview agent.SummaryPreview
+	err     error
+}
+
+type copySessionSnippetMsg struct{}
+
+type postSessionSummaryMsg struct{}
+
+// resetContextMsg clears the messages of the current session, archiving them
+// first when archive is set.
+type resetContextMsg struct {
+	archive bool
+}
+
+// sessionDeletedMsg reports a session deleted from the session dialog.
+type sessionDeletedMsg struct {
+	session session.Session
+	err     error
+}
+
+// sessionRenamedMsg reports a session renamed from the session dialog.
+type sessionRenamedMsg struct {
+	session session.Session
+	err     error
+}
+
+// droppedTurnsMsg reports the oldest turns deleted to make room for a prompt
+// that did not fit in the context window.
+type droppedTurnsMsg struct {
+	overflow *agent.ContextOverflowError
+	dropped  int
+	err      error
+}
+
+type showToolsDialogMsg struct{}
+
+type toggleAskModeMsg struct{}
+
+const (
+	goToMessageCommandID   = "goto"
+	deleteMessageCommandID = "delete-message"
+	editMessageCommandID   = "edit-message"
+	messageNumberArg       = "MESSAGE_NUMBER"
+)
+
 const (
 	quitKey = "q"
 )
@@ -78,6 +136,10 @@ var keys = keyMap{
 		key.WithKeys("ctrl+t"),
 		key.WithHelp("ctrl+t", "switch theme"),
 	),
+	Compact: key.NewBinding(
+		key.WithKeys("ctrl+p"),
+		key.WithHelp("ctrl+p", "compact session"),
+	),
 }
 
 var helpEsc = key.NewBinding(
@@ -133,9 +195,21 @@ type appModel struct {
 	showThemeDialog bool
 	themeDialog     dialog.ThemeDialog
 
+	showToolsDialog bool
+	toolsDialog     dialog.ToolsDialog
+
+	showCompactDialog bool
+	compactDialog     dialog.CompactDialog
+
 	showMultiArgumentsDialog bool
 	multiArgumentsDialog     dialog.MultiArgumentsDialogCmp
 
+	showContextOverflowDialog bool
+	contextOverflowDialog     dialog.ContextOverflowDialog
+
+	showStepLimitDialog bool
+	stepLimitDialog     dialog.StepLimitDialog
+
 	isCompacting      bool
 	compactingMessage string
 }
@@ -214,6 +288,10 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		a.initDialog.SetSize(msg.Width, msg.Height)
 
+		compact, compactCmd := a.compactDialog.Update(msg)
+		a.compactDialog = compact.(dialog.CompactDialog)
+		cmds = append(cmds, compactCmd)
+
 		if a.showMultiArgumentsDialog {
 			a.multiArgumentsDialog.SetSize(msg.Width, msg.Height)
 			args, argsCmd := a.multiArgumentsDialog.Update(msg)
@@ -283,7 +361,11 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case dialog.PermissionAllowForSession:
 			a.app.Permissions.GrantPersistant(msg.Permission)
 		case dialog.PermissionDeny:
-			a.app.Permissions.Deny(msg.Permission)
+			if msg.Reason != "" {
+				a.app.Permissions.DenyWithReason(msg.Permission, msg.Reason)
+			} else {
+				a.app.Permissions.Deny(msg.Permission)
+			}
 		}
 		a.showPermissions = false
 		return a, cmd
@@ -320,6 +402,142 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return nil
 		}
 
+	case startCompactPreviewMsg:
+		if a.selectedSession.ID == "" {
+			return a, util.ReportWarn("No active session to summarize")
+		}
+		if a.app.CoderAgent.IsSessionBusy(a.selectedSession.ID) {
+			return a, util.ReportWarn("Wait for the current request to finish before compacting")
+		}
+		msgs, err := a.app.Messages.List(context.Background(), a.selectedSession.ID)
+		if err != nil {
+			return a, util.ReportError(err)
+		}
+		if len(msgs) == 0 {
+			return a, util.ReportWarn("No messages to summarize")
+		}
+		a.compactDialog.SetStats(len(msgs), a.selectedSession.PromptTokens+a.selectedSession.CompletionTokens)
+		a.showCompactDialog = true
+		sessionID := a.selectedSession.ID
+		return a, func() tea.Msg {
+			preview, err := a.app.CoderAgent.PreviewSummary(context.Background(), sessionID)
+			return compactPreviewMsg{preview: preview, err: err}
+		}
+
+	case compactPreviewMsg:
+		if !a.showCompactDialog {
+			return a, nil
+		}
+		if msg.err != nil {
+			a.showCompactDialog = false
+			if errors.Is(msg.err, agent.ErrRequestCancelled) {
+				return a, nil
+			}
+			return a, util.ReportError(msg.err)
+		}
+		a.compactDialog.SetPreview(msg.preview)
+		return a, nil
+
+	case dialog.CloseCompactDialogMsg:
+		a.showCompactDialog = false
+		// Stop a summary that is still being generated.
+		a.app.CoderAgent.Cancel(a.selectedSession.ID)
+		return a, nil
+
+	case dialog.CompactApprovedMsg:
+		a.showCompactDialog = false
+		if err := a.app.CoderAgent.ApplySummary(context.Background(), msg.Preview); err != nil {
+			return a, util.ReportError(err)
+		}
+		before := a.selectedSession.PromptTokens + a.selectedSession.CompletionTokens
+		after := msg.Preview.Usage.OutputTokens
+		if before <= after {
+			return a, util.ReportInfo(fmt.Sprintf("Session compacted: %d messages summarized", msg.Preview.Messages))
+		}
+		return a, util.ReportInfo(fmt.Sprintf("Session compacted: %d messages summarized, context down from %s to %s tokens (%s saved)",
+			msg.Preview.Messages, format.Tokens(before), format.Tokens(after), format.Tokens(before-after)))
+
+	case dialog.ShowContextOverflowMsg:
+		a.contextOverflowDialog.SetOverflow(msg.Overflow)
+		a.showContextOverflowDialog = true
+		return a, nil
+
+	case dialog.ShowStepLimitMsg:
+		a.stepLimitDialog.SetPause(msg.Pause)
+		a.showStepLimitDialog = true
+		return a, nil
+
+	case dialog.StepLimitActionMsg:
+		a.showStepLimitDialog = false
+		if !msg.Continue || msg.Pause.SessionID != a.selectedSession.ID {
+			return a, util.ReportInfo("Stopped at the step limit")
+		}
+		return a, util.CmdHandler(chat.SendMsg{Text: agent.StepLimitContinuePrompt})
+
+	case dialog.CloseContextOverflowDialogMsg:
+		a.showContextOverflowDialog = false
+		return a, util.CmdHandler(chat.RestorePromptMsg{Text: msg.Overflow.Content, Attachments: msg.Overflow.Attachments})
+
+	case dialog.ContextOverflowActionMsg:
+		a.showContextOverflowDialog = false
+		overflow := msg.Overflow
+		switch msg.Action {
+		case dialog.OverflowCompact:
+			return a, tea.Batch(
+				util.CmdHandler(chat.RestorePromptMsg{Text: overflow.Content, Attachments: overflow.Attachments}),
+				util.CmdHandler(startCompactPreviewMsg{}),
+			)
+		case dialog.OverflowDropOldest:
+			return a, func() tea.Msg {
+				dropped, err := a.app.CoderAgent.DropOldestTurns(context.Background(), overflow)
+				return droppedTurnsMsg{overflow: overflow, dropped: dropped, err: err}
+			}
+		case dialog.OverflowNewSession:
+			return a, tea.Sequence(
+				util.CmdHandler(chat.SessionClearedMsg{}),
+				util.CmdHandler(chat.SendMsg{Text: overflow.Content, Attachments: overflow.Attachments}),
+			)
+		}
+		return a, nil
+
+	case droppedTurnsMsg:
+		if msg.err != nil {
+			return a, tea.Batch(
+				util.ReportError(msg.err),
+				util.CmdHandler(chat.RestorePromptMsg{Text: msg.overflow.Content, Attachments: msg.overflow.Attachments}),
+			)
+		}
+		return a, tea.Batch(
+			util.ReportInfo(fmt.Sprintf("Dropped the %d oldest messages to fit the context window", msg.dropped)),
+			util.CmdHandler(chat.SendMsg{Text: msg.overflow.Content, Attachments: msg.overflow.Attachments}),
+		)
+
+	case chat.SessionClearedMsg:
+		a.app.Webhook.SessionEnded(a.selectedSession.ID)
+		a.selectedSession = session.Session{}
+
+	case postSessionSummaryMsg:
+		if !a.app.Webhook.Enabled() {
+			return a, util.ReportWarn("No webhook is configured, set webhook.url in the config")
+		}
+		if a.selectedSession.ID == "" {
+			return a, util.ReportWarn("No active session to post")
+		}
+		a.app.Webhook.Post(a.selectedSession.ID)
+		return a, util.ReportInfo("Posting session summary to the webhook")
+
+	case resetContextMsg:
+		if a.selectedSession.ID == "" {
+			return a, util.ReportWarn("No active session to reset")
+		}
+		return a, a.resetContext(a.selectedSession.ID, msg.archive)
+
+	case copySessionSnippetMsg:
+		if a.selectedSession.ID == "" {
+			return a, util.ReportWarn("No active session to share")
+		}
+		return a, a.copySessionSnippet(a.selectedSession)
+
 	case pubsub.Event[agent.AgentEvent]:
 		payload := msg.Payload
 		if payload.Error != nil {
@@ -332,8 +550,13 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if payload.Done && payload.Type == agent.AgentEventTypeSummarize {
 			a.isCompacting = false
 			return a, util.ReportInfo("Session summarization complete")
+		} else if payload.StepLimit != nil {
+			if payload.StepLimit.SessionID != a.selectedSession.ID {
+				return a, util.ReportWarn("A session paused at the step limit")
+			}
+			return a, util.CmdHandler(dialog.ShowStepLimitMsg{Pause: payload.StepLimit})
 		} else if payload.Done && payload.Type == agent.AgentEventTypeResponse && a.selectedSession.ID != "" {
-			model := a.app.CoderAgent.Model()
+			model := a.app.CoderAgent.SessionModel(context.Background(), a.selectedSession.ID)
 			contextWindow := model.ContextWindow
 			tokens := a.selectedSession.CompletionTokens + a.selectedSession.PromptTokens
 			if (tokens >= int64(float64(contextWindow)*0.95)) && config.Get().AutoCompact {
@@ -347,6 +570,49 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.showThemeDialog = false
 		return a, nil
 
+	case showToolsDialogMsg:
+		if a.selectedSession.ID == "" {
+			return a, util.ReportWarn("No active session to configure tools for")
+		}
+		a.toolsDialog.SetTools(a.app.CoderAgent.Tools(a.selectedSession.ID))
+		a.showToolsDialog = true
+		return a, nil
+
+	case toggleAskModeMsg:
+		if a.selectedSession.ID == "" {
+			return a, util.ReportWarn("No active session to switch to ask mode")
+		}
+		enabled := !a.app.CoderAgent.AskMode(a.selectedSession.ID)
+		if err := a.app.CoderAgent.SetAskMode(a.selectedSession.ID, enabled); err != nil {
+			return a, util.ReportError(err)
+		}
+		if enabled {
+			return a, util.ReportInfo("Ask mode on: the assistant answers without tools")
+		}
+		return a, util.ReportInfo("Ask mode off: tools are available again")
+
+	case dialog.CloseToolsDialogMsg:
+		a.showToolsDialog = false
+		return a, nil
+
+	case dialog.ToolToggledMsg:
+		if err := a.app.CoderAgent.SetToolEnabled(a.selectedSession.ID, msg.Name, msg.Enabled); err != nil {
+			return a, util.ReportError(err)
+		}
+		toolStates := a.app.CoderAgent.Tools(a.selectedSession.ID)
+		a.toolsDialog.SetTools(toolStates)
+		enabledCount := 0
+		for _, tool := range toolStates {
+			if tool.Enabled {
+				enabledCount++
+			}
+		}
+		action := "Disabled"
+		if msg.Enabled {
+			action = "Enabled"
+		}
+		return a, util.ReportInfo(fmt.Sprintf("%s %s (%d of %d tools enabled)", action, msg.Name, enabledCount, len(toolStates)))
+
 	case dialog.ThemeChangedMsg:
 		a.pages[a.currentPage], cmd = a.pages[a.currentPage].Update(msg)
 		a.showThemeDialog = false
@@ -359,6 +625,24 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case dialog.ModelSelectedMsg:
 		a.showModelDialog = false
 
+		// Picking the model in use keeps the config file and provider as
+		// they are.
+		current := a.app.CoderAgent.Model()
+		if a.selectedSession.ID != "" {
+			current = a.app.CoderAgent.SessionModel(context.Background(), a.selectedSession.ID)
+		}
+		if current.ID == msg.Model.ID {
+			return a, util.ReportInfo(fmt.Sprintf("Already using %s", msg.Model.Name))
+		}
+
+		// With a session open the model is only changed for that session.
+		if a.selectedSession.ID != "" {
+			if err := a.app.CoderAgent.SetSessionModel(context.Background(), a.selectedSession.ID, msg.Model.ID); err != nil {
+				return a, util.ReportError(err)
+			}
+			return a, util.ReportInfo(fmt.Sprintf("Model of this session changed to %s", msg.Model.Name))
+		}
+
 		model, err := a.app.CoderAgent.Update(config.AgentCoder, msg.Model.ID)
 		if err != nil {
 			return a, util.ReportError(err)
@@ -392,6 +676,9 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a, nil
 
 	case chat.SessionSelectedMsg:
+		if a.selectedSession.ID != "" && a.selectedSession.ID != msg.ID {
+			a.app.Webhook.SessionEnded(a.selectedSession.ID)
+		}
 		a.selectedSession = msg
 		a.sessionDialog.SetSelectedSession(msg.ID)
 
@@ -406,6 +693,51 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return a, nil
 
+	case dialog.DeleteSessionMsg:
+		if a.app.CoderAgent.IsSessionBusy(msg.Session.ID) {
+			return a, util.ReportWarn("The session is still running, cancel it before deleting it")
+		}
+		sess := msg.Session
+		return a, func() tea.Msg {
+			return sessionDeletedMsg{session: sess, err: a.app.Sessions.Delete(context.Background(), sess.ID)}
+		}
+
+	case sessionDeletedMsg:
+		if msg.err != nil {
+			return a, util.ReportError(msg.err)
+		}
+		cmds := []tea.Cmd{util.ReportInfo("Session deleted: " + msg.session.Title)}
+		if msg.session.ID == a.selectedSession.ID {
+			cmds = append(cmds, util.CmdHandler(chat.SessionClearedMsg{}))
+		}
+		sessions, err := a.app.Sessions.List(context.Background())
+		if err != nil {
+			return a, tea.Batch(append(cmds, util.ReportError(err))...)
+		}
+		if len(sessions) == 0 {
+			a.showSessionDialog = false
+		}
+		a.sessionDialog.SetSessions(sessions)
+		return a, tea.Batch(cmds...)
+
+	case dialog.RenameSessionMsg:
+		id, title := msg.Session.ID, msg.Title
+		return a, func() tea.Msg {
+			renamed, err := a.app.Sessions.Rename(context.Background(), id, title)
+			return sessionRenamedMsg{session: renamed, err: err}
+		}
+
+	case sessionRenamedMsg:
+		if msg.err != nil {
+			return a, util.ReportError(msg.err)
+		}
+		sessions, err := a.app.Sessions.List(context.Background())
+		if err != nil {
+			return a, util.ReportError(err)
+		}
+		a.sessionDialog.SetSessions(sessions)
+		return a, util.ReportInfo("Session renamed to " + msg.session.Title)
+
 	case dialog.CommandSelectedMsg:
 		a.showCommandDialog = false
 		// Execute the command handler if available
@@ -424,10 +756,24 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Close multi-arguments dialog
 		a.showMultiArgumentsDialog = false
 
+		if msg.Submit && (msg.CommandID == goToMessageCommandID || msg.CommandID == deleteMessageCommandID || msg.CommandID == editMessageCommandID) {
+			number, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(msg.Args[messageNumberArg]), "#"))
+			if err != nil || number < 1 {
+				return a, util.ReportWarn("Enter a message number, e.g. 12")
+			}
+			switch msg.CommandID {
+			case deleteMessageCommandID:
+				return a, util.CmdHandler(chat.DeleteMessageMsg{Number: number})
+			case editMessageCommandID:
+				return a, util.CmdHandler(chat.EditMessageMsg{Number: number})
+			}
+			return a, util.CmdHandler(chat.GoToMessageMsg{Number: number})
+		}
+
 		// If submitted, replace all named arguments and run the command
 		if msg.Submit {
 			content := msg.Content
-			
+
 			// Replace each named argument with its value
 			for name, value := range msg.Args {
 				placeholder := "$" + name
@@ -470,9 +816,26 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if a.showModelDialog {
 				a.showModelDialog = false
 			}
+			if a.showToolsDialog {
+				a.showToolsDialog = false
+			}
+			if a.showCompactDialog {
+				a.showCompactDialog = false
+				a.app.CoderAgent.Cancel(a.selectedSession.ID)
+			}
 			if a.showMultiArgumentsDialog {
 				a.showMultiArgumentsDialog = false
 			}
+			if a.showContextOverflowDialog {
+				a.showContextOverflowDialog = false
+				// Give the prompt back as esc and cancel do, or it is lost
+				overflow := a.contextOverflowDialog.Overflow()
+				return a, util.CmdHandler(chat.RestorePromptMsg{Text: overflow.Content, Attachments: overflow.Attachments})
+			}
+			if a.showStepLimitDialog {
+				a.showStepLimitDialog = false
+				return a, util.ReportInfo("Stopped at the step limit")
+			}
 			return a, nil
 		case key.Matches(msg, keys.SwitchSession):
 			if a.currentPage == page.ChatPage && !a.showQuit && !a.showPermissions && !a.showCommandDialog {
@@ -485,6 +848,7 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return a, util.ReportWarn("No sessions available")
 				}
 				a.sessionDialog.SetSessions(sessions)
+				a.sessionDialog.ClearQuery()
 				a.showSessionDialog = true
 				return a, nil
 			}
@@ -506,10 +870,16 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return a, nil
 			}
 			if a.currentPage == page.ChatPage && !a.showQuit && !a.showPermissions && !a.showSessionDialog && !a.showCommandDialog {
+				a.modelDialog.SetCurrentModel(a.app.CoderAgent.SessionModel(context.Background(), a.selectedSession.ID).ID)
 				a.showModelDialog = true
 				return a, nil
 			}
 			return a, nil
+		case key.Matches(msg, keys.Compact):
+			if a.currentPage == page.ChatPage && !a.showQuit && !a.showPermissions && !a.showSessionDialog && !a.showCommandDialog && !a.showCompactDialog {
+				return a, util.CmdHandler(startCompactPreviewMsg{})
+			}
+			return a, nil
 		case key.Matches(msg, keys.SwitchTheme):
 			if !a.showQuit && !a.showPermissions && !a.showSessionDialog && !a.showCommandDialog {
 				// Show theme switcher dialog
@@ -558,7 +928,8 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.showHelp = !a.showHelp
 			return a, nil
 		case key.Matches(msg, helpEsc):
-			if a.app.CoderAgent.IsBusy() {
+			// Leave "?" to the permission dialog, which may be taking a reason.
+			if a.app.CoderAgent.IsBusy() && !a.showPermissions {
 				if a.showQuit {
 					return a, nil
 				}
@@ -656,6 +1027,46 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	if a.showToolsDialog {
+		d, toolsCmd := a.toolsDialog.Update(msg)
+		a.toolsDialog = d.(dialog.ToolsDialog)
+		cmds = append(cmds, toolsCmd)
+		// Only block key messages send all other messages down
+		if _, ok := msg.(tea.KeyMsg); ok {
+			return a, tea.Batch(cmds...)
+		}
+	}
+
+	if a.showCompactDialog {
+		d, compactCmd := a.compactDialog.Update(msg)
+		a.compactDialog = d.(dialog.CompactDialog)
+		cmds = append(cmds, compactCmd)
+		// Only block key messages send all other messages down
+		if _, ok := msg.(tea.KeyMsg); ok {
+			return a, tea.Batch(cmds...)
+		}
+	}
+
+	if a.showContextOverflowDialog {
+		d, overflowCmd := a.contextOverflowDialog.Update(msg)
+		a.contextOverflowDialog = d.(dialog.ContextOverflowDialog)
+		cmds = append(cmds, overflowCmd)
+		// Only block key messages send all other messages down
+		if _, ok := msg.(tea.KeyMsg); ok {
+			return a, tea.Batch(cmds...)
+		}
+	}
+
+	if a.showStepLimitDialog {
+		d, stepLimitCmd := a.stepLimitDialog.Update(msg)
+		a.stepLimitDialog = d.(dialog.StepLimitDialog)
+		cmds = append(cmds, stepLimitCmd)
+		// Only block key messages send all other messages down
+		if _, ok := msg.(tea.KeyMsg); ok {
+			return a, tea.Batch(cmds...)
+		}
+	}
+
 	s, _ := a.status.Update(msg)
 	a.status = s.(core.StatusCmp)
 	a.pages[a.currentPage], cmd = a.pages[a.currentPage].Update(msg)
@@ -677,6 +1088,45 @@ func (a *appModel) findCommand(id string) (dialog.Command, bool) {
 	return dialog.Command{}, false
 }
 
+// resetContext starts the session over with an empty context, archiving its
+// messages first when archive is set.
+func (a *appModel) resetContext(sessionID string, archive bool) tea.Cmd {
+	return func() tea.Msg {
+		archivePath, err := a.app.CoderAgent.ResetContext(context.Background(), sessionID, archive)
+		if err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+		}
+		if archivePath != "" {
+			return util.InfoMsg{Type: util.InfoTypeInfo, Msg: fmt.Sprintf("Context reset, messages archived to %s", archivePath)}
+		}
+		return util.InfoMsg{Type: util.InfoTypeInfo, Msg: "Context reset"}
+	}
+}
+
+// copySessionSnippet copies a redacted markdown transcript of the session to
+// the clipboard so it can be pasted into a bug report.
+func (a *appModel) copySessionSnippet(sess session.Session) tea.Cmd {
+	return func() tea.Msg {
+		messages, err := a.app.Messages.List(context.Background(), sess.ID)
+		if err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+		}
+
+		var secrets []string
+		for _, provider := range config.Get().Providers {
+			secrets = append(secrets, provider.APIKey)
+		}
+		homeDir, _ := os.UserHomeDir()
+		redactor := export.NewRedactor(config.WorkingDirectory(), homeDir, secrets)
+		snippet := redactor.Redact(export.Markdown(sess, messages))
+
+		if err := clipboard.WriteAll(snippet); err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: fmt.Sprintf("Failed to copy to clipboard: %v", err)}
+		}
+		return util.InfoMsg{Type: util.InfoTypeInfo, Msg: "Redacted session copied to clipboard"}
+	}
+}
+
 func (a *appModel) moveToPage(pageID page.PageID) tea.Cmd {
 	if a.app.CoderAgent.IsBusy() {
 		// For now we don't move to any page if the agent is busy
@@ -880,6 +1330,66 @@ func (a appModel) View() string {
 		)
 	}
 
+	if a.showToolsDialog {
+		overlay := a.toolsDialog.View()
+		row := lipgloss.Height(appView) / 2
+		row -= lipgloss.Height(overlay) / 2
+		col := lipgloss.Width(appView) / 2
+		col -= lipgloss.Width(overlay) / 2
+		appView = layout.PlaceOverlay(
+			col,
+			row,
+			overlay,
+			appView,
+			true,
+		)
+	}
+
+	if a.showCompactDialog {
+		overlay := a.compactDialog.View()
+		row := lipgloss.Height(appView) / 2
+		row -= lipgloss.Height(overlay) / 2
+		col := lipgloss.Width(appView) / 2
+		col -= lipgloss.Width(overlay) / 2
+		appView = layout.PlaceOverlay(
+			col,
+			row,
+			overlay,
+			appView,
+			true,
+		)
+	}
+
+	if a.showContextOverflowDialog {
+		overlay := a.contextOverflowDialog.View()
+		row := lipgloss.Height(appView) / 2
+		row -= lipgloss.Height(overlay) / 2
+		col := lipgloss.Width(appView) / 2
+		col -= lipgloss.Width(overlay) / 2
+		appView = layout.PlaceOverlay(
+			col,
+			row,
+			overlay,
+			appView,
+			true,
+		)
+	}
+
+	if a.showStepLimitDialog {
+		overlay := a.stepLimitDialog.View()
+		row := lipgloss.Height(appView) / 2
+		row -= lipgloss.Height(overlay) / 2
+		col := lipgloss.Width(appView) / 2
+		col -= lipgloss.Width(overlay) / 2
+		appView = layout.PlaceOverlay(
+			col,
+			row,
+			overlay,
+			appView,
+			true,
+		)
+	}
+
 	if a.showMultiArgumentsDialog {
 		overlay := a.multiArgumentsDialog.View()
 		row := lipgloss.Height(appView) / 2
@@ -901,19 +1411,23 @@ func (a appModel) View() string {
 func New(app *app.App) tea.Model {
 	startPage := page.ChatPage
 	model := &appModel{
-		currentPage:   startPage,
-		loadedPages:   make(map[page.PageID]bool),
-		status:        core.NewStatusCmp(app.LSPClients),
-		help:          dialog.NewHelpCmp(),
-		quit:          dialog.NewQuitCmp(),
-		sessionDialog: dialog.NewSessionDialogCmp(),
-		commandDialog: dialog.NewCommandDialogCmp(),
-		modelDialog:   dialog.NewModelDialogCmp(),
-		permissions:   dialog.NewPermissionDialogCmp(),
-		initDialog:    dialog.NewInitDialogCmp(),
-		themeDialog:   dialog.NewThemeDialogCmp(),
-		app:           app,
-		commands:      []dialog.Command{},
+		currentPage:           startPage,
+		loadedPages:           make(map[page.PageID]bool),
+		status:                core.NewStatusCmp(app.LSPClients, app.CoderAgent),
+		help:                  dialog.NewHelpCmp(),
+		quit:                  dialog.NewQuitCmp(),
+		sessionDialog:         dialog.NewSessionDialogCmp(),
+		commandDialog:         dialog.NewCommandDialogCmp(),
+		modelDialog:           dialog.NewModelDialogCmp(),
+		permissions:           dialog.NewPermissionDialogCmp(),
+		initDialog:            dialog.NewInitDialogCmp(),
+		themeDialog:           dialog.NewThemeDialogCmp(),
+		toolsDialog:           dialog.NewToolsDialogCmp(),
+		compactDialog:         dialog.NewCompactDialogCmp(),
+		contextOverflowDialog: dialog.NewContextOverflowDialogCmp(),
+		stepLimitDialog:       dialog.NewStepLimitDialogCmp(),
+		app:                   app,
+		commands:              []dialog.Command{},
 		pages: map[page.PageID]tea.Model{
 			page.ChatPage: page.NewChatPage(app),
 			page.LogsPage: page.NewLogsPage(),
@@ -944,11 +1458,110 @@ If there are Cursor rules (in .cursor/rules/ or .cursorrules) or Copilot rules (
 	model.RegisterCommand(dialog.Command{
 		ID:          "compact",
 		Title:       "Compact Session",
-		Description: "Summarize the current session and create a new one with the summary",
+		Description: "Preview a summary of the current session and approve it before it replaces the history sent to the model",
+		Handler: func(cmd dialog.Command) tea.Cmd {
+			return util.CmdHandler(startCompactPreviewMsg{})
+		},
+	})
+	model.RegisterCommand(dialog.Command{
+		ID:          "reset",
+		Title:       "Reset Context",
+		Description: "Clear the messages of the current session and start fresh, keeping the session and its file changes",
+		Handler: func(cmd dialog.Command) tea.Cmd {
+			return util.CmdHandler(resetContextMsg{})
+		},
+	})
+	model.RegisterCommand(dialog.Command{
+		ID:          "reset-archive",
+		Title:       "Archive and Reset Context",
+		Description: "Save the transcript to the data directory, then clear the messages of the current session",
+		Handler: func(cmd dialog.Command) tea.Cmd {
+			return util.CmdHandler(resetContextMsg{archive: true})
+		},
+	})
+	model.RegisterCommand(dialog.Command{
+		ID:          "share",
+		Title:       "Copy Shareable Snippet",
+		Description: "Copy the session transcript with paths, secrets and IDs redacted",
+		Handler: func(cmd dialog.Command) tea.Cmd {
+			return util.CmdHandler(copySessionSnippetMsg{})
+		},
+	})
+	model.RegisterCommand(dialog.Command{
+		ID:          "post-summary",
+		Title:       "Post Session Summary",
+		Description: "Post a summary of the session to the configured webhook",
+		Handler: func(cmd dialog.Command) tea.Cmd {
+			return util.CmdHandler(postSessionSummaryMsg{})
+		},
+	})
+	model.RegisterCommand(dialog.Command{
+		ID:          goToMessageCommandID,
+		Title:       "Go to Message",
+		Description: "Scroll the transcript to a message by its number",
+		Handler: func(cmd dialog.Command) tea.Cmd {
+			return util.CmdHandler(dialog.ShowMultiArgumentsDialogMsg{
+				CommandID: goToMessageCommandID,
+				ArgNames:  []string{messageNumberArg},
+			})
+		},
+	})
+	model.RegisterCommand(dialog.Command{
+		ID:          deleteMessageCommandID,
+		Title:       "Delete Message",
+		Description: "Delete a message by its number, with the results of its tool calls",
+		Handler: func(cmd dialog.Command) tea.Cmd {
+			return util.CmdHandler(dialog.ShowMultiArgumentsDialogMsg{
+				CommandID: deleteMessageCommandID,
+				ArgNames:  []string{messageNumberArg},
+			})
+		},
+	})
+	model.RegisterCommand(dialog.Command{
+		ID:          editMessageCommandID,
+		Title:       "Edit Message",
+		Description: "Edit one of your messages and continue from it in a new branch of the session",
+		Handler: func(cmd dialog.Command) tea.Cmd {
+			return util.CmdHandler(dialog.ShowMultiArgumentsDialogMsg{
+				CommandID: editMessageCommandID,
+				ArgNames:  []string{messageNumberArg},
+			})
+		},
+	})
+	model.RegisterCommand(dialog.Command{
+		ID:          "code-wrap",
+		Title:       "Toggle Code Wrapping",
+		Description: "Switch code blocks between wrapping and horizontal scrolling",
+		Handler: func(cmd dialog.Command) tea.Cmd {
+			return util.CmdHandler(chat.ToggleCodeWrapMsg{})
+		},
+	})
+	model.RegisterCommand(dialog.Command{
+		ID:          "tools",
+		Title:       "Configure Session Tools",
+		Description: "Enable or disable individual tools for the current session",
+		Handler: func(cmd dialog.Command) tea.Cmd {
+			return util.CmdHandler(showToolsDialogMsg{})
+		},
+	})
+	model.RegisterCommand(dialog.Command{
+		ID:          "ask",
+		Title:       "Toggle Ask Mode",
+		Description: "Chat about the code without tools; the assistant only answers and cannot read or change anything",
+		Handler: func(cmd dialog.Command) tea.Cmd {
+			return util.CmdHandler(toggleAskModeMsg{})
+		},
+	})
+	model.RegisterCommand(dialog.Command{
+		ID:          "clear-network-cache",
+		Title:       "Clear Network Cache",
+		Description: "Remove the cached fetch and sourcegraph responses",
 		Handler: func(cmd dialog.Command) tea.Cmd {
-			return func() tea.Msg {
-				return startCompactSessionMsg{}
+			removed, err := tools.ClearNetworkCache()
+			if err != nil {
+				return util.ReportError(err)
 			}
+			return util.ReportInfo(fmt.Sprintf("Removed %d cached responses", removed))
 		},
 	})
 	// Load custom commands
@@ -0,0 +1,56 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/opencode-ai/opencode/internal/llm/agent"
+	"github.com/opencode-ai/opencode/internal/tui/components/chat"
+	"github.com/opencode-ai/opencode/internal/tui/components/dialog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// restoredPrompt feeds msg to the model and then the messages its commands
+// produce, and returns the prompt it gives back to the editor, if any.
+func restoredPrompt(m tea.Model, msg tea.Msg) *chat.RestorePromptMsg {
+	m, cmd := m.Update(msg)
+	for cmd != nil {
+		switch msg := cmd().(type) {
+		case chat.RestorePromptMsg:
+			return &msg
+		case dialog.CloseContextOverflowDialogMsg:
+			m, cmd = m.Update(msg)
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func TestCloseContextOverflowDialogRestoresPrompt(t *testing.T) {
+	tests := []struct {
+		name string
+		key  tea.KeyMsg
+	}{
+		{"esc", tea.KeyMsg{Type: tea.KeyEsc}},
+		{"ctrl+c", tea.KeyMsg{Type: tea.KeyCtrlC}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			overflow := &agent.ContextOverflowError{SessionID: "session", Content: "a very long prompt"}
+			a := appModel{
+				contextOverflowDialog: dialog.NewContextOverflowDialogCmp(),
+				filepicker:            dialog.NewFilepickerCmp(nil),
+			}
+			a.contextOverflowDialog.SetOverflow(overflow)
+			a.showContextOverflowDialog = true
+
+			restored := restoredPrompt(a, tt.key)
+			require.NotNil(t, restored)
+			assert.Equal(t, overflow.Content, restored.Text)
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/export"
+	"github.com/opencode-ai/opencode/internal/format"
+	"github.com/opencode-ai/opencode/internal/history"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/opencode-ai/opencode/internal/session"
+)
+
+// Reasons a summary is posted.
+const (
+	ReasonEnded     = "ended"
+	ReasonRequested = "requested"
+)
+
+// maxSummaryBytes caps the final assistant message sent as the summary.
+const maxSummaryBytes = 2000
+
+// Summary is the JSON body posted to the webhook.
+type Summary struct {
+	Reason           string        `json:"reason"`
+	SessionID        string        `json:"sessionId"`
+	Title            string        `json:"title"`
+	Summary          string        `json:"summary"`
+	Project          string        `json:"project"`
+	FilesChanged     []FileChanged `json:"filesChanged"`
+	PromptTokens     int64         `json:"promptTokens"`
+	CompletionTokens int64         `json:"completionTokens"`
+	Cost             float64       `json:"cost"`
+	StartedAt        time.Time     `json:"startedAt"`
+	EndedAt          time.Time     `json:"endedAt"`
+	DurationSeconds  int64         `json:"durationSeconds"`
+}
+
+// FileChanged is a file the session changed, relative to the working
+// directory when it is inside it.
+type FileChanged struct {
+	Path      string `json:"path"`
+	Additions int    `json:"additions"`
+	Removals  int    `json:"removals"`
+}
+
+// Notifier posts session summaries to the configured webhook. Posting never
+// blocks the caller; failures are only logged.
+type Notifier struct {
+	sessions session.Service
+	messages message.Service
+	files    history.Service
+
+	mu     sync.Mutex
+	active map[string]bool
+	wg     sync.WaitGroup
+}
+
+func NewNotifier(sessions session.Service, messages message.Service, files history.Service) *Notifier {
+	return &Notifier{
+		sessions: sessions,
+		messages: messages,
+		files:    files,
+		active:   make(map[string]bool),
+	}
+}
+
+// Enabled reports whether a webhook URL is configured.
+func (n *Notifier) Enabled() bool {
+	cfg := config.Get()
+	return cfg != nil && cfg.Webhook.URL != ""
+}
+
+// MarkActive records that the session had activity since its last summary,
+// so a summary is posted when it ends.
+func (n *Notifier) MarkActive(sessionID string) {
+	if !n.Enabled() || sessionID == "" {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.active[sessionID] = true
+}
+
+// SessionEnded posts a summary of the session if it had activity since its
+// last summary.
+func (n *Notifier) SessionEnded(sessionID string) {
+	n.mu.Lock()
+	active := n.active[sessionID]
+	delete(n.active, sessionID)
+	n.mu.Unlock()
+
+	if active {
+		n.post(sessionID, ReasonEnded)
+	}
+}
+
+// Post posts a summary of the session now, whether or not it had activity.
+func (n *Notifier) Post(sessionID string) {
+	n.mu.Lock()
+	delete(n.active, sessionID)
+	n.mu.Unlock()
+
+	n.post(sessionID, ReasonRequested)
+}
+
+// Shutdown ends every active session and waits for the pending posts, for at
+// most the configured timeout.
+func (n *Notifier) Shutdown() {
+	n.mu.Lock()
+	sessionIDs := make([]string, 0, len(n.active))
+	for id := range n.active {
+		sessionIDs = append(sessionIDs, id)
+	}
+	n.mu.Unlock()
+
+	for _, id := range sessionIDs {
+		n.SessionEnded(id)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		n.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout()):
+		logging.Warn("Timed out waiting for session summaries to be posted")
+	}
+}
+
+func (n *Notifier) post(sessionID, reason string) {
+	if !n.Enabled() || sessionID == "" {
+		return
+	}
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		defer logging.RecoverPanic("webhook", nil)
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout())
+		defer cancel()
+		if err := n.send(ctx, sessionID, reason); err != nil {
+			logging.Warn("Failed to post session summary", "session_id", sessionID, "error", err)
+		}
+	}()
+}
+
+func (n *Notifier) send(ctx context.Context, sessionID, reason string) error {
+	sess, err := n.sessions.Get(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to load session: %w", err)
+	}
+	// Summaries of sub-agent sessions are part of their parent session.
+	if sess.ParentSessionID != "" {
+		return nil
+	}
+	changes, err := history.SessionChanges(ctx, n.files, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to load changed files: %w", err)
+	}
+	msgs, err := n.messages.List(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to load messages: %w", err)
+	}
+
+	body, err := json.Marshal(newSummary(sess, changes, finalResponse(msgs), reason, redactor()))
+	if err != nil {
+		return err
+	}
+
+	cfg := config.Get()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "opencode/1.0")
+	for name, value := range cfg.Webhook.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	logging.Debug("Posted session summary", "session_id", sessionID, "reason", reason)
+	return nil
+}
+
+// finalResponse returns the text of the last assistant message that has any,
+// which is how the run ended.
+func finalResponse(msgs []message.Message) string {
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Role != message.Assistant {
+			continue
+		}
+		if text := strings.TrimSpace(msgs[i].Content().Text); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+func newSummary(sess session.Session, changes []history.FileChange, response, reason string, r *export.Redactor) Summary {
+	files := make([]FileChanged, 0, len(changes))
+	for _, change := range changes {
+		files = append(files, FileChanged{
+			Path:      r.Redact(change.Path),
+			Additions: change.Additions,
+			Removals:  change.Removals,
+		})
+	}
+	startedAt, endedAt := time.Unix(sess.CreatedAt, 0), time.Unix(sess.UpdatedAt, 0)
+	return Summary{
+		Reason:           reason,
+		SessionID:        sess.ID,
+		Title:            r.Redact(sess.Title),
+		Summary:          format.Truncate(r.Redact(response), maxSummaryBytes, "[truncated]"),
+		Project:          filepath.Base(config.WorkingDirectory()),
+		FilesChanged:     files,
+		PromptTokens:     sess.PromptTokens,
+		CompletionTokens: sess.CompletionTokens,
+		Cost:             sess.Cost,
+		StartedAt:        startedAt.UTC(),
+		EndedAt:          endedAt.UTC(),
+		DurationSeconds:  int64(endedAt.Sub(startedAt).Seconds()),
+	}
+}
+
+// redactor masks the configured API keys and webhook headers along with
+// absolute paths and anything that looks like a secret.
+func redactor() *export.Redactor {
+	cfg := config.Get()
+	var secrets []string
+	for _, provider := range cfg.Providers {
+		secrets = append(secrets, provider.APIKey)
+	}
+	for _, value := range cfg.Webhook.Headers {
+		secrets = append(secrets, value)
+	}
+	homeDir, _ := os.UserHomeDir()
+	return export.NewRedactor(config.WorkingDirectory(), homeDir, secrets)
+}
+
+func timeout() time.Duration {
+	seconds := config.Get().Webhook.TimeoutSeconds
+	if seconds <= 0 {
+		seconds = 5
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,24 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFinalResponse(t *testing.T) {
+	text := func(role message.MessageRole, content string) message.Message {
+		return message.Message{Role: role, Parts: []message.ContentPart{message.TextContent{Text: content}}}
+	}
+	msgs := []message.Message{
+		text(message.User, "Fix the parser."),
+		text(message.Assistant, "Looking at the parser."),
+		{Role: message.Assistant, Parts: []message.ContentPart{message.ToolCall{ID: "1", Name: "edit"}}},
+		text(message.Assistant, "Fixed the off-by-one in the tokenizer.\n"),
+		text(message.Assistant, "  "),
+		text(message.User, "Thanks."),
+	}
+	assert.Equal(t, "Fixed the off-by-one in the tokenizer.", finalResponse(msgs))
+	assert.Empty(t, finalResponse(msgs[:1]))
+}